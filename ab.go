@@ -0,0 +1,332 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ABConfig configures `wind ab`, which keeps the last good build running next
+// to the newest one behind a switchable proxy
+type ABConfig struct {
+	// Port is the public port the proxy listens on
+	Port int
+	// OldPort and NewPort are handed to the two instances via PortEnv
+	OldPort int
+	NewPort int
+	PortEnv string
+	// HealthPath turns on health-based routing: both instances are polled
+	// every HealthInterval, and while the selected one answers 500 or more,
+	// or not at all, the proxy falls back to the other one
+	HealthPath     string
+	HealthInterval time.Duration
+}
+
+// abSlot is one of the two side-by-side instances
+type abSlot struct {
+	name   string
+	port   int
+	binary string
+	// exit waits for the running instance, nil while none runs
+	exit *processExit
+	// healthy is the result of the latest health check; good is set once
+	// the instance passed the check after its start
+	healthy atomic.Bool
+	good    bool
+}
+
+// abMode supervises the old/new instance pair and the proxy in front of them
+type abMode struct {
+	config ABConfig
+	old    *abSlot
+	new    *abSlot
+	// app starts and stops the instances as it does its own process
+	app *WindApp
+
+	// useNew selects which instance the proxy forwards to by default
+	useNew atomic.Bool
+	mutex  sync.Mutex
+	server *http.Server
+	// done stops the health checks
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func newABMode(config ABConfig) *abMode {
+	ab := &abMode{
+		config: config,
+		old:    &abSlot{name: "old", port: config.OldPort, binary: filepath.Join("tmp", "ab", "old")},
+		new:    &abSlot{name: "new", port: config.NewPort, binary: filepath.Join("tmp", "ab", "new")},
+		done:   make(chan struct{}),
+	}
+	ab.useNew.Store(true)
+	return ab
+}
+
+// start launches the proxy on the public port
+func (ab *abMode) start() error {
+	if err := os.MkdirAll(filepath.Join("tmp", "ab"), 0755); err != nil {
+		return err
+	}
+
+	ab.server = &http.Server{
+		Addr:    fmt.Sprintf(":%d", ab.config.Port),
+		Handler: ab.proxy(),
+	}
+
+	go func() {
+		if err := ab.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logf(levelError, Red+"Error: "+Reset+"A/B proxy failed: %v\n", err)
+		}
+	}()
+
+	logf(levelInfo, Cyan+"Info: "+Reset+"A/B proxy on http://localhost:%d (old → :%d, new → :%d)\n",
+		ab.config.Port, ab.config.OldPort, ab.config.NewPort)
+	logf(levelInfo, Cyan+"Info: "+Reset+"Press s to switch, or send header X-Wind-AB: old|new\n")
+	if ab.config.HealthPath != "" {
+		go ab.watchHealth()
+		logf(levelInfo, Cyan+"Info: "+Reset+"Routing to the healthy build (%s every %s)\n", ab.config.HealthPath, ab.config.HealthInterval)
+	}
+	return nil
+}
+
+// proxy forwards to the selected instance. The X-Wind-AB request header
+// overrides the selection for a single request.
+func (ab *abMode) proxy() http.Handler {
+	return &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			slot := ab.route()
+			switch r.In.Header.Get("X-Wind-AB") {
+			case "old":
+				slot = ab.old
+			case "new":
+				slot = ab.new
+			}
+			r.Out.Header.Del("X-Wind-AB")
+			r.SetURL(&url.URL{Scheme: "http", Host: fmt.Sprintf("127.0.0.1:%d", slot.port)})
+			r.SetXForwarded()
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			resp.Header.Set("X-Wind-AB", ab.slotForPort(resp.Request.URL.Port()))
+			return nil
+		},
+	}
+}
+
+func (ab *abMode) selected() *abSlot {
+	if ab.useNew.Load() {
+		return ab.new
+	}
+	return ab.old
+}
+
+// other returns the instance that is not slot
+func (ab *abMode) other(slot *abSlot) *abSlot {
+	if slot == ab.new {
+		return ab.old
+	}
+	return ab.new
+}
+
+// route returns the instance the proxy forwards to: the selected one, unless
+// health-based routing is on and only the other one is healthy
+func (ab *abMode) route() *abSlot {
+	slot := ab.selected()
+	if ab.config.HealthPath == "" || slot.healthy.Load() {
+		return slot
+	}
+	if other := ab.other(slot); other.healthy.Load() {
+		return other
+	}
+	return slot
+}
+
+// checkHealth probes both instances once
+func (ab *abMode) checkHealth() {
+	for _, slot := range []*abSlot{ab.old, ab.new} {
+		healthy := probeHTTP(fmt.Sprintf("http://127.0.0.1:%d%s", slot.port, ab.config.HealthPath))()
+		if slot.healthy.Swap(healthy) && !healthy {
+			logf(levelWarn, Yellow+"Warning: "+Reset+"A/B %s build (:%d) is unhealthy\n", slot.name, slot.port)
+		}
+	}
+}
+
+// watchHealth checks both instances every HealthInterval and reports when
+// the proxy's traffic moves to the other one
+func (ab *abMode) watchHealth() {
+	ticker := time.NewTicker(ab.config.HealthInterval)
+	defer ticker.Stop()
+	serving := ab.route()
+	for {
+		select {
+		case <-ab.done:
+			return
+		case <-ticker.C:
+		}
+		ab.checkHealth()
+		if slot := ab.route(); slot != serving {
+			serving = slot
+			logf(levelInfo, Cyan+"Info: "+Reset+"A/B proxy now serving %s build (:%d)\n", slot.name, slot.port)
+		}
+	}
+}
+
+func (ab *abMode) slotForPort(port string) string {
+	if port == fmt.Sprint(ab.old.port) {
+		return ab.old.name
+	}
+	return ab.new.name
+}
+
+// toggle switches the proxy between the old and new instance
+func (ab *abMode) toggle() {
+	ab.useNew.Store(!ab.useNew.Load())
+	slot := ab.selected()
+	if route := ab.route(); route != slot {
+		logf(levelWarn, Yellow+"Warning: "+Reset+"A/B proxy selected %s build (:%d), which is unhealthy; serving %s until it recovers\n", slot.name, slot.port, route.name)
+		return
+	}
+	logf(levelInfo, Cyan+"Info: "+Reset+"A/B proxy now serving %s build (:%d)\n", slot.name, slot.port)
+}
+
+// deploy starts the freshly built binary in the new slot with the given
+// environment. The build running there is promoted to the old slot only if
+// it passed its health check, so the old slot always runs the last good
+// build.
+func (ab *abMode) deploy(built string, env []string) error {
+	ab.mutex.Lock()
+	defer ab.mutex.Unlock()
+
+	if ab.new.good {
+		ab.stopSlot(ab.old)
+		ab.stopSlot(ab.new)
+		if err := os.Rename(ab.new.binary, ab.old.binary); err != nil {
+			return err
+		}
+		// The promoted build is healthy again once a check passes
+		ab.old.healthy.Store(false)
+		if err := ab.startSlot(ab.old, env); err != nil {
+			return err
+		}
+		ab.old.good = true
+	} else {
+		ab.stopSlot(ab.new)
+	}
+
+	ab.new.healthy.Store(false)
+	ab.new.good = false
+	if err := copyFile(built, ab.new.binary); err != nil {
+		return err
+	}
+	if err := ab.startSlot(ab.new, env); err != nil {
+		return err
+	}
+	if err := ab.waitHealthy(ab.new); err != nil {
+		logf(levelWarn, Yellow+"Warning: "+Reset+"A/B new build (:%d) %v; it will not replace the old build\n", ab.new.port, err)
+		return nil
+	}
+	ab.new.good = true
+	return nil
+}
+
+// waitHealthy waits for the instance in slot to pass the health check, or
+// to accept connections without HealthPath
+func (ab *abMode) waitHealthy(slot *abSlot) error {
+	addr := fmt.Sprintf("127.0.0.1:%d", slot.port)
+	probe := probeTCP(addr)
+	if ab.config.HealthPath != "" {
+		probe = probeHTTP("http://" + addr + ab.config.HealthPath)
+	}
+	if _, err := pollProcessReady(probe, ab.app.config.ReadyTimeout, slot.exit); err != nil {
+		return err
+	}
+	slot.healthy.Store(true)
+	return nil
+}
+
+// startSlot runs the application's run command on the binary of slot
+func (ab *abMode) startSlot(slot *abSlot, env []string) error {
+	app := ab.app
+	command, err := slotCommand(app.config.RunCmd, app.deployBinary(), slot.binary)
+	if err != nil {
+		return err
+	}
+	env = append(env[:len(env):len(env)], fmt.Sprintf("%s=%d", ab.config.PortEnv, slot.port))
+	exit, err := app.startCommand(command, env)
+	if err != nil {
+		return err
+	}
+	slot.exit = exit
+	logf(levelSuccess, Green+"Success: "+Reset+"%s build started on :%d (PID: %d)\n", slot.name, slot.port, exit.process.Pid)
+	return nil
+}
+
+// stopSlot stops the instance in slot like the application's process,
+// with StopSignal and StopTimeout
+func (ab *abMode) stopSlot(slot *abSlot) {
+	if slot.exit == nil {
+		return
+	}
+	ab.app.terminate(slot.exit.process, slot.exit)
+	slot.exit = nil
+}
+
+// slotCommand returns runCmd starting slotBinary in place of binary, the
+// build output
+func slotCommand(runCmd, binary, slotBinary string) (string, error) {
+	for _, field := range strings.Fields(runCmd) {
+		if filepath.Clean(field) == filepath.Clean(binary) {
+			return strings.Replace(runCmd, field, "./"+filepath.ToSlash(slotBinary), 1), nil
+		}
+	}
+	return "", fmt.Errorf("A/B mode needs a run command that starts %s, got %q", binary, runCmd)
+}
+
+// stop shuts down both instances and the proxy; it may be called more than
+// once
+func (ab *abMode) stop() {
+	ab.mutex.Lock()
+	defer ab.mutex.Unlock()
+
+	ab.stopSlot(ab.old)
+	ab.stopSlot(ab.new)
+	ab.stopOnce.Do(func() {
+		close(ab.done)
+		if ab.server != nil {
+			ab.server.Close()
+		}
+	})
+	os.RemoveAll(filepath.Join("tmp", "ab"))
+}
+
+// copyFile copies src to dst preserving the executable bit
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestABProxyRouting(t *testing.T) {
+	backend := func(name string) (*httptest.Server, int) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, name)
+		}))
+		u, _ := url.Parse(srv.URL)
+		port, _ := strconv.Atoi(u.Port())
+		return srv, port
+	}
+
+	oldSrv, oldPort := backend("old")
+	defer oldSrv.Close()
+	newSrv, newPort := backend("new")
+	defer newSrv.Close()
+
+	ab := newABMode(ABConfig{OldPort: oldPort, NewPort: newPort, PortEnv: "PORT"})
+	proxy := httptest.NewServer(ab.proxy())
+	defer proxy.Close()
+
+	get := func(header string) (string, string) {
+		req, _ := http.NewRequest("GET", proxy.URL, nil)
+		if header != "" {
+			req.Header.Set("X-Wind-AB", header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request through proxy failed: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body), resp.Header.Get("X-Wind-AB")
+	}
+
+	// New build is served by default
+	if body, served := get(""); body != "new" || served != "new" {
+		t.Errorf("Expected new instance by default, got body=%q header=%q", body, served)
+	}
+
+	// Header overrides the selection for one request
+	if body, _ := get("old"); body != "old" {
+		t.Errorf("Expected X-Wind-AB: old to reach old instance, got %q", body)
+	}
+
+	ab.toggle()
+	if body, served := get(""); body != "old" || served != "old" {
+		t.Errorf("Expected old instance after toggle, got body=%q header=%q", body, served)
+	}
+}
+
+func TestABHealthRouting(t *testing.T) {
+	var newHealthy atomic.Bool
+	backend := func(name string, healthy func() bool) (*httptest.Server, int) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/healthz" && !healthy() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprint(w, name)
+		}))
+		u, _ := url.Parse(srv.URL)
+		port, _ := strconv.Atoi(u.Port())
+		return srv, port
+	}
+	oldSrv, oldPort := backend("old", func() bool { return true })
+	defer oldSrv.Close()
+	newSrv, newPort := backend("new", newHealthy.Load)
+	defer newSrv.Close()
+
+	ab := newABMode(ABConfig{OldPort: oldPort, NewPort: newPort, HealthPath: "/healthz", HealthInterval: time.Second})
+
+	// Without results yet the selected build is served
+	if slot := ab.route(); slot != ab.new {
+		t.Errorf("Expected the new build before any health check, got %s", slot.name)
+	}
+
+	ab.checkHealth()
+	if slot := ab.route(); slot != ab.old {
+		t.Errorf("Expected a fallback to the old build while the new one is unhealthy, got %s", slot.name)
+	}
+
+	newHealthy.Store(true)
+	ab.checkHealth()
+	if slot := ab.route(); slot != ab.new {
+		t.Errorf("Expected the new build once healthy, got %s", slot.name)
+	}
+
+	// Switching to an unhealthy build keeps serving the healthy one
+	newHealthy.Store(false)
+	ab.toggle()
+	ab.toggle()
+	ab.checkHealth()
+	if slot := ab.route(); slot != ab.old {
+		t.Errorf("Expected the old build while the selected new one is unhealthy, got %s", slot.name)
+	}
+
+	// Without a health path the selection is followed as is
+	ab.config.HealthPath = ""
+	if slot := ab.route(); slot != ab.new {
+		t.Errorf("Expected the selected build without health checks, got %s", slot.name)
+	}
+}
+
+// abServeEnv makes TestABDeploy's child serve HTTP on $PORT like a built
+// application
+const abServeEnv = "WIND_TEST_AB_SERVE"
+
+func TestABDeploy(t *testing.T) {
+	if os.Getenv(abServeEnv) != "" {
+		http.ListenAndServe("127.0.0.1:"+os.Getenv("PORT"), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		return
+	}
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	os.Mkdir("tmp", 0755)
+
+	oldPort, _ := freePort()
+	newPort, _ := freePort()
+	config := defaultConfig()
+	config.BuildCmd = "go build -o ./tmp/main ."
+	config.RunCmd = "exec ./tmp/main -test.run=^TestABDeploy$"
+	config.ReadyTimeout = 5 * time.Second
+	config.StopTimeout = time.Second
+	app := newWindApp(config, "", "")
+	ab := newABMode(ABConfig{OldPort: oldPort, NewPort: newPort, PortEnv: "PORT"})
+	ab.app = app
+	if err := ab.start(); err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	defer ab.stop()
+	env := append(os.Environ(), abServeEnv+"=1")
+
+	good := func() {
+		if err := copyFile(os.Args[0], "tmp/main"); err != nil {
+			t.Fatalf("Failed to copy the test binary: %v", err)
+		}
+	}
+	broken := func() {
+		os.Remove("tmp/main")
+		os.WriteFile("tmp/main", []byte("#!/bin/sh\nexit 1\n"), 0755)
+	}
+
+	good()
+	if err := ab.deploy("tmp/main", env); err != nil || !ab.new.good {
+		t.Fatalf("Expected a healthy first build, got %v", err)
+	}
+	good()
+	if err := ab.deploy("tmp/main", env); err != nil || ab.old.exit == nil || !ab.old.good {
+		t.Fatalf("Expected the healthy build to be promoted, got %v", err)
+	}
+
+	// A build that never became healthy is not promoted over the old one
+	broken()
+	if err := ab.deploy("tmp/main", env); err != nil || ab.new.good {
+		t.Fatalf("Expected the broken build to be reported unhealthy, got %v", err)
+	}
+	lastGood := ab.old.exit.process.Pid
+	good()
+	if err := ab.deploy("tmp/main", env); err != nil {
+		t.Fatalf("deploy failed: %v", err)
+	}
+	if ab.old.exit == nil || ab.old.exit.process.Pid != lastGood {
+		t.Error("Expected the old slot to keep running the last good build")
+	}
+
+	// Stopping twice, as cleanup after an error may, must not panic
+	ab.stop()
+}
+
+func TestSlotCommand(t *testing.T) {
+	got, err := slotCommand("./tmp/main --port 9090", "tmp/main", "tmp/ab/new")
+	if err != nil || got != "./tmp/ab/new --port 9090" {
+		t.Errorf("Expected the slot binary to replace the build output, got %q (%v)", got, err)
+	}
+	if _, err := slotCommand("go run .", "tmp/main", "tmp/ab/new"); err == nil {
+		t.Error("Expected an error for a run command without the binary")
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiChangelogFile collects the API changes of a session, for writing PR
+// descriptions
+const apiChangelogFile = "tmp/api-changes.md"
+
+// openAPIMethods are the operations of an OpenAPI path item
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// apiSurface maps each element of an API, such as "GET /users/{id}" or
+// "Query.users", to a canonical form of its definition, so two versions of a
+// schema can be compared element by element
+type apiSurface map[string]string
+
+// loadAPISurface reads an OpenAPI document (JSON or YAML) or a GraphQL
+// schema, chosen by extension
+func loadAPISurface(path string) (apiSurface, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".graphql", ".graphqls", ".gql":
+		return graphQLSurface(string(data)), nil
+	case ".json":
+		var doc map[string]any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		return openAPISurface(doc), nil
+	default:
+		doc, err := parseYAML(data)
+		if err != nil {
+			return nil, err
+		}
+		return openAPISurface(doc), nil
+	}
+}
+
+// canonical renders a decoded value with sorted keys
+func canonical(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// openAPISurface lists the operations and component schemas of an OpenAPI
+// document. Parameters declared on a path apply to each of its operations.
+func openAPISurface(doc map[string]any) apiSurface {
+	surface := apiSurface{}
+	paths, _ := doc["paths"].(map[string]any)
+	for path, item := range paths {
+		ops, _ := item.(map[string]any)
+		for _, method := range openAPIMethods {
+			op, ok := ops[method]
+			if !ok {
+				continue
+			}
+			surface[strings.ToUpper(method)+" "+path] = canonical([]any{ops["parameters"], op})
+		}
+	}
+	components, _ := doc["components"].(map[string]any)
+	schemas, _ := components["schemas"].(map[string]any)
+	for name, schema := range schemas {
+		surface["schema "+name] = canonical(schema)
+	}
+	return surface
+}
+
+var (
+	graphQLComment     = regexp.MustCompile(`(?m)#.*$`)
+	graphQLDescription = regexp.MustCompile(`(?s)""".*?"""|(?m:^\s*"(?:[^"\\\n]|\\.)*"\s*$)`)
+	graphQLBlock       = regexp.MustCompile(`(?s)\b(type|input|interface|enum)\s+(\w+)[^{]*\{(.*?)\}`)
+	graphQLSingle      = regexp.MustCompile(`(?m)^\s*(scalar|union)\s+(\w+)(.*)$`)
+	graphQLSpace       = regexp.MustCompile(`\s+`)
+)
+
+// graphQLSurface lists the fields, input fields and enum values of a GraphQL
+// schema as Type.field, plus its scalars and unions. Descriptions and
+// comments are not part of the surface.
+func graphQLSurface(schema string) apiSurface {
+	schema = graphQLDescription.ReplaceAllString(schema, "")
+	schema = graphQLComment.ReplaceAllString(schema, "")
+
+	surface := apiSurface{}
+	for _, m := range graphQLBlock.FindAllStringSubmatch(schema, -1) {
+		for _, field := range graphQLFields(m[3]) {
+			name := field
+			if i := strings.IndexAny(field, "(: @"); i >= 0 {
+				name = field[:i]
+			}
+			surface[m[2]+"."+name] = field
+		}
+	}
+	for _, m := range graphQLSingle.FindAllStringSubmatch(schema, -1) {
+		surface[m[1]+" "+m[2]] = graphQLSpace.ReplaceAllString(strings.TrimSpace(m[3]), " ")
+	}
+	return surface
+}
+
+// graphQLFields splits the body of a type into its fields, which may span
+// lines inside argument lists
+func graphQLFields(body string) []string {
+	var fields []string
+	var current strings.Builder
+	depth := 0
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if current.Len() > 0 {
+			current.WriteByte(' ')
+		}
+		current.WriteString(line)
+		depth += strings.Count(line, "(") - strings.Count(line, ")")
+		if depth <= 0 {
+			fields = append(fields, graphQLSpace.ReplaceAllString(strings.TrimRight(current.String(), ","), " "))
+			current.Reset()
+			depth = 0
+		}
+	}
+	return fields
+}
+
+// apiDiff is how an API surface changed between two builds
+type apiDiff struct {
+	added, removed, changed []string
+}
+
+func (d apiDiff) empty() bool {
+	return len(d.added)+len(d.removed)+len(d.changed) == 0
+}
+
+// diffAPISurface compares two versions of an API
+func diffAPISurface(old, new apiSurface) apiDiff {
+	var d apiDiff
+	for key, def := range new {
+		previous, ok := old[key]
+		switch {
+		case !ok:
+			d.added = append(d.added, key)
+		case previous != def:
+			d.changed = append(d.changed, key)
+		}
+	}
+	for key := range old {
+		if _, ok := new[key]; !ok {
+			d.removed = append(d.removed, key)
+		}
+	}
+	sort.Strings(d.added)
+	sort.Strings(d.removed)
+	sort.Strings(d.changed)
+	return d
+}
+
+// formatAPIDiff renders the changes of one schema as a markdown section
+func formatAPIDiff(schema string, d apiDiff) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### %s\n\n", schema)
+	for _, group := range []struct {
+		verb string
+		keys []string
+	}{{"Added", d.added}, {"Removed", d.removed}, {"Changed", d.changed}} {
+		for _, key := range group.keys {
+			fmt.Fprintf(&b, "- %s `%s`\n", group.verb, key)
+		}
+	}
+	return b.String()
+}
+
+// apiTracker follows the APISchemas across the rebuilds of every target of
+// a session and appends their changes to apiChangelogFile
+type apiTracker struct {
+	mutex    sync.Mutex
+	schemas  []string
+	surfaces map[string]apiSurface
+	started  time.Time
+}
+
+// newAPITracker records the current version of every schema and starts a
+// new changelog for the session
+func newAPITracker(schemas []string) *apiTracker {
+	t := &apiTracker{schemas: schemas, surfaces: make(map[string]apiSurface), started: time.Now()}
+	for _, schema := range schemas {
+		surface, err := loadAPISurface(schema)
+		if err != nil {
+			logf(levelWarn, Yellow+"Warning: "+Reset+"Failed to read API schema %s: %v\n", schema, err)
+			continue
+		}
+		t.surfaces[schema] = surface
+	}
+	os.Remove(apiChangelogFile)
+	return t
+}
+
+// watches reports whether path is one of the schemas, which are watched
+// whatever their extension
+func (t *apiTracker) watches(path string) bool {
+	path = projectPath(path)
+	for _, schema := range t.schemas {
+		if projectPath(schema) == path {
+			return true
+		}
+	}
+	return false
+}
+
+// check compares every schema with its version at the previous build and
+// appends the differences to the changelog under build
+func (t *apiTracker) check(build int, label string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var sections []string
+	var added, removed, changed int
+	for _, schema := range t.schemas {
+		surface, err := loadAPISurface(schema)
+		if err != nil {
+			logf(levelWarn, Yellow+"Warning: "+Reset+"%sFailed to read API schema %s: %v\n", label, schema, err)
+			continue
+		}
+		previous, known := t.surfaces[schema]
+		t.surfaces[schema] = surface
+		if !known {
+			continue
+		}
+		d := diffAPISurface(previous, surface)
+		if d.empty() {
+			continue
+		}
+		sections = append(sections, formatAPIDiff(schema, d))
+		added, removed, changed = added+len(d.added), removed+len(d.removed), changed+len(d.changed)
+	}
+	if len(sections) == 0 {
+		return
+	}
+
+	if err := appendAPIChangelog(t.started, build, time.Now(), sections); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%sFailed to write %s: %v\n", label, apiChangelogFile, err)
+		return
+	}
+	logf(levelInfo, Cyan+"Info: "+Reset+"%sAPI changed: %d added, %d removed, %d changed (%s)\n", label, added, removed, changed, apiChangelogFile)
+}
+
+// appendAPIChangelog adds the sections of a build to the changelog of the
+// session started at started, beginning it with a title
+func appendAPIChangelog(started time.Time, build int, at time.Time, sections []string) error {
+	file, err := os.OpenFile(apiChangelogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if info, err := file.Stat(); err == nil && info.Size() == 0 {
+		fmt.Fprintf(file, "# API changes\n\nChanges made to the API during the Wind session started %s.\n", started.Format("2006-01-02 15:04"))
+	}
+	_, err = fmt.Fprintf(file, "\n## Build #%d (%s)\n\n%s", build, at.Format("15:04:05"), strings.Join(sections, "\n"))
+	return err
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPISurface(t *testing.T) {
+	doc, err := parseYAML([]byte(`openapi: 3.0.0
+paths:
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+    get:
+      responses:
+        "200":
+          description: ok
+    delete:
+      responses:
+        "204":
+          description: gone
+components:
+  schemas:
+    User:
+      type: object
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	surface := openAPISurface(doc)
+	diff := diffAPISurface(nil, surface)
+	if expected := []string{"DELETE /users/{id}", "GET /users/{id}", "schema User"}; !reflect.DeepEqual(diff.added, expected) {
+		t.Errorf("Expected %v, got %v", expected, diff.added)
+	}
+	if !strings.Contains(surface["GET /users/{id}"], `"in":"path"`) {
+		t.Errorf("Expected path parameters in the operation, got %s", surface["GET /users/{id}"])
+	}
+}
+
+func TestGraphQLSurface(t *testing.T) {
+	surface := graphQLSurface(`
+"""The root query"""
+type Query {
+  "Users by signup date"
+  # Lists users
+  users(
+    first: Int
+    after: String
+  ): [User!]!
+  user(id: ID!): User @deprecated(reason: "use node")
+}
+
+enum Role { ADMIN
+  MEMBER }
+
+scalar Date
+union SearchResult = User | Post
+`)
+	expected := apiSurface{
+		"Query.users":        "users( first: Int after: String ): [User!]!",
+		"Query.user":         `user(id: ID!): User @deprecated(reason: "use node")`,
+		"Role.ADMIN":         "ADMIN",
+		"Role.MEMBER":        "MEMBER",
+		"scalar Date":        "",
+		"union SearchResult": "= User | Post",
+	}
+	if !reflect.DeepEqual(surface, expected) {
+		t.Errorf("graphQLSurface() = %q, expected %q", surface, expected)
+	}
+}
+
+func TestDiffAPISurface(t *testing.T) {
+	old := apiSurface{"GET /users": "a", "POST /users": "b", "DELETE /users/{id}": "c"}
+	new := apiSurface{"GET /users": "a", "POST /users": "b2", "GET /orders": "d"}
+
+	d := diffAPISurface(old, new)
+	if !reflect.DeepEqual(d.added, []string{"GET /orders"}) || !reflect.DeepEqual(d.removed, []string{"DELETE /users/{id}"}) || !reflect.DeepEqual(d.changed, []string{"POST /users"}) {
+		t.Errorf("Unexpected diff %+v", d)
+	}
+	if !diffAPISurface(old, old).empty() {
+		t.Error("Expected no changes between equal surfaces")
+	}
+
+	expected := "### api.yaml\n\n- Added `GET /orders`\n- Removed `DELETE /users/{id}`\n- Changed `POST /users`\n"
+	if got := formatAPIDiff("api.yaml", d); got != expected {
+		t.Errorf("formatAPIDiff() = %q, expected %q", got, expected)
+	}
+}
+
+func TestAPITracker(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	os.Mkdir("tmp", 0755)
+
+	os.WriteFile("schema.graphql", []byte("type Query {\n  users: [User]\n}\n"), 0644)
+	tracker := newAPITracker([]string{"schema.graphql"})
+	if !tracker.watches("./schema.graphql") {
+		t.Error("Expected the schema to be watched")
+	}
+
+	// An unchanged schema writes nothing
+	tracker.check(1, "")
+	if _, err := os.Stat(apiChangelogFile); !os.IsNotExist(err) {
+		t.Fatalf("Expected no changelog without changes, got %v", err)
+	}
+
+	os.WriteFile("schema.graphql", []byte("type Query {\n  users(first: Int): [User]\n  orders: [Order]\n}\n"), 0644)
+	tracker.check(2, "")
+	tracker.check(3, "")
+	data, err := os.ReadFile(apiChangelogFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := string(data)
+	if !strings.HasPrefix(log, "# API changes\n") || !strings.Contains(log, "## Build #2 (") ||
+		!strings.Contains(log, "- Added `Query.orders`\n- Changed `Query.users`\n") {
+		t.Errorf("Unexpected changelog:\n%s", log)
+	}
+	if strings.Contains(log, "Build #3") {
+		t.Errorf("Expected only builds with changes, got:\n%s", log)
+	}
+
+	// A new session starts a new changelog
+	newAPITracker([]string{"schema.graphql"})
+	if _, err := os.Stat(apiChangelogFile); !os.IsNotExist(err) {
+		t.Errorf("Expected the previous session's changelog to be removed, got %v", err)
+	}
+}
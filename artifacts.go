@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+)
+
+// windOutputs are the files and directories Wind itself writes in the
+// project. They are under tmp/, which is excluded by default, but stay
+// unwatched when excludeDirs no longer lists it.
+var windOutputs = []string{buildLogDir, screenshotDir, stateFile, daemonPidFile, daemonSocket, daemonLog, observeSocket, rollbackDir}
+
+// buildOutputs returns the -o targets of a build command, which may chain
+// several commands
+func buildOutputs(buildCmd string) []string {
+	var outputs []string
+	fields := strings.Fields(buildCmd)
+	for i, field := range fields {
+		switch {
+		case field == "-o" && i+1 < len(fields):
+			outputs = append(outputs, fields[i+1])
+		case strings.HasPrefix(field, "-o="):
+			outputs = append(outputs, strings.TrimPrefix(field, "-o="))
+		}
+	}
+	return outputs
+}
+
+// shareArtifacts gives every target the build outputs of all targets plus
+// Wind's own files and ArtifactDirs, so no build retriggers itself or
+// another target
+func shareArtifacts(apps []*WindApp) {
+	seen := map[string]bool{}
+	var artifacts []string
+	add := func(path string) {
+		path = projectPath(strings.Trim(path, `"'`))
+		if path == "." || seen[path] {
+			return
+		}
+		seen[path] = true
+		artifacts = append(artifacts, path)
+	}
+
+	for _, path := range windOutputs {
+		add(path)
+	}
+	for _, app := range apps {
+		for _, path := range buildOutputs(app.config.BuildCmd) {
+			add(path)
+		}
+		for _, path := range app.config.ArtifactDirs {
+			add(path)
+		}
+		if app.config.LogFile.Path != "" {
+			add(app.config.LogFile.Path)
+		}
+	}
+	for _, app := range apps {
+		app.artifacts = artifacts
+	}
+}
+
+// isArtifact reports whether the normalized path is or lies inside a build
+// output
+func isArtifact(path string, artifacts []string) bool {
+	for _, artifact := range artifacts {
+		if underDir(path, artifact) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBuildOutputs(t *testing.T) {
+	tests := map[string][]string{
+		"go build -o ./tmp/main .":                                {"./tmp/main"},
+		"go build -o=bin/api ./cmd/api && go build -o bin/ ./...": {"bin/api", "bin/"},
+		"make build": nil,
+	}
+	for cmd, want := range tests {
+		if got := buildOutputs(cmd); !reflect.DeepEqual(got, want) {
+			t.Errorf("buildOutputs(%q) = %v, want %v", cmd, got, want)
+		}
+	}
+}
+
+func TestArtifactsAreNotWatched(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"main.go", "bin/api.json", "worker/out/data.json", "dist/app.js", "web/app.js"} {
+		path := filepath.Join(tmpDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("x"), 0644)
+	}
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	config := defaultConfig()
+	config.ExcludeDirs = nil
+	config.BuildCmd = "go build -o bin/ ./cmd/api"
+	config.ArtifactDirs = []string{"dist"}
+	api := newWindApp(config, "api", "")
+	worker := newWindApp(defaultConfig(), "worker", "")
+	worker.config.BuildCmd = "go build -o ./worker/out ./cmd/worker"
+	shareArtifacts([]*WindApp{api, worker})
+
+	for _, app := range []*WindApp{api, worker} {
+		app.scanFiles()
+		for _, path := range []string{"bin/api.json", "worker/out/data.json", "dist/app.js"} {
+			if _, ok := app.fileStates[path]; ok {
+				t.Errorf("%s: expected the build output %s not to be watched", app.name, path)
+			}
+		}
+		if _, ok := app.fileStates["web/app.js"]; !ok {
+			t.Errorf("%s: expected web/app.js to be watched", app.name)
+		}
+		if app.shouldWatch(buildLogDir + "/1.json") {
+			t.Errorf("%s: expected Wind's own build logs not to be watched", app.name)
+		}
+	}
+}
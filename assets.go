@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// matchesAssets reports whether path matches an AssetsGlobs pattern, so
+// assets are watched even when their extension is not in IncludeExts
+func (app *WindApp) matchesAssets(path string) bool {
+	for _, pattern := range app.config.AssetsGlobs {
+		if matchPattern(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// onlyAssetsChanged reports whether every file changed in this cycle
+// matches an AssetsGlobs pattern
+func (app *WindApp) onlyAssetsChanged() bool {
+	if len(app.config.AssetsGlobs) == 0 || len(app.changedFiles) == 0 {
+		return false
+	}
+	for _, path := range app.changedFiles {
+		if !app.matchesAssets(path) {
+			return false
+		}
+	}
+	return true
+}
+
+// reloadAssets is the fast path for static assets the app serves from disk:
+// when only they changed, nothing is rebuilt. The browsers reload, or the
+// app restarts with AssetsRestart. It reports whether the fast path applied.
+func (app *WindApp) reloadAssets() bool {
+	if !app.onlyAssetsChanged() {
+		return false
+	}
+
+	files := make([]string, len(app.changedFiles))
+	for i, path := range app.changedFiles {
+		files[i] = filepath.ToSlash(path)
+	}
+	switch {
+	case app.config.AssetsRestart:
+		logf(levelInfo, Cyan+"Info: "+Reset+"%sOnly assets changed (%s), restarting without rebuild\n", app.label(), describeChanged(files))
+		app.restartProcess()
+	case app.liveReload != nil:
+		n := app.liveReload.broadcast()
+		logf(levelSuccess, Green+"Success: "+Reset+"%sOnly assets changed (%s), reloaded %d browser tab(s) without rebuild\n", app.label(), describeChanged(files), n)
+	default:
+		logf(levelInfo, Cyan+"Info: "+Reset+"%sOnly assets changed (%s), skipping rebuild\n", app.label(), describeChanged(files))
+	}
+	return true
+}
+
+// validateAssetsGlobs checks the AssetsGlobs patterns
+func validateAssetsGlobs(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid assetsGlobs pattern %q", pattern)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import "testing"
+
+func TestOnlyAssetsChanged(t *testing.T) {
+	app := newWindApp(WindConfig{AssetsGlobs: []string{"web/static/**", "*.css"}}, "", "")
+	tests := []struct {
+		changed  []string
+		expected bool
+	}{
+		{[]string{"web/static/js/app.js"}, true},
+		{[]string{"web/static/js/app.js", "theme.css"}, true},
+		{[]string{"web/static/js/app.js", "main.go"}, false},
+		{[]string{"web/templates/index.html"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		app.changedFiles = tt.changed
+		if got := app.onlyAssetsChanged(); got != tt.expected {
+			t.Errorf("onlyAssetsChanged(%v) = %v, expected %v", tt.changed, got, tt.expected)
+		}
+	}
+
+	if !app.shouldWatch("web/static/js/app.js") {
+		t.Error("Expected assets to be watched whatever their extension")
+	}
+}
+
+func TestReloadAssets(t *testing.T) {
+	lr, err := newLiveReload(LiveReloadConfig{AppURL: "http://127.0.0.1:1"})
+	if err != nil {
+		t.Fatalf("newLiveReload failed: %v", err)
+	}
+	browser := make(chan string, 1)
+	lr.clients[browser] = true
+
+	app := newWindApp(WindConfig{AssetsGlobs: []string{"*.css"}}, "", "")
+	app.liveReload = lr
+	app.changedFiles = []string{"main.go", "theme.css"}
+	if app.reloadAssets() {
+		t.Fatal("Expected a Go change to take the regular path")
+	}
+
+	app.changedFiles = []string{"theme.css"}
+	if !app.reloadAssets() {
+		t.Fatal("Expected the fast path for an asset change")
+	}
+	select {
+	case ev := <-browser:
+		if ev != "reload" {
+			t.Errorf("Expected a reload event, got %q", ev)
+		}
+	default:
+		t.Error("Expected the browsers to reload")
+	}
+}
+
+func TestValidateAssetsGlobs(t *testing.T) {
+	if err := validateAssetsGlobs([]string{"web/static/**", "*.css"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := validateAssetsGlobs([]string{"["}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
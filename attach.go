@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// observeSocket serves a read-only view of a session to `wind attach`. It
+// accepts the owner's group, so a teammate pairing on the machine can
+// watch without being able to rebuild or stop anything.
+const observeSocket = "tmp/wind-observe.sock"
+
+// observeClient reads from a session's observeSocket
+var observeClient = socketClient(observeSocket)
+
+// readOnlyHandler serves only the endpoints that observe the session
+func (c *controlAPI) readOnlyHandler() http.Handler {
+	mux := http.NewServeMux()
+	c.observeRoutes(mux)
+	return mux
+}
+
+// listenReadOnly serves the read-only endpoints on observeSocket in the
+// background
+func (c *controlAPI) listenReadOnly() error {
+	// A socket left behind by a crashed session would block the listen
+	os.Remove(observeSocket)
+	c.observer = &http.Server{Handler: c.readOnlyHandler()}
+	if err := serveUnix(c.observer, observeSocket); err != nil {
+		return err
+	}
+	return os.Chmod(observeSocket, 0660)
+}
+
+// runAttach implements `wind attach`: it prints the state of the session
+// running in this project, then follows its output until it exits
+func runAttach() {
+	resp, err := observeClient.Get("http://wind/status")
+	if err != nil {
+		logf(levelInfo, Cyan+"Info: "+Reset+"No attachable Wind session (start one with wind daemon, or set observe: true in %s)\n", configFileName)
+		return
+	}
+	var status struct{ Targets []appStatus }
+	err = json.NewDecoder(resp.Body).Decode(&status)
+	resp.Body.Close()
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Invalid status response: %v\n", err)
+		return
+	}
+	fmt.Printf(Green + "Attached" + Reset + " (read-only, Ctrl+C to detach)\n")
+	for _, s := range status.Targets {
+		fmt.Println("  " + fitLine(formatStatus(s), " · ", "    ", terminalWidth()-2))
+	}
+
+	resp, err = observeClient.Get("http://wind/logs/stream")
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Failed to follow the session: %v\n", err)
+		return
+	}
+	defer resp.Body.Close()
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if line, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			fmt.Println(line)
+		}
+	}
+	logf(levelInfo, Cyan+"Info: "+Reset+"Session ended\n")
+}
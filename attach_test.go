@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestReadOnlyHandler(t *testing.T) {
+	c, _ := newTestControlAPI()
+	server := httptest.NewServer(c.readOnlyHandler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/status")
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected GET /status to work, got %v (%v)", resp, err)
+	}
+	for _, path := range []string{"/rebuild", "/stop"} {
+		resp, err := http.Post(server.URL+path, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode < 400 {
+			t.Errorf("Expected POST %s to be refused, got %d", path, resp.StatusCode)
+		}
+	}
+	select {
+	case <-c.orch.quitChan:
+		t.Error("Expected the session to keep running")
+	default:
+	}
+}
+
+func TestListenReadOnly(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	os.Mkdir("tmp", 0755)
+
+	c, _ := newTestControlAPI()
+	if err := c.listenReadOnly(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.stop()
+
+	info, err := os.Stat(observeSocket)
+	if err != nil || info.Mode().Perm() != 0660 {
+		t.Errorf("Expected the socket to be open to the group, got %v (%v)", info.Mode(), err)
+	}
+	resp, err := observeClient.Get("http://wind/status")
+	if err != nil {
+		t.Fatalf("GET /status over %s failed: %v", observeSocket, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Build cache policies applied when a session ends
+const (
+	buildCacheReport = "report"
+	buildCachePrune  = "prune"
+)
+
+// BuildCacheConfig keeps the Go build cache from quietly growing to tens of
+// GB on small disks
+type BuildCacheConfig struct {
+	// OnExit is report, printing how much the session added to the build
+	// cache when Wind stops, or prune, which also removes those entries;
+	// empty does neither
+	OnExit string
+	// MaxGrowth only prunes when the session added more than this, e.g.
+	// 2GB; empty always prunes
+	MaxGrowth string
+}
+
+// validateBuildCache checks the build cache settings
+func validateBuildCache(config BuildCacheConfig) error {
+	switch config.OnExit {
+	case "", buildCacheReport, buildCachePrune:
+	default:
+		return fmt.Errorf("buildCache.onExit must be %s or %s, got %q", buildCacheReport, buildCachePrune, config.OnExit)
+	}
+	if config.MaxGrowth != "" {
+		if config.OnExit != buildCachePrune {
+			return fmt.Errorf("buildCache.maxGrowth only applies to onExit: %s", buildCachePrune)
+		}
+		if _, err := parseSize(config.MaxGrowth); err != nil {
+			return fmt.Errorf("buildCache.maxGrowth: %v", err)
+		}
+	}
+	return nil
+}
+
+// cacheUsage is what a session added to the build cache
+type cacheUsage struct {
+	// files are the cache entries written since the session started
+	files []string
+	added uint64
+	total uint64
+}
+
+// sessionCacheUsage measures the build cache in dir and the entries written
+// since the given time. The go command also refreshes the time of entries it
+// reuses after an hour, so those count as well; every entry can be rebuilt.
+// Only the entries in the cache's two-character subdirectories are counted,
+// leaving files such as README and trim.txt alone.
+func sessionCacheUsage(dir string, since time.Time) (cacheUsage, error) {
+	var usage cacheUsage
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Dir(path) == filepath.Clean(dir) || len(filepath.Base(filepath.Dir(path))) != 2 {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			// The entry was trimmed while walking
+			return nil
+		}
+		size := uint64(info.Size())
+		usage.total += size
+		if !info.ModTime().Before(since) {
+			usage.files = append(usage.files, path)
+			usage.added += size
+		}
+		return nil
+	})
+	return usage, err
+}
+
+// finishBuildCache applies the OnExit policy for a session started at
+// started. Other builds running meanwhile, in another session or terminal,
+// are attributed to this session as well.
+func finishBuildCache(config BuildCacheConfig, started time.Time) {
+	if config.OnExit == "" {
+		return
+	}
+	dir := goBuildCache()
+	if dir == "" {
+		return
+	}
+	usage, err := sessionCacheUsage(dir, started)
+	if err != nil {
+		logf(levelWarn, Yellow+"Warning: "+Reset+"Failed to measure the build cache in %s: %v\n", dir, err)
+		return
+	}
+
+	// The size was validated with the config
+	maxGrowth, _ := parseSize(config.MaxGrowth)
+	if config.OnExit == buildCacheReport || usage.added <= maxGrowth || usage.added == 0 {
+		logf(levelInfo, Cyan+"Info: "+Reset+"The session added %s to the build cache (%s in %s)\n", formatSize(usage.added), formatSize(usage.total), dir)
+		return
+	}
+
+	var removed uint64
+	for _, path := range usage.files {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if os.Remove(path) == nil {
+			removed += uint64(info.Size())
+		}
+	}
+	logf(levelInfo, Cyan+"Info: "+Reset+"Pruned %s the session added to the build cache (%s left in %s)\n", formatSize(removed), formatSize(usage.total-removed), dir)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestValidateBuildCache(t *testing.T) {
+	for _, config := range []BuildCacheConfig{{}, {OnExit: "report"}, {OnExit: "prune", MaxGrowth: "2GB"}} {
+		if err := validateBuildCache(config); err != nil {
+			t.Errorf("Expected %+v to be valid, got %v", config, err)
+		}
+	}
+	for _, config := range []BuildCacheConfig{{OnExit: "clean"}, {OnExit: "report", MaxGrowth: "2GB"}, {OnExit: "prune", MaxGrowth: "lots"}} {
+		if err := validateBuildCache(config); err == nil {
+			t.Errorf("Expected %+v to be invalid", config)
+		}
+	}
+}
+
+func TestSessionCacheUsage(t *testing.T) {
+	dir := t.TempDir()
+	started := time.Now().Add(-time.Minute)
+	write := func(path string, size int, modified time.Time) {
+		path = filepath.Join(dir, path)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, make([]byte, size), 0644)
+		os.Chtimes(path, modified, modified)
+	}
+	write("README", 100, time.Now())
+	write("trim.txt", 10, time.Now())
+	write("0a/0a1b-a", 1000, started.Add(-time.Hour))
+	write("0a/0a2c-d", 300, time.Now())
+	write("ff/ff3d-d", 200, time.Now())
+
+	usage, err := sessionCacheUsage(dir, started)
+	if err != nil {
+		t.Fatalf("sessionCacheUsage() failed: %v", err)
+	}
+	if usage.added != 500 || usage.total != 1500 || len(usage.files) != 2 {
+		t.Errorf("sessionCacheUsage() = %d added of %d in %v, expected 500 of 1500 in 2 files", usage.added, usage.total, usage.files)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// compileError is one file:line:col diagnostic of a failed build
+type compileError struct {
+	File    string
+	Line    int
+	Col     int
+	Message string
+}
+
+func (e compileError) location() string {
+	if e.Col == 0 {
+		return fmt.Sprintf("%s:%d", e.File, e.Line)
+	}
+	return fmt.Sprintf("%s:%d:%d", e.File, e.Line, e.Col)
+}
+
+// compileErrorPattern matches diagnostics such as
+// "./internal/store/store.go:12:5: undefined: db"
+var compileErrorPattern = regexp.MustCompile(`^(\S+\.go):(\d+)(?::(\d+))?: (.+)$`)
+
+// parseBuildErrors splits build stderr into diagnostics and the remaining
+// lines, such as linker errors. Repeated diagnostics and "# package"
+// headers are dropped; indented continuation lines (have/want) stay with
+// their diagnostic.
+func parseBuildErrors(output string) (errs []compileError, other []string) {
+	seen := map[compileError]bool{}
+	// current is the diagnostic continuation lines belong to; -1 when the
+	// previous line was not one, and for skipped duplicates
+	current, inDiagnostic := -1, false
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "\t") && inDiagnostic {
+			if current >= 0 {
+				errs[current].Message += "\n" + line
+			}
+			continue
+		}
+		current, inDiagnostic = -1, false
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "# ") {
+			continue
+		}
+
+		m := compileErrorPattern.FindStringSubmatch(line)
+		if m == nil {
+			other = append(other, line)
+			continue
+		}
+		e := compileError{File: projectPath(m[1]), Message: m[4]}
+		e.Line, _ = strconv.Atoi(m[2])
+		e.Col, _ = strconv.Atoi(m[3])
+		inDiagnostic = true
+		if !seen[e] {
+			seen[e] = true
+			errs = append(errs, e)
+			current = len(errs) - 1
+		}
+	}
+	return errs, other
+}
+
+// printBuildErrors writes a colorized summary of a failed build
+func printBuildErrors(w io.Writer, errs []compileError, other []string) {
+	for _, e := range errs {
+		fmt.Fprintf(w, "  "+Yellow+"%s"+Reset+"  %s\n", e.location(), strings.ReplaceAll(e.Message, "\n", "\n  "))
+	}
+	for _, line := range other {
+		fmt.Fprintln(w, line)
+	}
+}
+
+// pluralize formats a count with a singular or plural noun
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
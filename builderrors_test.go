@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseBuildErrors(t *testing.T) {
+	output := `# example.com/app/internal/store
+internal/store/store.go:12:5: undefined: db
+internal/store/store.go:20:9: cannot use id (variable of type int) as string value in return statement
+# example.com/app/internal/store
+internal/store/store.go:12:5: undefined: db
+./main.go:8:2: too many arguments in call to store.Open
+	have (string, int)
+	want (string)
+main.go:3: //go:embed pattern static/*: no matching files found
+/usr/bin/ld: cannot find -lsqlite3
+`
+	errs, other := parseBuildErrors(output)
+
+	expected := []compileError{
+		{File: "internal/store/store.go", Line: 12, Col: 5, Message: "undefined: db"},
+		{File: "internal/store/store.go", Line: 20, Col: 9, Message: "cannot use id (variable of type int) as string value in return statement"},
+		{File: "main.go", Line: 8, Col: 2, Message: "too many arguments in call to store.Open\n\thave (string, int)\n\twant (string)"},
+		{File: "main.go", Line: 3, Message: "//go:embed pattern static/*: no matching files found"},
+	}
+	if !reflect.DeepEqual(errs, expected) {
+		t.Errorf("parseBuildErrors() =\n%+v\nexpected\n%+v", errs, expected)
+	}
+	if len(other) != 1 || other[0] != "/usr/bin/ld: cannot find -lsqlite3" {
+		t.Errorf("Expected the linker error to be kept as is, got %q", other)
+	}
+}
+
+func TestParseBuildErrorsDuplicateContinuation(t *testing.T) {
+	line := "main.go:8:2: too many arguments in call to f\n\thave (int)\n\twant ()\n"
+	errs, other := parseBuildErrors(line + line)
+	if len(errs) != 1 || len(other) != 0 {
+		t.Errorf("Expected one diagnostic and no other lines, got %+v %q", errs, other)
+	}
+}
+
+func TestPrintBuildErrors(t *testing.T) {
+	var buf bytes.Buffer
+	printBuildErrors(&buf, []compileError{{File: "main.go", Line: 3, Col: 7, Message: "undefined: x"}}, []string{"ld: failed"})
+	out := buf.String()
+	if !strings.Contains(out, "main.go:3:7"+Reset+"  undefined: x") || !strings.Contains(out, "ld: failed\n") {
+		t.Errorf("Unexpected summary:\n%s", out)
+	}
+
+	if pluralize(1, "error") != "1 error" || pluralize(3, "error") != "3 errors" {
+		t.Errorf("Unexpected pluralization")
+	}
+}
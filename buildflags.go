@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Flags overriding the BuildTags, LDFlags and GoFlags settings
+const (
+	tagsFlag    = "--tags"
+	ldflagsFlag = "--ldflags"
+	goflagsFlag = "--goflags"
+)
+
+// buildVariable matches a template variable such as {{gitSHA}}
+var buildVariable = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// buildVariables are the template variables of LDFlags and GoFlags,
+// evaluated again for every build
+var buildVariables = map[string]func() string{
+	// gitSHA is the short commit hash, "unknown" outside a repository
+	"gitSHA": func() string {
+		out, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output()
+		if err != nil {
+			return "unknown"
+		}
+		return strings.TrimSpace(string(out))
+	},
+	"buildTime": func() string { return time.Now().UTC().Format(time.RFC3339) },
+}
+
+// validateBuildTemplate reports template variables Wind does not know
+func validateBuildTemplate(key, value string) error {
+	for _, m := range buildVariable.FindAllStringSubmatch(value, -1) {
+		if _, ok := buildVariables[m[1]]; !ok {
+			return fmt.Errorf("%s: unknown variable {{%s}} (expected {{gitSHA}} or {{buildTime}})", key, m[1])
+		}
+	}
+	return nil
+}
+
+// expandBuildTemplate replaces the template variables in value, evaluating
+// each one at most once
+func expandBuildTemplate(value string) string {
+	values := make(map[string]string)
+	return buildVariable.ReplaceAllStringFunc(value, func(match string) string {
+		name := buildVariable.FindStringSubmatch(match)[1]
+		eval, ok := buildVariables[name]
+		if !ok {
+			return match
+		}
+		if _, done := values[name]; !done {
+			values[name] = eval()
+		}
+		return values[name]
+	})
+}
+
+// goBuildFlags returns the go build arguments for the BuildTags, LDFlags and
+// GoFlags settings, with template variables expanded for this build
+func goBuildFlags(config WindConfig) []string {
+	var flags []string
+	if len(config.BuildTags) > 0 {
+		flags = append(flags, "-tags="+strings.Join(config.BuildTags, ","))
+	}
+	if config.LDFlags != "" {
+		flags = append(flags, "-ldflags="+expandBuildTemplate(config.LDFlags))
+	}
+	if config.GoFlags != "" {
+		flags = append(flags, strings.Fields(expandBuildTemplate(config.GoFlags))...)
+	}
+	return flags
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGoBuildFlags(t *testing.T) {
+	config := WindConfig{
+		BuildTags: []string{"dev", "sqlite"},
+		LDFlags:   "-s -X main.version=v1",
+		GoFlags:   "-trimpath  -buildvcs=false",
+	}
+	expected := []string{"-tags=dev,sqlite", "-ldflags=-s -X main.version=v1", "-trimpath", "-buildvcs=false"}
+	if got := goBuildFlags(config); !reflect.DeepEqual(got, expected) {
+		t.Errorf("goBuildFlags() = %q, expected %q", got, expected)
+	}
+	if got := goBuildFlags(WindConfig{}); got != nil {
+		t.Errorf("Expected no flags without settings, got %q", got)
+	}
+}
+
+func TestBuildCommandFlags(t *testing.T) {
+	app := &WindApp{config: WindConfig{
+		BuildCmd:   "go build -o ./tmp/main .",
+		PGOProfile: "profiles/my app.pgo",
+		BuildTags:  []string{"dev"},
+		LDFlags:    "-X 'main.name=it''s'",
+	}}
+
+	expected := `go build '-pgo=profiles/my app.pgo' -tags=dev '-ldflags=-X '\''main.name=it'\'''\''s'\''' -o ./tmp/main .`
+	if got := app.buildCommand(); got != expected {
+		t.Errorf("buildCommand() = %s, expected %s", got, expected)
+	}
+
+	app.config.BuildCmd = "make build"
+	if got := app.buildCommand(); got != "make build" {
+		t.Errorf("Expected other build commands unchanged, got %s", got)
+	}
+}
+
+func TestExpandBuildTemplate(t *testing.T) {
+	got := expandBuildTemplate("-X main.commit={{gitSHA}} -X main.date={{ buildTime }} -X main.other={{other}}")
+
+	if strings.Contains(got, "{{gitSHA}}") || strings.Contains(got, "buildTime") {
+		t.Errorf("Expected variables to be expanded, got %q", got)
+	}
+	if !regexp.MustCompile(`main\.date=\d{4}-\d\d-\d\dT\d\d:\d\d:\d\dZ`).MatchString(got) {
+		t.Errorf("Expected an RFC 3339 build time, got %q", got)
+	}
+	if !strings.HasSuffix(got, "main.other={{other}}") {
+		t.Errorf("Expected unknown variables to be kept, got %q", got)
+	}
+}
+
+func TestValidateBuildTemplate(t *testing.T) {
+	if err := validateBuildTemplate("ldFlags", "-X main.v={{gitSHA}} -X main.t={{buildTime}}"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := validateBuildTemplate("ldFlags", "-X main.v={{gitSha}}"); err == nil {
+		t.Error("Expected an error for an unknown variable")
+	}
+}
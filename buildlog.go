@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// buildLogDir holds one log file per build cycle
+const buildLogDir = "tmp/builds"
+
+// buildLogPath returns the log file path for a build id
+func buildLogPath(id int) string {
+	return filepath.Join(buildLogDir, strconv.Itoa(id)+".log")
+}
+
+// listBuildLogs returns the ids of all persisted build logs in ascending order
+func listBuildLogs() ([]int, error) {
+	entries, err := os.ReadDir(buildLogDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var ids []int
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".log") {
+			continue
+		}
+		if id, err := strconv.Atoi(strings.TrimSuffix(name, ".log")); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// nextBuildID continues numbering after the logs of previous sessions
+func nextBuildID() int {
+	ids, _ := listBuildLogs()
+	if len(ids) == 0 {
+		return 1
+	}
+	return ids[len(ids)-1] + 1
+}
+
+// createBuildLog allocates the next build id and creates its log file. Ids
+// are claimed with O_EXCL so concurrent builds of several processes never
+// share a log.
+func createBuildLog() (int, *os.File, error) {
+	if err := os.MkdirAll(buildLogDir, 0755); err != nil {
+		return 0, nil, err
+	}
+	for id := nextBuildID(); ; id++ {
+		f, err := os.OpenFile(buildLogPath(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if os.IsExist(err) {
+			continue
+		}
+		return id, f, err
+	}
+}
+
+// runLogs implements `wind logs build [<n> [<m>]]` and `wind logs daemon`
+func runLogs(args []string) {
+	if len(args) > 0 && args[0] == "daemon" {
+		followDaemonLog(20)
+		return
+	}
+	if len(args) == 0 || args[0] != "build" {
+		fmt.Println("Usage: wind logs build [<n> [<m>]] | wind logs daemon")
+		return
+	}
+	args = args[1:]
+
+	switch len(args) {
+	case 0:
+		ids, err := listBuildLogs()
+		if err != nil {
+			logf(levelError, Red+"Error: "+Reset+"Failed to list build logs: %v\n", err)
+			return
+		}
+		if len(ids) == 0 {
+			logf(levelInfo, Yellow+"Info: "+Reset+"No build logs found in "+buildLogDir+"\n")
+			return
+		}
+		for _, id := range ids {
+			fmt.Printf("  #%d  %s\n", id, buildLogPath(id))
+		}
+
+	case 1:
+		data, err := readBuildLog(args[0])
+		if err != nil {
+			logf(levelError, Red+"Error: "+Reset+"%v\n", err)
+			return
+		}
+		fmt.Print(data)
+
+	default:
+		a, err := readBuildLog(args[0])
+		if err != nil {
+			logf(levelError, Red+"Error: "+Reset+"%v\n", err)
+			return
+		}
+		b, err := readBuildLog(args[1])
+		if err != nil {
+			logf(levelError, Red+"Error: "+Reset+"%v\n", err)
+			return
+		}
+		fmt.Printf(Cyan+"--- build #%s\n+++ build #%s"+Reset+"\n", args[0], args[1])
+		for _, line := range diffLines(splitLines(a), splitLines(b)) {
+			switch line[0] {
+			case '-':
+				fmt.Println(Red + line + Reset)
+			case '+':
+				fmt.Println(Green + line + Reset)
+			default:
+				fmt.Println(line)
+			}
+		}
+	}
+}
+
+func readBuildLog(arg string) (string, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+	if err != nil {
+		return "", fmt.Errorf("invalid build id: %s", arg)
+	}
+	data, err := os.ReadFile(buildLogPath(id))
+	if err != nil {
+		return "", fmt.Errorf("build log #%d not found", id)
+	}
+	return string(data), nil
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// diffLines returns a line diff of a and b, each line prefixed with
+// "-" (only in a), "+" (only in b) or " " (common)
+func diffLines(a, b []string) []string {
+	// Longest common subsequence table
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, " "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, "-"+a[i])
+			i++
+		default:
+			out = append(out, "+"+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		out = append(out, "-"+a[i])
+	}
+	for ; j < len(b); j++ {
+		out = append(out, "+"+b[j])
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	a := []string{"building", "main.go:3: undefined: foo", "done"}
+	b := []string{"building", "main.go:4: undefined: bar", "done"}
+
+	expected := []string{
+		" building",
+		"-main.go:3: undefined: foo",
+		"+main.go:4: undefined: bar",
+		" done",
+	}
+
+	if got := diffLines(a, b); !reflect.DeepEqual(got, expected) {
+		t.Errorf("diffLines() = %q, expected %q", got, expected)
+	}
+}
+
+func TestBuildLogNumbering(t *testing.T) {
+	tmpDir := createTempProject(t, "root")
+	defer os.RemoveAll(tmpDir)
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+
+	if id := nextBuildID(); id != 1 {
+		t.Errorf("Expected first build id 1, got %d", id)
+	}
+
+	for _, id := range []int{1, 2, 10} {
+		if err := os.MkdirAll(buildLogDir, 0755); err != nil {
+			t.Fatalf("Failed to create build log dir: %v", err)
+		}
+		if err := os.WriteFile(buildLogPath(id), nil, 0644); err != nil {
+			t.Fatalf("Failed to create build log: %v", err)
+		}
+	}
+
+	// Numeric ordering, not lexical: 10 comes after 2
+	if id := nextBuildID(); id != 11 {
+		t.Errorf("Expected next build id 11, got %d", id)
+	}
+
+	id, f, err := createBuildLog()
+	if err != nil {
+		t.Fatalf("Failed to create build log: %v", err)
+	}
+	f.Close()
+	if id != 11 {
+		t.Errorf("Expected createBuildLog to claim id 11, got %d", id)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ciReportFile holds the results of the latest wind ci run
+const ciReportFile = "tmp/ci.json"
+
+// CIConfig selects the checks wind ci runs after generating and building,
+// so the dev-loop config doubles as the CI definition
+type CIConfig struct {
+	// Vet runs go vet ./... (true by default)
+	Vet bool
+	// Lint is a command run after go vet, e.g. golangci-lint run
+	Lint string
+	// Tests runs go test ./... last (true by default)
+	Tests bool
+}
+
+// ciStep is the result of one step of wind ci
+type ciStep struct {
+	Name       string `json:"name"`
+	Target     string `json:"target,omitempty"`
+	Passed     bool   `json:"passed"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ciReport is written to ciReportFile
+type ciReport struct {
+	Passed     bool     `json:"passed"`
+	DurationMs int64    `json:"duration_ms"`
+	Steps      []ciStep `json:"steps"`
+}
+
+// ciRun collects the steps of a wind ci run
+type ciRun struct {
+	report  ciReport
+	started time.Time
+}
+
+// step runs fn as the step name of target, announcing it and recording its
+// result, and reports whether it passed
+func (r *ciRun) step(name, target string, fn func() error) bool {
+	label := name
+	if target != "" {
+		label += " (" + target + ")"
+	}
+	fmt.Printf(Cyan+"▶ %s"+Reset+"\n", label)
+	if events != nil {
+		events.write(event{Event: "step_start", Target: target, Step: name})
+	}
+
+	started := time.Now()
+	err := fn()
+	s := ciStep{Name: name, Target: target, Passed: err == nil, DurationMs: time.Since(started).Milliseconds()}
+	ev := event{Event: "step_ok", Target: target, Step: name, DurationMs: s.DurationMs}
+	if err != nil {
+		s.Error = err.Error()
+		ev.Event, ev.Error = "step_fail", s.Error
+	}
+	if events != nil {
+		events.write(ev)
+	}
+	r.report.Steps = append(r.report.Steps, s)
+	return s.Passed
+}
+
+// finish completes the report, writes it to ciReportFile and prints a
+// summary of every step
+func (r *ciRun) finish() {
+	r.report.Passed = true
+	for _, s := range r.report.Steps {
+		r.report.Passed = r.report.Passed && s.Passed
+	}
+	r.report.DurationMs = time.Since(r.started).Milliseconds()
+
+	fmt.Println()
+	rows := newTable("  ")
+	for _, s := range r.report.Steps {
+		mark := Green + "✓" + Reset
+		if !s.Passed {
+			mark = Red + "✗" + Reset
+		}
+		name := s.Name
+		if s.Target != "" {
+			name += " (" + s.Target + ")"
+		}
+		rows.addRow(mark+" "+name, (time.Duration(s.DurationMs) * time.Millisecond).String(), s.Error)
+	}
+	fmt.Print(rows.render(terminalWidth()))
+
+	data, _ := json.MarshalIndent(r.report, "", "  ")
+	if err := os.WriteFile(ciReportFile, append(data, '\n'), 0644); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Failed to write %s: %v\n", ciReportFile, err)
+	}
+	ev := event{Event: "ci_ok", DurationMs: r.report.DurationMs}
+	if !r.report.Passed {
+		ev.Event = "ci_fail"
+	}
+	if events != nil {
+		events.write(ev)
+	}
+	if r.report.Passed {
+		logf(levelSuccess, Green+"Success: "+Reset+"CI passed (report: %s)\n", ciReportFile)
+	} else {
+		logf(levelError, Red+"Error: "+Reset+"CI failed (report: %s)\n", ciReportFile)
+	}
+}
+
+// runCommand runs a command with Wind's output streams
+func runCommand(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// runCI implements `wind ci`: the steps of a reload cycle, generators and
+// the build of every target, then go vet, the lint command and go test, run
+// once. A failed generator or build ends the run; the checks after it all
+// run. The exit status is the failed build's, or 1 when a check failed.
+func runCI(opts watchOptions) {
+	exitStatus = 1
+	config, ok := loadWatchConfig(opts)
+	if !ok {
+		return
+	}
+	apps, _, ok := createApps(&config, opts)
+	if !ok {
+		return
+	}
+	if err := os.MkdirAll("tmp", 0755); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Failed to create tmp directory: %v\n", err)
+		return
+	}
+
+	run := &ciRun{started: time.Now()}
+	defer run.finish()
+	for _, app := range apps {
+		if len(app.config.Generators) > 0 && !run.step("generate", app.name, func() error {
+			if !app.generateAll() {
+				return fmt.Errorf("generator failed")
+			}
+			return nil
+		}) {
+			return
+		}
+		if !run.step("build", app.name, func() error {
+			if !app.build() {
+				return fmt.Errorf("build #%d failed with exit status %d", app.buildID, app.buildExitCode)
+			}
+			return nil
+		}) {
+			exitStatus = app.buildExitCode
+			return
+		}
+	}
+
+	passed := true
+	if config.CI.Vet {
+		args := []string{"vet"}
+		if len(config.BuildTags) > 0 {
+			args = append(args, "-tags="+strings.Join(config.BuildTags, ","))
+		}
+		passed = run.step("vet", "", func() error { return runCommand("go", append(args, "./...")...) }) && passed
+	}
+	if config.CI.Lint != "" {
+		passed = run.step("lint", "", func() error { return runCommand("sh", "-c", config.CI.Lint) }) && passed
+	}
+	if config.CI.Tests {
+		passed = run.step("test", "", func() error {
+			if !newTestRunner(goBuildFlags(config), nil).run([]string{"./..."}) {
+				return fmt.Errorf("tests failed")
+			}
+			return nil
+		}) && passed
+	}
+	if passed {
+		exitStatus = 0
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// runCIWith runs wind ci in a new project with config and returns the
+// report
+func runCIWith(t *testing.T, config string) ciReport {
+	os.Chdir(t.TempDir())
+	os.WriteFile(configFileName, []byte(config), 0644)
+	runCI(watchOptions{})
+
+	var report ciReport
+	data, err := os.ReadFile(ciReportFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func stepNames(report ciReport) []string {
+	var names []string
+	for _, s := range report.Steps {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestRunCI(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	defer func() { exitStatus = 0 }()
+
+	base := "runCmd: ./tmp/main\nci:\n  vet: false\n  tests: false\n"
+
+	report := runCIWith(t, base+"  lint: 'true'\nbuildCmd: test -f generated\ngenerators:\n  - pattern: '*.proto'\n    command: touch generated\n")
+	if !report.Passed || exitStatus != 0 {
+		t.Errorf("Expected CI to pass, got %+v (exit status %d)", report, exitStatus)
+	}
+	if names := stepNames(report); len(names) != 3 || names[0] != "generate" || names[1] != "build" || names[2] != "lint" {
+		t.Errorf("Expected generate, build and lint steps, got %v", names)
+	}
+
+	// A failed check fails the run after every check ran
+	report = runCIWith(t, base+"  lint: exit 2\nbuildCmd: 'true'\n")
+	if report.Passed || exitStatus != 1 {
+		t.Errorf("Expected a failed lint to fail CI with status 1, got %+v (exit status %d)", report, exitStatus)
+	}
+
+	// A failed build ends the run with the build's status
+	report = runCIWith(t, base+"  lint: 'true'\nbuildCmd: exit 4\n")
+	if report.Passed || exitStatus != 4 {
+		t.Errorf("Expected a failed build to fail CI with status 4, got %+v (exit status %d)", report, exitStatus)
+	}
+	if names := stepNames(report); len(names) != 1 || names[0] != "build" {
+		t.Errorf("Expected no checks after a failed build, got %v", names)
+	}
+}
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// version is reported by `wind version` and in generated docs
+const version = "1.1.0"
+
+// command is a wind subcommand. The help overview, `wind help <command>` and
+// `--help` on every command are generated from these entries.
+type command struct {
+	name    string
+	aliases []string
+	// args is the argument synopsis, e.g. "<target>"
+	args    string
+	summary string
+	// description is the longer text of `wind help <command>`
+	description string
+	flags       []commandFlag
+	examples    []string
+	// project is set for commands that run in the project root, so Wind
+	// can be started from any subdirectory
+	project bool
+	run     func(opts watchOptions, args []string)
+}
+
+// commandFlag documents a flag accepted by a command
+type commandFlag struct {
+	name        string
+	description string
+}
+
+// globalFlags are accepted by every command
+var globalFlags = []commandFlag{
+	{recordFlag + " <file.cast>", "Record terminal output (asciinema v2)"},
+	{logFormatFlag + " json", "Emit NDJSON events instead of text"},
+	{editorFlag + " '<cmd {file}:{line}>'", "Editor opening compile errors (key e)"},
+	{verboseFlag, "Print timings, commands and scan durations; short -v"},
+	{traceFlag, "Also print every file the scan notices (trace)"},
+	{quietFlag, "Only print errors and restarts; long --quiet"},
+	{noColorFlag, "No colors; also with NO_COLOR or when not on a terminal"},
+	{exitOnFailFlag, "Exit non-zero when the first build or run fails"},
+	{stdinFlag, "Pass input to the application; keys are off (stdin)"},
+	{ptyFlag, "Run the application in a pseudo-terminal (pty)"},
+	{eventsFromFlag + " stdin", "Read changed paths, one per line, instead of polling"},
+	{tagsFlag + " <tag,...>", "Build tags for go build and go test (buildTags)"},
+	{ldflagsFlag + " '<flags>'", "Linker flags, e.g. -X main.version={{gitSHA}} (ldFlags)"},
+	{goflagsFlag + " '<flags>'", "Extra go build flags, e.g. -trimpath (goFlags)"},
+	{logFileFlag + " <file>", "Also write the output to a rotated log (logFile.path)"},
+	{grepFlag + " <regex>", "Only show app output matching it (logFilter.grep)"},
+	{levelFlag + " <level>", "Only show app logs of this level or above (logFilter.level)"},
+}
+
+// commands lists the subcommands in the order of the help overview. It is
+// filled in by init because help refers back to it.
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{
+			name:        "init",
+			summary:     "Start watching the project (the default)",
+			description: "Builds the detected or configured target, runs it and rebuilds and restarts it whenever a watched file changes. Running wind without a command does the same.",
+			examples:    []string{"wind", "wind -- --port=9090 --debug"},
+			project:     true,
+			run:         func(opts watchOptions, args []string) { runWatcher(opts) },
+		},
+		{
+			name:        "run",
+			args:        "<target>",
+			summary:     "Build and watch a specific cmd/ binary",
+			description: "Watches one of the main packages listed by wind targets instead of the default one.",
+			examples:    []string{"wind run worker", "wind run worker -- --queue=dev"},
+			project:     true,
+			run: func(opts watchOptions, args []string) {
+				if len(args) < 1 {
+					logf(levelError, Red+"Error: "+Reset+"Usage: wind run <target> (see wind targets)\n")
+					return
+				}
+				opts.target = args[0]
+				runWatcher(opts)
+			},
+		},
+		{
+			name:        "targets",
+			summary:     "List detected build targets",
+			description: "Lists the main packages of the project. The first one is built by default.",
+			project:     true,
+			run:         func(opts watchOptions, args []string) { showTargets() },
+		},
+		{
+			name:        "build",
+			args:        "[target]",
+			summary:     "Build once and exit with the build's status",
+			description: "Builds every target, or the named cmd/ binary, once with the configured flags, generators and build mode, as the first build of a session would, and exits with the exit status of a failed build. Useful in CI.",
+			examples:    []string{"wind build", "wind build worker", "wind build --tags integration"},
+			project:     true,
+			run:         func(opts watchOptions, args []string) { runBuild(opts, args) },
+		},
+		{
+			name:        "check",
+			args:        "[target]",
+			summary:     "Validate the config and show what would be watched",
+			description: "Detects the project and validates the config like wind does on start, then prints the build and run commands and the watched files of every target without building anything. Exits with status 1 on an invalid config. With --verbose, every watched file is listed.",
+			examples:    []string{"wind check", "wind check --verbose"},
+			project:     true,
+			run:         func(opts watchOptions, args []string) { runCheck(opts, args) },
+		},
+		{
+			name:        "ci",
+			summary:     "Run generators, build, vet, lint and tests once",
+			description: "Runs the steps of a reload cycle once without watching: the generators and build of every target, then go vet, the ci.lint command and go test ./... The results are written to tmp/ci.json, and with --log-format json every step is an event. Exits with the failed build's status, or 1 when a check failed.",
+			examples:    []string{"wind ci", "wind ci --log-format json", "wind ci --tags integration"},
+			project:     true,
+			run:         func(opts watchOptions, args []string) { runCI(opts) },
+		},
+		{
+			name:        "pgo",
+			args:        "[secs]",
+			summary:     "Collect a PGO profile from wind proxy traffic",
+			description: "Has wind proxy read a CPU profile of secs seconds (pgoCollectDuration by default) from the app's pgoCollectPath while it forwards traffic, into the profile used by -pgo builds.",
+			examples:    []string{"wind pgo", "wind pgo 60"},
+			project:     true,
+			run:         func(opts watchOptions, args []string) { runPGO(args) },
+		},
+		{
+			name:        "serve",
+			args:        "[dir]",
+			summary:     "Serve static files with live reload",
+			description: "Serves dir, the current directory by default, with the live reload script injected into HTML pages, and reloads open tabs whenever a file in it changes. The serve config block does the same next to the watched app.",
+			flags:       []commandFlag{{servePortFlag + " <n>", "Port to listen on (3000)"}},
+			examples:    []string{"wind serve ./public --port 3000"},
+			run:         func(opts watchOptions, args []string) { runServe(args) },
+		},
+		{
+			name:        "ab",
+			summary:     "Run previous and new build side by side",
+			description: "Keeps the previous build running next to the new one behind a proxy on ab.port; press s to switch between them.",
+			project:     true,
+			run: func(opts watchOptions, args []string) {
+				opts.abMode = true
+				runWatcher(opts)
+			},
+		},
+		{
+			name:        "proxy",
+			summary:     "Zero-downtime restarts behind a proxy",
+			description: "Listens on proxy.port and hands traffic over to each new build once it is healthy, holding requests while no build is up.",
+			project:     true,
+			run: func(opts watchOptions, args []string) {
+				opts.proxyMode = true
+				runWatcher(opts)
+			},
+		},
+		{
+			name:        "test",
+			args:        "[flags]",
+			summary:     "Run go test for affected packages on every save",
+			description: "Tests the packages containing the changed files plus the packages that import them. Flags are passed through to go test, arguments after -- to the test binaries.",
+			flags:       []commandFlag{{"<go test flags>", "Passed through to go test, e.g. -race or -run TestAPI"}},
+			examples:    []string{"wind test", "wind test -race -run TestAPI", "wind test -- -update"},
+			project:     true,
+			run: func(opts watchOptions, args []string) {
+				opts.testMode, opts.testArgs = true, args
+				runWatcher(opts)
+			},
+		},
+		{
+			name:        "daemon",
+			summary:     "Keep watching in the background",
+			description: "Starts Wind detached from the terminal, logging to tmp/wind.log. Control it with wind status, wind rebuild and wind stop.",
+			examples:    []string{"wind daemon", "wind daemon -- --port=9090"},
+			project:     true,
+			run:         func(opts watchOptions, args []string) { runDaemon(opts) },
+		},
+		{
+			name:        "status",
+			summary:     "Show the state of the background daemon",
+			description: "Prints the state, PID and latest build of every target of the daemon.",
+			project:     true,
+			run:         func(opts watchOptions, args []string) { runStatus() },
+		},
+		{
+			name:        "rebuild",
+			args:        "[target]",
+			summary:     "Make the daemon rebuild (one target)",
+			description: "Asks the background daemon to rebuild every target, or only the named one.",
+			examples:    []string{"wind rebuild", "wind rebuild api"},
+			project:     true,
+			run:         func(opts watchOptions, args []string) { runRebuild(args) },
+		},
+		{
+			name:        "stop",
+			summary:     "Stop the background daemon",
+			description: "Stops the daemon and the applications it runs.",
+			project:     true,
+			run:         func(opts watchOptions, args []string) { runStop() },
+		},
+		{
+			name:        "attach",
+			summary:     "Follow a running session read-only",
+			description: "Prints the state of the daemon, or of a session with observe: true, running in this project and follows its output without being able to control it, e.g. for a teammate pairing over SSH. Members of the owner's group may attach.",
+			project:     true,
+			run:         func(opts watchOptions, args []string) { runAttach() },
+		},
+		{
+			name:        "ports",
+			summary:     "List the ports Wind assigned, across projects",
+			description: "Lists every port in the registry shared by all projects, with its target and whether it is running or reserved.",
+			run:         func(opts watchOptions, args []string) { runPorts() },
+		},
+		{
+			name:        "ps",
+			summary:     "List the running Wind sessions and their ports",
+			description: "Lists every running session with its owner, uptime, targets, ports and project. On a dev box where shared mode is used, sessions of every user are listed.",
+			run:         func(opts watchOptions, args []string) { runPS() },
+		},
+		{
+			name:        "logs",
+			args:        "build|daemon",
+			summary:     "Show build logs or follow the daemon's output",
+			description: "wind logs build lists the kept build logs, shows build <n> or diffs builds <n> and <m>. wind logs daemon follows the background daemon's output.",
+			examples:    []string{"wind logs build", "wind logs build 3", "wind logs build 3 4", "wind logs daemon"},
+			project:     true,
+			run:         func(opts watchOptions, args []string) { runLogs(args) },
+		},
+		{
+			name:        "report",
+			args:        "size",
+			summary:     "Show the binary size trend of the session",
+			description: "wind report size lists the binary size of every build of the latest session with the change from the previous build and a bar per build, to spot when the binary started to grow.",
+			examples:    []string{"wind report size"},
+			project:     true,
+			run:         func(opts watchOptions, args []string) { runReport(args) },
+		},
+		{
+			name:        "stats",
+			summary:     "Show build timing statistics",
+			description: "Summarizes the build cycles recorded with history: true: the average, p95 and maximum time of the scan, the generators and formatters, the build, the startup of the app and the whole cycle, and the slowest builds with the files that triggered them.",
+			examples:    []string{"wind stats"},
+			project:     true,
+			run:         func(opts watchOptions, args []string) { runStats() },
+		},
+		{
+			name:        "explain",
+			args:        "[error]",
+			summary:     "Explain a build error (reads stdin if omitted)",
+			description: "Matches a compiler error against known causes and prints likely fixes.",
+			examples:    []string{"wind explain 'declared and not used: x'", "go build ./... 2>&1 | wind explain"},
+			run:         func(opts watchOptions, args []string) { runExplain(args) },
+		},
+		{
+			name:        "docs",
+			summary:     "Generate the reference as a man page or markdown",
+			description: "Prints the reference of every command, option and config key, generated from the same registry as this help, for packaging as a man page.",
+			flags:       []commandFlag{{docsFormatFlag + " man|markdown", "Output format (markdown)"}},
+			examples:    []string{"wind docs --format man > wind.1", "wind docs --format markdown > docs/reference.md"},
+			run:         func(opts watchOptions, args []string) { runDocs(args) },
+		},
+		{
+			name:     "help",
+			aliases:  []string{"-h", "--help"},
+			args:     "[command]",
+			summary:  "Show help, or the help of a command",
+			examples: []string{"wind help", "wind help test", "wind test --help"},
+			run:      func(opts watchOptions, args []string) { runHelp(args) },
+		},
+		{
+			name:    "version",
+			aliases: []string{"-V", "--version"},
+			summary: "Show version",
+			run: func(opts watchOptions, args []string) {
+				fmt.Printf("Wind v%s - Enhanced with smart project detection\n", version)
+			},
+		},
+	}
+}
+
+// findCommand looks a command up by name or alias
+func findCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
+		}
+		for _, alias := range c.aliases {
+			if alias == name {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// wantsHelp reports whether args ask for a command's help
+func wantsHelp(args []string) bool {
+	for _, arg := range args {
+		if arg == "-h" || arg == "--help" {
+			return true
+		}
+	}
+	return false
+}
+
+// usage is the synopsis of c
+func (c *command) usage() string {
+	if c.args == "" {
+		return "wind " + c.name
+	}
+	return "wind " + c.name + " " + c.args
+}
+
+// longDescription is the description of c, or its summary without one
+func (c *command) longDescription() string {
+	if c.description != "" {
+		return c.description
+	}
+	return c.summary
+}
+
+// runHelp implements `wind help [command]`
+func runHelp(args []string) {
+	if len(args) == 0 {
+		showHelp()
+		return
+	}
+	c := findCommand(args[0])
+	if c == nil {
+		logf(levelError, Red+"Error: "+Reset+"Unknown command: %s\n", args[0])
+		showHelp()
+		return
+	}
+	showCommandHelp(c)
+}
+
+// showHelp prints the overview of every command
+func showHelp() {
+	width := terminalWidth()
+	fmt.Printf(Cyan + "Wind - Go Web Application Watcher" + Reset + "\n")
+	fmt.Println()
+	fmt.Printf(Yellow + "Usage:" + Reset + "\n")
+	usage := newTable("  ")
+	for _, c := range commands {
+		usage.addRow(c.usage(), c.summary)
+	}
+	usage.addRow("wind -- <args>", "Pass arguments through to the application")
+	fmt.Print(usage.render(width))
+	fmt.Println()
+	fmt.Printf(Yellow + "Options:" + Reset + "\n")
+	fmt.Print(flagTable(globalFlags).render(width))
+	fmt.Println()
+	fmt.Println("Run 'wind help <command>' for details and examples.")
+}
+
+// showCommandHelp prints the usage, description, flags and examples of c
+func showCommandHelp(c *command) {
+	width := terminalWidth()
+	fmt.Printf(Yellow+"Usage:"+Reset+" %s [options]\n", c.usage())
+	if len(c.aliases) > 0 {
+		fmt.Printf("Aliases: %s\n", strings.Join(c.aliases, ", "))
+	}
+	fmt.Println()
+	for _, line := range wrapWords(c.longDescription(), width) {
+		fmt.Println(line)
+	}
+	if len(c.flags) > 0 {
+		fmt.Println()
+		fmt.Printf(Yellow + "Flags:" + Reset + "\n")
+		fmt.Print(flagTable(c.flags).render(width))
+	}
+	if len(c.examples) > 0 {
+		fmt.Println()
+		fmt.Printf(Yellow + "Examples:" + Reset + "\n")
+		for _, example := range c.examples {
+			fmt.Println("  " + example)
+		}
+	}
+	fmt.Println()
+	fmt.Printf(Yellow + "Options:" + Reset + "\n")
+	fmt.Print(flagTable(globalFlags).render(width))
+}
+
+func flagTable(flags []commandFlag) *table {
+	rows := newTable("  ")
+	for _, f := range flags {
+		rows.addRow(f.name, f.description)
+	}
+	return rows
+}
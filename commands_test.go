@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCommandsRegistry(t *testing.T) {
+	seen := map[string]bool{}
+	for _, c := range commands {
+		for _, name := range append([]string{c.name}, c.aliases...) {
+			if seen[name] {
+				t.Errorf("%s is registered twice", name)
+			}
+			seen[name] = true
+		}
+		if c.summary == "" || c.run == nil {
+			t.Errorf("command %s needs a summary and run", c.name)
+		}
+	}
+}
+
+func TestFindCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"init", "init"},
+		{"test", "test"},
+		{"--help", "help"},
+		{"-V", "version"},
+		{"-v", ""},
+		{"deploy", ""},
+	}
+	for _, tt := range tests {
+		c := findCommand(tt.name)
+		got := ""
+		if c != nil {
+			got = c.name
+		}
+		if got != tt.want {
+			t.Errorf("findCommand(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCommandUsage(t *testing.T) {
+	if got := findCommand("run").usage(); got != "wind run <target>" {
+		t.Errorf("usage() = %q", got)
+	}
+	if got := findCommand("stop").usage(); got != "wind stop" {
+		t.Errorf("usage() = %q", got)
+	}
+}
+
+func TestWantsHelp(t *testing.T) {
+	if !wantsHelp([]string{"-race", "--help"}) {
+		t.Error("--help not detected")
+	}
+	if !wantsHelp([]string{"-h"}) {
+		t.Error("-h not detected")
+	}
+	if wantsHelp([]string{"-run", "TestHelp"}) {
+		t.Error("help detected in plain arguments")
+	}
+}
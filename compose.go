@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ComposeConfig manages the docker compose services an app depends on, such
+// as a database, that keep running across rebuilds
+type ComposeConfig struct {
+	// File is passed to docker compose -f; empty lets compose find its file
+	File string
+	// Services are started with docker compose up -d before the first run
+	// and left running when Wind exits
+	Services []string
+	// Restart restarts a service when a changed path matches a rule
+	Restart []ComposeRestartRule
+}
+
+// ComposeRestartRule restarts a compose service when a changed path matches
+// its pattern, e.g. db/init/*.sql → db
+type ComposeRestartRule struct {
+	// Pattern is a glob as in generator rules
+	Pattern string
+	Service string
+}
+
+// command returns the docker compose invocation for args
+func (c ComposeConfig) command(args ...string) []string {
+	cmd := []string{"docker", "compose"}
+	if c.File != "" {
+		cmd = append(cmd, "-f", c.File)
+	}
+	return append(cmd, args...)
+}
+
+// validateCompose checks the compose settings
+func validateCompose(config ComposeConfig) error {
+	for i, service := range config.Services {
+		if service == "" {
+			return fmt.Errorf("compose.services[%d]: empty service name", i)
+		}
+	}
+	for i, rule := range config.Restart {
+		if rule.Pattern == "" || rule.Service == "" {
+			return fmt.Errorf("compose.restart[%d]: pattern and service are required", i)
+		}
+		if _, err := filepath.Match(rule.Pattern, ""); err != nil {
+			return fmt.Errorf("compose.restart[%d]: invalid pattern %q", i, rule.Pattern)
+		}
+	}
+	return nil
+}
+
+// startComposeServices brings the dependent services up and waits until
+// they are running, or healthy when they have a health check
+func startComposeServices(config ComposeConfig) error {
+	if len(config.Services) == 0 {
+		return nil
+	}
+	logf(levelInfo, Cyan+"Info: "+Reset+"Starting compose services: %s\n", strings.Join(config.Services, ", "))
+	args := config.command(append([]string{"up", "-d", "--wait"}, config.Services...)...)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("docker compose up failed: %v", err)
+	}
+	return nil
+}
+
+// matchesComposeRestart reports whether path is an input of a restart rule,
+// so it is watched whatever its extension
+func (app *WindApp) matchesComposeRestart(path string) bool {
+	for _, rule := range app.config.Compose.Restart {
+		if matchPattern(rule.Pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// restartComposeServices restarts, once per cycle and in declaration order,
+// the services whose rules match a path changed in this cycle. It reports
+// whether every changed path was covered by a rule, in which case the app
+// itself needs no rebuild.
+func (app *WindApp) restartComposeServices() bool {
+	if len(app.config.Compose.Restart) == 0 || len(app.changedFiles) == 0 {
+		return false
+	}
+
+	var services []string
+	matched := make(map[string][]string)
+	covered := 0
+	for _, path := range app.changedFiles {
+		hit := false
+		for _, rule := range app.config.Compose.Restart {
+			if !matchPattern(rule.Pattern, path) {
+				continue
+			}
+			hit = true
+			if _, ok := matched[rule.Service]; !ok {
+				services = append(services, rule.Service)
+			}
+			matched[rule.Service] = append(matched[rule.Service], filepath.ToSlash(path))
+		}
+		if hit {
+			covered++
+		}
+	}
+
+	for _, service := range services {
+		logf(levelInfo, app.label()+Cyan+"🐳 %s changed, restarting compose service %s..."+Reset+"\n", describeChanged(matched[service]), service)
+		args := app.config.Compose.command("restart", service)
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdout = app.output(os.Stdout)
+		cmd.Stderr = app.output(os.Stderr)
+		if err := cmd.Run(); err != nil {
+			logf(levelError, Red+"Error: "+Reset+"%sFailed to restart compose service %s: %v\n", app.label(), service, err)
+		}
+	}
+	return len(services) > 0 && covered == len(app.changedFiles)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeDocker puts a docker script recording its arguments first on PATH
+// and returns the file it records to
+func fakeDocker(t *testing.T) string {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	script := "#!/bin/sh\necho \"$@\" >> " + calls + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return calls
+}
+
+func readCalls(t *testing.T, calls string) []string {
+	data, err := os.ReadFile(calls)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestComposeCommand(t *testing.T) {
+	if got := (ComposeConfig{}).command("restart", "db"); !reflect.DeepEqual(got, []string{"docker", "compose", "restart", "db"}) {
+		t.Errorf("command = %v", got)
+	}
+	got := ComposeConfig{File: "deploy/compose.dev.yaml"}.command("up", "-d")
+	if !reflect.DeepEqual(got, []string{"docker", "compose", "-f", "deploy/compose.dev.yaml", "up", "-d"}) {
+		t.Errorf("command with file = %v", got)
+	}
+}
+
+func TestValidateCompose(t *testing.T) {
+	valid := ComposeConfig{
+		Services: []string{"db", "redis"},
+		Restart:  []ComposeRestartRule{{Pattern: "db/init/*.sql", Service: "db"}},
+	}
+	if err := validateCompose(valid); err != nil {
+		t.Errorf("Expected valid compose config, got %v", err)
+	}
+
+	for _, c := range []ComposeConfig{
+		{Services: []string{""}},
+		{Restart: []ComposeRestartRule{{Pattern: "*.sql"}}},
+		{Restart: []ComposeRestartRule{{Service: "db"}}},
+		{Restart: []ComposeRestartRule{{Pattern: "[", Service: "db"}}},
+	} {
+		if err := validateCompose(c); err == nil {
+			t.Errorf("Expected %+v to be invalid", c)
+		}
+	}
+}
+
+func TestStartComposeServices(t *testing.T) {
+	calls := fakeDocker(t)
+
+	if err := startComposeServices(ComposeConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := readCalls(t, calls); got != nil {
+		t.Errorf("Expected no docker calls without services, got %v", got)
+	}
+
+	if err := startComposeServices(ComposeConfig{Services: []string{"db", "redis"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := readCalls(t, calls); !reflect.DeepEqual(got, []string{"compose up -d --wait db redis"}) {
+		t.Errorf("docker calls = %v", got)
+	}
+}
+
+func TestRestartComposeServices(t *testing.T) {
+	calls := fakeDocker(t)
+	app := newWindApp(WindConfig{
+		Compose: ComposeConfig{Restart: []ComposeRestartRule{
+			{Pattern: "db/init/*.sql", Service: "db"},
+			{Pattern: "db/**", Service: "db"},
+			{Pattern: "redis.conf", Service: "redis"},
+		}},
+	}, "", "")
+
+	// Only compose files changed: the app needs no rebuild, and db restarts
+	// once however many rules match
+	app.changedFiles = []string{"db/init/001.sql", "db/init/002.sql", "config/redis.conf"}
+	if !app.restartComposeServices() {
+		t.Error("Expected the cycle to be handled by the compose restarts")
+	}
+	if got := readCalls(t, calls); !reflect.DeepEqual(got, []string{"compose restart db", "compose restart redis"}) {
+		t.Errorf("docker calls = %v", got)
+	}
+
+	os.Remove(calls)
+	app.changedFiles = []string{"db/init/001.sql", "main.go"}
+	if app.restartComposeServices() {
+		t.Error("A Go change still needs a rebuild")
+	}
+	if got := readCalls(t, calls); !reflect.DeepEqual(got, []string{"compose restart db"}) {
+		t.Errorf("docker calls = %v", got)
+	}
+
+	os.Remove(calls)
+	app.changedFiles = []string{"main.go"}
+	if app.restartComposeServices() || readCalls(t, calls) != nil {
+		t.Error("No service should restart when no rule matches")
+	}
+}
+
+func TestMatchesComposeRestartIsWatched(t *testing.T) {
+	app := newWindApp(WindConfig{
+		IncludeExts: []string{".go"},
+		Compose:     ComposeConfig{Restart: []ComposeRestartRule{{Pattern: "db/init/*.sql", Service: "db"}}},
+	}, "", "")
+	if !app.shouldWatch("db/init/001.sql") {
+		t.Error("Expected files of a restart rule to be watched")
+	}
+	if app.shouldWatch("db/queries/users.sql") {
+		t.Error("Expected other .sql files not to be watched")
+	}
+}
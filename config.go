@@ -0,0 +1,430 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// configFileName is the optional per-project config file
+const configFileName = ".wind.yaml"
+
+// localConfigFileName holds a developer's own settings on top of
+// configFileName, such as the target picked at startup; it is not meant to
+// be committed
+const localConfigFileName = ".wind.local.yaml"
+
+// Change detection modes
+const (
+	ChangeDetectionMtime = "mtime"
+	ChangeDetectionHash  = "hash"
+)
+
+// defaultConfig returns the zero-configuration defaults
+func defaultConfig() WindConfig {
+	return WindConfig{
+		RunCmd:          "./tmp/main",
+		Detect:          true,
+		ExcludeDirs:     []string{"vendor", ".git", "node_modules", "tmp", ".idea", ".vscode"},
+		IncludeExts:     []string{".go", ".html", ".css", ".js", ".json", ".yaml", ".yml"},
+		PollInterval:    500 * time.Millisecond,
+		MaxPollInterval: 2 * time.Second,
+		MaxWatchedFiles: 10000,
+		DebounceDelay:   300 * time.Millisecond,
+		ChangeDetection: ChangeDetectionMtime,
+		ReadyTimeout:    30 * time.Second,
+		StopSignal:      "SIGTERM",
+		StopTimeout:     10 * time.Second,
+		ForwardSignals:  defaultForwardSignals,
+		EnvFiles:        []string{".env", ".env.local"},
+		MinFreeSpace:    "1GB",
+		SizeAlert:       "20%",
+		AB: ABConfig{
+			Port:           8080,
+			OldPort:        8081,
+			NewPort:        8082,
+			PortEnv:        "PORT",
+			HealthInterval: time.Second,
+		},
+		Proxy: ProxyConfig{
+			Port:         8080,
+			PortEnv:      "PORT",
+			StartTimeout: 30 * time.Second,
+			HoldTimeout:  30 * time.Second,
+		},
+		LoadTest: LoadTestConfig{
+			Requests:    50,
+			Concurrency: 5,
+			Delay:       time.Second,
+			Timeout:     5 * time.Second,
+		},
+		Screenshots: ScreenshotConfig{
+			Width:   1280,
+			Height:  800,
+			Delay:   time.Second,
+			Timeout: 30 * time.Second,
+		},
+		LiveReload: LiveReloadConfig{
+			Port:    3000,
+			Timeout: 5 * time.Second,
+		},
+		ReloadSignal: ReloadSignalConfig{
+			Signal: "SIGHUP",
+		},
+		Retry: RetryConfig{
+			Attempts: 1,
+			Backoff:  2 * time.Second,
+			Patterns: defaultTransientPatterns,
+		},
+		LogFile: LogFileConfig{
+			MaxSize: "10MB",
+			Keep:    3,
+		},
+		PrettyLogs: PrettyLogsConfig{
+			MaxValue: 80,
+		},
+		Rollback: RollbackConfig{
+			Window: 5 * time.Second,
+		},
+		Serve: ServeConfig{
+			Port: 3000,
+		},
+		CI: CIConfig{
+			Vet:   true,
+			Tests: true,
+		},
+		Notifications: NotificationsConfig{
+			Timeout: 5 * time.Second,
+		},
+		HotPatch: HotPatchConfig{
+			Patterns: []string{"*.html", "*.tmpl", "*.gohtml", "*.css", "*.js"},
+			Timeout:  2 * time.Second,
+		},
+		PGOCollectPath:     "/debug/pprof/profile",
+		PGOCollectDuration: 30 * time.Second,
+	}
+}
+
+// loadConfigFile overlays the settings in path, and the base configs it
+// extends, onto config. It reports whether the file existed. The result is
+// not validated, as later overlays may still complete it; see validateConfig.
+func loadConfigFile(path string, config *WindConfig) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	doc, err := parseYAML(data)
+	if err != nil {
+		return true, fmt.Errorf("%s: %v", path, err)
+	}
+
+	docs, err := resolveExtends(path, doc, map[string]bool{})
+	if err != nil {
+		return true, err
+	}
+	for _, d := range docs {
+		if err := decodeConfig(reflect.ValueOf(config).Elem(), d.data, ""); err != nil {
+			return true, fmt.Errorf("%s: %v", d.source, err)
+		}
+	}
+
+	return true, nil
+}
+
+// loadProjectConfig overlays .wind.yaml and then .wind.local.yaml onto
+// config, reporting which of them existed
+func loadProjectConfig(config *WindConfig) (project, local bool, err error) {
+	if project, err = loadConfigFile(configFileName, config); err != nil {
+		return project, false, err
+	}
+	local, err = loadConfigFile(localConfigFileName, config)
+	return project, local, err
+}
+
+// validateConfig checks the settings once every overlay was applied
+func validateConfig(config *WindConfig) error {
+	if config.PollInterval <= 0 {
+		return fmt.Errorf("pollInterval must be positive, got %s", config.PollInterval)
+	}
+	if config.MaxPollInterval < config.PollInterval {
+		return fmt.Errorf("maxPollInterval (%s) must be at least pollInterval (%s)", config.MaxPollInterval, config.PollInterval)
+	}
+	if config.DebounceDelay < 0 {
+		return fmt.Errorf("debounceDelay must not be negative, got %s", config.DebounceDelay)
+	}
+	switch config.ChangeDetection {
+	case "", ChangeDetectionMtime, ChangeDetectionHash:
+	default:
+		return fmt.Errorf("invalid ChangeDetection %q (expected %q or %q)",
+			config.ChangeDetection, ChangeDetectionMtime, ChangeDetectionHash)
+	}
+	if config.MinFreeSpace != "" {
+		if _, err := parseSize(config.MinFreeSpace); err != nil {
+			return fmt.Errorf("minFreeSpace: %v", err)
+		}
+	}
+	if err := validateBuildCache(config.BuildCache); err != nil {
+		return err
+	}
+	if config.SizeAlert != "" {
+		if _, err := parseSizeThreshold(config.SizeAlert); err != nil {
+			return err
+		}
+	}
+	if config.AB.HealthPath != "" && config.AB.HealthInterval <= 0 {
+		return fmt.Errorf("ab.healthInterval must be positive")
+	}
+	if config.Mocks.Preset != "" {
+		if _, ok := mockPresets[config.Mocks.Preset]; !ok {
+			return fmt.Errorf("invalid mocks.preset %q (expected mockery or gomock)", config.Mocks.Preset)
+		}
+	}
+	if config.Watch != "" {
+		if _, err := compileWatchExpr(config.Watch); err != nil {
+			return err
+		}
+	}
+	if err := validateRetry(config.Retry); err != nil {
+		return err
+	}
+	if err := validateRollback(config.Rollback); err != nil {
+		return err
+	}
+	if err := validateNotifications(config.Notifications); err != nil {
+		return err
+	}
+	if err := validateLogFilter(config.LogFilter); err != nil {
+		return err
+	}
+	if err := validateLogFile(config.LogFile); err != nil {
+		return err
+	}
+	if err := validatePreset(config.Preset); err != nil {
+		return err
+	}
+	if err := validateFormat(config.Format); err != nil {
+		return err
+	}
+	if err := validateGenerators(config.Generators); err != nil {
+		return err
+	}
+	if err := validateSidecars(config.Sidecars); err != nil {
+		return err
+	}
+	if err := validateServe(*config); err != nil {
+		return err
+	}
+	if err := validateAssetsGlobs(config.AssetsGlobs); err != nil {
+		return err
+	}
+	if err := validateReloadSignal(config.ReloadSignal); err != nil {
+		return err
+	}
+	if err := validatePalette(config.Palette); err != nil {
+		return err
+	}
+	if err := validateDeploy(config.Deploy); err != nil {
+		return err
+	}
+	if err := validateRemoteBuild(*config); err != nil {
+		return err
+	}
+	if err := validateLint(config.Lint); err != nil {
+		return err
+	}
+	if err := validateCompose(config.Compose); err != nil {
+		return err
+	}
+	if err := validateDocker(*config); err != nil {
+		return err
+	}
+	if err := validateBuildTemplate("ldFlags", config.LDFlags); err != nil {
+		return err
+	}
+	if err := validateBuildTemplate("goFlags", config.GoFlags); err != nil {
+		return err
+	}
+	for _, pattern := range config.HotPatch.Patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid hotPatch pattern %q", pattern)
+		}
+	}
+	if !config.Detect {
+		if config.Target != "" {
+			return fmt.Errorf("target %q requires detect", config.Target)
+		}
+		for _, p := range config.Processes {
+			if p.Target != "" {
+				return fmt.Errorf("process %s: target %q requires detect", p.Name, p.Target)
+			}
+		}
+		if config.BuildCmd == "" && len(config.Processes) == 0 {
+			return fmt.Errorf("detect is off: set buildCmd or processes")
+		}
+	}
+	if _, err := parseStopSignal(config.StopSignal); err != nil {
+		return err
+	}
+	if _, err := parseForwardSignals(config.ForwardSignals); err != nil {
+		return err
+	}
+	if _, err := bindKeys(config.Keys); err != nil {
+		return err
+	}
+	if config.EnvProfile != "" {
+		if _, ok := config.EnvProfiles[config.EnvProfile]; !ok {
+			return fmt.Errorf("envProfile %q is not defined in envProfiles", config.EnvProfile)
+		}
+	}
+	return nil
+}
+
+// normalizeKey lets config keys be written as BuildCmd, buildCmd,
+// build_cmd or build-cmd
+func normalizeKey(key string) string {
+	key = strings.ToLower(key)
+	key = strings.ReplaceAll(key, "_", "")
+	return strings.ReplaceAll(key, "-", "")
+}
+
+// decodeConfig assigns a parsed YAML value to v, converting scalars to the
+// destination type. path is used in error messages.
+func decodeConfig(v reflect.Value, data any, path string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decodeConfig(v.Elem(), data, path)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		m, ok := data.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected a mapping", path)
+		}
+		fields := map[string]int{}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				fields[normalizeKey(v.Type().Field(i).Name)] = i
+			}
+		}
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			i, ok := fields[normalizeKey(key)]
+			if !ok {
+				return fmt.Errorf("unknown config key %q", joinConfigPath(path, key))
+			}
+			field := v.Type().Field(i)
+			if err := decodeConfig(v.Field(i), m[key], joinConfigPath(path, field.Name)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		m, ok := data.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected a mapping", path)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		for key, item := range m {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := decodeConfig(elem, item, joinConfigPath(path, key)); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+		}
+		return nil
+
+	case reflect.Slice:
+		items, ok := data.([]any)
+		if !ok {
+			// A single scalar is shorthand for a one-element list
+			items = []any{data}
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeConfig(slice.Index(i), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+
+	s, ok := data.(string)
+	if !ok {
+		return fmt.Errorf("%s: expected a scalar value", path)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+
+	case reflect.Bool:
+		switch strings.ToLower(s) {
+		case "true", "yes", "on":
+			v.SetBool(true)
+		case "false", "no", "off", "":
+			v.SetBool(false)
+		default:
+			return fmt.Errorf("%s: invalid boolean %q", path, s)
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return fmt.Errorf("%s: invalid duration %q", path, s)
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: invalid integer %q", path, s)
+		}
+		v.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: invalid number %q", path, s)
+		}
+		v.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("%s: invalid number %q", path, s)
+		}
+		v.SetFloat(n)
+
+	default:
+		return fmt.Errorf("%s: unsupported config type %s", path, v.Type())
+	}
+	return nil
+}
+
+func joinConfigPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
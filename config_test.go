@@ -0,0 +1,131 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, configFileName)
+
+	content := `run_cmd: ./tmp/main --debug
+pollInterval: 1s
+change-detection: hash
+includeExts: [.go]
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	config := defaultConfig()
+	found, err := loadConfigFile(path, &config)
+	if err != nil {
+		t.Fatalf("loadConfigFile failed: %v", err)
+	}
+	if !found {
+		t.Fatal("Expected config file to be found")
+	}
+
+	if config.RunCmd != "./tmp/main --debug" {
+		t.Errorf("Expected RunCmd override, got %q", config.RunCmd)
+	}
+	if config.PollInterval != time.Second {
+		t.Errorf("Expected PollInterval 1s, got %v", config.PollInterval)
+	}
+	if config.ChangeDetection != ChangeDetectionHash {
+		t.Errorf("Expected hash change detection, got %q", config.ChangeDetection)
+	}
+	if len(config.IncludeExts) != 1 || config.IncludeExts[0] != ".go" {
+		t.Errorf("Expected IncludeExts [.go], got %v", config.IncludeExts)
+	}
+
+	// Settings not in the file keep their defaults
+	if config.DebounceDelay != 300*time.Millisecond {
+		t.Errorf("Expected default DebounceDelay, got %v", config.DebounceDelay)
+	}
+}
+
+func TestLoadConfigFileMissing(t *testing.T) {
+	config := defaultConfig()
+	found, err := loadConfigFile(filepath.Join(t.TempDir(), configFileName), &config)
+	if err != nil || found {
+		t.Errorf("Expected missing config to be ignored, got found=%v err=%v", found, err)
+	}
+}
+
+func TestLoadConfigFileInvalid(t *testing.T) {
+	tests := []string{
+		"unknownKey: 1",
+		"pollInterval: soon",
+		"pollInterval: 0s",
+		"pollInterval: 1s\nmaxPollInterval: 500ms",
+		"debounceDelay: -1s",
+		"changeDetection: inotify",
+		"detect: false",
+		"detect: false\nbuildCmd: go build -o ./tmp/main .\ntarget: api",
+		"detect: false\nprocesses:\n  - name: api\n    target: api",
+	}
+
+	for _, content := range tests {
+		path := filepath.Join(t.TempDir(), configFileName)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		config := defaultConfig()
+		_, err := loadConfigFile(path, &config)
+		if err == nil {
+			err = validateConfig(&config)
+		}
+		if err == nil {
+			t.Errorf("Expected an error for %q", content)
+		}
+	}
+}
+
+func TestLoadWatchConfigValidatesMerged(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+
+	// The project's settings alone are incomplete; the local file completes
+	// them
+	os.WriteFile(configFileName, []byte("detect: false\n"), 0644)
+	os.WriteFile(localConfigFileName, []byte("buildCmd: go build -o ./tmp/main ./cmd/api\n"), 0644)
+	if _, ok := loadWatchConfig(watchOptions{}); !ok {
+		t.Error("Expected the merged config to be valid")
+	}
+
+	// Command line overrides are validated like the files
+	if _, ok := loadWatchConfig(watchOptions{ldflags: "-X main.v={{gitTag}}"}); ok {
+		t.Error("Expected an unknown template variable in --ldflags to be rejected")
+	}
+}
+
+func TestDetectDisabled(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	// A main package that detection would otherwise pick up
+	os.MkdirAll(filepath.Join("cmd", "experiment"), 0755)
+	os.WriteFile(filepath.Join("cmd", "experiment", "main.go"), []byte("package main\n"), 0644)
+
+	content := "detect: false\nbuildCmd: go build -o ./tmp/main ./internal/app\n"
+	os.WriteFile(configFileName, []byte(content), 0644)
+	config := defaultConfig()
+	if _, err := loadConfigFile(configFileName, &config); err != nil {
+		t.Fatalf("loadConfigFile failed: %v", err)
+	}
+
+	if _, err := resolveBuildCmd(&config, ""); err != nil {
+		t.Fatalf("resolveBuildCmd failed: %v", err)
+	}
+	if config.BuildCmd != "go build -o ./tmp/main ./internal/app" {
+		t.Errorf("Expected the configured build command, got %q", config.BuildCmd)
+	}
+	if _, err := resolveBuildCmd(&config, "experiment"); err == nil {
+		t.Errorf("Expected wind run <target> to fail with detect off")
+	}
+}
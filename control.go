@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// controlAPI serves the local HTTP endpoint that lets editors and scripts
+// query build state and drive the watcher
+type controlAPI struct {
+	orch   *orchestrator
+	log    *eventLog
+	server *http.Server
+	// observer serves the read-only endpoints, nil when not attachable
+	observer *http.Server
+}
+
+func newControlAPI(orch *orchestrator, log *eventLog) *controlAPI {
+	c := &controlAPI{orch: orch, log: log}
+	c.server = &http.Server{Handler: c.handler()}
+	return c
+}
+
+// listen serves the API on a TCP address, or on a unix socket for network
+// "unix", in the background
+func (c *controlAPI) listen(network, addr string) error {
+	listener, err := net.Listen(network, addr)
+	if err != nil {
+		return err
+	}
+	go serveListener(c.server, listener)
+	return nil
+}
+
+// serveUnix serves server on a unix socket at path in the background
+func serveUnix(server *http.Server, path string) error {
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	go serveListener(server, listener)
+	return nil
+}
+
+func serveListener(server *http.Server, listener net.Listener) {
+	if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+		logf(levelError, Red+"Error: "+Reset+"Control API failed: %v\n", err)
+	}
+}
+
+func (c *controlAPI) stop() {
+	c.server.Close()
+	if c.observer != nil {
+		c.observer.Close()
+	}
+}
+
+func (c *controlAPI) handler() http.Handler {
+	mux := http.NewServeMux()
+	c.observeRoutes(mux)
+	mux.HandleFunc("POST /rebuild", c.serveRebuild)
+	mux.HandleFunc("POST /stop", c.serveStop)
+	return mux
+}
+
+// observeRoutes adds the endpoints that only read the session's state
+func (c *controlAPI) observeRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /status", c.serveStatus)
+	mux.HandleFunc("GET /stats", c.serveStats)
+	mux.HandleFunc("GET /logs/stream", func(w http.ResponseWriter, r *http.Request) {
+		c.stream(w, r, true)
+	})
+	mux.HandleFunc("GET /events", func(w http.ResponseWriter, r *http.Request) {
+		c.stream(w, r, false)
+	})
+}
+
+// serveStatus reports the state of every target
+func (c *controlAPI) serveStatus(w http.ResponseWriter, r *http.Request) {
+	var targets []appStatus
+	for _, app := range c.orch.apps {
+		targets = append(targets, app.currentStatus())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]appStatus{"targets": targets})
+}
+
+// serveStats reports the timing of the latest build cycles of every target
+func (c *controlAPI) serveStats(w http.ResponseWriter, r *http.Request) {
+	cycles := []cycleStats{}
+	for _, app := range c.orch.apps {
+		cycles = append(cycles, app.recentCycles()...)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]cycleStats{"cycles": cycles})
+}
+
+// serveRebuild rebuilds every target, or only ?target=<name>
+func (c *controlAPI) serveRebuild(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("target")
+	found := false
+	for _, app := range c.orch.apps {
+		if name == "" || app.name == name {
+			app.requestRebuild()
+			found = true
+		}
+	}
+	if !found {
+		http.Error(w, fmt.Sprintf("unknown target %q", name), http.StatusNotFound)
+		return
+	}
+	logf(levelInfo, Cyan+"Info: "+Reset+"Rebuild requested via control API\n")
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// serveStop shuts Wind down as if interrupted
+func (c *controlAPI) serveStop(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusAccepted)
+	c.orch.requestQuit()
+}
+
+// stream sends events as Server-Sent Events: the output lines for
+// /logs/stream, everything else for /events
+func (c *controlAPI) stream(w http.ResponseWriter, r *http.Request, logs bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	evs, unsubscribe := c.log.subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev := <-evs:
+			switch {
+			case logs && ev.Event == "log":
+				fmt.Fprintf(w, "data: %s\n\n", ev.Message)
+			case !logs && ev.Event != "log":
+				data, _ := json.Marshal(ev)
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Event, data)
+			default:
+				continue
+			}
+			flusher.Flush()
+		}
+	}
+}
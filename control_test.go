@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestControlAPI() (*controlAPI, *httptest.Server) {
+	api := newWindApp(defaultConfig(), "api", Cyan)
+	worker := newWindApp(defaultConfig(), "worker", Purple)
+	log := &eventLog{subscribers: make(map[chan event]bool)}
+	c := newControlAPI(newOrchestrator([]*WindApp{api, worker}), log)
+	return c, httptest.NewServer(c.handler())
+}
+
+func TestControlStatus(t *testing.T) {
+	c, server := newTestControlAPI()
+	defer server.Close()
+
+	api := c.orch.apps[0]
+	api.emit(event{Event: "build_start", Build: 4})
+	api.emit(event{Event: "build_ok", Build: 4, DurationMs: 800})
+	api.emit(event{Event: "app_start", PID: 1234})
+	// The previous process of a proxy handoff exiting doesn't stop the target
+	api.emit(event{Event: "app_exit", PID: 1000})
+
+	resp, err := http.Get(server.URL + "/status")
+	if err != nil {
+		t.Fatalf("GET /status failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct{ Targets []appStatus }
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Invalid status response: %v", err)
+	}
+	if len(body.Targets) != 2 {
+		t.Fatalf("Expected 2 targets, got %+v", body.Targets)
+	}
+	if s := body.Targets[0]; s.Target != "api" || s.State != "running" || s.PID != 1234 || s.Build != 4 || s.BuildMs != 800 {
+		t.Errorf("Unexpected api status: %+v", s)
+	}
+	if s := body.Targets[1]; s.State != "idle" {
+		t.Errorf("Expected worker to be idle, got %+v", s)
+	}
+}
+
+func TestControlRebuildAndStop(t *testing.T) {
+	c, server := newTestControlAPI()
+	defer server.Close()
+
+	resp, _ := http.Post(server.URL+"/rebuild?target=worker", "", nil)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected 202 for rebuild, got %d", resp.StatusCode)
+	}
+	if len(c.orch.apps[0].rebuildChan) != 0 || len(c.orch.apps[1].rebuildChan) != 1 {
+		t.Errorf("Expected only worker to be rebuilt")
+	}
+
+	resp, _ = http.Post(server.URL+"/rebuild?target=nope", "", nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown target, got %d", resp.StatusCode)
+	}
+
+	resp, _ = http.Get(server.URL + "/rebuild")
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected GET /rebuild to be rejected, got %d", resp.StatusCode)
+	}
+
+	resp, _ = http.Post(server.URL+"/stop", "", nil)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected 202 for stop, got %d", resp.StatusCode)
+	}
+	select {
+	case <-c.orch.quitChan:
+	default:
+		t.Errorf("Expected /stop to request quit")
+	}
+}
+
+func TestControlStreams(t *testing.T) {
+	c, server := newTestControlAPI()
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	read := func(path string) <-chan string {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		lines := make(chan string, 16)
+		go func() {
+			defer resp.Body.Close()
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				if strings.HasPrefix(scanner.Text(), "data: ") || strings.HasPrefix(scanner.Text(), "event: ") {
+					lines <- scanner.Text()
+				}
+			}
+		}()
+		return lines
+	}
+	logs := read("/logs/stream")
+	evs := read("/events")
+
+	// Wait for both streams to subscribe
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		c.log.mutex.Lock()
+		n := len(c.log.subscribers)
+		c.log.mutex.Unlock()
+		if n == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	events = c.log
+	defer func() { events = nil }()
+	stream := &eventStream{log: c.log, stream: "stdout"}
+	stream.Write([]byte("listening on :8080\n"))
+	c.orch.apps[0].emit(event{Event: "build_start", Build: 1})
+
+	expect := func(lines <-chan string, want string) {
+		t.Helper()
+		select {
+		case line := <-lines:
+			if !strings.Contains(line, want) {
+				t.Errorf("Expected %q in stream, got %q", want, line)
+			}
+		case <-time.After(2 * time.Second):
+			t.Errorf("Timed out waiting for %q", want)
+		}
+	}
+	expect(logs, "data: listening on :8080")
+	expect(evs, "event: build_start")
+	expect(evs, `"build":1`)
+}
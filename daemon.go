@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Files of a background session, relative to the project root
+const (
+	daemonPidFile = "tmp/wind.pid"
+	daemonSocket  = "tmp/wind.sock"
+	daemonLog     = "tmp/wind.log"
+)
+
+// daemonEnv marks the detached process started by `wind daemon`
+const daemonEnv = "WIND_DAEMON"
+
+// daemonTimeout bounds how long `wind daemon` and `wind stop` wait for the
+// daemon to come up or go away
+const daemonTimeout = 10 * time.Second
+
+// runDaemon implements `wind daemon`: it starts Wind again as a detached
+// process writing to daemonLog, waits until its socket answers and returns.
+// The detached process parses the same command line, so it runs with the
+// global flags given to `wind daemon`.
+func runDaemon(opts watchOptions) {
+	if os.Getenv(daemonEnv) == "1" {
+		opts.daemon = true
+		runWatcher(opts)
+		return
+	}
+
+	if pid := daemonPID(); pid != 0 {
+		logf(levelWarn, Yellow+"Warning: "+Reset+"Wind daemon already running (PID: %d)\n", pid)
+		return
+	}
+	if err := os.MkdirAll("tmp", 0755); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Failed to create tmp directory: %v\n", err)
+		return
+	}
+	logFile, err := os.OpenFile(daemonLog, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Failed to open %s: %v\n", daemonLog, err)
+		return
+	}
+	defer logFile.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Failed to locate the wind binary: %v\n", err)
+		return
+	}
+	cmd := exec.Command(exe, daemonArgs(os.Args[1:])...)
+	cmd.Env = append(os.Environ(), daemonEnv+"=1")
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = detachedAttr()
+	if err := cmd.Start(); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Failed to start the daemon: %v\n", err)
+		return
+	}
+	pid := cmd.Process.Pid
+	cmd.Process.Release()
+
+	_, err = pollReady(func() bool {
+		_, err := daemonRequest(http.MethodGet, "/status")
+		return err == nil
+	}, daemonTimeout)
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Wind daemon (PID: %d) did not come up %v; see %s\n", pid, err, daemonLog)
+		return
+	}
+	logf(levelSuccess, Green+"Success: "+Reset+"Wind daemon started (PID: %d), output in %s\n", pid, daemonLog)
+	logf(levelInfo, Cyan+"Info: "+Reset+"Control it with wind status, wind logs daemon, wind rebuild and wind stop\n")
+}
+
+// daemonArgs returns the command line of the detached process: the one of
+// `wind daemon` without --record-session, as the session is recorded here
+func daemonArgs(args []string) []string {
+	for i, arg := range args {
+		if arg == "--" {
+			return append(daemonArgs(args[:i]), args[i:]...)
+		}
+	}
+	rest, _, _ := extractValueFlag(args, recordFlag)
+	return rest
+}
+
+// daemonPID returns the PID of the running daemon, or 0
+func daemonPID() int {
+	data, err := os.ReadFile(daemonPidFile)
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || !processAlive(pid) {
+		return 0
+	}
+	return pid
+}
+
+// daemonClient talks HTTP to the daemon's control API over daemonSocket
+var daemonClient = socketClient(daemonSocket)
+
+// socketClient returns an HTTP client connecting to the unix socket at path
+func socketClient(path string) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		},
+	}
+}
+
+// daemonRequest calls the daemon's control API and returns the response
+// body
+func daemonRequest(method, path string) ([]byte, error) {
+	req, err := http.NewRequest(method, "http://wind"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := daemonClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s", strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// runStatus implements `wind status`
+func runStatus() {
+	pid := daemonPID()
+	if pid == 0 {
+		logf(levelInfo, Cyan+"Info: "+Reset+"No Wind daemon running (start one with wind daemon)\n")
+		return
+	}
+	body, err := daemonRequest(http.MethodGet, "/status")
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Wind daemon (PID: %d) not responding: %v\n", pid, err)
+		return
+	}
+	var status struct{ Targets []appStatus }
+	if err := json.Unmarshal(body, &status); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Invalid status response: %v\n", err)
+		return
+	}
+
+	fmt.Printf(Green+"Wind daemon running"+Reset+" (PID: %d)\n", pid)
+	for _, s := range status.Targets {
+		fmt.Println("  " + fitLine(formatStatus(s), " · ", "    ", terminalWidth()-2))
+	}
+}
+
+// formatStatus describes a target's status on one line
+func formatStatus(s appStatus) string {
+	color := Yellow
+	switch s.State {
+	case "running":
+		color = Green
+	case "building":
+		color = Cyan
+	case "build_failed":
+		color = Red
+	}
+
+	parts := []string{color + s.State + Reset}
+	if s.Target != "" {
+		parts[0] = "[" + s.Target + "] " + parts[0]
+	}
+	if s.Paused {
+		parts = append(parts, "paused")
+	}
+	if s.PID != 0 {
+		parts = append(parts, fmt.Sprintf("PID %d", s.PID))
+	}
+	if s.Build != 0 {
+		build := fmt.Sprintf("build #%d", s.Build)
+		if s.BuildMs != 0 {
+			build += fmt.Sprintf(" in %v", time.Duration(s.BuildMs)*time.Millisecond)
+		}
+		parts = append(parts, build)
+	}
+	if s.Error != "" {
+		parts = append(parts, "error: "+s.Error)
+	}
+	if s.LastChange != "" {
+		parts = append(parts, "last change: "+s.LastChange)
+	}
+	if len(s.Vulns) > 0 {
+		parts = append(parts, Red+"vulnerable: "+strings.Join(s.Vulns, ", ")+Reset)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// runRebuild implements `wind rebuild [target]`
+func runRebuild(args []string) {
+	path := "/rebuild"
+	if len(args) > 0 {
+		path += "?target=" + url.QueryEscape(args[0])
+	}
+	if _, err := daemonRequest(http.MethodPost, path); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Rebuild failed: %v\n", err)
+		return
+	}
+	logf(levelSuccess, Green+"Success: "+Reset+"Rebuild requested\n")
+}
+
+// runStop implements `wind stop`: it asks the daemon to shut down, falling
+// back to SIGTERM, and waits for it to exit
+func runStop() {
+	pid := daemonPID()
+	if pid == 0 {
+		logf(levelInfo, Cyan+"Info: "+Reset+"No Wind daemon running\n")
+		return
+	}
+	if _, err := daemonRequest(http.MethodPost, "/stop"); err != nil {
+		signalPID(pid, syscall.SIGTERM)
+	}
+
+	if _, err := pollReady(func() bool { return !processAlive(pid) }, daemonTimeout); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Wind daemon (PID: %d) is still running\n", pid)
+		return
+	}
+	logf(levelSuccess, Green+"Success: "+Reset+"Wind daemon (PID: %d) stopped\n", pid)
+}
+
+// followDaemonLog implements `wind logs daemon`: it prints the end of
+// daemonLog, then new output until the daemon exits
+func followDaemonLog(lines int) {
+	file, err := os.Open(daemonLog)
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"No daemon log: %v\n", err)
+		return
+	}
+	defer file.Close()
+
+	data, _ := io.ReadAll(file)
+	fmt.Print(lastLines(string(data), lines))
+
+	for daemonPID() != 0 {
+		time.Sleep(readyPollInterval)
+		io.Copy(os.Stdout, file)
+	}
+	io.Copy(os.Stdout, file)
+}
+
+// lastLines returns the last n lines of s
+func lastLines(s string, n int) string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "")
+}
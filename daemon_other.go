@@ -0,0 +1,11 @@
+//go:build !unix
+
+package main
+
+import "syscall"
+
+// detachedAttr starts the daemon like any child; there are no sessions to
+// detach it from the terminal
+func detachedAttr() *syscall.SysProcAttr {
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDaemonPID(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	os.MkdirAll("tmp", 0755)
+
+	if pid := daemonPID(); pid != 0 {
+		t.Errorf("Expected no daemon without a pidfile, got %d", pid)
+	}
+	os.WriteFile(daemonPidFile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644)
+	if pid := daemonPID(); pid != os.Getpid() {
+		t.Errorf("Expected daemon PID %d, got %d", os.Getpid(), pid)
+	}
+	// A pidfile left behind by a crashed daemon is ignored
+	os.WriteFile(daemonPidFile, []byte("999999999"), 0644)
+	if pid := daemonPID(); pid != 0 {
+		t.Errorf("Expected a stale pidfile to be ignored, got %d", pid)
+	}
+}
+
+func TestDaemonSocket(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	os.MkdirAll("tmp", 0755)
+
+	app := newWindApp(defaultConfig(), "", "")
+	orch := newOrchestrator([]*WindApp{app})
+	api := newControlAPI(orch, &eventLog{subscribers: make(map[chan event]bool)})
+	if err := api.listen("unix", daemonSocket); err != nil {
+		t.Fatalf("Failed to listen on %s: %v", daemonSocket, err)
+	}
+	defer api.stop()
+
+	app.emit(event{Event: "build_fail", Build: 2, Error: "exit status 1"})
+	body, err := daemonRequest(http.MethodGet, "/status")
+	if err != nil {
+		t.Fatalf("GET /status over the socket failed: %v", err)
+	}
+	if !strings.Contains(string(body), `"state":"build_failed"`) {
+		t.Errorf("Unexpected status: %s", body)
+	}
+
+	if _, err := daemonRequest(http.MethodPost, "/rebuild"); err != nil {
+		t.Errorf("POST /rebuild failed: %v", err)
+	}
+	if len(app.rebuildChan) != 1 {
+		t.Errorf("Expected a rebuild to be requested")
+	}
+	if _, err := daemonRequest(http.MethodPost, "/rebuild?target=nope"); err == nil {
+		t.Errorf("Expected an error for an unknown target")
+	}
+}
+
+func TestFormatStatus(t *testing.T) {
+	s := appStatus{Target: "api", State: "running", PID: 42, Build: 7, BuildMs: 1250, LastChange: "main.go"}
+	line := formatStatus(s)
+	for _, want := range []string{"[api]", "running", "PID 42", "build #7 in 1.25s", "last change: main.go"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("Expected %q in %q", want, line)
+		}
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	if got := lastLines("a\nb\nc\n", 2); got != "b\nc\n" {
+		t.Errorf("lastLines = %q", got)
+	}
+	if got := lastLines("a\nb", 5); got != "a\nb" {
+		t.Errorf("lastLines = %q", got)
+	}
+}
+
+func TestDaemonArgs(t *testing.T) {
+	args := []string{"--tags", "x", "-q", "daemon", recordFlag, "s.cast", "--", recordFlag, "app"}
+	expected := []string{"--tags", "x", "-q", "daemon", "--", recordFlag, "app"}
+	if got := daemonArgs(args); !reflect.DeepEqual(got, expected) {
+		t.Errorf("daemonArgs() = %q, expected %q", got, expected)
+	}
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// goPackage is the part of `go list` output Wind uses to relate changed
+// files to packages
+type goPackage struct {
+	ImportPath string
+	// Dir is the normalized package directory (see projectPath)
+	Dir string
+	// Deps holds every package the package depends on, transitively
+	Deps []string
+	// TestImports holds the direct imports of the package's tests
+	TestImports []string
+	// EmbedFiles are the //go:embed files, relative to Dir
+	EmbedFiles []string
+}
+
+// listPackagesFormat prints one package per line for loadPackages
+const listPackagesFormat = `{{.ImportPath}}|{{.Dir}}|{{join .Deps ","}}|{{join .TestImports ","}},{{join .XTestImports ","}}|{{join .EmbedFiles ","}}`
+
+// loadPackages lists the packages of the module in the current directory
+func loadPackages() ([]goPackage, error) {
+	out, err := exec.Command("go", "list", "-e", "-f", listPackagesFormat, "./...").Output()
+	if err != nil {
+		return nil, err
+	}
+	var pkgs []goPackage
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+		pkgs = append(pkgs, goPackage{
+			ImportPath:  parts[0],
+			Dir:         projectPath(parts[1]),
+			Deps:        splitList(parts[2]),
+			TestImports: splitList(parts[3]),
+			EmbedFiles:  splitList(parts[4]),
+		})
+	}
+	return pkgs, nil
+}
+
+// splitList splits a comma-separated go list field, dropping empty items
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// changeImpact is what a set of changes requires of a build target
+type changeImpact int
+
+const (
+	// impactNone: no changed file reaches the target
+	impactNone changeImpact = iota
+	// impactRestart: only files read at runtime changed
+	impactRestart
+	// impactRebuild: the target's code or embedded files changed
+	impactRebuild
+)
+
+// depGraph caches the module's package graph. It is shared by every target
+// of a session and reloaded when a Go file's imports change.
+type depGraph struct {
+	mutex sync.Mutex
+	pkgs  []goPackage
+	byDir map[string]goPackage
+	// imports holds the import fingerprint of every Go file as of the last
+	// load
+	imports map[string]string
+	// closures caches relevantPackages per target directory until the next
+	// load
+	closures map[string]map[string]bool
+	// failed is set when the first load for outsideTarget failed, so a
+	// broken module doesn't run go list on every scanned file
+	failed bool
+}
+
+func newDepGraph() *depGraph {
+	return &depGraph{}
+}
+
+// load runs go list and fingerprints the imports of every package's files
+func (g *depGraph) load() error {
+	pkgs, err := loadPackages()
+	if err != nil {
+		return err
+	}
+	g.pkgs = pkgs
+	g.closures = make(map[string]map[string]bool)
+	g.byDir = make(map[string]goPackage, len(pkgs))
+	g.imports = make(map[string]string)
+	for _, pkg := range pkgs {
+		g.byDir[pkg.Dir] = pkg
+		files, _ := filepath.Glob(filepath.Join(pkg.Dir, "*.go"))
+		for _, file := range files {
+			if fp, err := importFingerprint(file); err == nil {
+				g.imports[projectPath(file)] = fp
+			}
+		}
+	}
+	return nil
+}
+
+// importFingerprint lists the imports of a Go file
+func importFingerprint(path string) (string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ImportsOnly)
+	if err != nil {
+		return "", err
+	}
+	var imports []string
+	for _, imp := range file.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		imports = append(imports, p)
+	}
+	return strings.Join(imports, "\n"), nil
+}
+
+// refresh reloads the graph on first use and whenever a changed Go file is
+// new or has different imports
+func (g *depGraph) refresh(changed []string) error {
+	stale := g.pkgs == nil
+	for _, path := range changed {
+		if stale {
+			break
+		}
+		if filepath.Ext(path) != ".go" {
+			continue
+		}
+		fp, err := importFingerprint(path)
+		previous, known := g.imports[projectPath(path)]
+		stale = err != nil || !known || fp != previous
+	}
+	if !stale {
+		return nil
+	}
+	return g.load()
+}
+
+// impact classifies changed files for the main package in targetDir.
+// Generator inputs always count as rebuilds, since generators run as part of
+// the build. When the graph cannot be loaded every change rebuilds.
+func (g *depGraph) impact(targetDir string, changed []string, isGeneratorInput func(string) bool) changeImpact {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if err := g.refresh(changed); err != nil {
+		logf(levelWarn, Yellow+"Warning: "+Reset+"Failed to load package graph: %v\n", err)
+		return impactRebuild
+	}
+	relevant, ok := g.relevantPackages(targetDir)
+	if !ok {
+		return impactRebuild
+	}
+
+	impact := impactNone
+	for _, path := range changed {
+		path = projectPath(path)
+		switch {
+		case isGeneratorInput(path), isModuleFile(path):
+			return impactRebuild
+		case strings.HasSuffix(path, "_test.go"):
+		case filepath.Ext(path) == ".go":
+			pkg, ok := g.byDir[projectPath(filepath.Dir(path))]
+			if !ok || relevant[pkg.ImportPath] {
+				return impactRebuild
+			}
+		case g.embedded(path, relevant):
+			return impactRebuild
+		default:
+			impact = impactRestart
+		}
+	}
+	return impact
+}
+
+// relevantPackages returns the import paths of the main package in
+// targetDir and of every package it depends on. It reports false when the
+// graph doesn't hold the package.
+func (g *depGraph) relevantPackages(targetDir string) (map[string]bool, bool) {
+	targetDir = projectPath(targetDir)
+	if relevant, ok := g.closures[targetDir]; ok {
+		return relevant, true
+	}
+	target, ok := g.byDir[targetDir]
+	if !ok {
+		return nil, false
+	}
+	relevant := map[string]bool{target.ImportPath: true}
+	for _, dep := range target.Deps {
+		relevant[dep] = true
+	}
+	g.closures[targetDir] = relevant
+	return relevant, true
+}
+
+// outsideTarget reports whether path belongs to a package of the module the
+// main package in targetDir doesn't depend on: one of its Go files, or
+// another file directly in its directory that isn't embedded into the
+// target. Files outside every package directory, such as templates/, and
+// the files next to go.mod are never left out. Neither is anything when
+// the graph cannot be loaded.
+func (g *depGraph) outsideTarget(targetDir, file string) bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.pkgs == nil {
+		if g.failed {
+			return false
+		}
+		if err := g.load(); err != nil {
+			g.failed = true
+			logf(levelWarn, Yellow+"Warning: "+Reset+"Failed to load package graph, watching every package: %v\n", err)
+			return false
+		}
+	}
+	relevant, ok := g.relevantPackages(targetDir)
+	if !ok {
+		return false
+	}
+	file = projectPath(file)
+	pkg, ok := g.byDir[path.Dir(file)]
+	if !ok || relevant[pkg.ImportPath] {
+		return false
+	}
+	if filepath.Ext(file) != ".go" {
+		return pkg.Dir != "." && !g.embedded(file, relevant)
+	}
+	return true
+}
+
+// scopes reports whether outsideTarget leaves files out for the main
+// package in targetDir, i.e. the loaded graph holds it
+func (g *depGraph) scopes(targetDir string) bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	_, ok := g.byDir[projectPath(targetDir)]
+	return ok
+}
+
+// update reloads the graph when the imports of a changed Go file differ,
+// so the files outsideTarget leaves out follow the target's imports
+func (g *depGraph) update(changed []string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.pkgs == nil {
+		return
+	}
+	if err := g.refresh(changed); err != nil {
+		logf(levelWarn, Yellow+"Warning: "+Reset+"Failed to load package graph: %v\n", err)
+	}
+}
+
+// embedded reports whether path is embedded into one of the relevant
+// packages
+func (g *depGraph) embedded(path string, relevant map[string]bool) bool {
+	for _, pkg := range g.pkgs {
+		if !relevant[pkg.ImportPath] || len(pkg.EmbedFiles) == 0 {
+			continue
+		}
+		if !underDir(path, pkg.Dir) {
+			continue
+		}
+		for _, file := range pkg.EmbedFiles {
+			if projectPath(filepath.Join(pkg.Dir, file)) == path {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// buildPackageDir extracts the main package directory from a "go build"
+// command. It reports false for other build commands.
+func buildPackageDir(buildCmd string) (string, bool) {
+	fields := strings.Fields(buildCmd)
+	if len(fields) < 2 || fields[0] != "go" || fields[1] != "build" {
+		return "", false
+	}
+
+	dir := "."
+	for i := 2; i < len(fields); i++ {
+		field := fields[i]
+		switch {
+		case field == "-o" || field == "-tags" || field == "-ldflags" || field == "-gcflags" || field == "-pgo":
+			i++
+		case strings.HasPrefix(field, "-"):
+		default:
+			dir = field
+		}
+	}
+	if strings.HasSuffix(dir, ".go") {
+		dir = filepath.Dir(dir)
+	}
+	return projectPath(dir), true
+}
+
+// changeImpact classifies the current cycle's changes for this target. Test
+// mode, custom build commands and sessions without DependencyGraph always
+// rebuild.
+func (app *WindApp) changeImpact() changeImpact {
+	if app.depGraph == nil || app.tests != nil || len(app.changedFiles) == 0 {
+		return impactRebuild
+	}
+	dir, ok := buildPackageDir(app.config.BuildCmd)
+	if !ok {
+		return impactRebuild
+	}
+	return app.depGraph.impact(dir, app.changedFiles, app.matchesGenerator)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildPackageDir(t *testing.T) {
+	tests := []struct {
+		cmd      string
+		expected string
+		ok       bool
+	}{
+		{"go build -o ./tmp/main ./cmd/api", "cmd/api", true},
+		{"go build -o ./tmp/main .", ".", true},
+		{"go build -tags dev -o ./tmp/main", ".", true},
+		{"go build -o ./tmp/main ./cmd/api/main.go", "cmd/api", true},
+		{"make build", "", false},
+	}
+
+	for _, tt := range tests {
+		dir, ok := buildPackageDir(tt.cmd)
+		if dir != tt.expected || ok != tt.ok {
+			t.Errorf("buildPackageDir(%q) = %q, %v; expected %q, %v", tt.cmd, dir, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestDepGraphImpact(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"go.mod":                         "module example.com/dg\n\ngo 1.21\n",
+		"cmd/api/main.go":                "package main\n\nimport (\n\t_ \"example.com/dg/internal/assets\"\n\t_ \"example.com/dg/internal/store\"\n)\n\nfunc main() {}\n",
+		"cmd/worker/main.go":             "package main\n\nfunc main() {}\n",
+		"internal/store/store.go":        "package store\n",
+		"internal/store/store_test.go":   "package store\n",
+		"internal/assets/assets.go":      "package assets\n\nimport \"embed\"\n\n//go:embed static\nvar FS embed.FS\n",
+		"internal/assets/static/app.css": "body {}\n",
+		"web/templates/index.html":       "<html></html>\n",
+		"api/user.proto":                 "syntax = \"proto3\";\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	isProto := func(path string) bool { return filepath.Ext(path) == ".proto" }
+	graph := newDepGraph()
+	tests := []struct {
+		target   string
+		changed  string
+		expected changeImpact
+	}{
+		{"cmd/api", "internal/store/store.go", impactRebuild},
+		{"cmd/worker", "internal/store/store.go", impactNone},
+		{"cmd/api", "cmd/worker/main.go", impactNone},
+		{"cmd/api", "internal/store/store_test.go", impactNone},
+		{"cmd/api", "web/templates/index.html", impactRestart},
+		{"cmd/api", "internal/assets/static/app.css", impactRebuild},
+		{"cmd/worker", "internal/assets/static/app.css", impactRestart},
+		{"cmd/worker", "api/user.proto", impactRebuild},
+		{"cmd/worker", "go.mod", impactRebuild},
+	}
+
+	for _, tt := range tests {
+		if got := graph.impact(tt.target, []string{tt.changed}, isProto); got != tt.expected {
+			t.Errorf("impact(%s, %s) = %v, expected %v", tt.target, tt.changed, got, tt.expected)
+		}
+	}
+
+	// A new import makes the worker depend on the store
+	os.WriteFile("cmd/worker/main.go", []byte("package main\n\nimport _ \"example.com/dg/internal/store\"\n\nfunc main() {}\n"), 0644)
+	if got := graph.impact("cmd/worker", []string{"cmd/worker/main.go"}, isProto); got != impactRebuild {
+		t.Errorf("Expected worker change to rebuild, got %v", got)
+	}
+	if got := graph.impact("cmd/worker", []string{"internal/store/store.go"}, isProto); got != impactRebuild {
+		t.Errorf("Expected the graph to pick up the new import, got %v", got)
+	}
+}
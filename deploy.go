@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DeployConfig runs each build on a remote host instead of locally: the
+// binary is copied over SSH and the app restarted there
+type DeployConfig struct {
+	// Host is the SSH destination, e.g. pi@raspberrypi.local; empty runs
+	// builds locally
+	Host string
+	// Path is where the binary is installed on the host, by default its
+	// file name in the remote home directory
+	Path string
+	// Copy is the transfer tool, scp (the default) or rsync
+	Copy string
+	// Restart is run on the host after each install, e.g.
+	// sudo systemctl restart app
+	Restart string
+}
+
+// validateDeploy checks the deploy settings
+func validateDeploy(deploy DeployConfig) error {
+	switch deploy.Copy {
+	case "", "scp", "rsync":
+	default:
+		return fmt.Errorf("deploy.copy must be scp or rsync, got %q", deploy.Copy)
+	}
+	if deploy.Host == "" && (deploy.Path != "" || deploy.Restart != "") {
+		return fmt.Errorf("deploy requires a host")
+	}
+	return nil
+}
+
+// sshOptions keep ssh, scp and rsync from prompting for a password, which
+// would hang with the terminal in raw mode; use keys or an agent
+var sshOptions = []string{"-o", "BatchMode=yes"}
+
+// crossCompiled reports whether builds target another platform than the
+// one Wind runs on
+func (app *WindApp) crossCompiled() bool {
+	return (app.config.GOOS != "" && app.config.GOOS != runtime.GOOS) ||
+		(app.config.GOARCH != "" && app.config.GOARCH != runtime.GOARCH)
+}
+
+// buildPlatform names the platform builds target, e.g. linux/arm64
+func (app *WindApp) buildPlatform() string {
+	goos, goarch := app.config.GOOS, app.config.GOARCH
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	if goarch == "" {
+		goarch = runtime.GOARCH
+	}
+	return goos + "/" + goarch
+}
+
+// deployBinary is the local binary to deploy: the output of the build
+// command, or the program of the run command
+func (app *WindApp) deployBinary() string {
+	if outputs := buildOutputs(app.config.BuildCmd); len(outputs) > 0 {
+		return strings.Trim(outputs[0], `"'`)
+	}
+	if fields := strings.Fields(app.config.RunCmd); len(fields) > 0 {
+		return fields[0]
+	}
+	return filepath.Join("tmp", "main")
+}
+
+// deployCommands returns the command copying binary next to its remote
+// path and the ssh command moving it into place and restarting the app.
+// The copy gets a .new suffix because a running binary cannot be
+// overwritten.
+func deployCommands(deploy DeployConfig, binary string) (copyCmd, restartCmd []string) {
+	path := deploy.Path
+	if path == "" {
+		path = filepath.Base(binary)
+	}
+	staged := path + ".new"
+
+	if deploy.Copy == "rsync" {
+		copyCmd = []string{"rsync", "-z", "-e", "ssh " + strings.Join(sshOptions, " "), binary, deploy.Host + ":" + staged}
+	} else {
+		copyCmd = append(append([]string{"scp", "-q"}, sshOptions...), binary, deploy.Host+":"+staged)
+	}
+
+	remote := "chmod +x " + shellQuote(staged) + " && mv -f " + shellQuote(staged) + " " + shellQuote(path)
+	if deploy.Restart != "" {
+		remote += " && " + deploy.Restart
+	}
+	restartCmd = append(append([]string{"ssh"}, sshOptions...), deploy.Host, remote)
+	return copyCmd, restartCmd
+}
+
+// deploy installs the latest build on the deploy host and restarts it there.
+// The caller holds app.mutex.
+func (app *WindApp) deploy() {
+	started := time.Now()
+	copyCmd, restartCmd := deployCommands(app.config.Deploy, app.deployBinary())
+	logf(levelInfo, app.label()+Cyan+"🚀 Deploying build #%d to %s..."+Reset+"\n", app.buildID, app.config.Deploy.Host)
+
+	for _, args := range [][]string{copyCmd, restartCmd} {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdout = app.output(os.Stdout)
+		cmd.Stderr = app.output(os.Stderr)
+		if err := cmd.Run(); err != nil {
+			logf(levelError, Red+"Error: "+Reset+"%sDeploy failed: %s: %v\n", app.label(), args[0], err)
+			return
+		}
+	}
+	logf(levelInfo, app.label()+Green+"✅ Deployed build #%d to %s"+Reset+" (%s)\n", app.buildID, app.config.Deploy.Host, time.Since(started).Round(time.Millisecond))
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestDeployCommands(t *testing.T) {
+	deploy := DeployConfig{Host: "pi@raspberrypi.local", Path: "/opt/app/server", Restart: "sudo systemctl restart app"}
+
+	copyCmd, restartCmd := deployCommands(deploy, "tmp/main")
+	if expected := []string{"scp", "-q", "-o", "BatchMode=yes", "tmp/main", "pi@raspberrypi.local:/opt/app/server.new"}; !reflect.DeepEqual(copyCmd, expected) {
+		t.Errorf("copy = %q, expected %q", copyCmd, expected)
+	}
+	expected := []string{"ssh", "-o", "BatchMode=yes", "pi@raspberrypi.local",
+		"chmod +x /opt/app/server.new && mv -f /opt/app/server.new /opt/app/server && sudo systemctl restart app"}
+	if !reflect.DeepEqual(restartCmd, expected) {
+		t.Errorf("restart = %q, expected %q", restartCmd, expected)
+	}
+
+	deploy = DeployConfig{Host: "staging", Copy: "rsync"}
+	copyCmd, restartCmd = deployCommands(deploy, "./bin/api")
+	if expected := []string{"rsync", "-z", "-e", "ssh -o BatchMode=yes", "./bin/api", "staging:api.new"}; !reflect.DeepEqual(copyCmd, expected) {
+		t.Errorf("copy = %q, expected %q", copyCmd, expected)
+	}
+	if remote := restartCmd[len(restartCmd)-1]; remote != "chmod +x api.new && mv -f api.new api" {
+		t.Errorf("Expected only the install without a restart command, got %q", remote)
+	}
+}
+
+func TestDeployBinary(t *testing.T) {
+	tests := []struct {
+		buildCmd, runCmd string
+		expected         string
+	}{
+		{"go build -o ./bin/api ./cmd/api", "./bin/api", "./bin/api"},
+		{"make build", "./out/server --port 80", "./out/server"},
+		{"make build", "", "tmp/main"},
+	}
+
+	for _, tt := range tests {
+		app := &WindApp{config: WindConfig{BuildCmd: tt.buildCmd, RunCmd: tt.runCmd}}
+		if got := app.deployBinary(); got != tt.expected {
+			t.Errorf("deployBinary(%q, %q) = %q, expected %q", tt.buildCmd, tt.runCmd, got, tt.expected)
+		}
+	}
+}
+
+func TestCrossCompiled(t *testing.T) {
+	app := &WindApp{config: WindConfig{GOOS: runtime.GOOS}}
+	if app.crossCompiled() {
+		t.Error("Expected a build for this platform not to be cross-compiled")
+	}
+
+	other := "arm64"
+	if runtime.GOARCH == other {
+		other = "amd64"
+	}
+	app.config.GOARCH = other
+	if !app.crossCompiled() {
+		t.Error("Expected a build for another architecture to be cross-compiled")
+	}
+	if got := app.buildPlatform(); got != runtime.GOOS+"/"+other {
+		t.Errorf("buildPlatform() = %q", got)
+	}
+
+	env := app.buildEnv()
+	if env[len(env)-1] != "GOARCH="+other {
+		t.Errorf("Expected GOARCH in build env, got %q", env[len(env)-1])
+	}
+}
+
+func TestValidateDeploy(t *testing.T) {
+	if err := validateDeploy(DeployConfig{Host: "pi", Copy: "rsync"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := validateDeploy(DeployConfig{Host: "pi", Copy: "ftp"}); err == nil {
+		t.Error("Expected an error for an unknown copy tool")
+	}
+	if err := validateDeploy(DeployConfig{Restart: "systemctl restart app"}); err == nil {
+		t.Error("Expected an error for a restart command without a host")
+	}
+}
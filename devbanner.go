@@ -0,0 +1,22 @@
+package main
+
+import "fmt"
+
+// devBannerTemplate is the floating banner LiveReload.Banner adds to pages.
+// It counts the time since the page was loaded and follows the building and
+// failed events on the live reload stream; a successful rebuild reloads the
+// page, which resets it.
+const devBannerTemplate = `<div id="__wind_banner" style="position:fixed;right:12px;bottom:12px;z-index:2147483647;padding:2px 10px;border-radius:10px;background:rgba(24,24,24,.85);color:#e8e8e8;font:12px/1.6 ui-monospace,Menlo,Consolas,monospace;pointer-events:none"></div>` +
+	`<script>(function(){var b=document.getElementById("__wind_banner"),t=Date.now(),state="";` +
+	`function age(){var s=Math.round((Date.now()-t)/1000);return s<60?s+"s":Math.floor(s/60)+"m"}` +
+	`function draw(){b.textContent="wind · build #%d · reloaded "+age()+" ago"+state}` +
+	`function set(text,color){state=text;b.style.background=color;draw()}` +
+	`draw();setInterval(draw,1000);var s=window.__wind;if(s){` +
+	`s.addEventListener("building",function(){set(" · rebuilding…","%s")});` +
+	`s.addEventListener("failed",function(){set(" · build failed","%s")})}})();</script>`
+
+// devBanner returns the banner for a page served by build, in the colors of
+// the active palette
+func devBanner(build int) string {
+	return fmt.Sprintf(devBannerTemplate, build, activePalette.buildingCSS, activePalette.failedCSS)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDevBanner(t *testing.T) {
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html><body><h1>Hi</h1></body></html>")
+	}))
+	defer app.Close()
+
+	lr, err := newLiveReload(LiveReloadConfig{AppURL: app.URL, Banner: true})
+	if err != nil {
+		t.Fatalf("newLiveReload failed: %v", err)
+	}
+	lr.build.Store(7)
+	proxy := httptest.NewServer(lr.handler())
+	defer proxy.Close()
+
+	resp, err := http.Get(proxy.URL)
+	if err != nil {
+		t.Fatalf("Request through proxy failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	expected := liveReloadScript + devBanner(7) + "</body>"
+	if !strings.Contains(string(body), expected) {
+		t.Errorf("Expected the script and banner before </body>, got %q", body)
+	}
+	if !strings.Contains(devBanner(7), "build #7") {
+		t.Error("Expected the banner to show the build number")
+	}
+
+	// Build state changes reach the page on the live reload stream
+	resp, err = http.Get(proxy.URL + liveReloadPath)
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	reader.ReadString('\n') // ": connected"
+	for i := 0; i < 50 && lr.send("building") == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Stream ended before a building event: %v", err)
+		}
+		if strings.TrimSpace(line) == "event: building" {
+			return
+		}
+	}
+}
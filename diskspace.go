@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// sizeUnits are the units of MinFreeSpace, largest first
+var sizeUnits = []struct {
+	name  string
+	bytes uint64
+}{
+	{"TB", 1 << 40},
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// parseSize parses a size such as 2GB, 1.5 GB or 500MB; a number without
+// a unit is in bytes
+func parseSize(s string) (uint64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := uint64(1)
+	for _, unit := range sizeUnits {
+		if number, ok := strings.CutSuffix(value, unit.name); ok {
+			value, multiplier = strings.TrimSpace(number), unit.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+		return 0, fmt.Errorf("invalid size %q (expected e.g. 2GB or 500MB)", s)
+	}
+	return uint64(n * float64(multiplier)), nil
+}
+
+// formatSize renders n bytes in the largest unit that keeps it at least 1,
+// e.g. 300 MB or 1.5 GB
+func formatSize(n uint64) string {
+	for _, unit := range sizeUnits {
+		if n >= unit.bytes || unit.bytes == 1 {
+			value := strconv.FormatFloat(float64(n)/float64(unit.bytes), 'f', 1, 64)
+			return strings.TrimSuffix(value, ".0") + " " + unit.name
+		}
+	}
+	return ""
+}
+
+var (
+	goCacheOnce sync.Once
+	goCacheDir  string
+)
+
+// goBuildCache returns the Go build cache directory, or "" when it is off
+// or unknown
+func goBuildCache() string {
+	goCacheOnce.Do(func() {
+		if out, err := exec.Command("go", "env", "GOCACHE").Output(); err == nil {
+			goCacheDir = strings.TrimSpace(string(out))
+		}
+	})
+	if goCacheDir == "off" {
+		return ""
+	}
+	return goCacheDir
+}
+
+// buildDirs are where builds write: the project's tmp directory for the
+// binary, the Go build cache and the compiler's work directory
+func buildDirs() []string {
+	dirs := []string{"tmp"}
+	if cache := goBuildCache(); cache != "" {
+		dirs = append(dirs, cache)
+	}
+	work := os.Getenv("GOTMPDIR")
+	if work == "" {
+		work = os.TempDir()
+	}
+	return append(dirs, work)
+}
+
+// checkDiskSpace fails fast when a file system a build writes to has less
+// than MinFreeSpace available, rather than letting the build die mid-write
+// and leave truncated artifacts behind
+func (app *WindApp) checkDiskSpace() error {
+	if app.config.MinFreeSpace == "" {
+		return nil
+	}
+	// The size was validated with the config
+	required, _ := parseSize(app.config.MinFreeSpace)
+	if required == 0 {
+		return nil
+	}
+	for _, dir := range buildDirs() {
+		available, err := freeSpace(dir)
+		if err != nil {
+			// A directory that does not exist yet is created by the build;
+			// elsewhere the free space may not be known at all
+			continue
+		}
+		if available < required {
+			return fmt.Errorf("not enough disk space in %s: %s free required, %s available", dir, formatSize(required), formatSize(available))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+
+package main
+
+import "errors"
+
+// freeSpace fails: the free space is only read from statfs on Linux and
+// macOS
+func freeSpace(path string) (uint64, error) {
+	return 0, errors.ErrUnsupported
+}
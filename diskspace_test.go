@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected uint64
+	}{
+		{"2GB", 2 << 30},
+		{"1.5 GB", 3 << 29},
+		{"500mb", 500 << 20},
+		{"0", 0},
+		{"64KB", 64 << 10},
+	}
+	for _, tt := range tests {
+		got, err := parseSize(tt.input)
+		if err != nil || got != tt.expected {
+			t.Errorf("parseSize(%q) = %d, %v; expected %d", tt.input, got, err, tt.expected)
+		}
+	}
+
+	for _, input := range []string{"", "lots", "2 XB", "-1GB", "GB", "NaN", "inf GB"} {
+		if _, err := parseSize(input); err == nil {
+			t.Errorf("Expected parseSize(%q) to fail", input)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[uint64]string{
+		2 << 30:   "2 GB",
+		300 << 20: "300 MB",
+		3 << 29:   "1.5 GB",
+		512:       "512 B",
+		0:         "0 B",
+	}
+	for n, expected := range tests {
+		if got := formatSize(n); got != expected {
+			t.Errorf("formatSize(%d) = %q, expected %q", n, got, expected)
+		}
+	}
+}
+
+func TestCheckDiskSpace(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	os.Mkdir("tmp", 0755)
+
+	app := newWindApp(WindConfig{MinFreeSpace: "1KB"}, "", "")
+	if err := app.checkDiskSpace(); err != nil {
+		t.Errorf("Expected 1KB to be available, got %v", err)
+	}
+
+	app.config.MinFreeSpace = "1000000TB"
+	err := app.checkDiskSpace()
+	if err == nil || !strings.Contains(err.Error(), "1000000 TB free required") || !strings.Contains(err.Error(), "available") {
+		t.Errorf("Expected a clear error about the missing space, got %v", err)
+	}
+	if app.build() {
+		t.Error("Expected the build to be skipped")
+	}
+	if _, err := os.Stat("tmp/builds"); !os.IsNotExist(err) {
+		t.Error("Expected a skipped build to write nothing")
+	}
+
+	app.config.MinFreeSpace = ""
+	if err := app.checkDiskSpace(); err != nil {
+		t.Errorf("Expected no check without minFreeSpace, got %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DockerConfig builds and runs the app as a container instead of a local
+// binary, for apps needing CGO dependencies that only the image has
+type DockerConfig struct {
+	// Image is built with docker build and run with docker run
+	Image string
+	// Dockerfile and Context are passed to docker build, Dockerfile and .
+	// by default
+	Dockerfile string
+	Context    string
+	// RunArgs are passed to docker run, e.g. [-p, "8080:8080"]
+	RunArgs []string
+	// Service is built and started with docker compose instead of Image
+	Service string
+}
+
+// enabled reports whether builds run through Docker
+func (d DockerConfig) enabled() bool {
+	return d.Image != "" || d.Service != ""
+}
+
+func (d DockerConfig) dockerfile() string {
+	if d.Dockerfile == "" {
+		return "Dockerfile"
+	}
+	return d.Dockerfile
+}
+
+// validateDocker checks the docker settings
+func validateDocker(config WindConfig) error {
+	d := config.Docker
+	switch {
+	case d.Image != "" && d.Service != "":
+		return fmt.Errorf("docker: set image or service, not both")
+	case d.Service != "" && (d.Dockerfile != "" || d.Context != "" || len(d.RunArgs) > 0):
+		return fmt.Errorf("docker: dockerfile, context and runArgs apply to image; compose reads them from the compose file")
+	case d.enabled() && len(config.Processes) > 0:
+		return fmt.Errorf("docker cannot be combined with processes")
+	}
+	return nil
+}
+
+var containerNameInvalid = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// containerName names the container of a project, so a leftover one from
+// a killed session can be removed
+func containerName(project string) string {
+	name := strings.Trim(containerNameInvalid.ReplaceAllString(filepath.Base(project), "-"), "-.")
+	return "wind-" + name
+}
+
+// dockerCommands returns the build and run commands of docker mode. The run
+// command execs the docker CLI so the stop signal reaches it, and the CLI
+// passes it on to the container, whose output it streams.
+func dockerCommands(d DockerConfig, project string) (buildCmd, runCmd string) {
+	if d.Service != "" {
+		service := shellQuote(d.Service)
+		return "docker compose build " + service, "exec docker compose up " + service
+	}
+
+	context := d.Context
+	if context == "" {
+		context = "."
+	}
+	buildCmd = "docker build -t " + shellQuote(d.Image) + " -f " + shellQuote(d.dockerfile()) + " " + shellQuote(context)
+
+	name := shellQuote(containerName(project))
+	runCmd = "docker rm -f " + name + " >/dev/null 2>&1; exec docker run --rm --init --name " + name
+	for _, arg := range d.RunArgs {
+		runCmd += " " + shellQuote(arg)
+	}
+	return buildCmd, runCmd + " " + shellQuote(d.Image)
+}
+
+// applyDocker replaces the build and run commands with docker mode's and
+// describes the mode
+func applyDocker(config *WindConfig, project string) string {
+	config.BuildCmd, config.RunCmd = dockerCommands(config.Docker, project)
+	if config.Docker.Service != "" {
+		return "Docker mode (compose service " + config.Docker.Service + ")"
+	}
+	return "Docker mode (image " + config.Docker.Image + ")"
+}
+
+// isDockerInput reports whether a file changes what docker mode builds: the
+// Dockerfile, .dockerignore or a compose file
+func (app *WindApp) isDockerInput(path string) bool {
+	d := app.config.Docker
+	if !d.enabled() {
+		return false
+	}
+	path = projectPath(path)
+	switch filepath.Base(path) {
+	case ".dockerignore", "compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml":
+		return true
+	}
+	return d.Image != "" && path == projectPath(d.dockerfile())
+}
+
+// removeContainer removes the container of docker mode on shutdown, in case
+// stopping the docker CLI left it running
+func (app *WindApp) removeContainer() {
+	d := app.config.Docker
+	switch {
+	case d.Image != "":
+		exec.Command("docker", "rm", "-f", containerName(projectRoot())).Run()
+	case d.Service != "":
+		exec.Command("docker", "compose", "stop", d.Service).Run()
+	}
+}
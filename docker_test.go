@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestDockerCommands(t *testing.T) {
+	d := DockerConfig{Image: "shop:dev", RunArgs: []string{"-p", "8080:8080", "--env-file", ".env"}}
+	buildCmd, runCmd := dockerCommands(d, "/home/me/my shop")
+
+	if expected := "docker build -t shop:dev -f Dockerfile ."; buildCmd != expected {
+		t.Errorf("build = %q, expected %q", buildCmd, expected)
+	}
+	expected := "docker rm -f wind-my-shop >/dev/null 2>&1; exec docker run --rm --init --name wind-my-shop -p 8080:8080 --env-file .env shop:dev"
+	if runCmd != expected {
+		t.Errorf("run = %q, expected %q", runCmd, expected)
+	}
+
+	d = DockerConfig{Image: "shop:dev", Dockerfile: "build/Dockerfile.dev", Context: "build"}
+	if buildCmd, _ := dockerCommands(d, "/src/shop"); buildCmd != "docker build -t shop:dev -f build/Dockerfile.dev build" {
+		t.Errorf("Unexpected build command %q", buildCmd)
+	}
+
+	buildCmd, runCmd = dockerCommands(DockerConfig{Service: "api"}, "/src/shop")
+	if buildCmd != "docker compose build api" || runCmd != "exec docker compose up api" {
+		t.Errorf("Unexpected compose commands %q, %q", buildCmd, runCmd)
+	}
+}
+
+func TestValidateDocker(t *testing.T) {
+	tests := []struct {
+		config WindConfig
+		valid  bool
+	}{
+		{WindConfig{Docker: DockerConfig{Image: "app", RunArgs: []string{"-p", "80:80"}}}, true},
+		{WindConfig{Docker: DockerConfig{Service: "api"}}, true},
+		{WindConfig{Docker: DockerConfig{Image: "app", Service: "api"}}, false},
+		{WindConfig{Docker: DockerConfig{Service: "api", Dockerfile: "Dockerfile.dev"}}, false},
+		{WindConfig{Docker: DockerConfig{Image: "app"}, Processes: []ProcessConfig{{Name: "api"}}}, false},
+	}
+
+	for i, tt := range tests {
+		if err := validateDocker(tt.config); (err == nil) != tt.valid {
+			t.Errorf("case %d: validateDocker() = %v, expected valid=%v", i, err, tt.valid)
+		}
+	}
+}
+
+func TestIsDockerInput(t *testing.T) {
+	app := &WindApp{config: WindConfig{Docker: DockerConfig{Image: "app", Dockerfile: "build/Dockerfile"}}}
+	for path, expected := range map[string]bool{
+		"build/Dockerfile":   true,
+		"Dockerfile":         false,
+		".dockerignore":      true,
+		"compose.yaml":       true,
+		"cmd/api/handler.go": false,
+	} {
+		if got := app.isDockerInput(path); got != expected {
+			t.Errorf("isDockerInput(%q) = %v, expected %v", path, got, expected)
+		}
+	}
+
+	app.config.Docker = DockerConfig{}
+	if app.isDockerInput(".dockerignore") {
+		t.Error("Expected no docker inputs outside docker mode")
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// docsFormatFlag selects the output of `wind docs`
+const docsFormatFlag = "--format"
+
+// configKey describes one key of .wind.yaml for generated docs
+type configKey struct {
+	// key is the dotted path, e.g. proxy.port
+	key  string
+	kind string
+	// value is the default, empty when there is none
+	value string
+}
+
+// runDocs implements `wind docs`
+func runDocs(args []string) {
+	args, format, err := extractValueFlag(args, docsFormatFlag)
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%v\n", err)
+		return
+	}
+	if len(args) > 0 {
+		logf(levelError, Red+"Error: "+Reset+"Unexpected argument %q (usage: wind docs %s man|markdown)\n", args[0], docsFormatFlag)
+		return
+	}
+	switch format {
+	case "", "markdown", "md":
+		fmt.Print(markdownDocs())
+	case "man":
+		fmt.Print(manPage())
+	default:
+		logf(levelError, Red+"Error: "+Reset+"%s must be man or markdown, got %q\n", docsFormatFlag, format)
+	}
+}
+
+// configKeys lists every config key with its type and default, derived
+// from WindConfig and defaultConfig
+func configKeys() []configKey {
+	var keys []configKey
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			key := prefix + configKeyName(field.Name)
+			value := v.Field(i)
+			if value.Kind() == reflect.Struct {
+				walk(value, key+".")
+				continue
+			}
+			keys = append(keys, configKey{key: key, kind: configKind(field.Type), value: configDefault(value)})
+		}
+	}
+	walk(reflect.ValueOf(defaultConfig()), "")
+	return keys
+}
+
+// configKeyName turns a field name into the key used in .wind.yaml, e.g.
+// PGOCollectURL into pgoCollectURL. Keys are matched case-insensitively.
+func configKeyName(field string) string {
+	runes := []rune(field)
+	upper := 0
+	for upper < len(runes) && unicode.IsUpper(runes[upper]) {
+		upper++
+	}
+	// Keep the capital that starts the next word: URLs → urls, but
+	// PGOCollect → pgoCollect
+	if upper > 1 && upper < len(runes) && unicode.IsLower(runes[upper]) && string(runes[upper:]) != "s" {
+		upper--
+	}
+	return strings.ToLower(string(runes[:upper])) + string(runes[upper:])
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// configKind names the YAML type of a config field
+func configKind(t reflect.Type) string {
+	switch {
+	case t == durationType:
+		return "duration"
+	case t.Kind() == reflect.Bool:
+		return "bool"
+	case t.Kind() == reflect.Int, t.Kind() == reflect.Int64, t.Kind() == reflect.Float64:
+		return "number"
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Struct:
+		return "list of mappings"
+	case t.Kind() == reflect.Slice:
+		return "list"
+	case t.Kind() == reflect.Map:
+		return "mapping"
+	}
+	return "string"
+}
+
+// configDefault formats a default value, or returns "" for the zero value
+func configDefault(v reflect.Value) string {
+	if v.IsZero() {
+		return ""
+	}
+	switch {
+	case v.Type() == durationType:
+		return v.Interface().(time.Duration).String()
+	case v.Kind() == reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case v.Kind() == reflect.Map:
+		keys := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			keys = append(keys, fmt.Sprint(k.Interface()))
+		}
+		sort.Strings(keys)
+		return "{" + strings.Join(keys, ", ") + "}"
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// markdownDocs renders the reference as markdown
+func markdownDocs() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# wind %s\n\n", version)
+	b.WriteString("Go web application watcher: rebuilds and restarts the application when its files change.\n\n")
+
+	b.WriteString("## Commands\n\n")
+	for _, c := range commands {
+		fmt.Fprintf(&b, "### %s\n\n```\n%s\n```\n\n", c.name, c.usage())
+		b.WriteString(c.longDescription() + "\n\n")
+		if len(c.aliases) > 0 {
+			fmt.Fprintf(&b, "Aliases: `%s`\n\n", strings.Join(c.aliases, "`, `"))
+		}
+		if len(c.flags) > 0 {
+			for _, f := range c.flags {
+				fmt.Fprintf(&b, "- `%s`: %s\n", f.name, f.description)
+			}
+			b.WriteString("\n")
+		}
+		if len(c.examples) > 0 {
+			b.WriteString("```bash\n" + strings.Join(c.examples, "\n") + "\n```\n\n")
+		}
+	}
+
+	b.WriteString("## Options\n\nAccepted by every command. Arguments after `--` are passed to the application.\n\n")
+	for _, f := range globalFlags {
+		fmt.Fprintf(&b, "- `%s`: %s\n", f.name, f.description)
+	}
+
+	fmt.Fprintf(&b, "\n## Configuration\n\nKeys of `%s`, which is optional. Keys are case-insensitive.\n\n", configFileName)
+	b.WriteString("| Key | Type | Default |\n| --- | --- | --- |\n")
+	for _, k := range configKeys() {
+		value := ""
+		if k.value != "" {
+			value = "`" + strings.ReplaceAll(k.value, "|", `\|`) + "`"
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s |\n", k.key, k.kind, value)
+	}
+	return b.String()
+}
+
+// roffEscape escapes text for a man page
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+// manPage renders the reference as a wind(1) man page
+func manPage() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, ".TH WIND 1 \"\" \"wind %s\" \"User Commands\"\n", version)
+	b.WriteString(".SH NAME\nwind \\- Go web application watcher\n")
+	b.WriteString(".SH SYNOPSIS\n.B wind\n[\\fIcommand\\fR] [\\fIoptions\\fR] [\\fB\\-\\-\\fR \\fIargs\\fR...]\n")
+	b.WriteString(".SH DESCRIPTION\nWind builds a Go web application, runs it and rebuilds and restarts it whenever a watched file changes. " +
+		"Arguments after \\fB\\-\\-\\fR are passed to the application.\n")
+
+	b.WriteString(".SH COMMANDS\n")
+	for _, c := range commands {
+		fmt.Fprintf(&b, ".TP\n.B %s\n%s\n", roffEscape(c.usage()), roffEscape(c.longDescription()))
+		if len(c.aliases) > 0 {
+			fmt.Fprintf(&b, "Aliases: %s.\n", roffEscape(strings.Join(c.aliases, ", ")))
+		}
+		for _, f := range c.flags {
+			fmt.Fprintf(&b, ".RS\n.TP\n.B %s\n%s\n.RE\n", roffEscape(f.name), roffEscape(f.description))
+		}
+	}
+
+	b.WriteString(".SH OPTIONS\n")
+	for _, f := range globalFlags {
+		fmt.Fprintf(&b, ".TP\n.B %s\n%s\n", roffEscape(f.name), roffEscape(f.description))
+	}
+
+	b.WriteString(".SH CONFIGURATION\n")
+	fmt.Fprintf(&b, "Settings are read from the optional \\fI%s\\fR in the project root. Keys are case-insensitive.\n", roffEscape(configFileName))
+	for _, k := range configKeys() {
+		fmt.Fprintf(&b, ".TP\n.BR %s \" (%s)\"\n", roffEscape(k.key), k.kind)
+		if k.value != "" {
+			fmt.Fprintf(&b, "Default: %s\n", roffEscape(k.value))
+		}
+	}
+
+	b.WriteString(".SH EXAMPLES\n")
+	for _, c := range commands {
+		for _, example := range c.examples {
+			fmt.Fprintf(&b, ".PP\n.nf\n%s\n.fi\n", roffEscape(example))
+		}
+	}
+
+	b.WriteString(".SH FILES\n")
+	for _, f := range [][2]string{
+		{configFileName, "Project configuration"},
+		{daemonLog, "Output of the background daemon"},
+		{"~/.config/" + portRegistryFile, "Ports assigned across projects"},
+		{"~/.config/" + instanceRegistryFile, "Running sessions, listed by wind ps"},
+		{defaultSharedDir, "Ports and sessions of every user in shared mode"},
+	} {
+		fmt.Fprintf(&b, ".TP\n.I %s\n%s\n", roffEscape(f[0]), f[1])
+	}
+	return b.String()
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfigKeyName(t *testing.T) {
+	tests := map[string]string{
+		"PollInterval":  "pollInterval",
+		"PGOCollectURL": "pgoCollectURL",
+		"URLs":          "urls",
+		"AB":            "ab",
+		"Env":           "env",
+	}
+	for field, want := range tests {
+		if got := configKeyName(field); got != want {
+			t.Errorf("configKeyName(%q) = %q, want %q", field, got, want)
+		}
+		if normalizeKey(configKeyName(field)) != normalizeKey(field) {
+			t.Errorf("configKeyName(%q) does not decode to the field", field)
+		}
+	}
+}
+
+func TestConfigKeys(t *testing.T) {
+	keys := map[string]configKey{}
+	for _, k := range configKeys() {
+		keys[k.key] = k
+	}
+	tests := []configKey{
+		{"pollInterval", "duration", "500ms"},
+		{"proxy.port", "number", "8080"},
+		{"detect", "bool", "true"},
+		{"excludeDirs", "list", "[vendor, .git, node_modules, tmp, .idea, .vscode]"},
+		{"processes", "list of mappings", ""},
+		{"env", "mapping", ""},
+	}
+	for _, want := range tests {
+		if got := keys[want.key]; got != want {
+			t.Errorf("config key %s = %+v, want %+v", want.key, got, want)
+		}
+	}
+	if _, ok := keys["proxy"]; ok {
+		t.Error("nested settings should be listed by their keys, not the mapping")
+	}
+}
+
+func TestMarkdownDocs(t *testing.T) {
+	docs := markdownDocs()
+	for _, c := range commands {
+		if !strings.Contains(docs, "### "+c.name+"\n") {
+			t.Errorf("markdown docs lack command %s", c.name)
+		}
+	}
+	for _, want := range []string{"`--verbose`", "| `proxy.port` | number | `8080` |"} {
+		if !strings.Contains(docs, want) {
+			t.Errorf("markdown docs lack %q", want)
+		}
+	}
+}
+
+func TestManPage(t *testing.T) {
+	page := manPage()
+	if !strings.HasPrefix(page, ".TH WIND 1 ") {
+		t.Errorf("man page starts with %q", strings.SplitN(page, "\n", 2)[0])
+	}
+	for _, want := range []string{".SH COMMANDS\n", ".B wind run <target>\n", `.B \-\-verbose`, ".SH CONFIGURATION\n"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("man page lacks %q", want)
+		}
+	}
+	for _, line := range strings.Split(page, "\n") {
+		if strings.HasPrefix(line, "'") {
+			t.Errorf("unescaped control line %q", line)
+		}
+	}
+}
+
+func TestRoffEscape(t *testing.T) {
+	tests := map[string]string{
+		"--format":   `\-\-format`,
+		`C:\path`:    `C:\epath`,
+		".hidden":    `\&.hidden`,
+		"'quoted'":   `\&'quoted'`,
+		"plain text": "plain text",
+	}
+	for in, want := range tests {
+		if got := roffEscape(in); got != want {
+			t.Errorf("roffEscape(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
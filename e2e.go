@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// processKindTest marks a process entry as an end-to-end suite: RunCmd runs
+// to completion against the services in DependsOn instead of being
+// supervised
+const processKindTest = "test"
+
+// e2eSuite runs an end-to-end test command once all of its services are
+// ready, and again whenever one of them restarts
+type e2eSuite struct {
+	name   string
+	runCmd string
+	color  string
+	deps   []*WindApp
+	// settle is how long to wait after a service became ready before
+	// running, so services restarting for the same change can catch up
+	settle time.Duration
+
+	mutex   sync.Mutex
+	running bool
+	// pending is set when a service became ready during a run
+	pending bool
+	process *os.Process
+	stopped bool
+	cycle   int
+}
+
+// newE2ESuites creates the suites of the test processes and hooks them up to
+// the services they depend on
+func newE2ESuites(config WindConfig, apps []*WindApp) ([]*e2eSuite, error) {
+	byName := map[string]*WindApp{}
+	for _, app := range apps {
+		byName[app.name] = app
+	}
+
+	var suites []*e2eSuite
+	for i, p := range config.Processes {
+		if p.Kind != processKindTest {
+			continue
+		}
+		if p.Name == "" {
+			return nil, fmt.Errorf("processes[%d]: name is required", i)
+		}
+		if p.RunCmd == "" || p.Target != "" || p.BuildCmd != "" {
+			return nil, fmt.Errorf("process %s: a test process needs runCmd and no target or buildCmd", p.Name)
+		}
+		if len(p.DependsOn) == 0 {
+			return nil, fmt.Errorf("process %s: a test process needs dependsOn", p.Name)
+		}
+
+		suite := &e2eSuite{
+			name:   p.Name,
+			runCmd: p.RunCmd,
+			color:  processColors[i%len(processColors)],
+			settle: config.PollInterval + config.DebounceDelay,
+		}
+		if c, ok := colorNames[strings.ToLower(p.Color)]; ok {
+			suite.color = c
+		}
+		for _, dep := range p.DependsOn {
+			app, ok := byName[dep]
+			if !ok {
+				return nil, fmt.Errorf("process %s: dependsOn names unknown service %q", p.Name, dep)
+			}
+			suite.deps = append(suite.deps, app)
+			app.readyHooks = append(app.readyHooks, suite.trigger)
+		}
+		suites = append(suites, suite)
+	}
+	return suites, nil
+}
+
+func (s *e2eSuite) label() string {
+	return s.color + "[" + s.name + "]" + Reset + " "
+}
+
+// trigger schedules a run once every service is up. Runs are never
+// concurrent; a trigger during a run queues one more.
+func (s *e2eSuite) trigger() {
+	go func() {
+		time.Sleep(s.settle)
+		for _, dep := range s.deps {
+			if dep.currentStatus().State != "running" {
+				return
+			}
+		}
+
+		s.mutex.Lock()
+		if s.stopped {
+			s.mutex.Unlock()
+			return
+		}
+		if s.running {
+			s.pending = true
+			s.mutex.Unlock()
+			return
+		}
+		s.running = true
+		s.mutex.Unlock()
+
+		for {
+			s.run()
+			s.mutex.Lock()
+			if !s.pending || s.stopped {
+				s.running, s.pending = false, false
+				s.mutex.Unlock()
+				return
+			}
+			s.pending = false
+			s.mutex.Unlock()
+		}
+	}()
+}
+
+// failedTestPattern matches go test's failure lines
+var failedTestPattern = regexp.MustCompile(`^\s*--- FAIL: (\S+)`)
+
+// failedTests lists the tests reported as failing in go test output
+func failedTests(output string) []string {
+	var tests []string
+	for _, line := range strings.Split(output, "\n") {
+		if m := failedTestPattern.FindStringSubmatch(line); m != nil {
+			tests = append(tests, m[1])
+		}
+	}
+	return tests
+}
+
+// run executes the suite once and prints a one-line summary
+func (s *e2eSuite) run() {
+	s.mutex.Lock()
+	s.cycle++
+	cycle := s.cycle
+	s.mutex.Unlock()
+
+	var names []string
+	for _, dep := range s.deps {
+		names = append(names, dep.name)
+	}
+	logf(levelInfo, s.label()+Cyan+"🧪 E2E run #%d (%s ready)..."+Reset+"\n", cycle, strings.Join(names, ", "))
+
+	var output bytes.Buffer
+	cmd := exec.Command("sh", "-c", s.runCmd)
+	cmd.Stdout = io.MultiWriter(newPrefixWriter(os.Stdout, s.label()), &output)
+	cmd.Stderr = io.MultiWriter(newPrefixWriter(os.Stderr, s.label()), &output)
+
+	started := time.Now()
+	if err := cmd.Start(); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%sFailed to start e2e suite: %v\n", s.label(), err)
+		return
+	}
+	s.mutex.Lock()
+	s.process = cmd.Process
+	s.mutex.Unlock()
+	err := cmd.Wait()
+	s.mutex.Lock()
+	s.process = nil
+	s.mutex.Unlock()
+
+	elapsed := time.Since(started).Round(100 * time.Millisecond)
+	if err == nil {
+		logf(levelInfo, Green+"E2E: "+Reset+"%srun #%d passed in %v\n", s.label(), cycle, elapsed)
+		return
+	}
+	summary := fmt.Sprintf("%srun #%d failed in %v (%v)", s.label(), cycle, elapsed, err)
+	if failed := failedTests(output.String()); len(failed) > 0 {
+		summary += ": " + strings.Join(failed, ", ")
+	}
+	logf(levelError, "%s\n", fitLine(Red+"E2E: "+Reset+summary, ", ", "  ", terminalWidth()))
+}
+
+// stop kills a running suite and prevents further runs
+func (s *e2eSuite) stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.stopped = true
+	if s.process != nil {
+		s.process.Kill()
+	}
+}
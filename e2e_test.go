@@ -0,0 +1,117 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewE2ESuites(t *testing.T) {
+	config := defaultConfig()
+	config.Processes = []ProcessConfig{
+		{Name: "api", BuildCmd: "true", RunCmd: "true"},
+		{Name: "worker", BuildCmd: "true", RunCmd: "true", Kind: "service"},
+		{Name: "e2e", Kind: "test", RunCmd: "go test ./e2e", DependsOn: []string{"api", "worker"}},
+	}
+
+	apps, err := newSupervisors(config)
+	if err != nil {
+		t.Fatalf("newSupervisors failed: %v", err)
+	}
+	if len(apps) != 2 {
+		t.Fatalf("Expected test processes not to be supervised, got %d apps", len(apps))
+	}
+	suites, err := newE2ESuites(config, apps)
+	if err != nil {
+		t.Fatalf("newE2ESuites failed: %v", err)
+	}
+	if len(suites) != 1 || len(suites[0].deps) != 2 {
+		t.Fatalf("Expected one suite depending on both services, got %+v", suites)
+	}
+	for _, app := range apps {
+		if len(app.readyHooks) != 1 {
+			t.Errorf("Expected %s to trigger the suite when ready", app.name)
+		}
+	}
+
+	invalid := [][]ProcessConfig{
+		{{Name: "e2e", Kind: "test", RunCmd: "true"}},
+		{{Name: "e2e", Kind: "test", RunCmd: "true", DependsOn: []string{"db"}}},
+		{{Name: "e2e", Kind: "test", BuildCmd: "true", RunCmd: "true", DependsOn: []string{"api"}}},
+	}
+	for _, processes := range invalid {
+		config.Processes = append([]ProcessConfig{{Name: "api", BuildCmd: "true", RunCmd: "true"}}, processes...)
+		apps, _ := newSupervisors(config)
+		if _, err := newE2ESuites(config, apps); err == nil {
+			t.Errorf("Expected an error for %+v", processes)
+		}
+	}
+
+	config.Processes = []ProcessConfig{{Name: "api", Kind: "cron", BuildCmd: "true", RunCmd: "true"}}
+	if _, err := newSupervisors(config); err == nil {
+		t.Error("Expected an error for an unknown kind")
+	}
+}
+
+func TestE2ESuiteRunsWhenServicesReady(t *testing.T) {
+	runs := filepath.Join(t.TempDir(), "runs")
+	api := newWindApp(WindConfig{}, "api", Cyan)
+	worker := newWindApp(WindConfig{}, "worker", Green)
+	suite := &e2eSuite{
+		name:   "e2e",
+		runCmd: "echo run >> " + runs,
+		deps:   []*WindApp{api, worker},
+	}
+	api.readyHooks = append(api.readyHooks, suite.trigger)
+	worker.readyHooks = append(worker.readyHooks, suite.trigger)
+
+	countRuns := func() int {
+		data, _ := os.ReadFile(runs)
+		return strings.Count(string(data), "run")
+	}
+	ready := func(app *WindApp, pid int) {
+		app.updateStatus(event{Event: "app_start", PID: pid})
+		for _, hook := range app.readyHooks {
+			hook()
+		}
+	}
+	waitRuns := func(n int) {
+		for i := 0; i < 100 && countRuns() < n; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		time.Sleep(50 * time.Millisecond)
+		if got := countRuns(); got != n {
+			t.Fatalf("Expected %d run(s), got %d", n, got)
+		}
+	}
+
+	// Nothing runs until every service is up
+	ready(api, 1)
+	time.Sleep(50 * time.Millisecond)
+	if countRuns() != 0 {
+		t.Fatal("Expected no run while worker is down")
+	}
+	ready(worker, 2)
+	waitRuns(1)
+
+	// A restarted service triggers another run
+	ready(api, 3)
+	waitRuns(2)
+
+	suite.stop()
+	ready(worker, 4)
+	time.Sleep(50 * time.Millisecond)
+	if countRuns() != 2 {
+		t.Error("Expected no runs after stop")
+	}
+}
+
+func TestFailedTests(t *testing.T) {
+	output := "=== RUN   TestLogin\n--- FAIL: TestLogin (0.01s)\n    --- FAIL: TestLogin/bad_password (0.00s)\n--- PASS: TestHome (0.00s)\nFAIL\n"
+	failed := failedTests(output)
+	if strings.Join(failed, ",") != "TestLogin,TestLogin/bad_password" {
+		t.Errorf("Unexpected failed tests %v", failed)
+	}
+}
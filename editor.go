@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// editorFlag sets the editor command on the command line
+const editorFlag = "--editor"
+
+// editorTemplates open a file at a position in editors commonly set as
+// $EDITOR, keyed by command name
+var editorTemplates = map[string]string{
+	"code":        "{editor} -g {file}:{line}:{col}",
+	"cursor":      "{editor} -g {file}:{line}:{col}",
+	"subl":        "{editor} {file}:{line}:{col}",
+	"zed":         "{editor} {file}:{line}:{col}",
+	"vim":         "{editor} +{line} {file}",
+	"nvim":        "{editor} +{line} {file}",
+	"vi":          "{editor} +{line} {file}",
+	"nano":        "{editor} +{line},{col} {file}",
+	"emacs":       "{editor} +{line}:{col} {file}",
+	"emacsclient": "{editor} -n +{line}:{col} {file}",
+	"goland":      "{editor} --line {line} --column {col} {file}",
+	"idea":        "{editor} --line {line} --column {col} {file}",
+}
+
+// editorCommand returns the shell command opening e: the configured
+// template, else $WIND_EDITOR, else $EDITOR. A plain command without
+// placeholders gets the usual arguments of the editor it names. It reports
+// false when no editor is set.
+func editorCommand(configured string, e compileError) (string, bool) {
+	tmpl := configured
+	for _, env := range []string{"WIND_EDITOR", "EDITOR"} {
+		if tmpl == "" {
+			tmpl = os.Getenv(env)
+		}
+	}
+	if tmpl == "" {
+		return "", false
+	}
+
+	if !strings.Contains(tmpl, "{file}") {
+		editor := tmpl
+		tmpl = "{editor} {file}"
+		if fields := strings.Fields(editor); len(fields) > 0 {
+			if known, ok := editorTemplates[filepath.Base(fields[0])]; ok {
+				tmpl = known
+			}
+		}
+		tmpl = strings.ReplaceAll(tmpl, "{editor}", editor)
+	}
+
+	col := e.Col
+	if col == 0 {
+		col = 1
+	}
+	return strings.NewReplacer(
+		"{file}", shellQuote(e.File),
+		"{line}", strconv.Itoa(e.Line),
+		"{col}", strconv.Itoa(col),
+	).Replace(tmpl), true
+}
+
+// openInEditor opens the location of e without waiting for the editor
+func (app *WindApp) openInEditor(e compileError) {
+	command, ok := editorCommand(app.config.Editor, e)
+	if !ok {
+		logf(levelWarn, Yellow+"Warning: "+Reset+"No editor configured (set editor, --editor, $WIND_EDITOR or $EDITOR)\n")
+		return
+	}
+
+	cmd := exec.Command("sh", "-c", command)
+	if err := cmd.Start(); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Failed to open editor: %v\n", err)
+		return
+	}
+	logf(levelInfo, Cyan+"Info: "+Reset+"%sOpening %s\n", app.label(), e.location())
+	go cmd.Wait()
+}
+
+// setCompileErrors records the errors of the latest build
+func (app *WindApp) setCompileErrors(errs []compileError) {
+	app.statusMutex.Lock()
+	defer app.statusMutex.Unlock()
+	app.compileErrors = errs
+}
+
+// firstCompileError returns the first error of the latest build
+func (app *WindApp) firstCompileError() (compileError, bool) {
+	app.statusMutex.Lock()
+	defer app.statusMutex.Unlock()
+	if len(app.compileErrors) == 0 {
+		return compileError{}, false
+	}
+	return app.compileErrors[0], true
+}
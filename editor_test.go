@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEditorCommand(t *testing.T) {
+	e := compileError{File: "internal/my store/store.go", Line: 12, Col: 5}
+
+	t.Setenv("WIND_EDITOR", "")
+	t.Setenv("EDITOR", "")
+	if _, ok := editorCommand("", e); ok {
+		t.Errorf("Expected no editor without configuration")
+	}
+
+	tests := []struct {
+		configured, windEditor, editor string
+		expected                       string
+	}{
+		{"code -g {file}:{line}", "vim", "nano", "code -g 'internal/my store/store.go':12"},
+		{"", "subl", "vim", "subl 'internal/my store/store.go':12:5"},
+		{"", "", "/usr/bin/nvim", "/usr/bin/nvim +12 'internal/my store/store.go'"},
+		{"", "", "code --wait", "code --wait -g 'internal/my store/store.go':12:5"},
+		{"", "", "myeditor", "myeditor 'internal/my store/store.go'"},
+	}
+	for _, tt := range tests {
+		t.Setenv("WIND_EDITOR", tt.windEditor)
+		t.Setenv("EDITOR", tt.editor)
+		got, ok := editorCommand(tt.configured, e)
+		if !ok || got != tt.expected {
+			t.Errorf("editorCommand(%q) with WIND_EDITOR=%q EDITOR=%q = %q, expected %q",
+				tt.configured, tt.windEditor, tt.editor, got, tt.expected)
+		}
+	}
+}
+
+func TestEditorKey(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "opened")
+	config := defaultConfig()
+	config.Editor = "echo {file}:{line}:{col} > " + shellQuote(out)
+	clean := newWindApp(config, "api", Cyan)
+	broken := newWindApp(config, "worker", Purple)
+	broken.setCompileErrors([]compileError{
+		{File: "cmd/worker/main.go", Line: 7, Message: "undefined: run"},
+		{File: "cmd/worker/main.go", Line: 9, Col: 2, Message: "undefined: stop"},
+	})
+
+	newOrchestrator([]*WindApp{clean, broken}).handleKey(keyEditor)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		data, err := os.ReadFile(out)
+		if err == nil && strings.TrimSpace(string(data)) == "cmd/worker/main.go:7:1" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the first error to be opened, got %q (%v)", data, err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// parseEmbedDirectives returns the patterns of the //go:embed directives in
+// a Go source file, as written
+func parseEmbedDirectives(src []byte) []string {
+	var patterns []string
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	for scanner.Scan() {
+		args, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "//go:embed")
+		if !ok || (args != "" && args[0] != ' ' && args[0] != '\t') {
+			continue
+		}
+		patterns = append(patterns, splitEmbedArgs(args)...)
+	}
+	return patterns
+}
+
+// splitEmbedArgs splits the arguments of a //go:embed directive, which are
+// separated by spaces and may be quoted like Go strings
+func splitEmbedArgs(s string) []string {
+	var args []string
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return args
+		}
+		if s[0] != '"' && s[0] != '`' {
+			end := strings.IndexAny(s, " \t")
+			if end < 0 {
+				end = len(s)
+			}
+			args = append(args, s[:end])
+			s = s[end:]
+			continue
+		}
+		quoted, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return args
+		}
+		if arg, err := strconv.Unquote(quoted); err == nil {
+			args = append(args, arg)
+		}
+		s = s[len(quoted):]
+	}
+}
+
+// updateEmbeds records the embed patterns of the Go file at path, relative
+// to the project, and reports whether they changed
+func (app *WindApp) updateEmbeds(file string) bool {
+	if filepath.Ext(file) != ".go" {
+		return false
+	}
+	var patterns []string
+	if src, err := os.ReadFile(file); err == nil {
+		dir := path.Dir(projectPath(file))
+		for _, pattern := range parseEmbedDirectives(src) {
+			all := strings.HasPrefix(pattern, "all:")
+			pattern = path.Join(dir, strings.TrimPrefix(pattern, "all:"))
+			if all {
+				pattern = "all:" + pattern
+			}
+			patterns = append(patterns, pattern)
+		}
+	}
+	if reflect.DeepEqual(app.embeds[file], patterns) {
+		return false
+	}
+	if app.embeds == nil {
+		app.embeds = map[string][]string{}
+	}
+	if len(patterns) == 0 {
+		delete(app.embeds, file)
+	} else {
+		app.embeds[file] = patterns
+	}
+	return true
+}
+
+// isEmbedded reports whether file is embedded by a //go:embed directive, so
+// embedded files are watched whatever their extension. Directories embed
+// their files recursively, except hidden ones and those starting with _
+// unless the pattern has the all: prefix, as with go build.
+func (app *WindApp) isEmbedded(file string) bool {
+	file = projectPath(file)
+	for _, patterns := range app.embeds {
+		for _, pattern := range patterns {
+			pattern, all := strings.CutPrefix(pattern, "all:")
+			if ok, _ := path.Match(pattern, file); ok {
+				return true
+			}
+			// The pattern may name a directory the file is in
+			for dir := path.Dir(file); dir != "." && dir != "/"; dir = path.Dir(dir) {
+				if ok, _ := path.Match(pattern, dir); ok {
+					if all || !hiddenBelow(dir, file) {
+						return true
+					}
+					break
+				}
+			}
+		}
+	}
+	return false
+}
+
+// hiddenBelow reports whether a path element of file below dir starts with
+// . or _, which embedding a directory leaves out
+func hiddenBelow(dir, file string) bool {
+	for _, part := range strings.Split(strings.TrimPrefix(file, dir+"/"), "/") {
+		if strings.HasPrefix(part, ".") || strings.HasPrefix(part, "_") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseEmbedDirectives(t *testing.T) {
+	src := "package web\n\nimport \"embed\"\n\n" +
+		"//go:embed templates static/*.css\n" +
+		"var files embed.FS\n\n" +
+		"//go:embed \"my file.txt\" `all:public`\n" +
+		"var more embed.FS\n\n" +
+		"//go:embedded not a directive\n" +
+		"// go:embed neither\n"
+	got := parseEmbedDirectives([]byte(src))
+	expected := []string{"templates", "static/*.css", "my file.txt", "all:public"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("parseEmbedDirectives() = %q, expected %q", got, expected)
+	}
+}
+
+func TestIsEmbedded(t *testing.T) {
+	app := &WindApp{embeds: map[string][]string{
+		"web/web.go": {"web/templates", "web/static/*.css", "all:web/public"},
+	}}
+	tests := map[string]bool{
+		"web/templates/index.html":        true,
+		"web/templates/partials/nav.tmpl": true,
+		"web/templates/.swp":              false,
+		"web/templates/_draft/page.html":  false,
+		"web/static/app.css":              true,
+		"web/static/app.js":               false,
+		"web/public/.well-known/x.txt":    true,
+		"templates/index.html":            false,
+	}
+	for path, expected := range tests {
+		if got := app.isEmbedded(path); got != expected {
+			t.Errorf("isEmbedded(%q) = %v, expected %v", path, got, expected)
+		}
+	}
+}
+
+func TestScanWatchesEmbeddedFiles(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	os.MkdirAll("templates", 0755)
+	os.WriteFile("main.go", []byte("package main\n\nimport _ \"embed\"\n\n//go:embed templates/index.html\nvar index string\n\nfunc main() {}\n"), 0644)
+	os.WriteFile("templates/index.html", []byte("<h1>hi</h1>\n"), 0644)
+	os.WriteFile("templates/other.html", []byte("<h1>other</h1>\n"), 0644)
+
+	app := newWindApp(WindConfig{IncludeExts: []string{".go"}}, "", "")
+	if err := app.scanFiles(); err != nil {
+		t.Fatalf("scanFiles() failed: %v", err)
+	}
+	if _, ok := app.fileStates["templates/index.html"]; !ok {
+		t.Fatal("Expected the embedded template to be watched")
+	}
+	if _, ok := app.fileStates["templates/other.html"]; ok {
+		t.Error("Expected templates that are not embedded to stay unwatched")
+	}
+
+	later := time.Now().Add(time.Minute)
+	os.WriteFile("templates/index.html", []byte("<h1>hello</h1>\n"), 0644)
+	os.Chtimes("templates/index.html", later, later)
+	if !app.checkForChanges() {
+		t.Error("Expected a change to the embedded template to trigger a rebuild")
+	}
+}
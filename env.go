@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseEnvFile reads KEY=VALUE pairs in dotenv format. Blank lines, #
+// comments and an optional "export " prefix are accepted; values may be
+// single or double quoted.
+func parseEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	vars := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for num := 1; scanner.Scan(); num++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, num)
+		}
+
+		value = strings.TrimSpace(value)
+		switch {
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %v", path, num, err)
+			}
+			value = unquoted
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		default:
+			// Unquoted values may carry a trailing comment
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		vars[key] = value
+	}
+	return vars, scanner.Err()
+}
+
+// envFiles returns the dotenv files loaded into the run command's
+// environment, in increasing order of precedence: the selected profile's
+// files (or EnvFiles without a profile), then EnvFile
+func (app *WindApp) envFiles() []string {
+	files := app.config.EnvFiles
+	if app.config.EnvProfile != "" {
+		files = app.config.EnvProfiles[app.config.EnvProfile]
+	}
+	if app.config.EnvFile != "" {
+		files = append(files[:len(files):len(files)], app.config.EnvFile)
+	}
+	return files
+}
+
+// runEnv returns the environment for the run command: Wind's own
+// environment, then the env files, then Env, later sources overriding
+// earlier ones. Missing env files are skipped unless named by EnvFile.
+func (app *WindApp) runEnv() ([]string, error) {
+	vars := map[string]string{}
+	for _, path := range app.envFiles() {
+		fileVars, err := parseEnvFile(path)
+		if err != nil {
+			if os.IsNotExist(err) && path != app.config.EnvFile {
+				continue
+			}
+			return nil, err
+		}
+		for k, v := range fileVars {
+			vars[k] = v
+		}
+	}
+	for k, v := range app.config.Env {
+		vars[k] = v
+	}
+
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	env := os.Environ()
+	for _, k := range keys {
+		env = append(env, k+"="+vars[k])
+	}
+	if app.port != 0 {
+		env = append(env, fmt.Sprintf("%s=%d", app.config.AssignPort, app.port))
+	}
+	return env, nil
+}
+
+// checkEnvChanges reports whether any env file was created, modified or
+// removed since the last call. The first call only records the current state.
+func (app *WindApp) checkEnvChanges() bool {
+	first := app.envStates == nil
+	if first {
+		app.envStates = make(map[string]time.Time)
+	}
+
+	changed := false
+	for _, path := range app.envFiles() {
+		var modTime time.Time
+		if info, err := os.Stat(path); err == nil {
+			modTime = info.ModTime()
+		}
+		if last, ok := app.envStates[path]; !first && (!ok || !last.Equal(modTime)) {
+			logf(levelInfo, Yellow+"Change: "+Reset+"%sEnv file changed: %s\n", app.label(), path)
+			changed = true
+		}
+		app.envStates[path] = modTime
+	}
+	return changed
+}
+
+// shellQuote quotes an argument for inclusion in an sh -c command line
+func shellQuote(arg string) string {
+	if arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_=.,/:@%+") == "" {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// withRunArgs appends pass-through arguments to a run command
+func withRunArgs(cmd string, args []string) string {
+	for _, arg := range args {
+		cmd += " " + shellQuote(arg)
+	}
+	return cmd
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	content := `# database
+DATABASE_URL=postgres://localhost/dev
+export PORT=9090
+GREETING="hello\nworld"
+RAW='single $quoted'
+DEBUG=true # inline comment
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	vars, err := parseEnvFile(path)
+	if err != nil {
+		t.Fatalf("parseEnvFile failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"DATABASE_URL": "postgres://localhost/dev",
+		"PORT":         "9090",
+		"GREETING":     "hello\nworld",
+		"RAW":          "single $quoted",
+		"DEBUG":        "true",
+	}
+	for k, v := range expected {
+		if vars[k] != v {
+			t.Errorf("Expected %s=%q, got %q", k, v, vars[k])
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("NOT A PAIR\n"), 0644); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+	if _, err := parseEnvFile(path); err == nil {
+		t.Error("Expected an error for a malformed line")
+	}
+}
+
+func TestRunEnvPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("PORT=9090\nMODE=file\n"), 0644); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	app := &WindApp{config: WindConfig{
+		EnvFile: path,
+		Env:     map[string]string{"MODE": "config"},
+	}}
+
+	env, err := app.runEnv()
+	if err != nil {
+		t.Fatalf("runEnv failed: %v", err)
+	}
+
+	// The last assignment of a variable wins for the child process
+	values := map[string]string{}
+	for _, kv := range env {
+		for i := 0; i < len(kv); i++ {
+			if kv[i] == '=' {
+				values[kv[:i]] = kv[i+1:]
+				break
+			}
+		}
+	}
+	if values["PORT"] != "9090" || values["MODE"] != "config" {
+		t.Errorf("Expected PORT=9090 and MODE=config, got PORT=%q MODE=%q", values["PORT"], values["MODE"])
+	}
+}
+
+func TestWithRunArgs(t *testing.T) {
+	got := withRunArgs("./tmp/main", []string{"--port=9090", "--debug", "--name=it's me", ""})
+	expected := `./tmp/main --port=9090 --debug '--name=it'\''s me' ''`
+	if got != expected {
+		t.Errorf("withRunArgs() = %q, expected %q", got, expected)
+	}
+}
+
+func TestEnvProfilesAndChanges(t *testing.T) {
+	tmpDir := t.TempDir()
+	base := filepath.Join(tmpDir, ".env")
+	local := filepath.Join(tmpDir, ".env.local")
+	staging := filepath.Join(tmpDir, ".env.staging")
+
+	if err := os.WriteFile(base, []byte("MODE=base\n"), 0644); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+	if err := os.WriteFile(staging, []byte("MODE=staging\n"), 0644); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	app := &WindApp{config: WindConfig{
+		EnvFiles:    []string{base, local},
+		EnvProfiles: map[string][]string{"staging": {base, staging}},
+	}}
+
+	// A missing .env.local is skipped rather than failing the start
+	if _, err := app.runEnv(); err != nil {
+		t.Fatalf("runEnv failed with missing optional file: %v", err)
+	}
+
+	app.config.EnvProfile = "staging"
+	env, _ := app.runEnv()
+	if env[len(env)-1] != "MODE=staging" {
+		t.Errorf("Expected staging profile to win, got %q", env[len(env)-1])
+	}
+
+	// First call records state, later calls report edits
+	if app.checkEnvChanges() {
+		t.Error("First checkEnvChanges should only record state")
+	}
+	if app.checkEnvChanges() {
+		t.Error("Expected no change without edits")
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(staging, later, later); err != nil {
+		t.Fatalf("Failed to touch env file: %v", err)
+	}
+	if !app.checkEnvChanges() {
+		t.Error("Expected an env file edit to be reported")
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// logFormatFlag selects how Wind reports what it does: "text" (default) or
+// "json" for NDJSON events
+const logFormatFlag = "--log-format"
+
+// event is one line of --log-format=json output. Fields that don't apply to
+// an event are omitted.
+type event struct {
+	Time  string `json:"time"`
+	Event string `json:"event"`
+	// Target is the process name in multi-process mode
+	Target string `json:"target,omitempty"`
+	// Files is the number of watched files (scan)
+	Files int `json:"files,omitempty"`
+	// Path is the changed file (change)
+	Path string `json:"path,omitempty"`
+	// Build is the build number (build_*); a retried build continues as a
+	// new number after build_retry
+	Build int `json:"build,omitempty"`
+	// Step is the wind ci step (step_*)
+	Step string `json:"step,omitempty"`
+	// DurationMs is how long the build or step took (build_ok, build_fail,
+	// step_ok, step_fail, ci_ok, ci_fail)
+	DurationMs int64  `json:"duration_ms,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// Errors is the number of compiler errors (build_fail)
+	Errors int `json:"errors,omitempty"`
+	PID    int `json:"pid,omitempty"`
+	// ExitCode is the exit status of the application and Signal the signal
+	// that killed it, e.g. SIGSEGV (app_exit)
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Signal   string `json:"signal,omitempty"`
+	// Stream and Message carry other output: Wind's own messages and the
+	// output of builds and the application (log)
+	Stream  string `json:"stream,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// eventLog publishes events to subscribers such as the control API and,
+// with --log-format=json, writes them as NDJSON. While it is active every
+// other line written to stdout and stderr becomes a log event too.
+type eventLog struct {
+	mutex sync.Mutex
+	// w receives the NDJSON output; nil keeps the regular text output
+	w           io.Writer
+	subscribers map[chan event]bool
+	redirect    *outputRedirect
+}
+
+// events is the active event log, nil when nothing consumes events
+var events *eventLog
+
+// startEventLog starts capturing events. In JSON mode they replace the text
+// output, otherwise the output is left as it is.
+func startEventLog(json bool) (*eventLog, error) {
+	l := &eventLog{subscribers: make(map[chan event]bool)}
+	if json {
+		l.w = os.Stdout
+	}
+	redirect, err := redirectOutput(func(dst *os.File) io.Writer {
+		stream := "stdout"
+		if dst == os.Stderr {
+			stream = "stderr"
+		}
+		if json {
+			return &eventStream{log: l, stream: stream}
+		}
+		return io.MultiWriter(dst, &eventStream{log: l, stream: stream})
+	})
+	if err != nil {
+		return nil, err
+	}
+	l.redirect = redirect
+	return l, nil
+}
+
+// stop restores the original output streams
+func (l *eventLog) stop() {
+	l.redirect.restore()
+}
+
+func (l *eventLog) write(ev event) {
+	ev.Time = time.Now().Format(time.RFC3339Nano)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.w != nil {
+		line, _ := json.Marshal(ev)
+		fmt.Fprintf(l.w, "%s\n", line)
+	}
+	// Slow subscribers miss events rather than stall Wind
+	for ch := range l.subscribers {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// subscribe returns a channel receiving every event from now on and a
+// function that unsubscribes it
+func (l *eventLog) subscribe() (<-chan event, func()) {
+	ch := make(chan event, 256)
+	l.mutex.Lock()
+	l.subscribers[ch] = true
+	l.mutex.Unlock()
+	return ch, func() {
+		l.mutex.Lock()
+		delete(l.subscribers, ch)
+		l.mutex.Unlock()
+	}
+}
+
+// emit records an event about this target in its status and publishes it
+// when an event log is active
+func (app *WindApp) emit(ev event) {
+	ev.Target = app.name
+	app.updateStatus(ev)
+	if events != nil {
+		events.write(ev)
+	}
+}
+
+// appStatus is the state of a target as reported by the control API
+type appStatus struct {
+	Target string `json:"target,omitempty"`
+	// State is idle, building, build_failed, running or stopped
+	State  string `json:"state"`
+	Paused bool   `json:"paused"`
+	PID    int    `json:"pid,omitempty"`
+	// Build, BuildMs and Error describe the latest build
+	Build   int    `json:"build,omitempty"`
+	BuildMs int64  `json:"build_ms,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// Errors is the number of compiler errors (build_fail)
+	Errors     int    `json:"errors,omitempty"`
+	LastChange string `json:"last_change,omitempty"`
+	// Files is the number of watched files of the initial scan
+	Files int `json:"files,omitempty"`
+	// Vulns are the IDs of the vulnerabilities govulncheck found in the
+	// latest check (vulnCheck)
+	Vulns []string `json:"vulns,omitempty"`
+}
+
+// updateStatus applies an event to the target's status
+func (app *WindApp) updateStatus(ev event) {
+	app.statusMutex.Lock()
+	defer app.statusMutex.Unlock()
+
+	s := &app.status
+	switch ev.Event {
+	case "scan":
+		s.Files = ev.Files
+	case "change":
+		s.LastChange = ev.Path
+	case "build_start":
+		s.State, s.Build = "building", ev.Build
+	case "build_ok":
+		s.State, s.BuildMs, s.Error = "idle", ev.DurationMs, ""
+	case "build_fail":
+		s.State, s.BuildMs, s.Error = "build_failed", ev.DurationMs, ev.Error
+	case "app_start":
+		s.State, s.PID = "running", ev.PID
+	case "app_exit":
+		// In proxy mode the previous process exits after its successor
+		// started
+		if ev.PID == s.PID {
+			s.State, s.PID = "stopped", 0
+		}
+	}
+}
+
+// currentStatus returns a snapshot of the target's status
+func (app *WindApp) currentStatus() appStatus {
+	app.statusMutex.Lock()
+	defer app.statusMutex.Unlock()
+
+	s := app.status
+	s.Target = app.name
+	s.Paused = app.paused.Load()
+	if app.vulnCheck != nil {
+		s.Vulns = app.vulnCheck.ids()
+	}
+	if s.State == "" {
+		s.State = "idle"
+	}
+	return s
+}
+
+// exitCode returns a pointer for event.ExitCode
+func exitCode(state *os.ProcessState) *int {
+	if state == nil {
+		return nil
+	}
+	code := state.ExitCode()
+	return &code
+}
+
+// ansiPattern matches the color codes stripped from log events
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// eventStream turns the lines written to one output stream into log events
+type eventStream struct {
+	log     *eventLog
+	stream  string
+	pending []byte
+}
+
+func (s *eventStream) Write(data []byte) (int, error) {
+	s.pending = append(s.pending, data...)
+	for {
+		i := bytes.IndexByte(s.pending, '\n')
+		if i < 0 {
+			break
+		}
+		line := ansiPattern.ReplaceAllString(string(bytes.TrimRight(s.pending[:i], "\r")), "")
+		s.pending = s.pending[i+1:]
+		if line != "" {
+			s.log.write(event{Event: "log", Stream: s.stream, Message: line})
+		}
+	}
+	return len(data), nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func decodeEvents(t *testing.T, data string) []event {
+	t.Helper()
+	var evs []event
+	for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		var ev event
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatalf("Invalid event line %q: %v", line, err)
+		}
+		evs = append(evs, ev)
+	}
+	return evs
+}
+
+func TestEmitEvent(t *testing.T) {
+	var buf bytes.Buffer
+	events = &eventLog{w: &buf}
+	defer func() { events = nil }()
+
+	app := newWindApp(defaultConfig(), "api", Cyan)
+	app.emit(event{Event: "build_fail", Build: 3, DurationMs: 1200, Error: "exit status 1"})
+	app.emit(event{Event: "app_exit", PID: 42, ExitCode: new(int)})
+
+	evs := decodeEvents(t, buf.String())
+	if len(evs) != 2 {
+		t.Fatalf("Expected 2 events, got %d: %s", len(evs), buf.String())
+	}
+	if ev := evs[0]; ev.Event != "build_fail" || ev.Target != "api" || ev.Build != 3 || ev.DurationMs != 1200 || ev.Time == "" {
+		t.Errorf("Unexpected build_fail event: %+v", ev)
+	}
+	if ev := evs[1]; ev.ExitCode == nil || *ev.ExitCode != 0 {
+		t.Errorf("Expected exit code 0 to be reported, got %+v", ev)
+	}
+	if strings.Contains(buf.String(), `"path"`) {
+		t.Errorf("Expected unused fields to be omitted: %s", buf.String())
+	}
+}
+
+func TestEmitWithoutEventLog(t *testing.T) {
+	app := newWindApp(defaultConfig(), "", "")
+	// Text mode: emitting is a no-op
+	app.emit(event{Event: "scan", Files: 3})
+}
+
+func TestEventStream(t *testing.T) {
+	var buf bytes.Buffer
+	stream := &eventStream{log: &eventLog{w: &buf}, stream: "stderr"}
+
+	stream.Write([]byte(Red + "Error: " + Reset + "boom\npart"))
+	stream.Write([]byte("ial line\r\n\n"))
+
+	evs := decodeEvents(t, buf.String())
+	if len(evs) != 2 {
+		t.Fatalf("Expected 2 log events, got %d: %s", len(evs), buf.String())
+	}
+	if evs[0].Event != "log" || evs[0].Stream != "stderr" || evs[0].Message != "Error: boom" {
+		t.Errorf("Unexpected first event: %+v", evs[0])
+	}
+	if evs[1].Message != "partial line" {
+		t.Errorf("Expected lines split across writes to be joined, got %q", evs[1].Message)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// eventsFromFlag replaces Wind's own change detection with an external
+// source of changed paths
+const eventsFromFlag = "--events-from"
+
+// eventsFromStdin is the only source: newline-delimited paths on stdin
+const eventsFromStdin = "stdin"
+
+// externalEventBuffer bounds the paths queued for a target that is busy
+// building
+const externalEventBuffer = 1024
+
+// readExternalEvents passes every path read from r, one per line, to the
+// targets until r is exhausted. Paths may be absolute or relative to the
+// project root, as watchman and most notifiers print them.
+func (o *orchestrator) readExternalEvents(r io.Reader) {
+	defer guardTerminal()
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		path := strings.TrimSpace(scanner.Text())
+		if path == "" {
+			continue
+		}
+		path = projectPath(path)
+		for _, app := range o.apps {
+			app.externalEvents <- path
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Failed to read events: %v\n", err)
+	}
+	logf(levelWarn, Yellow+"Warning: "+Reset+"Event source closed; changes are no longer detected\n")
+}
+
+// externalChange handles a path reported by the event source and reports
+// whether it is a change that needs a rebuild
+func (app *WindApp) externalChange(path string) bool {
+	if isExcluded(path, app.config.ExcludeDirs) || !app.shouldWatch(path) {
+		return false
+	}
+	if app.scanCache != nil && app.scanCache.ignore.ignored(path, false) {
+		return false
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		// Deleted: a change if the file was known
+		if _, known := app.fileStates[path]; !known {
+			return false
+		}
+		delete(app.fileStates, path)
+		logf(levelInfo, Yellow+"Change: "+Reset+"%sFile removed: %s\n", app.label(), path)
+		app.emit(event{Event: "change", Path: path})
+		app.pendingChanges = append(app.pendingChanges, path)
+		return true
+	}
+	if info.IsDir() {
+		return false
+	}
+	return app.fileChanged(path, info)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadExternalEvents(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	apps := []*WindApp{newWindApp(defaultConfig(), "api", ""), newWindApp(defaultConfig(), "worker", "")}
+	for _, app := range apps {
+		app.externalEvents = make(chan string, externalEventBuffer)
+	}
+	orch := newOrchestrator(apps)
+
+	input := filepath.Join(projectRoot(), "api", "main.go") + "\n\n  ./web/app.js  \n"
+	orch.readExternalEvents(strings.NewReader(input))
+
+	for _, app := range apps {
+		var got []string
+		for len(app.externalEvents) > 0 {
+			got = append(got, <-app.externalEvents)
+		}
+		if strings.Join(got, ",") != "api/main.go,web/app.js" {
+			t.Errorf("%s received %v", app.name, got)
+		}
+	}
+}
+
+func TestExternalChange(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "vendor", "x"), 0755)
+	for _, name := range []string{"main.go", "README.md", "vendor/x/x.go"} {
+		os.WriteFile(filepath.Join(tmpDir, name), []byte("package main\n"), 0644)
+	}
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	app := newWindApp(defaultConfig(), "", "")
+	app.scanFiles()
+
+	if app.externalChange("main.go") {
+		t.Error("An unchanged file should not be a change")
+	}
+	later := time.Now().Add(time.Second)
+	os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644)
+	os.Chtimes("main.go", later, later)
+	if !app.externalChange("main.go") {
+		t.Error("Expected the edited file to be a change")
+	}
+
+	os.Chtimes("vendor/x/x.go", later, later)
+	if app.externalChange("vendor/x/x.go") || app.externalChange("README.md") {
+		t.Error("Excluded and unwatched files should be skipped")
+	}
+
+	// A new file only becomes known; removing a known file is a change
+	os.WriteFile("util.go", []byte("package main\n"), 0644)
+	if app.externalChange("util.go") {
+		t.Error("A new file should not be a change")
+	}
+	os.Remove("util.go")
+	if !app.externalChange("util.go") {
+		t.Error("Expected removing a known file to be a change")
+	}
+	if app.externalChange("gone.go") {
+		t.Error("Removing an unknown file should not be a change")
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// exitOnFailFlag makes Wind exit non-zero when the first build or run
+// fails, so wrapper scripts can detect broken starts
+const exitOnFailFlag = "--exit-on-fail"
+
+// crashSignals name the signals that kill a crashing application for
+// signalName, next to the signalNames it may be sent
+var crashSignals = map[syscall.Signal]string{
+	syscall.SIGKILL: "SIGKILL",
+	syscall.SIGSEGV: "SIGSEGV",
+	syscall.SIGABRT: "SIGABRT",
+	syscall.SIGBUS:  "SIGBUS",
+	syscall.SIGFPE:  "SIGFPE",
+	syscall.SIGILL:  "SIGILL",
+	syscall.SIGPIPE: "SIGPIPE",
+}
+
+// exitSignal returns the name of the signal that killed a process, empty
+// when it exited by itself
+func exitSignal(state *os.ProcessState) string {
+	if state == nil {
+		return ""
+	}
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return signalName(ws.Signal())
+	}
+	return ""
+}
+
+// describeExit explains how a process ended, e.g. "exited with code 2" or
+// "was killed by SIGSEGV (segmentation fault), core dumped"
+func describeExit(state *os.ProcessState) string {
+	if state == nil {
+		return "exited"
+	}
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		s := fmt.Sprintf("was killed by %s (%v)", signalName(ws.Signal()), ws.Signal())
+		if ws.CoreDump() {
+			s += ", core dumped"
+		}
+		return s
+	}
+	return fmt.Sprintf("exited with code %d", state.ExitCode())
+}
+
+// exitStatusOf is the status a shell reports for a process: its exit code,
+// or 128 plus the signal that killed it
+func exitStatusOf(state *os.ProcessState) int {
+	if state == nil {
+		return 1
+	}
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return 128 + int(ws.Signal())
+	}
+	return state.ExitCode()
+}
+
+// watchProcess waits for a process launch started. An exit Wind did not ask
+// for is reported with its exit code or signal; a new build crashing at
+// startup rolls back instead (see guardStartup).
+func (app *WindApp) watchProcess(exit *processExit, env []string) {
+	<-exit.done
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+
+	if app.process != exit.process || exit.stopping.Load() {
+		return
+	}
+	if app.rollBack(env) {
+		return
+	}
+	// rollBack reported why it could not roll back
+	if app.process != exit.process {
+		app.failStart(exitStatusOf(exit.state))
+		return
+	}
+
+	forgetChild(exit.process.Pid)
+	app.emit(event{Event: "app_exit", PID: exit.process.Pid, ExitCode: exitCode(exit.state), Signal: exitSignal(exit.state)})
+	app.process, app.exit = nil, nil
+	if exit.state != nil && exit.state.Success() {
+		logf(levelWarn, Yellow+"Warning: "+Reset+"%sApplication (PID: %d) exited with code 0; save a file or press r to restart it\n", app.label(), exit.process.Pid)
+		return
+	}
+	logf(levelError, Red+"Error: "+Reset+"%sApplication (PID: %d) %s; save a file or press r to restart it\n", app.label(), exit.process.Pid, describeExit(exit.state))
+	app.failStart(exitStatusOf(exit.state))
+}
+
+// failureStatus is the status --exit-on-fail reports for a process that
+// failed to start: its own when it exited, otherwise 1
+func failureStatus(exit *processExit) int {
+	select {
+	case <-exit.done:
+		return exitStatusOf(exit.state)
+	default:
+		return 1
+	}
+}
+
+// failStart reports a failed build or run to --exit-on-fail while it still
+// judges the first one. The caller holds app.mutex.
+func (app *WindApp) failStart(status int) {
+	if app.startFailed != nil {
+		app.startFailed(max(status, 1))
+	}
+}
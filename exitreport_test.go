@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestDescribeExit(t *testing.T) {
+	tests := []struct {
+		script      string
+		description string
+		status      int
+		signal      string
+	}{
+		{"exit 3", "exited with code 3", 3, ""},
+		{"kill -TERM $$", "was killed by SIGTERM (terminated)", 143, "SIGTERM"},
+		{"kill -KILL $$", "was killed by SIGKILL (killed)", 137, "SIGKILL"},
+	}
+	for _, tt := range tests {
+		cmd := exec.Command("sh", "-c", tt.script)
+		cmd.Run()
+		state := cmd.ProcessState
+		if got := describeExit(state); got != tt.description {
+			t.Errorf("%s: expected %q, got %q", tt.script, tt.description, got)
+		}
+		if got := exitStatusOf(state); got != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.script, tt.status, got)
+		}
+		if got := exitSignal(state); got != tt.signal {
+			t.Errorf("%s: expected signal %q, got %q", tt.script, tt.signal, got)
+		}
+	}
+}
+
+func TestUnexpectedExit(t *testing.T) {
+	failed := make(chan int, 1)
+	app := newWindApp(WindConfig{RunCmd: "sleep 0.2; exit 4"}, "", "")
+	app.startFailed = func(status int) { failed <- status }
+	app.mutex.Lock()
+	app.launch(os.Environ())
+	app.mutex.Unlock()
+
+	select {
+	case status := <-failed:
+		if status != 4 {
+			t.Errorf("Expected exit status 4, got %d", status)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the exit to be reported")
+	}
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	if app.process != nil || app.exit != nil {
+		t.Error("Expected the exited process to be forgotten")
+	}
+
+	// Stopping it is not a failure
+	app.launch(os.Environ())
+	exit := app.exit
+	app.stopProcess()
+	<-exit.done
+	select {
+	case status := <-failed:
+		t.Errorf("Expected no failure for a stopped process, got %d", status)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
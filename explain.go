@@ -0,0 +1,103 @@
+package main
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// buildHint pairs a recognizable build error with an actionable suggestion.
+// Submatches of pattern can be referenced in hint as $1, $2, ...
+type buildHint struct {
+	name    string
+	pattern *regexp.Regexp
+	hint    string
+}
+
+// buildHints is the knowledge base of common Go build failures
+var buildHints = []buildHint{
+	{
+		name:    "missing-module",
+		pattern: regexp.MustCompile(`no required module provides package ([^;\s]+)`),
+		hint:    "Package $1 is not in go.mod. Run `go get $1` or `go mod tidy`.",
+	},
+	{
+		name:    "missing-go-sum",
+		pattern: regexp.MustCompile(`missing go\.sum entry for module providing package ([^\s(]+)`),
+		hint:    "go.sum is out of date for $1. Run `go mod download` or `go mod tidy`.",
+	},
+	{
+		name:    "cgo-toolchain",
+		pattern: regexp.MustCompile(`(cgo: C compiler "?([^"\s]+)"? not found|exec: "(gcc|clang|cc)": executable file not found)`),
+		hint:    "cgo needs a C toolchain. Install gcc/clang, or build with CGO_ENABLED=0 if no C code is required.",
+	},
+	{
+		name:    "duplicate-main",
+		pattern: regexp.MustCompile(`main redeclared in this block`),
+		hint:    "More than one file in the package declares func main. Move extra mains into their own cmd/<name>/ directory.",
+	},
+	{
+		name:    "unused-import",
+		pattern: regexp.MustCompile(`"([^"]+)" imported and not used`),
+		hint:    "Remove the unused import \"$1\", or run goimports to clean up imports.",
+	},
+	{
+		name:    "unused-variable",
+		pattern: regexp.MustCompile(`declared and not used: (\w+)`),
+		hint:    "Variable $1 is never read. Use it, or assign it to _ while iterating.",
+	},
+	{
+		name:    "no-go-files",
+		pattern: regexp.MustCompile(`no Go files in (\S+)`),
+		hint:    "The build target $1 has no Go files. Check the detected project structure or set a build command.",
+	},
+}
+
+// explainBuildError returns the hints matching the given build output, one
+// per knowledge base entry, in table order.
+func explainBuildError(output string) []string {
+	var hints []string
+	for _, h := range buildHints {
+		match := h.pattern.FindStringSubmatchIndex(output)
+		if match == nil {
+			continue
+		}
+		hint := h.pattern.ExpandString(nil, h.hint, output, match)
+		hints = append(hints, string(hint))
+	}
+	return hints
+}
+
+// printBuildHints appends the hints for a failed build under the compiler output
+func printBuildHints(output string) {
+	for _, hint := range explainBuildError(output) {
+		logf(levelInfo, Purple+"Hint: "+Reset+"%s\n", hint)
+	}
+}
+
+// runExplain implements `wind explain <error>`. With no arguments (or "-")
+// the error text is read from stdin so build output can be piped in.
+func runExplain(args []string) {
+	var text string
+	if len(args) == 0 || (len(args) == 1 && args[0] == "-") {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			logf(levelError, Red+"Error: "+Reset+"Failed to read stdin: %v\n", err)
+			return
+		}
+		text = string(data)
+	} else {
+		text = strings.Join(args, " ")
+	}
+
+	hints := explainBuildError(text)
+	if len(hints) == 0 {
+		logf(levelInfo, Yellow+"Info: "+Reset+"No known explanation for this error\n")
+		return
+	}
+
+	for _, hint := range hints {
+		logf(levelInfo, Purple+"Hint: "+Reset+"%s\n", hint)
+	}
+}
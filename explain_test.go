@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplainBuildError(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		contains string
+	}{
+		{
+			name:     "missing module",
+			output:   `main.go:5:2: no required module provides package github.com/foo/bar; to add it:`,
+			contains: "go get github.com/foo/bar",
+		},
+		{
+			name:     "cgo toolchain absent",
+			output:   `cgo: C compiler "gcc" not found: exec: "gcc": executable file not found in $PATH`,
+			contains: "CGO_ENABLED=0",
+		},
+		{
+			name:     "duplicate main",
+			output:   `./other.go:3:6: main redeclared in this block`,
+			contains: "cmd/<name>/",
+		},
+		{
+			name:     "unused import",
+			output:   `./main.go:4:2: "strings" imported and not used`,
+			contains: `unused import "strings"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hints := explainBuildError(tt.output)
+			if len(hints) == 0 {
+				t.Fatalf("Expected a hint for %q", tt.output)
+			}
+			if !strings.Contains(hints[0], tt.contains) {
+				t.Errorf("Expected hint to contain %q, got %q", tt.contains, hints[0])
+			}
+		})
+	}
+}
+
+func TestExplainBuildErrorUnknown(t *testing.T) {
+	if hints := explainBuildError("something completely different"); len(hints) != 0 {
+		t.Errorf("Expected no hints, got %v", hints)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// configDoc is a parsed config file and where it came from
+type configDoc struct {
+	source string
+	data   map[string]any
+}
+
+// extendsFetchTimeout bounds the download of a remote base config
+const extendsFetchTimeout = 10 * time.Second
+
+// resolveExtends returns the chain of config documents doc extends, bases
+// first, ending with doc itself. Decoding them in order lets every file
+// override its bases: scalars and lists are replaced, mappings are merged
+// key by key. chain holds the files being resolved, to reject cycles.
+func resolveExtends(source string, doc map[string]any, chain map[string]bool) ([]configDoc, error) {
+	refs, err := popExtends(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", source, err)
+	}
+
+	chain[source] = true
+	defer delete(chain, source)
+
+	var docs []configDoc
+	for _, ref := range refs {
+		location, err := resolveConfigRef(source, ref)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", source, err)
+		}
+		if chain[location] {
+			return nil, fmt.Errorf("%s: extends cycle through %s", source, location)
+		}
+
+		data, err := readConfigSource(location)
+		if err != nil {
+			return nil, fmt.Errorf("%s: extends %s: %v", source, ref, err)
+		}
+		base, err := parseYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", location, err)
+		}
+		bases, err := resolveExtends(location, base, chain)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, bases...)
+	}
+	return append(docs, configDoc{source: source, data: doc}), nil
+}
+
+// popExtends removes the extends key from doc and returns its references,
+// a single one or a list
+func popExtends(doc map[string]any) ([]string, error) {
+	for key, value := range doc {
+		if normalizeKey(key) != "extends" {
+			continue
+		}
+		delete(doc, key)
+
+		items, ok := value.([]any)
+		if !ok {
+			items = []any{value}
+		}
+		var refs []string
+		for _, item := range items {
+			ref, ok := item.(string)
+			if !ok || ref == "" {
+				return nil, fmt.Errorf("extends: expected a file path or URL")
+			}
+			refs = append(refs, ref)
+		}
+		return refs, nil
+	}
+	return nil, nil
+}
+
+func isRemoteConfig(ref string) bool {
+	return strings.HasPrefix(ref, "https://") || strings.HasPrefix(ref, "http://")
+}
+
+// resolveConfigRef resolves a reference relative to the config that
+// contains it
+func resolveConfigRef(from, ref string) (string, error) {
+	switch {
+	case isRemoteConfig(ref):
+		return ref, nil
+	case isRemoteConfig(from):
+		base, err := url.Parse(from)
+		if err != nil {
+			return "", err
+		}
+		rel, err := url.Parse(ref)
+		if err != nil {
+			return "", err
+		}
+		return base.ResolveReference(rel).String(), nil
+	case filepath.IsAbs(ref):
+		return filepath.Clean(ref), nil
+	}
+	return filepath.Join(filepath.Dir(from), ref), nil
+}
+
+// readConfigSource reads a local base config or downloads a remote one
+func readConfigSource(location string) ([]byte, error) {
+	if isRemoteConfig(location) {
+		return fetchConfig(location)
+	}
+	return os.ReadFile(location)
+}
+
+// fetchConfig downloads a remote base config. The URL must pin its content
+// with a #sha256=<hex> fragment; verified downloads are cached by checksum
+// so later sessions work offline.
+func fetchConfig(rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	want, ok := strings.CutPrefix(u.Fragment, "sha256=")
+	if !ok || len(want) != sha256.Size*2 {
+		return nil, fmt.Errorf("remote configs must be pinned with #sha256=<checksum>")
+	}
+	want = strings.ToLower(want)
+	u.Fragment = ""
+
+	cachePath := ""
+	if dir, err := os.UserCacheDir(); err == nil {
+		cachePath = filepath.Join(dir, "wind", "extends", want+".yaml")
+		if data, err := os.ReadFile(cachePath); err == nil && sha256Hex(data) == want {
+			return data, nil
+		}
+	}
+
+	client := &http.Client{Timeout: extendsFetchTimeout}
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if got := sha256Hex(data); got != want {
+		return nil, fmt.Errorf("checksum mismatch: got sha256=%s", got)
+	}
+
+	if cachePath != "" {
+		if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err == nil {
+			os.WriteFile(cachePath, data, 0644)
+		}
+	}
+	return data, nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigs(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestLoadConfigFileExtends(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigs(t, dir, map[string]string{
+		"platform/wind.base.yaml": `pollInterval: 2s
+includeExts: [.go, .tmpl]
+env:
+  LOG_LEVEL: info
+  REGION: eu
+proxy:
+  port: 9000
+  portEnv: HTTP_PORT
+`,
+		"platform/wind.web.yaml": `extends: wind.base.yaml
+includeExts: [.go, .html]
+`,
+		"app/.wind.yaml": `extends: ../platform/wind.web.yaml
+env:
+  LOG_LEVEL: debug
+proxy:
+  port: 9100
+`,
+	})
+
+	config := defaultConfig()
+	if _, err := loadConfigFile(filepath.Join(dir, "app", configFileName), &config); err != nil {
+		t.Fatalf("loadConfigFile failed: %v", err)
+	}
+
+	if config.PollInterval != 2*time.Second {
+		t.Errorf("Expected PollInterval from the base, got %v", config.PollInterval)
+	}
+	// Lists are replaced by the extending file
+	if strings.Join(config.IncludeExts, ",") != ".go,.html" {
+		t.Errorf("Expected IncludeExts from wind.web.yaml, got %v", config.IncludeExts)
+	}
+	// Mappings are merged key by key
+	if config.Env["LOG_LEVEL"] != "debug" || config.Env["REGION"] != "eu" {
+		t.Errorf("Expected merged env, got %v", config.Env)
+	}
+	if config.Proxy.Port != 9100 || config.Proxy.PortEnv != "HTTP_PORT" {
+		t.Errorf("Expected merged proxy settings, got %+v", config.Proxy)
+	}
+}
+
+func TestLoadConfigFileExtendsErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigs(t, dir, map[string]string{
+		"cycle/.wind.yaml":    "extends: a.yaml\n",
+		"cycle/a.yaml":        "extends: b.yaml\n",
+		"cycle/b.yaml":        "extends: a.yaml\n",
+		"missing/.wind.yaml":  "extends: nowhere.yaml\n",
+		"unpinned/.wind.yaml": "extends: https://example.com/wind.base.yaml\n",
+		"invalid/.wind.yaml":  "extends: base.yaml\n",
+		"invalid/base.yaml":   "noSuchKey: 1\n",
+	})
+
+	tests := map[string]string{
+		"cycle":    "extends cycle",
+		"missing":  "nowhere.yaml",
+		"unpinned": "#sha256=",
+		"invalid":  "base.yaml",
+	}
+	for name, expected := range tests {
+		config := defaultConfig()
+		_, err := loadConfigFile(filepath.Join(dir, name, configFileName), &config)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected error mentioning %q, got %v", name, expected, err)
+		}
+	}
+}
+
+func TestLoadConfigFileExtendsRemote(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	base := "debounceDelay: 1s\n"
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(base))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, configFileName)
+	pinned := server.URL + "/wind.base.yaml#sha256=" + sha256Hex([]byte(base))
+	writeConfigs(t, dir, map[string]string{configFileName: "extends: " + pinned + "\n"})
+
+	for i := 0; i < 2; i++ {
+		config := defaultConfig()
+		if _, err := loadConfigFile(path, &config); err != nil {
+			t.Fatalf("loadConfigFile failed: %v", err)
+		}
+		if config.DebounceDelay != time.Second {
+			t.Errorf("Expected DebounceDelay from the remote base, got %v", config.DebounceDelay)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected the verified base to be cached, got %d downloads", requests)
+	}
+
+	wrong := server.URL + "/other.yaml#sha256=" + strings.Repeat("0", 64)
+	writeConfigs(t, dir, map[string]string{configFileName: "extends: " + wrong + "\n"})
+	config := defaultConfig()
+	if _, err := loadConfigFile(path, &config); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Expected a checksum mismatch, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// watchExpr is a compiled watch filter expression such as
+//
+//	**/*.go and not **/*_test.go and not (gen/** or vendor/**)
+//
+// Operands are globs as in matchPattern; "and" binds tighter than "or".
+type watchExpr interface {
+	match(path string) bool
+}
+
+type globExpr string
+type notExpr struct{ x watchExpr }
+type andExpr struct{ x, y watchExpr }
+type orExpr struct{ x, y watchExpr }
+
+func (g globExpr) match(p string) bool { return matchPattern(string(g), p) }
+func (n notExpr) match(p string) bool  { return !n.x.match(p) }
+func (a andExpr) match(p string) bool  { return a.x.match(p) && a.y.match(p) }
+func (o orExpr) match(p string) bool   { return o.x.match(p) || o.y.match(p) }
+
+// compileWatchExpr parses a watch filter expression
+func compileWatchExpr(src string) (watchExpr, error) {
+	p := &exprParser{tokens: tokenizeExpr(src)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty watch expression")
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in watch expression", p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+// tokenizeExpr splits an expression into words and parentheses. Quoted
+// globs may contain spaces.
+func tokenizeExpr(src string) []string {
+	var tokens []string
+	var word strings.Builder
+	var quote rune
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, c := range src {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+				flush()
+			} else {
+				word.WriteRune(c)
+			}
+		case c == '"' || c == '\'':
+			flush()
+			quote = c
+		case c == '(' || c == ')':
+			flush()
+			tokens = append(tokens, string(c))
+		case c == ' ' || c == '\t' || c == '\n':
+			flush()
+		default:
+			word.WriteRune(c)
+		}
+	}
+	flush()
+	return tokens
+}
+
+type exprParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *exprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *exprParser) parseOr() (watchExpr, error) {
+	x, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "or") {
+		p.pos++
+		y, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		x = orExpr{x, y}
+	}
+	return x, nil
+}
+
+func (p *exprParser) parseAnd() (watchExpr, error) {
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "and") {
+		p.pos++
+		y, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		x = andExpr{x, y}
+	}
+	return x, nil
+}
+
+func (p *exprParser) parseUnary() (watchExpr, error) {
+	tok := p.peek()
+	switch {
+	case tok == "":
+		return nil, fmt.Errorf("unexpected end of watch expression")
+	case strings.EqualFold(tok, "not"):
+		p.pos++
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{x}, nil
+	case tok == "(":
+		p.pos++
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing ')' in watch expression")
+		}
+		p.pos++
+		return x, nil
+	case tok == ")" || strings.EqualFold(tok, "and") || strings.EqualFold(tok, "or"):
+		return nil, fmt.Errorf("unexpected %q in watch expression", tok)
+	}
+
+	p.pos++
+	if _, err := path.Match(tok, ""); err != nil {
+		return nil, fmt.Errorf("invalid glob %q in watch expression", tok)
+	}
+	return globExpr(tok), nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import "testing"
+
+func TestWatchExpr(t *testing.T) {
+	expr, err := compileWatchExpr(`**/*.go and not **/*_test.go and not (gen/** or vendor/**) or "web/templates/*.html"`)
+	if err != nil {
+		t.Fatalf("compileWatchExpr failed: %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"main.go", true},
+		{"internal/store/store.go", true},
+		{"internal/store/store_test.go", false},
+		{"gen/proto/user.pb.go", false},
+		{"vendor/github.com/x/y.go", false},
+		{"web/templates/index.html", true},
+		{"web/static/app.css", false},
+	}
+
+	for _, tt := range tests {
+		if got := expr.match(tt.path); got != tt.expected {
+			t.Errorf("match(%q) = %v, expected %v", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestWatchExprErrors(t *testing.T) {
+	for _, src := range []string{"", "*.go and", "(*.go or *.html", "not", "*.go )", "[a-"} {
+		if _, err := compileWatchExpr(src); err == nil {
+			t.Errorf("Expected an error for %q", src)
+		}
+	}
+}
+
+func TestMatchPatternDoubleStar(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "a/b/c.go", true},
+		{"gen/**", "gen/a/b.go", true},
+		{"gen/**", "src/gen/a.go", false},
+		{"api/**/*.proto", "api/v1/user.proto", true},
+		{"api/**/*.proto", "api/user.proto", true},
+	}
+
+	for _, tt := range tests {
+		if got := matchPattern(tt.pattern, tt.path); got != tt.expected {
+			t.Errorf("matchPattern(%q, %q) = %v, expected %v", tt.pattern, tt.path, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Formatters of the format stage
+const (
+	formatGofmt     = "gofmt"
+	formatGoimports = "goimports"
+)
+
+// validateFormat checks the Format setting
+func validateFormat(format string) error {
+	switch format {
+	case "", formatGofmt, formatGoimports:
+		return nil
+	}
+	return fmt.Errorf("format must be %s or %s, got %q", formatGofmt, formatGoimports, format)
+}
+
+// changedGoFiles returns the Go files changed in this cycle that still exist
+func changedGoFiles(paths []string) []string {
+	var files []string
+	for _, path := range paths {
+		if filepath.Ext(path) != ".go" {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// formatChanges rewrites the Go files changed in this cycle with the
+// configured formatter before the build. It reports whether any file was
+// rewritten, so the caller rescans and the write-back does not start
+// another cycle. Files with syntax errors are left alone for the build to
+// report.
+func (app *WindApp) formatChanges() bool {
+	if app.config.Format == "" {
+		return false
+	}
+	files := changedGoFiles(app.changedFiles)
+	if len(files) == 0 {
+		return false
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(app.config.Format, append([]string{"-l", "-w"}, files...)...)
+	cmd.Stdout = &stdout
+	err := cmd.Run()
+	if errors.Is(err, exec.ErrNotFound) {
+		logf(levelWarn, Yellow+"Warning: "+Reset+"%s%s not found; files are not formatted\n", app.label(), app.config.Format)
+		return false
+	}
+
+	// -l lists the files that were rewritten, also when others failed
+	formatted := strings.Fields(stdout.String())
+	if len(formatted) == 0 {
+		return false
+	}
+	logf(levelInfo, Cyan+"Info: "+Reset+"%sFormatted %s with %s\n", app.label(), describeChanged(formatted), app.config.Format)
+	return true
+}
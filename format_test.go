@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidateFormat(t *testing.T) {
+	for _, format := range []string{"", "gofmt", "goimports"} {
+		if err := validateFormat(format); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", format, err)
+		}
+	}
+	if err := validateFormat("gofumpt"); err == nil {
+		t.Error("Expected an error for an unknown formatter")
+	}
+}
+
+func TestChangedGoFiles(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	os.WriteFile("main.go", []byte("package main\n"), 0644)
+	os.WriteFile("index.html", []byte("<html>\n"), 0644)
+
+	got := changedGoFiles([]string{"main.go", "deleted.go", "index.html"})
+	if expected := []string{"main.go"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("changedGoFiles() = %v, expected %v", got, expected)
+	}
+}
+
+func TestFormatChangesDoesNotRetrigger(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+
+	past := time.Now().Add(-time.Hour)
+	os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644)
+	os.Chtimes("main.go", past, past)
+	app := newWindApp(WindConfig{IncludeExts: []string{".go"}, Format: "gofmt"}, "", "")
+	app.scanFiles()
+
+	saved := past.Add(time.Minute)
+	os.WriteFile("main.go", []byte("package main\n\nfunc main()   {\n}\n"), 0644)
+	os.Chtimes("main.go", saved, saved)
+	if !app.checkForChanges() {
+		t.Fatal("Expected the edit to be detected")
+	}
+	app.beginCycle()
+
+	if !app.formatChanges() {
+		t.Fatal("Expected formatChanges to rewrite main.go")
+	}
+	data, _ := os.ReadFile("main.go")
+	if expected := "package main\n\nfunc main() {\n}\n"; string(data) != expected {
+		t.Errorf("main.go = %q, expected %q", data, expected)
+	}
+	if app.formatChanges() {
+		t.Error("A formatted file should not be reported again")
+	}
+
+	app.scanFiles()
+	if app.checkForChanges() {
+		t.Error("The write-back of the formatter should not start another cycle")
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// funcChanges lists the top-level functions and methods that differ between
+// two versions of a Go file
+type funcChanges struct {
+	added    []string
+	removed  []string
+	modified []string
+}
+
+func (c funcChanges) empty() bool {
+	return len(c.added)+len(c.removed)+len(c.modified) == 0
+}
+
+// parseFuncs maps each top-level function ("Name") and method
+// ("Recv.Name") of a Go file to its printed source, comments excluded
+func parseFuncs(path string) (map[string]string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	funcs := map[string]string{}
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		fn.Doc = nil
+
+		var buf bytes.Buffer
+		printer.Fprint(&buf, token.NewFileSet(), fn)
+		funcs[funcName(fn)] = buf.String()
+	}
+	return funcs, nil
+}
+
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	switch r := recv.(type) {
+	case *ast.IndexExpr:
+		recv = r.X
+	case *ast.IndexListExpr:
+		recv = r.X
+	}
+	if ident, ok := recv.(*ast.Ident); ok {
+		return ident.Name + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
+
+// diffFuncs compares two function maps from parseFuncs
+func diffFuncs(before, after map[string]string) funcChanges {
+	var c funcChanges
+	for name, src := range after {
+		prev, ok := before[name]
+		switch {
+		case !ok:
+			c.added = append(c.added, name)
+		case prev != src:
+			c.modified = append(c.modified, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			c.removed = append(c.removed, name)
+		}
+	}
+	sort.Strings(c.added)
+	sort.Strings(c.removed)
+	sort.Strings(c.modified)
+	return c
+}
+
+// snapshotFuncs records the functions of a Go file for later reports.
+// Existing snapshots are kept; reportFuncChanges advances them.
+func (app *WindApp) snapshotFuncs(path string) {
+	if !app.config.FunctionReport || filepath.Ext(path) != ".go" {
+		return
+	}
+	if app.funcSnapshots == nil {
+		app.funcSnapshots = make(map[string]map[string]string)
+	}
+	if _, ok := app.funcSnapshots[path]; ok {
+		return
+	}
+	if funcs, err := parseFuncs(path); err == nil {
+		app.funcSnapshots[path] = funcs
+	}
+}
+
+// reportFuncChanges prints which functions the changed Go files of this
+// cycle added, removed or modified
+func (app *WindApp) reportFuncChanges() {
+	if !app.config.FunctionReport {
+		return
+	}
+
+	seen := map[string]bool{}
+	for _, path := range app.changedFiles {
+		if filepath.Ext(path) != ".go" || seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		after, err := parseFuncs(path)
+		if err != nil {
+			continue
+		}
+		changes := diffFuncs(app.funcSnapshots[path], after)
+		app.funcSnapshots[path] = after
+		if changes.empty() {
+			continue
+		}
+
+		var parts []string
+		for _, name := range changes.added {
+			parts = append(parts, Green+"+"+name+Reset)
+		}
+		for _, name := range changes.modified {
+			parts = append(parts, Yellow+"~"+name+Reset)
+		}
+		for _, name := range changes.removed {
+			parts = append(parts, Red+"-"+name+Reset)
+		}
+		logf(levelInfo, Cyan+"Functions: "+Reset+"%s%s: %s\n", app.label(), path, strings.Join(parts, " "))
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiffFuncs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "handlers.go")
+
+	parse := func(src string) map[string]string {
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		funcs, err := parseFuncs(path)
+		if err != nil {
+			t.Fatalf("parseFuncs failed: %v", err)
+		}
+		return funcs
+	}
+
+	before := parse(`package main
+
+type Server struct{}
+
+func (s *Server) Home() string { return "home" }
+
+func helper() int { return 1 }
+
+func legacy() {}
+`)
+
+	after := parse(`package main
+
+type Server struct{}
+
+// Home now has a doc comment, which is not a modification
+func (s *Server) Home() string { return "home" }
+
+func helper() int { return 2 }
+
+func (s Server) Health() string { return "ok" }
+`)
+
+	got := diffFuncs(before, after)
+	expected := funcChanges{
+		added:    []string{"Server.Health"},
+		removed:  []string{"legacy"},
+		modified: []string{"helper"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("diffFuncs() = %+v, expected %+v", got, expected)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GeneratorRule runs a code generator before the build when a changed path
+// matches its pattern, e.g. *.proto → buf generate
+type GeneratorRule struct {
+	// Pattern is a glob (see matchPattern). Patterns without a slash match
+	// the file name in any directory; others match the project-relative path.
+	Pattern string
+	// Command runs once per cycle, however many matching files changed
+	Command string
+}
+
+// matches reports whether path is covered by the rule's pattern
+func (r GeneratorRule) matches(path string) bool {
+	return matchPattern(r.Pattern, path)
+}
+
+// matchPattern matches path against a glob. Patterns without a slash match
+// the file name in any directory; "**" matches any number of directories.
+func matchPattern(pattern, path string) bool {
+	path = projectPath(path)
+	if !strings.Contains(pattern, "/") {
+		path = filepath.Base(path)
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(path, "/"))
+}
+
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := filepath.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+func validateGenerators(rules []GeneratorRule) error {
+	for i, rule := range rules {
+		if rule.Pattern == "" || rule.Command == "" {
+			return fmt.Errorf("generators[%d]: pattern and command are required", i)
+		}
+		if _, err := filepath.Match(rule.Pattern, ""); err != nil {
+			return fmt.Errorf("generators[%d]: invalid pattern %q", i, rule.Pattern)
+		}
+	}
+	return nil
+}
+
+// matchesGenerator reports whether path is an input of any generator rule,
+// so generator sources are watched even when their extension is not in
+// IncludeExts
+func (app *WindApp) matchesGenerator(path string) bool {
+	for _, rule := range app.config.Generators {
+		if rule.matches(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// runGenerators runs, in declaration order, the generators whose patterns
+// match a path changed in this cycle. It reports whether any generator ran.
+func (app *WindApp) runGenerators() bool {
+	ran := false
+	for _, rule := range app.config.Generators {
+		var matched []string
+		for _, path := range app.changedFiles {
+			if rule.matches(path) {
+				matched = append(matched, path)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+
+		logf(levelInfo, app.label()+Cyan+"⚙️  %s changed, running %s..."+Reset+"\n", describeChanged(matched), rule.Command)
+
+		cmd := exec.Command("sh", "-c", rule.Command)
+		cmd.Stdout = app.output(os.Stdout)
+		cmd.Stderr = app.output(os.Stderr)
+		if err := cmd.Run(); err != nil {
+			logf(levelError, Red+"Error: "+Reset+"%sGenerator %q failed: %v\n", app.label(), rule.Command, err)
+			continue
+		}
+		ran = true
+	}
+	return ran
+}
+
+// describeChanged names a single changed file or counts several
+func describeChanged(paths []string) string {
+	if len(paths) == 1 {
+		return paths[0]
+	}
+	return fmt.Sprintf("%d files", len(paths))
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGeneratorRuleMatches(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"*.proto", "api/v1/user.proto", true},
+		{"*.proto", "user.proto", true},
+		{"*.proto", "api/user.go", false},
+		{"*.sql", "db/queries/users.sql", true},
+		{"db/queries/*.sql", "db/queries/users.sql", true},
+		{"db/queries/*.sql", "db/migrations/001.sql", false},
+	}
+
+	for _, tt := range tests {
+		rule := GeneratorRule{Pattern: tt.pattern, Command: "true"}
+		if got := rule.matches(tt.path); got != tt.expected {
+			t.Errorf("%q matches %q = %v, expected %v", tt.pattern, tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestRunGeneratorsOnlyMatching(t *testing.T) {
+	dir := t.TempDir()
+	marker := func(name string) string { return filepath.Join(dir, name) }
+
+	app := newWindApp(WindConfig{
+		Generators: []GeneratorRule{
+			{Pattern: "*.proto", Command: "touch " + marker("buf")},
+			{Pattern: "*.templ", Command: "touch " + marker("templ")},
+			{Pattern: "*.sql", Command: "touch " + marker("sqlc")},
+		},
+	}, "", "")
+	app.changedFiles = []string{"api/user.proto", "views/index.templ", "main.go"}
+
+	if !app.runGenerators() {
+		t.Fatal("Expected runGenerators to report that generators ran")
+	}
+	for name, expected := range map[string]bool{"buf": true, "templ": true, "sqlc": false} {
+		_, err := os.Stat(marker(name))
+		if ran := err == nil; ran != expected {
+			t.Errorf("Generator %s ran = %v, expected %v", name, ran, expected)
+		}
+	}
+
+	app.changedFiles = []string{"main.go"}
+	if app.runGenerators() {
+		t.Error("No generator should run when no pattern matches")
+	}
+}
+
+func TestValidateGenerators(t *testing.T) {
+	if err := validateGenerators([]GeneratorRule{{Pattern: "*.proto", Command: "buf generate"}}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := validateGenerators([]GeneratorRule{{Pattern: "*.proto"}}); err == nil {
+		t.Error("Expected an error for a rule without a command")
+	}
+	if err := validateGenerators([]GeneratorRule{{Pattern: "[", Command: "x"}}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+)
+
+// fileDigest identifies a file's contents by size and SHA-256
+func fileDigest(path string, size int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d:%s", size, hex.EncodeToString(h.Sum(nil))), nil
+}
+
+// contentChanged records the digest of a file whose mtime moved and reports
+// whether its bytes differ from the last recorded digest. In mtime mode every
+// mtime change counts as a content change.
+func (app *WindApp) contentChanged(path string, info os.FileInfo) bool {
+	if app.config.ChangeDetection != ChangeDetectionHash {
+		return true
+	}
+	if app.fileHashes == nil {
+		app.fileHashes = make(map[string]string)
+	}
+
+	digest, err := fileDigest(path, info.Size())
+	if err != nil {
+		// Unreadable files fall back to mtime semantics
+		return true
+	}
+
+	previous, exists := app.fileHashes[path]
+	app.fileHashes[path] = digest
+	return !exists || previous != digest
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestHashChangeDetection(t *testing.T) {
+	tmpDir := createTempProject(t, "root")
+	defer os.RemoveAll(tmpDir)
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+
+	app := &WindApp{
+		config: WindConfig{
+			IncludeExts:     []string{".go"},
+			ExcludeDirs:     []string{"tmp"},
+			ChangeDetection: ChangeDetectionHash,
+		},
+		fileStates: make(map[string]time.Time),
+		fileHashes: make(map[string]string),
+	}
+
+	if err := app.scanFiles(); err != nil {
+		t.Fatalf("Failed to scan files: %v", err)
+	}
+
+	// Touching the file moves its mtime without changing its bytes
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes("main.go", future, future); err != nil {
+		t.Fatalf("Failed to touch main.go: %v", err)
+	}
+	if app.checkForChanges() {
+		t.Error("Touch without content change should not trigger a rebuild in hash mode")
+	}
+
+	// A real edit must still be detected
+	if err := os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatalf("Failed to modify main.go: %v", err)
+	}
+	later := future.Add(time.Minute)
+	if err := os.Chtimes("main.go", later, later); err != nil {
+		t.Fatalf("Failed to touch main.go: %v", err)
+	}
+	if !app.checkForChanges() {
+		t.Error("Content change should trigger a rebuild in hash mode")
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// readyPollInterval is the delay between readiness probes
+const readyPollInterval = 100 * time.Millisecond
+
+// pollReady runs probe until it succeeds or timeout passes and returns how
+// long the application took to become ready
+func pollReady(probe func() bool, timeout time.Duration) (time.Duration, error) {
+	return pollProcessReady(probe, timeout, nil)
+}
+
+// pollProcessReady is pollReady for the process exit waits for, failing as
+// soon as it exits; exit may be nil
+func pollProcessReady(probe func() bool, timeout time.Duration, exit *processExit) (time.Duration, error) {
+	var exited chan struct{}
+	if exit != nil {
+		exited = exit.done
+	}
+	start := time.Now()
+	for {
+		if probe() {
+			return time.Since(start), nil
+		}
+		if time.Since(start) >= timeout {
+			return 0, fmt.Errorf("not ready after %v", timeout)
+		}
+		select {
+		case <-exited:
+			return 0, fmt.Errorf("%s before it was ready", describeExit(exit.state))
+		case <-time.After(readyPollInterval):
+		}
+	}
+}
+
+// probeTCP reports whether addr accepts connections
+func probeTCP(addr string) func() bool {
+	return func() bool {
+		conn, err := net.DialTimeout("tcp", addr, readyPollInterval)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}
+}
+
+// probeHTTP reports whether url answers with a status below 500
+func probeHTTP(url string) func() bool {
+	client := &http.Client{Timeout: time.Second}
+	return func() bool {
+		resp, err := client.Get(url)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode < 500
+	}
+}
+
+// readyProbe returns the configured readiness check, or nil when the app is
+// considered started as soon as its process is
+func (app *WindApp) readyProbe() func() bool {
+	switch {
+	case app.config.HealthCheckURL != "":
+		return probeHTTP(app.config.HealthCheckURL)
+	case app.config.ReadyTCPPort != 0:
+		return probeTCP(fmt.Sprintf("127.0.0.1:%d", app.config.ReadyTCPPort))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestPollReady(t *testing.T) {
+	calls := 0
+	latency, err := pollReady(func() bool {
+		calls++
+		return calls == 3
+	}, time.Second)
+	if err != nil {
+		t.Fatalf("Expected probe to pass, got %v", err)
+	}
+	if latency < 2*readyPollInterval {
+		t.Errorf("Expected latency to cover two poll intervals, got %v", latency)
+	}
+
+	if _, err := pollReady(func() bool { return false }, 200*time.Millisecond); err == nil {
+		t.Error("Expected a probe that never passes to time out")
+	}
+}
+
+func TestProbeHTTP(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	probe := probeHTTP(srv.URL)
+	if probe() {
+		t.Error("A 503 response should not count as ready")
+	}
+	status = http.StatusOK
+	if !probe() {
+		t.Error("A 200 response should count as ready")
+	}
+}
+
+func TestLaunchFailsWhenNeverReady(t *testing.T) {
+	port, err := freePort()
+	if err != nil {
+		t.Fatalf("freePort failed: %v", err)
+	}
+
+	app := newWindApp(WindConfig{
+		RunCmd:       "exec sleep 5",
+		ReadyTCPPort: port,
+		ReadyTimeout: 200 * time.Millisecond,
+	}, "", "")
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	if app.launch(os.Environ()) {
+		t.Fatal("Expected launch to fail when the app never becomes ready")
+	}
+	if app.process != nil {
+		t.Error("The unready process should have been stopped")
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"time"
+)
+
+// HotPatchConfig configures hot patching: when only templates or static
+// assets changed, Wind asks the running app to reload them instead of
+// restarting it.
+//
+// The protocol is a single request. Wind POSTs
+//
+//	{"root": "/abs/project", "files": ["templates/index.html"]}
+//
+// to URL, with project-relative paths. A 2xx response means the app applied
+// the change; anything else, or no answer within Timeout, falls back to the
+// usual rebuild and restart. Apps that don't implement the endpoint need no
+// changes.
+type HotPatchConfig struct {
+	// URL is the app's patch endpoint; an empty URL disables hot patching
+	URL string
+	// Patterns select the files that can be patched (globs as in
+	// generator rules). Every file changed in a cycle must match.
+	Patterns []string
+	Timeout  time.Duration
+}
+
+// hotPatchRequest is the body POSTed to HotPatchConfig.URL
+type hotPatchRequest struct {
+	Root  string   `json:"root"`
+	Files []string `json:"files"`
+}
+
+// patchable reports whether every file changed in this cycle may be hot
+// patched
+func (app *WindApp) patchable() bool {
+	if app.config.HotPatch.URL == "" || len(app.changedFiles) == 0 {
+		return false
+	}
+	for _, path := range app.changedFiles {
+		matched := false
+		for _, pattern := range app.config.HotPatch.Patterns {
+			if matchPattern(pattern, path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// hotPatch offers the changed files to the running app and reports whether
+// it applied them, in which case no restart is needed
+func (app *WindApp) hotPatch() bool {
+	if !app.patchable() {
+		return false
+	}
+
+	app.mutex.Lock()
+	running := app.process != nil || app.ab != nil
+	app.mutex.Unlock()
+	if !running {
+		return false
+	}
+
+	files := make([]string, len(app.changedFiles))
+	for i, path := range app.changedFiles {
+		files[i] = filepath.ToSlash(path)
+	}
+	body, _ := json.Marshal(hotPatchRequest{Root: getCurrentDir(), Files: files})
+
+	client := &http.Client{Timeout: app.config.HotPatch.Timeout}
+	resp, err := client.Post(app.config.HotPatch.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		logf(levelInfo, Yellow+"Info: "+Reset+"%sHot patch unavailable (%v), restarting\n", app.label(), err)
+		return false
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		logf(levelInfo, Yellow+"Info: "+Reset+"%sHot patch declined (%s), restarting\n", app.label(), resp.Status)
+		return false
+	}
+
+	logf(levelSuccess, Green+"Success: "+Reset+"%sHot patched %s without restart\n", app.label(), describeChanged(files))
+	if app.liveReload != nil {
+		go app.liveReload.reload()
+	}
+	return true
+}
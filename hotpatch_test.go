@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestHotPatch(t *testing.T) {
+	status := http.StatusNoContent
+	var received hotPatchRequest
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(status)
+	}))
+	defer endpoint.Close()
+
+	config := defaultConfig()
+	config.HotPatch.URL = endpoint.URL
+	app := newWindApp(config, "", "")
+	app.process, _ = os.FindProcess(os.Getpid())
+
+	app.changedFiles = []string{"templates/index.html", "static/app.css"}
+	if !app.hotPatch() {
+		t.Fatal("Expected the app to accept the patch")
+	}
+	if !reflect.DeepEqual(received.Files, app.changedFiles) {
+		t.Errorf("Patch listed %v, expected %v", received.Files, app.changedFiles)
+	}
+
+	// Apps without the endpoint fall back to a restart
+	status = http.StatusNotFound
+	if app.hotPatch() {
+		t.Error("A declined patch should fall back to a restart")
+	}
+
+	// Go changes always rebuild
+	status = http.StatusNoContent
+	app.changedFiles = []string{"templates/index.html", "main.go"}
+	if app.hotPatch() {
+		t.Error("Cycles with Go changes should not be hot patched")
+	}
+}
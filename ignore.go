@@ -0,0 +1,130 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// ignoreFileName holds ignore rules in gitignore syntax, applied on top of
+// ExcludeDirs
+const ignoreFileName = ".windignore"
+
+// ignoreRule is one line of an ignore file
+type ignoreRule struct {
+	// segments is the pattern split at slashes; unanchored patterns start
+	// with "**"
+	segments []string
+	negate   bool
+	dirOnly  bool
+}
+
+// ignoreRules are the rules of an ignore file in order; the last rule
+// matching a path decides
+type ignoreRules []ignoreRule
+
+// parseIgnore parses gitignore syntax: # comments, ! negation, a trailing /
+// for directories only, a leading or inner / anchoring the pattern to the
+// project root, and *, ?, [...] and ** globs
+func parseIgnore(data string) ignoreRules {
+	var rules ignoreRules
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+		switch {
+		case strings.HasPrefix(line, "!"):
+			rule.negate = true
+			line = line[1:]
+		case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		// A slash anywhere but at the end anchors the pattern
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		rule.segments = strings.Split(line, "/")
+		if !anchored && line != "**" {
+			rule.segments = append([]string{"**"}, rule.segments...)
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// decide returns whether the rules ignore the normalized path itself,
+// without looking at its parent directories
+func (r ignoreRules) decide(path string, isDir bool) bool {
+	parts := strings.Split(path, "/")
+	ignored := false
+	for _, rule := range r {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if matchSegments(rule.segments, parts) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// ignored reports whether the normalized path, or a directory it is in, is
+// ignored. As with git, a file cannot be re-included when its directory is
+// ignored. Paths outside the project are never ignored.
+func (r ignoreRules) ignored(path string, isDir bool) bool {
+	if len(r) == 0 || path == "." || path == ".." || strings.HasPrefix(path, "../") {
+		return false
+	}
+	parts := strings.Split(path, "/")
+	for i := 1; i < len(parts); i++ {
+		if r.decide(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return r.decide(path, isDir)
+}
+
+// reloadIgnore loads .windignore on the first scan and again whenever it is
+// edited, dropping the cached listings that were filtered with the old rules
+func (app *WindApp) reloadIgnore() {
+	info, err := os.Stat(ignoreFileName)
+	var modTime time.Time
+	if err == nil {
+		modTime = info.ModTime()
+	}
+	if app.ignoreLoaded && modTime.Equal(app.ignoreModTime) {
+		return
+	}
+	first := !app.ignoreLoaded
+	app.ignoreLoaded, app.ignoreModTime = true, modTime
+
+	var rules ignoreRules
+	if err == nil {
+		data, err := os.ReadFile(ignoreFileName)
+		if err != nil {
+			logf(levelError, Red+"Error: "+Reset+"%sFailed to read %s: %v\n", app.label(), ignoreFileName, err)
+			return
+		}
+		rules = parseIgnore(string(data))
+	}
+	app.scanCache.setIgnore(rules)
+
+	switch {
+	case err != nil && !first:
+		logf(levelInfo, Cyan+"Info: "+Reset+"%s%s removed\n", app.label(), ignoreFileName)
+	case err == nil && first:
+		logf(levelInfo, Cyan+"Info: "+Reset+"%sLoaded %s (%s)\n", app.label(), ignoreFileName, pluralize(len(rules), "rule"))
+	case err == nil:
+		logf(levelInfo, Cyan+"Info: "+Reset+"%sReloaded %s (%s)\n", app.label(), ignoreFileName, pluralize(len(rules), "rule"))
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIgnoreRules(t *testing.T) {
+	rules := parseIgnore(`# generated code
+*.pb.go
+!keep.pb.go
+/build
+docs/*.md
+logs/
+web/**/fixtures
+\#notes.txt
+`)
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"api/api.pb.go", false, true},
+		{"api.pb.go", false, true},
+		{"api/keep.pb.go", false, false},
+		{"api/api.go", false, false},
+		{"build", true, true},
+		{"build/main", false, true},
+		{"cmd/build", true, false},
+		{"docs/intro.md", false, true},
+		{"docs/api/intro.md", false, false},
+		{"logs", true, true},
+		{"logs", false, false},
+		{"app/logs/today.txt", false, true},
+		{"web/fixtures/a.json", false, true},
+		{"web/a/b/fixtures", true, true},
+		{"#notes.txt", false, true},
+		{"../shared/api.pb.go", false, false},
+	}
+	for _, tt := range tests {
+		if got := rules.ignored(tt.path, tt.isDir); got != tt.ignored {
+			t.Errorf("ignored(%q, dir=%v) = %v, want %v", tt.path, tt.isDir, got, tt.ignored)
+		}
+	}
+
+	// A file cannot be re-included when its directory is ignored
+	rules = parseIgnore("vendor/\n!vendor/keep.go\n")
+	if !rules.ignored("vendor/keep.go", false) {
+		t.Error("Expected files in an ignored directory to stay ignored")
+	}
+}
+
+func TestWindIgnoreHotReload(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"main.go", "gen/models.go", "api/api.go"} {
+		path := filepath.Join(tmpDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("package x\n"), 0644)
+	}
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	os.WriteFile(ignoreFileName, []byte("gen/\n"), 0644)
+
+	app := newWindApp(defaultConfig(), "", "")
+	app.scanFiles()
+	if _, ok := app.fileStates["gen/models.go"]; ok {
+		t.Error("Expected gen/ to be ignored")
+	}
+	if _, ok := app.fileStates["api/api.go"]; !ok {
+		t.Error("Expected api/api.go to be watched")
+	}
+
+	// Editing the ignore file applies the new rules on the next scan
+	os.WriteFile(ignoreFileName, []byte("api/\n"), 0644)
+	later := time.Now().Add(time.Second)
+	os.Chtimes(ignoreFileName, later, later)
+	app.fileStates = map[string]time.Time{}
+	app.scanFiles()
+	if _, ok := app.fileStates["gen/models.go"]; !ok {
+		t.Error("Expected gen/ to be watched after the rule was removed")
+	}
+	if _, ok := app.fileStates["api/api.go"]; ok {
+		t.Error("Expected api/ to be ignored after the edit")
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// instanceRegistryFile lists the running Wind sessions, next to
+// portRegistryFile
+const instanceRegistryFile = "wind/instances.json"
+
+// instance is a running Wind session
+type instance struct {
+	PID     int       `json:"pid"`
+	Owner   string    `json:"owner"`
+	Project string    `json:"project"`
+	Targets []string  `json:"targets"`
+	Started time.Time `json:"started"`
+}
+
+type instanceRegistry struct {
+	Instances []instance `json:"instances"`
+}
+
+// newInstance describes this session
+func newInstance(apps []*WindApp) instance {
+	self := instance{PID: os.Getpid(), Owner: currentUser(), Project: projectRoot(), Started: time.Now()}
+	for _, app := range apps {
+		target := app.name
+		if target == "" {
+			target = "main"
+		}
+		self.Targets = append(self.Targets, target)
+	}
+	return self
+}
+
+// registerInstance records this session for `wind ps`, dropping sessions
+// that are gone. It fails when another user's session watches the same
+// project, since both would build into the same tmp directory.
+func registerInstance(self instance) error {
+	path, err := registryPath(instanceRegistryFile)
+	if err != nil {
+		return err
+	}
+	var registry instanceRegistry
+	return updateJSON(path, &registry, func() error {
+		live := registry.Instances[:0]
+		for _, in := range registry.Instances {
+			if in.PID == self.PID || !processAlive(in.PID) {
+				continue
+			}
+			if in.Project == self.Project && in.Owner != self.Owner {
+				return fmt.Errorf("%s is already watching %s (PID: %d); sessions of two users would share its tmp directory, use a checkout of your own", in.Owner, in.Project, in.PID)
+			}
+			live = append(live, in)
+		}
+		registry.Instances = append(live, self)
+		return nil
+	})
+}
+
+// unregisterInstance removes this session from the registry
+func unregisterInstance() {
+	path, err := registryPath(instanceRegistryFile)
+	if err != nil {
+		return
+	}
+	pid := os.Getpid()
+	var registry instanceRegistry
+	updateJSON(path, &registry, func() error {
+		live := registry.Instances[:0]
+		for _, in := range registry.Instances {
+			if in.PID != pid {
+				live = append(live, in)
+			}
+		}
+		registry.Instances = live
+		return nil
+	})
+}
+
+// runPS implements `wind ps`: the running sessions with their ports
+func runPS() {
+	var instances []instance
+	for _, path := range registryPaths(instanceRegistryFile) {
+		var registry instanceRegistry
+		if err := readJSON(path, &registry); err != nil {
+			logf(levelError, Red+"Error: "+Reset+"Failed to read %s: %v\n", path, err)
+			return
+		}
+		for _, in := range registry.Instances {
+			if processAlive(in.PID) {
+				instances = append(instances, in)
+			}
+		}
+	}
+
+	ports := map[int][]int{}
+	for _, path := range registryPaths(portRegistryFile) {
+		registry, err := readPorts(path)
+		if err != nil {
+			continue
+		}
+		for _, e := range registry.Entries {
+			if e.PID != 0 {
+				ports[e.PID] = append(ports[e.PID], e.Port)
+			}
+		}
+	}
+	fmt.Print(formatInstances(instances, ports, time.Now(), terminalWidth()))
+}
+
+// formatInstances lists sessions by owner and project in width columns, with
+// the ports each one uses
+func formatInstances(instances []instance, ports map[int][]int, now time.Time, width int) string {
+	if len(instances) == 0 {
+		return "No Wind sessions running\n"
+	}
+	sorted := append([]instance(nil), instances...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Owner != sorted[j].Owner {
+			return sorted[i].Owner < sorted[j].Owner
+		}
+		return sorted[i].Project < sorted[j].Project
+	})
+
+	rows := newTable("")
+	for _, in := range sorted {
+		used := append([]int(nil), ports[in.PID]...)
+		sort.Ints(used)
+		list := make([]string, len(used))
+		for i, port := range used {
+			list[i] = ":" + strconv.Itoa(port)
+		}
+		portList := strings.Join(list, " ")
+		if portList == "" {
+			portList = "no ports"
+		}
+		up := "up " + now.Sub(in.Started).Round(time.Second).String()
+		rows.addRow(strconv.Itoa(in.PID), in.Owner, up, strings.Join(in.Targets, ","), portList, in.Project)
+	}
+	return rows.render(width)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegisterInstance(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	self := instance{PID: os.Getpid(), Owner: "bob", Project: "/src/shop", Targets: []string{"main"}, Started: time.Now()}
+	if err := registerInstance(self); err != nil {
+		t.Fatal(err)
+	}
+	// A stale entry of a session that is gone is dropped
+	path, _ := registryPath(instanceRegistryFile)
+	var registry instanceRegistry
+	updateJSON(path, &registry, func() error {
+		registry.Instances = append(registry.Instances, instance{PID: 1 << 30, Owner: "carol", Project: "/src/shop"})
+		return nil
+	})
+	if err := registerInstance(self); err != nil {
+		t.Fatalf("Expected a stale session not to conflict: %v", err)
+	}
+
+	registry = instanceRegistry{}
+	readJSON(path, &registry)
+	if len(registry.Instances) != 1 || registry.Instances[0].Owner != "bob" {
+		t.Fatalf("Expected only this session, got %+v", registry.Instances)
+	}
+
+	unregisterInstance()
+	registry = instanceRegistry{}
+	readJSON(path, &registry)
+	if len(registry.Instances) != 0 {
+		t.Errorf("Expected the session to be removed, got %+v", registry.Instances)
+	}
+}
+
+func TestRegisterInstanceConflict(t *testing.T) {
+	t.Setenv(sharedDirEnv, t.TempDir())
+	sharedRegistry = true
+	defer func() { sharedRegistry = false }()
+
+	// The parent process stands in for another user's live session
+	alice := instance{PID: os.Getppid(), Owner: "alice", Project: "/srv/shop"}
+	if err := registerInstance(alice); err != nil {
+		t.Fatal(err)
+	}
+
+	err := registerInstance(instance{PID: os.Getpid(), Owner: "bob", Project: "/srv/shop"})
+	if err == nil || !strings.Contains(err.Error(), "alice is already watching /srv/shop") {
+		t.Errorf("Expected a conflict with alice's session, got %v", err)
+	}
+	if err := registerInstance(instance{PID: os.Getpid(), Owner: "bob", Project: "/home/bob/shop"}); err != nil {
+		t.Errorf("Expected another checkout not to conflict: %v", err)
+	}
+}
+
+func TestFormatInstances(t *testing.T) {
+	now := time.Now()
+	instances := []instance{
+		{PID: 20, Owner: "bob", Project: "/srv/b", Targets: []string{"api", "worker"}, Started: now.Add(-90 * time.Second)},
+		{PID: 10, Owner: "alice", Project: "/srv/a", Targets: []string{"main"}, Started: now.Add(-time.Hour)},
+	}
+	got := formatInstances(instances, map[int][]int{20: {9000, 8080}}, now, 120)
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", got)
+	}
+	if !strings.HasPrefix(lines[0], "10") || !strings.Contains(lines[0], "up 1h0m0s") || !strings.Contains(lines[0], "no ports") {
+		t.Errorf("Unexpected first line: %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "api,worker") || !strings.Contains(lines[1], ":8080 :9000") {
+		t.Errorf("Unexpected second line: %q", lines[1])
+	}
+	if got := formatInstances(nil, nil, now, 80); got != "No Wind sessions running\n" {
+		t.Errorf("Unexpected empty listing: %q", got)
+	}
+}
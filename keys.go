@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Default key bindings for the interactive controls
+const (
+	keyRebuild    = 'r'
+	keyPause      = 'p'
+	keyClear      = 'c'
+	keyQuit       = 'q'
+	keySwitch     = 's'
+	keyEditor     = 'e'
+	keyToggleLogs = 'l'
+	keyRawLogs    = 'j'
+	keyFilter     = '/'
+	keyLogLevel   = 'v'
+	keyRunTests   = 't'
+	keyHelp       = '?'
+)
+
+// keyAction is something a key can be bound to with the keys setting
+type keyAction struct {
+	name        string
+	description string
+	key         byte
+}
+
+// keyActions lists the bindable actions in the order of the help overlay
+var keyActions = []keyAction{
+	{"rebuild", "rebuild and restart", keyRebuild},
+	{"pause", "pause/resume watching", keyPause},
+	{"clear", "clear the screen", keyClear},
+	{"open", "open the first compile error", keyEditor},
+	{"switch", "switch the A/B proxy between builds", keySwitch},
+	{"toggle-logs", "hide/show application output", keyToggleLogs},
+	{"raw-logs", "show JSON log lines raw/formatted (prettyLogs)", keyRawLogs},
+	{"filter", "only show output matching a regex (empty clears)", keyFilter},
+	{"level", "raise the minimum log level shown, then show all", keyLogLevel},
+	{"run-tests", "test the package of the last changed file", keyRunTests},
+	{"help", "show this help", keyHelp},
+	{"quit", "quit", keyQuit},
+}
+
+// keyActionAliases are alternative names accepted in the keys setting
+var keyActionAliases = map[string]string{
+	"restart": "rebuild",
+	"editor":  "open",
+	"logs":    "toggle-logs",
+	"test":    "run-tests",
+}
+
+// bindKeys applies the keys setting (action: key) to the default bindings
+// and returns the action of every bound key. Action names are matched like
+// config keys, so toggle-logs, toggle_logs and toggleLogs are the same.
+func bindKeys(custom map[string]string) (map[byte]string, error) {
+	byName := map[string]string{}
+	for _, a := range keyActions {
+		byName[normalizeKey(a.name)] = a.name
+	}
+	for alias, name := range keyActionAliases {
+		byName[normalizeKey(alias)] = name
+	}
+
+	keys := map[string]byte{}
+	for _, a := range keyActions {
+		keys[a.name] = a.key
+	}
+	for action, key := range custom {
+		name, ok := byName[normalizeKey(action)]
+		if !ok {
+			return nil, fmt.Errorf("keys: unknown action %q", action)
+		}
+		if len(key) != 1 || key[0] <= ' ' || key[0] > '~' {
+			return nil, fmt.Errorf("keys.%s: %q is not a single printable character", action, key)
+		}
+		keys[name] = key[0]
+	}
+
+	bindings := map[byte]string{}
+	for _, a := range keyActions {
+		key := keys[a.name]
+		if other, ok := bindings[key]; ok {
+			return nil, fmt.Errorf("keys: %q is bound to both %s and %s", key, other, a.name)
+		}
+		bindings[key] = a.name
+	}
+	return bindings, nil
+}
+
+// keyFor returns the key bound to action
+func (o *orchestrator) keyFor(action string) byte {
+	for key, name := range o.keys {
+		if name == action {
+			return key
+		}
+	}
+	return 0
+}
+
+// isTerminal reports whether f is attached to a character device
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// terminalOut is Wind's standard output if it is a terminal, otherwise nil.
+// It is taken at startup, before --record-session and the other output
+// redirects replace os.Stdout with a pipe, so checks for a terminal keep
+// seeing the one Wind runs in.
+var terminalOut = stdoutTerminal()
+
+func stdoutTerminal() *os.File {
+	if isTerminal(os.Stdout) {
+		return os.Stdout
+	}
+	return nil
+}
+
+// enableRawInput switches the terminal to unbuffered input so single key
+// presses are delivered without Enter. The previous state is restored by
+// terminal.restore.
+func enableRawInput() error {
+	saved, err := stty("-g")
+	if err != nil {
+		return err
+	}
+
+	terminal.add(func() {
+		stty(strings.TrimSpace(saved))
+	})
+	if _, err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		terminal.restore()
+		return err
+	}
+	return nil
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// readKeys reads key presses from r and dispatches them until r is exhausted
+// or a quit was requested. Whitespace is ignored so line-buffered input such
+// as "r<Enter>" works the same as a raw key press.
+func (o *orchestrator) readKeys(r io.Reader) {
+	defer guardTerminal()
+	reader := bufio.NewReader(r)
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return
+		}
+
+		// The filter prompt reads the rest of the line itself
+		if o.keys[b] == "filter" {
+			o.promptLogPattern(reader)
+			continue
+		}
+		o.handleKey(b)
+		if o.keys[b] == "quit" {
+			return
+		}
+	}
+}
+
+// handleKey applies a key press to every supervised target
+func (o *orchestrator) handleKey(key byte) {
+	switch o.keys[key] {
+	case "rebuild":
+		logf(levelInfo, Cyan+"Info: "+Reset+"Manual rebuild requested\n")
+		for _, app := range o.apps {
+			app.requestRebuild()
+		}
+	case "pause":
+		paused := len(o.apps) > 0 && !o.apps[0].paused.Load()
+		for _, app := range o.apps {
+			app.paused.Store(paused)
+		}
+		if paused {
+			logf(levelInfo, Yellow+"Info: "+Reset+"Watching paused (press %c to resume)\n", key)
+		} else {
+			logf(levelInfo, Cyan+"Info: "+Reset+"Watching resumed\n")
+		}
+	case "clear":
+		fmt.Print(clearScreen)
+	case "quit":
+		o.requestQuit()
+	case "switch":
+		for _, app := range o.apps {
+			if app.ab != nil {
+				app.ab.toggle()
+			}
+		}
+	case "open":
+		for _, app := range o.apps {
+			if e, ok := app.firstCompileError(); ok {
+				app.openInEditor(e)
+				return
+			}
+		}
+		logf(levelInfo, Cyan+"Info: "+Reset+"No compile errors to open\n")
+	case "toggle-logs":
+		hidden := len(o.apps) > 0 && !o.apps[0].logsHidden.Load()
+		for _, app := range o.apps {
+			app.logsHidden.Store(hidden)
+		}
+		if hidden {
+			logf(levelInfo, Yellow+"Info: "+Reset+"Application output hidden (press %c to show)\n", key)
+		} else {
+			logf(levelInfo, Cyan+"Info: "+Reset+"Application output shown\n")
+		}
+	case "raw-logs":
+		raw := len(o.apps) > 0 && !o.apps[0].rawLogs.Load()
+		for _, app := range o.apps {
+			app.rawLogs.Store(raw)
+		}
+		if raw {
+			logf(levelInfo, Cyan+"Info: "+Reset+"Showing JSON log lines raw (press %c to format them)\n", key)
+		} else {
+			logf(levelInfo, Cyan+"Info: "+Reset+"Formatting JSON log lines\n")
+		}
+	case "level":
+		o.cycleLogLevel()
+	case "run-tests":
+		o.testLastChange()
+	case "help":
+		o.showKeyOverlay()
+	}
+}
+
+// requestRebuild asks the watch loop for an immediate rebuild. Requests made
+// while one is already pending are coalesced.
+func (app *WindApp) requestRebuild() {
+	select {
+	case app.rebuildChan <- struct{}{}:
+	default:
+	}
+}
+
+// showKeyHelp prints the one-line reminder of the main bindings
+func (o *orchestrator) showKeyHelp() {
+	line := fmt.Sprintf(Yellow+"Keys: "+Reset+"%c rebuild · %c pause/resume · %c open error · %c test · %c help · %c quit",
+		o.keyFor("rebuild"), o.keyFor("pause"), o.keyFor("open"), o.keyFor("run-tests"), o.keyFor("help"), o.keyFor("quit"))
+	logf(levelInfo, "%s\n", fitLine(line, " · ", "      ", terminalWidth()))
+}
+
+// showKeyOverlay lists every binding
+func (o *orchestrator) showKeyOverlay() {
+	fmt.Printf(Yellow + "Keyboard controls:" + Reset + "\n")
+	rows := newTable("  ")
+	for _, a := range keyActions {
+		rows.addRow(string(o.keyFor(a.name)), a.description)
+	}
+	fmt.Print(rows.render(terminalWidth()))
+}
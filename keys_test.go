@@ -0,0 +1,107 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHandleKey(t *testing.T) {
+	api := newWindApp(WindConfig{}, "api", Cyan)
+	worker := newWindApp(WindConfig{}, "worker", Purple)
+	orch := newOrchestrator([]*WindApp{api, worker})
+
+	// Pause toggles on and off for every target
+	orch.handleKey(keyPause)
+	if !api.paused.Load() || !worker.paused.Load() {
+		t.Error("Expected all targets to be paused after pressing p")
+	}
+	orch.handleKey(keyPause)
+	if api.paused.Load() || worker.paused.Load() {
+		t.Error("Expected all targets to resume after pressing p again")
+	}
+
+	// Repeated rebuild requests are coalesced into a single pending one
+	orch.handleKey(keyRebuild)
+	orch.handleKey(keyRebuild)
+	for _, app := range orch.apps {
+		if len(app.rebuildChan) != 1 {
+			t.Errorf("Expected 1 pending rebuild for %s, got %d", app.name, len(app.rebuildChan))
+		}
+	}
+
+	orch.handleKey(keyQuit)
+	select {
+	case <-orch.quitChan:
+	default:
+		t.Error("Expected quit request after pressing q")
+	}
+}
+
+func TestReadKeysLineInput(t *testing.T) {
+	app := newWindApp(WindConfig{}, "", "")
+	orch := newOrchestrator([]*WindApp{app})
+
+	// Line-buffered input ("r<Enter>") must behave like a raw key press
+	orch.readKeys(strings.NewReader("r\nq\n"))
+
+	if len(app.rebuildChan) != 1 {
+		t.Error("Expected a rebuild request from line input")
+	}
+	if len(orch.quitChan) != 1 {
+		t.Error("Expected a quit request from line input")
+	}
+}
+
+func TestBindKeys(t *testing.T) {
+	keys, err := bindKeys(map[string]string{"restart": "R", "toggle_logs": "o", "open": "l"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Aliases and any spelling of an action name are accepted, and actions
+	// may trade keys
+	for key, action := range map[byte]string{'R': "rebuild", 'o': "toggle-logs", 'l': "open", 'q': "quit", '?': "help"} {
+		if keys[key] != action {
+			t.Errorf("Expected %q to be bound to %s, got %q", key, action, keys[key])
+		}
+	}
+	if _, ok := keys['r']; ok {
+		t.Error("Expected the default rebuild key to be released")
+	}
+
+	for _, custom := range []map[string]string{
+		{"launch": "x"},
+		{"quit": "qq"},
+		{"quit": " "},
+		{"quit": "r"},
+	} {
+		if _, err := bindKeys(custom); err == nil {
+			t.Errorf("Expected an error for %v", custom)
+		}
+	}
+}
+
+func TestHandleKeyCustomBindings(t *testing.T) {
+	app := newWindApp(WindConfig{}, "", "")
+	orch := newOrchestrator([]*WindApp{app})
+	orch.keys, _ = bindKeys(map[string]string{"quit": "x", "toggle-logs": "h"})
+
+	orch.handleKey('h')
+	if !app.logsHidden.Load() {
+		t.Error("Expected application output to be hidden")
+	}
+	var buf strings.Builder
+	app.runOutput(&buf).Write([]byte("hidden\n"))
+	orch.handleKey('h')
+	app.runOutput(&buf).Write([]byte("shown\n"))
+	if buf.String() != "shown\n" {
+		t.Errorf("Expected only output written while shown, got %q", buf.String())
+	}
+
+	orch.readKeys(strings.NewReader("qxr"))
+	if len(orch.quitChan) != 1 {
+		t.Error("Expected a quit request from the rebound key")
+	}
+	if len(app.rebuildChan) != 0 {
+		t.Error("Expected reading to stop after the quit key")
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// defaultTermWidth is used when the width of the terminal is unknown, e.g.
+// when output is piped
+const defaultTermWidth = 80
+
+// minColumnWidth is the narrowest a table's last column is wrapped into;
+// below it the column moves to a line of its own
+const minColumnWidth = 20
+
+// termWidth caches the terminal width, kept current by watchTerminalSize
+var termWidth atomic.Int32
+
+// terminalWidth returns the width output is laid out for
+func terminalWidth() int {
+	if w := termWidth.Load(); w > 0 {
+		return int(w)
+	}
+	return measureTerminalWidth()
+}
+
+// measureTerminalWidth asks the terminal on stdout for its width, then
+// falls back to $COLUMNS
+func measureTerminalWidth() int {
+	if cols := terminalColumns(terminalOut); cols > 0 {
+		return cols
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return defaultTermWidth
+}
+
+// watchTerminalSize remeasures the terminal whenever it is resized, until
+// the returned function is called
+func watchTerminalSize() (stop func()) {
+	termWidth.Store(int32(measureTerminalWidth()))
+
+	c := make(chan os.Signal, 1)
+	notifyResize(c)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-c:
+				termWidth.Store(int32(measureTerminalWidth()))
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(c)
+		close(done)
+	}
+}
+
+// visibleWidth is the number of columns s takes, ignoring color codes
+func visibleWidth(s string) int {
+	return utf8.RuneCountInString(ansiPattern.ReplaceAllString(s, ""))
+}
+
+// wrapWords breaks s into lines of at most width columns at spaces. Words
+// longer than width get a line of their own.
+func wrapWords(s string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		switch {
+		case line == "":
+			line = word
+		case visibleWidth(line)+1+visibleWidth(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fitLine lays out a line made of sep-separated parts, such as a summary
+// joined with " · ", in width columns: when it is too long it is broken
+// between parts, continuation lines indented by indent
+func fitLine(line, sep, indent string, width int) string {
+	if visibleWidth(line) <= width {
+		return line
+	}
+	parts := strings.Split(line, sep)
+	out := parts[0]
+	current := visibleWidth(out)
+	for _, part := range parts[1:] {
+		if current+visibleWidth(sep)+visibleWidth(part) > width {
+			out += strings.TrimRight(sep, " ") + "\n" + indent + part
+			current = visibleWidth(indent) + visibleWidth(part)
+			continue
+		}
+		out += sep + part
+		current += visibleWidth(sep) + visibleWidth(part)
+	}
+	return out
+}
+
+// table aligns rows of cells in columns. The last column gets the width
+// that is left and is word-wrapped into it.
+type table struct {
+	indent string
+	rows   [][]string
+}
+
+func newTable(indent string) *table {
+	return &table{indent: indent}
+}
+
+func (t *table) addRow(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// render lays the table out for width columns
+func (t *table) render(width int) string {
+	var widths []int
+	for _, row := range t.rows {
+		for i, cell := range row[:len(row)-1] {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], visibleWidth(cell))
+		}
+	}
+
+	var out strings.Builder
+	for _, row := range t.rows {
+		line := t.indent
+		for i, cell := range row[:len(row)-1] {
+			line += cell + strings.Repeat(" ", widths[i]-visibleWidth(cell)) + "  "
+		}
+		used := visibleWidth(line)
+
+		last := row[len(row)-1]
+		if used+visibleWidth(last) <= width {
+			out.WriteString(strings.TrimRight(line+last, " ") + "\n")
+			continue
+		}
+		pad := strings.Repeat(" ", used)
+		// Too narrow to wrap beside the other columns: give the last
+		// column lines of its own
+		ownLines := width-used < minColumnWidth
+		if ownLines {
+			out.WriteString(strings.TrimRight(line, " ") + "\n")
+			pad = t.indent + "    "
+		}
+		for i, wrapped := range wrapWords(last, max(width-visibleWidth(pad), minColumnWidth)) {
+			if i == 0 && !ownLines {
+				out.WriteString(line)
+			} else {
+				out.WriteString(pad)
+			}
+			out.WriteString(wrapped + "\n")
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// terminalColumns returns 0: the width is only known from $COLUMNS here
+func terminalColumns(f *os.File) int {
+	return 0
+}
+
+// notifyResize does nothing, as no signal reports a resized terminal here
+func notifyResize(c chan<- os.Signal) {}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVisibleWidth(t *testing.T) {
+	if got := visibleWidth(Green + "running" + Reset + " · ✓"); got != 11 {
+		t.Errorf("visibleWidth() = %d, want 11", got)
+	}
+}
+
+func TestWrapWords(t *testing.T) {
+	got := wrapWords("the quick brown fox jumps", 10)
+	want := []string{"the quick", "brown fox", "jumps"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("wrapWords() = %q, want %q", got, want)
+	}
+	if got := wrapWords("supercalifragilistic", 5); len(got) != 1 {
+		t.Errorf("long word split: %q", got)
+	}
+}
+
+func TestFitLine(t *testing.T) {
+	line := "Load: /health · 50 requests · p50 1ms · p95 3ms"
+	if got := fitLine(line, " · ", "  ", 80); got != line {
+		t.Errorf("fitLine() changed a line that fits: %q", got)
+	}
+
+	got := fitLine(line, " · ", "  ", 30)
+	want := "Load: /health · 50 requests ·\n  p50 1ms · p95 3ms"
+	if got != want {
+		t.Errorf("fitLine() = %q, want %q", got, want)
+	}
+	for _, l := range strings.Split(got, "\n") {
+		if visibleWidth(l) > 30 {
+			t.Errorf("line %q exceeds 30 columns", l)
+		}
+	}
+}
+
+func TestTableRender(t *testing.T) {
+	rows := newTable("  ")
+	rows.addRow("*", "api", "./cmd/api", "API server")
+	rows.addRow(" ", "worker", "./cmd/worker", "Background job runner for queues")
+
+	wide := rows.render(80)
+	want := "  *  api     ./cmd/api     API server\n" +
+		"     worker  ./cmd/worker  Background job runner for queues\n"
+	if wide != want {
+		t.Errorf("render(80) =\n%s\nwant\n%s", wide, want)
+	}
+
+	// The last column wraps beside the others
+	narrow := rows.render(48)
+	for _, l := range strings.Split(strings.TrimSuffix(narrow, "\n"), "\n") {
+		if visibleWidth(l) > 48 {
+			t.Errorf("line %q exceeds 48 columns", l)
+		}
+	}
+	if !strings.Contains(narrow, "Background job runner\n"+strings.Repeat(" ", 27)+"for queues\n") {
+		t.Errorf("render(48) did not wrap the description:\n%s", narrow)
+	}
+
+	// Below minColumnWidth it moves to lines of its own
+	tiny := rows.render(30)
+	if !strings.Contains(tiny, "     worker  ./cmd/worker\n      Background job runner\n") {
+		t.Errorf("render(30) =\n%s", tiny)
+	}
+}
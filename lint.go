@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Lint tools of the lint stage
+const (
+	lintVet          = "vet"
+	lintGolangciLint = "golangci-lint"
+)
+
+// LintConfig adds a lint stage after every successful rebuild, limited to
+// the packages that changed
+type LintConfig struct {
+	// Tool is vet or golangci-lint; empty turns the lint stage off
+	Tool string
+	// Rev is passed to golangci-lint --new-from-rev, HEAD by default, so
+	// only issues in uncommitted changes are reported
+	Rev string
+	// Block keeps a build with lint issues from starting; by default the
+	// issues are shown while the new build starts
+	Block bool
+}
+
+// validateLint checks the lint settings
+func validateLint(config LintConfig) error {
+	switch config.Tool {
+	case "", lintVet, lintGolangciLint:
+	default:
+		return fmt.Errorf("lint.tool must be %s or %s, got %q", lintVet, lintGolangciLint, config.Tool)
+	}
+	if config.Rev != "" && config.Tool != lintGolangciLint {
+		return fmt.Errorf("lint.rev only applies to %s", lintGolangciLint)
+	}
+	return nil
+}
+
+// lintCommand returns the command linting pkgs
+func lintCommand(config LintConfig, tags []string, pkgs []string) []string {
+	if config.Tool == lintGolangciLint {
+		rev := config.Rev
+		if rev == "" {
+			rev = "HEAD"
+		}
+		args := []string{lintGolangciLint, "run", "--new-from-rev=" + rev}
+		if len(tags) > 0 {
+			args = append(args, "--build-tags="+strings.Join(tags, ","))
+		}
+		return append(args, pkgs...)
+	}
+
+	args := []string{"go", "vet"}
+	if len(tags) > 0 {
+		args = append(args, "-tags="+strings.Join(tags, ","))
+	}
+	return append(args, pkgs...)
+}
+
+// changedPackages returns the package directories (./dir) of the Go files
+// changed in this cycle
+func changedPackages(paths []string) []string {
+	seen := map[string]bool{}
+	var pkgs []string
+	for _, path := range paths {
+		if filepath.Ext(path) != ".go" {
+			continue
+		}
+		if dir := packageDirOf(path); !seen[dir] {
+			seen[dir] = true
+			pkgs = append(pkgs, dir)
+		}
+	}
+	sort.Strings(pkgs)
+	return pkgs
+}
+
+// lint runs the lint tool on pkgs and prints its issues in yellow. It
+// reports whether the tool found no issues.
+func (app *WindApp) lint(pkgs []string) bool {
+	args := lintCommand(app.config.Lint, app.config.BuildTags, pkgs)
+	started := time.Now()
+	var output bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = app.buildEnv()
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := cmd.Run()
+	if err == nil {
+		if app.config.Verbose {
+			logf(levelInfo, Cyan+"Info: "+Reset+"%s%s: no issues in %s (%s)\n", app.label(), app.config.Lint.Tool, strings.Join(pkgs, " "), time.Since(started).Round(time.Millisecond))
+		}
+		return true
+	}
+
+	issues, other := parseBuildErrors(output.String())
+	if len(issues) == 0 {
+		// The tool itself failed, e.g. it is not installed; that is no
+		// reason to hold the build back
+		logf(levelWarn, Yellow+"Warning: "+Reset+"%s%s failed: %v\n", app.label(), app.config.Lint.Tool, err)
+		printBuildErrors(app.output(os.Stderr), nil, other)
+		return true
+	}
+	logf(levelInfo, Yellow+"Lint: "+Reset+"%s%s in %s\n", app.label(), pluralize(len(issues), "issue"), strings.Join(pkgs, " "))
+	printBuildErrors(app.output(os.Stderr), issues, nil)
+	return false
+}
+
+// lintChanges runs the lint stage for the packages changed in this cycle. It
+// reports whether the new build may start: always, unless Block is set and
+// there are issues.
+func (app *WindApp) lintChanges() bool {
+	if app.config.Lint.Tool == "" {
+		return true
+	}
+	pkgs := changedPackages(app.changedFiles)
+	if len(pkgs) == 0 {
+		return true
+	}
+	if !app.config.Lint.Block {
+		go app.lint(pkgs)
+		return true
+	}
+	if app.lint(pkgs) {
+		return true
+	}
+	logf(levelError, Red+"Error: "+Reset+"%sNot starting build #%d with lint issues (lint.block)\n", app.label(), app.buildID)
+	return false
+}
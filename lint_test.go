@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestLintCommand(t *testing.T) {
+	tests := []struct {
+		config   LintConfig
+		tags     []string
+		expected []string
+	}{
+		{LintConfig{Tool: "vet"}, nil, []string{"go", "vet", "./api", "."}},
+		{LintConfig{Tool: "vet"}, []string{"integration"}, []string{"go", "vet", "-tags=integration", "./api", "."}},
+		{LintConfig{Tool: "golangci-lint"}, nil, []string{"golangci-lint", "run", "--new-from-rev=HEAD", "./api", "."}},
+		{LintConfig{Tool: "golangci-lint", Rev: "origin/main"}, []string{"a", "b"}, []string{"golangci-lint", "run", "--new-from-rev=origin/main", "--build-tags=a,b", "./api", "."}},
+	}
+	for _, tt := range tests {
+		if got := lintCommand(tt.config, tt.tags, []string{"./api", "."}); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("lintCommand(%+v) = %v, expected %v", tt.config, got, tt.expected)
+		}
+	}
+}
+
+func TestValidateLint(t *testing.T) {
+	for _, config := range []LintConfig{{}, {Tool: "vet", Block: true}, {Tool: "golangci-lint", Rev: "main"}} {
+		if err := validateLint(config); err != nil {
+			t.Errorf("Expected %+v to be valid, got %v", config, err)
+		}
+	}
+	for _, config := range []LintConfig{{Tool: "staticcheck"}, {Tool: "vet", Rev: "main"}} {
+		if err := validateLint(config); err == nil {
+			t.Errorf("Expected %+v to be invalid", config)
+		}
+	}
+}
+
+func TestChangedPackages(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	os.MkdirAll("internal/store", 0755)
+	os.WriteFile("main.go", []byte("package main\n"), 0644)
+	os.WriteFile("internal/store/store.go", []byte("package store\n"), 0644)
+
+	got := changedPackages([]string{"internal/store/store.go", "internal/store/store_test.go", "main.go", "templates/index.html"})
+	if expected := []string{".", "./internal/store"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("changedPackages() = %v, expected %v", got, expected)
+	}
+}
+
+func TestLintChanges(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	os.WriteFile("go.mod", []byte("module example.com/lint\n\ngo 1.21\n"), 0644)
+	os.WriteFile("main.go", []byte("package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Printf(\"%d\\n\", \"text\")\n}\n"), 0644)
+
+	app := newWindApp(WindConfig{Lint: LintConfig{Tool: "vet", Block: true}}, "", "")
+	app.changedFiles = []string{"main.go"}
+	if app.lintChanges() {
+		t.Error("Expected a blocking lint stage to hold back a build with issues")
+	}
+
+	app.changedFiles = []string{"templates/index.html"}
+	if !app.lintChanges() {
+		t.Error("Expected no lint stage without Go changes")
+	}
+
+	os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644)
+	app.changedFiles = []string{"main.go"}
+	if !app.lintChanges() {
+		t.Error("Expected clean packages to pass")
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// liveReloadPath is the Server-Sent Events endpoint the injected script
+// listens on
+const liveReloadPath = "/__wind/livereload"
+
+// liveReloadScript reloads the page when Wind announces a restart. The
+// EventSource reconnects on its own if the proxy goes away.
+// The event source is shared with the dev banner as window.__wind.
+const liveReloadScript = `<script>(function(){var s=window.__wind=new EventSource("` + liveReloadPath + `");s.addEventListener("reload",function(){location.reload()})})();</script>`
+
+// LiveReloadConfig configures the dev proxy that refreshes the browser after
+// every restart
+type LiveReloadConfig struct {
+	// AppURL is the address the application listens on; an empty AppURL
+	// disables live reload
+	AppURL string
+	// Port is the public port of the proxy to open in the browser
+	Port int
+	// Timeout bounds how long to wait for the restarted app to accept
+	// connections before reloading anyway
+	Timeout time.Duration
+	// Banner adds a floating dev banner with the build number, page age and
+	// rebuild state to HTML pages
+	Banner bool
+}
+
+// liveReload proxies the application, injects liveReloadScript into HTML
+// responses and pushes reload events to connected browsers
+type liveReload struct {
+	config  LiveReloadConfig
+	target  *url.URL
+	mutex   sync.Mutex
+	clients map[chan string]bool
+	server  *http.Server
+	// build is the number of the build being served, for the dev banner
+	build atomic.Int64
+}
+
+func newLiveReload(config LiveReloadConfig) (*liveReload, error) {
+	target, err := url.Parse(config.AppURL)
+	if err != nil || target.Host == "" {
+		return nil, fmt.Errorf("invalid liveReload.appUrl %q", config.AppURL)
+	}
+	return &liveReload{
+		config:  config,
+		target:  target,
+		clients: make(map[chan string]bool),
+	}, nil
+}
+
+// start launches the proxy on the public port
+func (lr *liveReload) start() {
+	lr.server = &http.Server{
+		Addr:    fmt.Sprintf(":%d", lr.config.Port),
+		Handler: lr.handler(),
+	}
+
+	go func() {
+		if err := lr.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logf(levelError, Red+"Error: "+Reset+"Live reload proxy failed: %v\n", err)
+		}
+	}()
+
+	logf(levelInfo, Cyan+"Info: "+Reset+"Live reload on http://localhost:%d (→ %s)\n", lr.config.Port, lr.config.AppURL)
+}
+
+func (lr *liveReload) handler() http.Handler {
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(lr.target)
+			r.Out.Host = r.In.Host
+			r.SetXForwarded()
+			// Uncompressed responses can be rewritten
+			r.Out.Header.Del("Accept-Encoding")
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			return injectHTML(resp, lr.snippet())
+		},
+		// While the app restarts, serve a page that reloads once it is back
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprintf(w, "<html><body><p>Wind: waiting for the application (%v)</p>%s</body></html>",
+				err, liveReloadScript)
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(liveReloadPath, lr.serveEvents)
+	mux.Handle("/", proxy)
+	return mux
+}
+
+// snippet is what injectHTML adds to pages: the live reload script and,
+// with Banner, the dev banner
+func (lr *liveReload) snippet() string {
+	if !lr.config.Banner {
+		return liveReloadScript
+	}
+	return liveReloadScript + devBanner(int(lr.build.Load()))
+}
+
+// injectHTML adds snippet to HTML responses, before </body> when present
+func injectHTML(resp *http.Response, snippet string) error {
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") ||
+		resp.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	body = injectSnippet(body, snippet)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return nil
+}
+
+// injectSnippet adds snippet to an HTML page, before </body> when present
+func injectSnippet(body []byte, snippet string) []byte {
+	if i := bytes.LastIndex(bytes.ToLower(body), []byte("</body>")); i >= 0 {
+		return append(body[:i:i], append([]byte(snippet), body[i:]...)...)
+	}
+	return append(body, snippet...)
+}
+
+// serveEvents streams reload and build state events to one browser tab
+func (lr *liveReload) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	events := make(chan string, 4)
+	lr.mutex.Lock()
+	lr.clients[events] = true
+	lr.mutex.Unlock()
+	defer func() {
+		lr.mutex.Lock()
+		delete(lr.clients, events)
+		lr.mutex.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case name := <-events:
+			fmt.Fprintf(w, "event: %s\ndata: {}\n\n", name)
+			flusher.Flush()
+		}
+	}
+}
+
+// broadcast sends a reload event to every connected browser
+func (lr *liveReload) broadcast() int {
+	return lr.send("reload")
+}
+
+// send passes the named event to every connected browser: reload, or
+// building and failed for the dev banner
+func (lr *liveReload) send(name string) int {
+	lr.mutex.Lock()
+	defer lr.mutex.Unlock()
+
+	for events := range lr.clients {
+		select {
+		case events <- name:
+		default:
+		}
+	}
+	return len(lr.clients)
+}
+
+// reload waits for the restarted application to accept connections, then
+// tells the browsers to refresh
+func (lr *liveReload) reload() {
+	addr := lr.target.Host
+	if lr.target.Port() == "" {
+		addr = net.JoinHostPort(lr.target.Hostname(), "80")
+	}
+
+	pollReady(probeTCP(addr), lr.config.Timeout)
+
+	if n := lr.broadcast(); n > 0 {
+		logf(levelInfo, Cyan+"Info: "+Reset+"Reloaded %d browser tab(s)\n", n)
+	}
+}
+
+func (lr *liveReload) stop() {
+	if lr.server != nil {
+		lr.server.Close()
+	}
+}
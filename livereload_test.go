@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLiveReloadInjectsScript(t *testing.T) {
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api" {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"ok":true}`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, "<html><body><h1>Hi</h1></body></html>")
+	}))
+	defer app.Close()
+
+	lr, err := newLiveReload(LiveReloadConfig{AppURL: app.URL})
+	if err != nil {
+		t.Fatalf("newLiveReload failed: %v", err)
+	}
+	proxy := httptest.NewServer(lr.handler())
+	defer proxy.Close()
+
+	get := func(path string) string {
+		resp, err := http.Get(proxy.URL + path)
+		if err != nil {
+			t.Fatalf("Request through proxy failed: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	expected := "<h1>Hi</h1>" + liveReloadScript + "</body>"
+	if body := get("/"); !strings.Contains(body, expected) {
+		t.Errorf("Expected script before </body>, got %q", body)
+	}
+	if body := get("/api"); body != `{"ok":true}` {
+		t.Errorf("Non-HTML responses should pass through unchanged, got %q", body)
+	}
+}
+
+func TestLiveReloadBroadcast(t *testing.T) {
+	lr, err := newLiveReload(LiveReloadConfig{AppURL: "http://127.0.0.1:1"})
+	if err != nil {
+		t.Fatalf("newLiveReload failed: %v", err)
+	}
+	proxy := httptest.NewServer(lr.handler())
+	defer proxy.Close()
+
+	resp, err := http.Get(proxy.URL + liveReloadPath)
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	reader.ReadString('\n') // ": connected"
+
+	// Wait for the subscription to register before broadcasting
+	for i := 0; i < 50 && lr.broadcast() == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Stream ended before a reload event: %v", err)
+		}
+		if strings.TrimSpace(line) == "event: reload" {
+			return
+		}
+	}
+}
+
+func TestNewLiveReloadRejectsInvalidURL(t *testing.T) {
+	if _, err := newLiveReload(LiveReloadConfig{AppURL: "localhost:8080"}); err == nil {
+		t.Error("Expected an error for an app URL without a scheme")
+	}
+}
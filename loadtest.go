@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LoadTestConfig configures the optional load burst fired after each restart
+type LoadTestConfig struct {
+	// URL is the endpoint to hit; an empty URL disables the hook
+	URL         string
+	Requests    int
+	Concurrency int
+	// Delay gives the application time to start listening
+	Delay   time.Duration
+	Timeout time.Duration
+}
+
+type loadTestResult struct {
+	requests int
+	failures int
+	p50      time.Duration
+	p95      time.Duration
+	max      time.Duration
+}
+
+// runLoadTest sends config.Requests GET requests to config.URL using
+// config.Concurrency workers and summarizes the latencies of the successful
+// ones
+func runLoadTest(config LoadTestConfig) loadTestResult {
+	client := &http.Client{Timeout: config.Timeout}
+	jobs := make(chan struct{})
+
+	var mutex sync.Mutex
+	var latencies []time.Duration
+	failures := 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < max(config.Concurrency, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				start := time.Now()
+				resp, err := client.Get(config.URL)
+				elapsed := time.Since(start)
+
+				ok := err == nil && resp.StatusCode < 500
+				if err == nil {
+					io.Copy(io.Discard, resp.Body)
+					resp.Body.Close()
+				}
+
+				mutex.Lock()
+				if ok {
+					latencies = append(latencies, elapsed)
+				} else {
+					failures++
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	for i := 0; i < config.Requests; i++ {
+		jobs <- struct{}{}
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	result := loadTestResult{requests: config.Requests, failures: failures}
+	if len(latencies) > 0 {
+		result.p50 = percentile(latencies, 50)
+		result.p95 = percentile(latencies, 95)
+		result.max = latencies[len(latencies)-1]
+	}
+	return result
+}
+
+// percentile returns the p-th percentile of an ascending slice using the
+// nearest-rank method
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	rank = min(max(rank, 0), len(sorted)-1)
+	return sorted[rank]
+}
+
+// runLoadTestHook fires the configured load burst once the restarted
+// application had time to come up, and prints the latency summary
+func (app *WindApp) runLoadTestHook() {
+	config := app.config.LoadTest
+	time.Sleep(config.Delay)
+
+	result := runLoadTest(config)
+	color := Green
+	if result.failures > 0 {
+		color = Yellow
+	}
+	line := fmt.Sprintf(color+"Load: "+Reset+"%s · %d requests · p50 %v · p95 %v · max %v · %d failed",
+		config.URL, result.requests,
+		result.p50.Round(time.Microsecond), result.p95.Round(time.Microsecond),
+		result.max.Round(time.Microsecond), result.failures)
+	fmt.Println(fitLine(line, " · ", "      ", terminalWidth()))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Millisecond)
+	}
+
+	if p := percentile(sorted, 50); p != 50*time.Millisecond {
+		t.Errorf("Expected p50 of 50ms, got %v", p)
+	}
+	if p := percentile(sorted, 95); p != 95*time.Millisecond {
+		t.Errorf("Expected p95 of 95ms, got %v", p)
+	}
+	if p := percentile(nil, 95); p != 0 {
+		t.Errorf("Expected 0 for empty input, got %v", p)
+	}
+}
+
+func TestRunLoadTest(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1)%10 == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	result := runLoadTest(LoadTestConfig{
+		URL:         srv.URL,
+		Requests:    20,
+		Concurrency: 4,
+		Timeout:     time.Second,
+	})
+
+	if hits.Load() != 20 {
+		t.Errorf("Expected 20 requests, server saw %d", hits.Load())
+	}
+	if result.failures != 2 {
+		t.Errorf("Expected 2 failures (5xx), got %d", result.failures)
+	}
+	if result.p95 < result.p50 || result.max < result.p95 {
+		t.Errorf("Expected p50 <= p95 <= max, got %v %v %v", result.p50, result.p95, result.max)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+// logFileFlag writes the session's output to a file as well (LogFile.Path)
+const logFileFlag = "--log-file"
+
+// escapePattern matches the terminal escape sequences left out of the log
+// file: colors, cursor movement and line clearing
+var escapePattern = regexp.MustCompile("\x1b\\[[0-9;?]*[A-Za-z]")
+
+// LogFileConfig copies everything Wind and its child processes print to a
+// file, so build errors that scrolled off the terminal can be looked up
+type LogFileConfig struct {
+	// Path turns the log on; --log-file overrides it
+	Path string
+	// MaxSize rotates the log once it would grow beyond it (10MB by
+	// default)
+	MaxSize string
+	// Keep is how many rotated logs are kept next to it as Path.1 (newest)
+	// to Path.<keep> (3 by default)
+	Keep int
+	// Colors keeps the escape sequences, which are stripped by default
+	Colors bool
+}
+
+// validateLogFile checks the log file settings
+func validateLogFile(config LogFileConfig) error {
+	if size, err := parseSize(config.MaxSize); err != nil || size == 0 {
+		return fmt.Errorf("invalid logFile.maxSize %q (expected e.g. 10MB)", config.MaxSize)
+	}
+	if config.Keep < 0 {
+		return fmt.Errorf("logFile.keep must not be negative")
+	}
+	return nil
+}
+
+// rotatingFile appends to a file and rotates it once it reaches maxSize
+type rotatingFile struct {
+	mutex   sync.Mutex
+	path    string
+	maxSize int64
+	keep    int
+	file    *os.File
+	size    int64
+}
+
+func openRotatingFile(path string, maxSize int64, keep int) (*rotatingFile, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+	r := &rotatingFile{path: path, maxSize: maxSize, keep: keep}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.file, r.size = file, info.Size()
+	return nil
+}
+
+// rotate shifts the rotated logs up by one, dropping the oldest, and starts
+// a new file. Without any kept logs the file is truncated.
+func (r *rotatingFile) rotate() error {
+	r.file.Close()
+	if r.keep == 0 {
+		os.Remove(r.path)
+	} else {
+		os.Remove(fmt.Sprintf("%s.%d", r.path, r.keep))
+		for i := r.keep - 1; i >= 1; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+		}
+		os.Rename(r.path, r.path+".1")
+	}
+	return r.open()
+}
+
+func (r *rotatingFile) Write(data []byte) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	if r.size > 0 && r.size+int64(len(data)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			r.file = nil
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(data)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// logStream copies one output stream to the terminal and the log file
+type logStream struct {
+	dst   io.Writer
+	log   io.Writer
+	strip bool
+}
+
+func (s *logStream) Write(data []byte) (int, error) {
+	logged := data
+	if s.strip {
+		logged = escapePattern.ReplaceAll(data, nil)
+	}
+	// A failing log must not break the terminal output
+	s.log.Write(logged)
+	return s.dst.Write(data)
+}
+
+// startLogFile tees os.Stdout and os.Stderr, and with them the output of
+// child processes started afterwards, into the log file of config. The
+// returned function stops it.
+func startLogFile(config LogFileConfig) (func(), error) {
+	// The size was validated with the config
+	maxSize, _ := parseSize(config.MaxSize)
+	file, err := openRotatingFile(config.Path, int64(maxSize), config.Keep)
+	if err != nil {
+		return nil, err
+	}
+	redirect, err := redirectOutput(func(dst *os.File) io.Writer {
+		return &logStream{dst: dst, log: file, strip: !config.Colors}
+	})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return func() {
+		redirect.restore()
+		file.Close()
+	}, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "wind.log")
+	file, err := openRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatalf("openRotatingFile failed: %v", err)
+	}
+	defer file.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := file.Write([]byte(line)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	expected := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	}
+	for p, content := range expected {
+		if data, _ := os.ReadFile(p); string(data) != content {
+			t.Errorf("Expected %s to hold %q, got %q", filepath.Base(p), content, data)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("Expected only 2 rotated logs to be kept")
+	}
+}
+
+func TestRotatingFileAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wind.log")
+	os.WriteFile(path, []byte("earlier session\n"), 0644)
+
+	file, err := openRotatingFile(path, 1024, 0)
+	if err != nil {
+		t.Fatalf("openRotatingFile failed: %v", err)
+	}
+	file.Write([]byte("this session\n"))
+	file.Close()
+	if data, _ := os.ReadFile(path); string(data) != "earlier session\nthis session\n" {
+		t.Errorf("Expected the log to be appended to, got %q", data)
+	}
+}
+
+func TestLogStreamStripsEscapes(t *testing.T) {
+	var terminal, log bytes.Buffer
+	s := &logStream{dst: &terminal, log: &log, strip: true}
+	s.Write([]byte("\033[31mError: \033[0mbuild failed\n\r\033[K\033[2Adone\n"))
+
+	if log.String() != "Error: build failed\n\rdone\n" {
+		t.Errorf("Expected escape sequences to be stripped, got %q", log.String())
+	}
+	if terminal.String() == log.String() {
+		t.Error("Expected the terminal to get the colors")
+	}
+}
+
+func TestValidateLogFile(t *testing.T) {
+	if err := validateLogFile(defaultConfig().LogFile); err != nil {
+		t.Errorf("Expected the defaults to be valid, got %v", err)
+	}
+	if err := validateLogFile(LogFileConfig{MaxSize: "lots"}); err == nil {
+		t.Error("Expected an error for an invalid size")
+	}
+	if err := validateLogFile(LogFileConfig{MaxSize: "1MB", Keep: -1}); err == nil {
+		t.Error("Expected an error for a negative keep")
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Options filtering the application's output; they override LogFilter
+const (
+	grepFlag  = "--grep"
+	levelFlag = "--level"
+)
+
+// LogFilterConfig hides lines of the application's output. The / and v keys
+// change the filter at runtime.
+type LogFilterConfig struct {
+	// Grep is a regular expression; matching lines are always shown
+	Grep string
+	// Level is the minimum level shown: debug, info, warn or error
+	Level string
+}
+
+// validateLogFilter checks the filter settings
+func validateLogFilter(config LogFilterConfig) error {
+	if _, err := regexp.Compile(config.Grep); err != nil {
+		return fmt.Errorf("invalid logFilter.grep: %v", err)
+	}
+	if _, ok := logLevelRank(config.Level); config.Level != "" && !ok {
+		return fmt.Errorf("invalid logFilter.level %q (expected %s)", config.Level, strings.Join(logLevels, ", "))
+	}
+	return nil
+}
+
+// logLevels are the levels --level accepts, lowest first
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// logLevelAliases map other level names to logLevels
+var logLevelAliases = map[string]string{
+	"trace":    "debug",
+	"warning":  "warn",
+	"err":      "error",
+	"fatal":    "error",
+	"panic":    "error",
+	"dpanic":   "error",
+	"critical": "error",
+}
+
+// textLevelPattern finds the level of a plain text log line: level=warn,
+// an upper-case level word such as WARN or [warn]
+var textLevelPattern = regexp.MustCompile(`(?i:\b(?:level|lvl)=["']?([a-z]+))|\b(TRACE|DEBUG|INFO|WARN|WARNING|ERROR|FATAL|PANIC)\b|\[([a-zA-Z]+)\]`)
+
+// logLevelRank returns the position of a level name in logLevels
+func logLevelRank(name string) (int, bool) {
+	name = strings.ToLower(name)
+	if alias, ok := logLevelAliases[name]; ok {
+		name = alias
+	}
+	for i, level := range logLevels {
+		if level == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// parseLogLevel checks a --level value; empty means no level filter (-1)
+func parseLogLevel(name string) (int, error) {
+	if name == "" {
+		return -1, nil
+	}
+	rank, ok := logLevelRank(name)
+	if !ok {
+		return 0, fmt.Errorf("invalid %s %q (expected %s)", levelFlag, name, strings.Join(logLevels, ", "))
+	}
+	return rank, nil
+}
+
+// lineLevel returns the level of a log line: the level field of a JSON
+// line or a level found in a text line
+func lineLevel(line []byte) (int, bool) {
+	trimmed := bytes.TrimSpace(line)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		fields := map[string]any{}
+		if json.Unmarshal(trimmed, &fields) == nil {
+			level, ok := takeField(fields, logLevelKeys)
+			if !ok {
+				return 0, false
+			}
+			return logLevelRank(fmt.Sprint(level))
+		}
+	}
+	for _, m := range textLevelPattern.FindAllSubmatch(trimmed, -1) {
+		for _, name := range m[1:] {
+			if rank, ok := logLevelRank(string(name)); ok && len(name) > 0 {
+				return rank, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// logFilter decides which lines of the application's output are shown. A
+// line is shown when it matches pattern or, with a level set, has at least
+// that level. Lines without a level, such as panics and plain prints, only
+// fail the level filter when a pattern is set too.
+type logFilter struct {
+	mutex   sync.Mutex
+	pattern *regexp.Regexp
+	// level is the minimum rank in logLevels, -1 for none
+	level int
+}
+
+// newLogFilter returns the filter of config, which was validated
+func newLogFilter(config LogFilterConfig) *logFilter {
+	f := &logFilter{}
+	f.level, _ = parseLogLevel(config.Level)
+	if config.Grep != "" {
+		f.pattern, _ = regexp.Compile(config.Grep)
+	}
+	return f
+}
+
+// set replaces the pattern and the level
+func (f *logFilter) set(pattern *regexp.Regexp, level int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.pattern, f.level = pattern, level
+}
+
+// settings returns the pattern and the level
+func (f *logFilter) settings() (*regexp.Regexp, int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.pattern, f.level
+}
+
+// active reports whether any line can be hidden
+func (f *logFilter) active() bool {
+	pattern, level := f.settings()
+	return pattern != nil || level >= 0
+}
+
+// allows reports whether line is shown
+func (f *logFilter) allows(line []byte) bool {
+	pattern, level := f.settings()
+	if pattern != nil && pattern.Match(line) {
+		return true
+	}
+	if level < 0 {
+		return pattern == nil
+	}
+	rank, ok := lineLevel(line)
+	if !ok {
+		return pattern == nil
+	}
+	return rank >= level
+}
+
+// describe summarizes the filter for messages
+func (f *logFilter) describe() string {
+	pattern, level := f.settings()
+	var parts []string
+	if level >= 0 {
+		parts = append(parts, logLevels[level]+" and above")
+	}
+	if pattern != nil {
+		parts = append(parts, fmt.Sprintf("lines matching %q", pattern.String()))
+	}
+	if len(parts) == 0 {
+		return "all lines"
+	}
+	return strings.Join(parts, " or ")
+}
+
+// logFilterWriter drops the lines its filter doesn't allow. While the
+// filter is inactive output passes through as it comes.
+type logFilterWriter struct {
+	mutex  sync.Mutex
+	w      io.Writer
+	filter *logFilter
+	// pending is the start of a line waiting for its newline
+	pending []byte
+}
+
+func (l *logFilterWriter) Write(data []byte) (int, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if !l.filter.active() && len(l.pending) == 0 {
+		return l.w.Write(data)
+	}
+
+	var out bytes.Buffer
+	rest := append(l.pending, data...)
+	l.pending = nil
+	for len(rest) > 0 {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			l.pending = rest
+			break
+		}
+		if line := rest[:i+1]; l.filter.allows(line) {
+			out.Write(line)
+		}
+		rest = rest[i+1:]
+	}
+	if out.Len() > 0 {
+		if _, err := l.w.Write(out.Bytes()); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+// setLogFilter applies a filter to the output of every target
+func (o *orchestrator) setLogFilter(pattern *regexp.Regexp, level int) {
+	for _, app := range o.apps {
+		app.logFilter.set(pattern, level)
+	}
+	if len(o.apps) > 0 {
+		logf(levelInfo, Cyan+"Info: "+Reset+"Showing %s of the application output\n", o.apps[0].logFilter.describe())
+	}
+}
+
+// cycleLogLevel raises the minimum level shown by one, from none to error
+// and back to none
+func (o *orchestrator) cycleLogLevel() {
+	if len(o.apps) == 0 {
+		return
+	}
+	pattern, level := o.apps[0].logFilter.settings()
+	level++
+	if level >= len(logLevels) {
+		level = -1
+	}
+	o.setLogFilter(pattern, level)
+}
+
+// promptLogPattern reads a regular expression for the filter from the
+// keyboard; the terminal doesn't echo, so the typed characters are. An
+// empty pattern clears it.
+func (o *orchestrator) promptLogPattern(reader *bufio.Reader) {
+	if len(o.apps) == 0 {
+		return
+	}
+	fmt.Printf(Yellow + "Filter (regex, empty to clear): " + Reset)
+	var input []byte
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return
+		}
+		switch b {
+		case '\r', '\n':
+			fmt.Println()
+			_, level := o.apps[0].logFilter.settings()
+			if len(input) == 0 {
+				o.setLogFilter(nil, level)
+				return
+			}
+			pattern, err := regexp.Compile(string(input))
+			if err != nil {
+				logf(levelError, Red+"Error: "+Reset+"Invalid filter: %v\n", err)
+				return
+			}
+			o.setLogFilter(pattern, level)
+			return
+		case 127, '\b':
+			if len(input) > 0 {
+				input = input[:len(input)-1]
+				fmt.Print("\b \b")
+			}
+		default:
+			if b >= ' ' {
+				input = append(input, b)
+				fmt.Printf("%c", b)
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+)
+
+func TestLineLevel(t *testing.T) {
+	tests := []struct {
+		line  string
+		level string
+	}{
+		{`{"level":"warn","msg":"slow query"}`, "warn"},
+		{`{"severity":"CRITICAL","message":"down"}`, "error"},
+		{`time=2026-10-16T09:12:04Z level=DEBUG msg="cache miss"`, "debug"},
+		{`2026/10/16 09:12:04 WARNING disk almost full`, "warn"},
+		{`[info] listening on :8080`, "info"},
+		{`[GIN] 2026/10/16 | 200 | GET /health [trace]`, "debug"},
+		{`listening on :8080`, ""},
+		{`{"msg":"no level"}`, ""},
+	}
+	for _, tt := range tests {
+		rank, ok := lineLevel([]byte(tt.line))
+		got := ""
+		if ok {
+			got = logLevels[rank]
+		}
+		if got != tt.level {
+			t.Errorf("%s: expected level %q, got %q", tt.line, tt.level, got)
+		}
+	}
+}
+
+func TestLogFilterAllows(t *testing.T) {
+	warn, _ := parseLogLevel("warn")
+	tests := []struct {
+		name    string
+		pattern string
+		level   int
+		line    string
+		allowed bool
+	}{
+		{"no filter", "", -1, "level=debug anything", true},
+		{"below level", "", warn, "level=info started", false},
+		{"at level", "", warn, "level=warn slow", true},
+		{"above level", "", warn, `{"level":"error","msg":"failed"}`, true},
+		{"unleveled with level", "", warn, "panic: runtime error", true},
+		{"matching pattern", "users", -1, "GET /users 200", true},
+		{"not matching pattern", "users", -1, "GET /health 200", false},
+		{"pattern or level", "users", warn, "level=info GET /users", true},
+		{"level without match", "users", warn, "level=error failed", true},
+		{"unleveled without match", "users", warn, "GET /health 200", false},
+	}
+	for _, tt := range tests {
+		f := newLogFilter(LogFilterConfig{})
+		var pattern *regexp.Regexp
+		if tt.pattern != "" {
+			pattern = regexp.MustCompile(tt.pattern)
+		}
+		f.set(pattern, tt.level)
+		if got := f.allows([]byte(tt.line)); got != tt.allowed {
+			t.Errorf("%s: expected allowed %v, got %v", tt.name, tt.allowed, got)
+		}
+	}
+}
+
+func TestLogFilterWriter(t *testing.T) {
+	var buf bytes.Buffer
+	f := newLogFilter(LogFilterConfig{})
+	w := &logFilterWriter{w: &buf, filter: f}
+
+	// Without a filter output passes through, partial lines included
+	w.Write([]byte("Enter name: "))
+	if buf.String() != "Enter name: " {
+		t.Fatalf("Expected the prompt to pass through, got %q", buf.String())
+	}
+
+	buf.Reset()
+	f.set(nil, 2)
+	w.Write([]byte("level=info started\nlevel=er"))
+	w.Write([]byte("ror failed\nlevel=warn slow\n"))
+	if expected := "level=error failed\nlevel=warn slow\n"; buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestNewLogFilter(t *testing.T) {
+	f := newLogFilter(LogFilterConfig{Grep: "users", Level: "warning"})
+	pattern, level := f.settings()
+	if pattern == nil || pattern.String() != "users" || level != 2 {
+		t.Errorf("Expected users and warn, got %v and %d", pattern, level)
+	}
+	if f.describe() != `warn and above or lines matching "users"` {
+		t.Errorf("Unexpected description %q", f.describe())
+	}
+	if newLogFilter(LogFilterConfig{}).active() {
+		t.Errorf("Expected an empty config to show every line")
+	}
+
+	if err := validateLogFilter(LogFilterConfig{Grep: "("}); err == nil {
+		t.Errorf("Expected an invalid pattern to be rejected")
+	}
+	if err := validateLogFilter(LogFilterConfig{Level: "loud"}); err == nil {
+		t.Errorf("Expected an unknown level to be rejected")
+	}
+}
@@ -1,21 +1,28 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
 
-// ANSI color codes
-const (
-	Reset  = "\033[0m"
+// ANSI color codes. The colors are switched by applyPalette.
+const Reset = "\033[0m"
+
+var (
 	Red    = "\033[31m"
 	Green  = "\033[32m"
 	Yellow = "\033[33m"
@@ -32,6 +39,247 @@ type WindConfig struct {
 	IncludeExts   []string
 	PollInterval  time.Duration
 	DebounceDelay time.Duration
+	// MaxPollInterval is how far polling slows down while nothing changes;
+	// equal to PollInterval the interval is fixed
+	MaxPollInterval time.Duration
+
+	// Watch is a filter expression that replaces IncludeExts, e.g.
+	// "**/*.go and not **/*_test.go and not gen/**"
+	Watch string
+
+	// ChangeDetection is "mtime" (default) or "hash". Hash mode only
+	// rebuilds when a file's contents actually change.
+	ChangeDetection string
+	// IgnoreNoise skips rebuilds when a Go file change only touches
+	// comments or formatting
+	IgnoreNoise bool
+	// FunctionReport prints the functions added, removed or modified by
+	// the changes behind each successful rebuild
+	FunctionReport bool
+
+	// SinceRestart prefixes application output with the time since the
+	// last restart (+1.2s)
+	SinceRestart bool
+	// StreamTags prefixes application output with [app] and its standard
+	// error with [err], to tell it apart from Wind's own messages
+	StreamTags bool
+	// ClearOnRebuild clears the terminal when a change starts a new cycle
+	ClearOnRebuild bool
+	// StatusLine keeps the state of every target in a line at the bottom
+	// of the terminal, replacing the progress messages of each cycle
+	StatusLine bool
+	// PrettyLogs formats the application's JSON log lines
+	PrettyLogs PrettyLogsConfig
+
+	// Palette selects the colors of Wind's output and the pages it injects:
+	// "default", "deuteranopia" or "protanopia"
+	Palette string
+
+	// Timestamps prefixes every line of Wind and application output with
+	// the time, formatted per TimestampFormat: "local" (default), "utc",
+	// "relative" to Wind's start, or a Go time layout
+	Timestamps      bool
+	TimestampFormat string
+	// LogFile copies the output of the session to a rotated file
+	LogFile LogFileConfig
+	// LogFilter hides application output below a level or not matching a
+	// pattern
+	LogFilter LogFilterConfig
+
+	// Preset adapts the defaults to a project in another language: python,
+	// node or rust. Settings of the config file win over the preset's.
+	Preset string
+	// Target selects a detected main package by name (see `wind targets`)
+	Target string
+	// Detect enables project structure detection. With Detect off the build
+	// must be fully specified through BuildCmd or Processes.
+	Detect bool
+
+	// HealthCheckURL or ReadyTCPPort is polled after each start; the app
+	// only counts as started once it answers within ReadyTimeout
+	HealthCheckURL string
+	ReadyTCPPort   int
+	ReadyTimeout   time.Duration
+	// StopSignal asks the app to shut down (SIGTERM, SIGINT, SIGQUIT,
+	// SIGHUP, SIGUSR1 or SIGUSR2); it is killed if still running after
+	// StopTimeout, or never with a zero StopTimeout
+	StopSignal  string
+	StopTimeout time.Duration
+	// ForwardSignals are passed on to the running apps instead of being
+	// handled by Wind, e.g. SIGHUP for apps that reload their config. A
+	// forwarded SIGINT only stops Wind when repeated.
+	ForwardSignals []string
+	// Stdin passes Wind's standard input on to the application (of the
+	// first target) instead of reading keys from it
+	Stdin bool
+	// PTY runs the application in a pseudo-terminal, so it sees a terminal
+	// as if run directly: colored logs, prompts and line editing work
+	PTY bool
+
+	// Env and the env files (dotenv format) are merged into the run
+	// command's environment; Env wins over EnvFile, which wins over
+	// EnvFiles. Editing an env file restarts the app without rebuilding.
+	Env      map[string]string
+	EnvFile  string
+	EnvFiles []string
+	// EnvProfiles maps profile names to env files; EnvProfile selects one
+	// in place of EnvFiles
+	EnvProfiles map[string][]string
+	EnvProfile  string
+
+	// WatchPaths limits rebuilds to changes under these paths
+	WatchPaths []string
+	// MaxDepth limits how many directory levels below the project are
+	// scanned (0 is unlimited); MaxWatchedFiles is the number of watched
+	// files above which Wind warns about slow scans
+	MaxDepth        int
+	MaxWatchedFiles int
+	// ArtifactDirs are files and directories the build or the app writes,
+	// such as dist or coverage.out; they are never watched, like the -o
+	// outputs of the build commands
+	ArtifactDirs []string
+	// FollowSymlinks watches the targets of symlinked files and directories
+	FollowSymlinks bool
+	// WatchDirs are scanned in addition to the project, e.g. a shared
+	// proto/ checkout next to it; relative to the project root
+	WatchDirs []string
+	// DependencyGraph skips rebuilds for changes outside the build target's
+	// package dependencies and only restarts for non-Go files that are not
+	// embedded
+	DependencyGraph bool
+
+	// Processes runs several binaries side by side, each with its own
+	// build/run command (multi-process mode)
+	Processes []ProcessConfig
+
+	// Mocks regenerates mocks when interfaces in the given packages change
+	Mocks MocksConfig
+	// Generators run code generators (buf, templ, sqlc, ...) before the
+	// build when files matching their patterns change
+	Generators []GeneratorRule
+	// ModCommand, e.g. go mod tidy, runs before the build when go.mod or
+	// go.sum changed and after a build failed for a missing module
+	ModCommand string
+	// Retry retries builds that failed for a reason that looks transient
+	Retry RetryConfig
+	// Format rewrites changed Go files with gofmt or goimports before the
+	// build; empty leaves them alone
+	Format string
+	// History writes the timing of every build cycle to
+	// tmp/wind-history.jsonl for wind stats
+	History bool
+	// Rollback keeps the last good binaries and restarts the previous one
+	// when a new build crashes at startup
+	Rollback RollbackConfig
+
+	// AB configures the side-by-side mode of `wind ab`
+	AB ABConfig
+	// Proxy configures the zero-downtime mode of `wind proxy`
+	Proxy ProxyConfig
+
+	// LoadTest fires a short HTTP load burst after every restart
+	LoadTest LoadTestConfig
+	// Screenshots loads pages in a headless browser after every restart
+	Screenshots ScreenshotConfig
+
+	// LiveReload proxies the app and refreshes the browser after restarts
+	LiveReload LiveReloadConfig
+	// Sidecars are long-running helpers such as npx vite that start and
+	// stop with the session
+	Sidecars []SidecarConfig
+	// Serve serves a directory of frontend assets with live reload next to
+	// the app
+	Serve ServeConfig
+	// AssetsGlobs select static files the app serves from disk; when only
+	// they change nothing is rebuilt and the browsers reload
+	AssetsGlobs []string
+	// AssetsRestart restarts the app instead for asset changes, for apps
+	// that cache their assets
+	AssetsRestart bool
+	// HotPatch pushes template/asset changes into a cooperating app
+	// instead of restarting it
+	HotPatch HotPatchConfig
+	// Notifications POSTs build failures and recoveries to a webhook
+	Notifications NotificationsConfig
+	// ReloadSignal signals the app instead of restarting it when only
+	// files it reloads itself changed
+	ReloadSignal ReloadSignalConfig
+	// Editor is the command opening a compile error, with {file}, {line}
+	// and {col} placeholders; empty uses $WIND_EDITOR or $EDITOR
+	Editor string
+	// OpenErrors opens the first compile error of a failed build in the
+	// editor
+	OpenErrors bool
+	// Shared registers ports and sessions machine-wide, for dev boxes
+	// shared by several users (see `wind ps`)
+	Shared bool
+	// AssignPort names a variable that receives a port Wind assigns to
+	// each target and reuses on later runs (see `wind ports`)
+	AssignPort string
+	// Verbose prints timing details, such as how long each scan took, and
+	// the commands run
+	Verbose bool
+	// Trace also prints every file the scan notices, including the ones
+	// that need no rebuild (-vv)
+	Trace bool
+	// Quiet only prints errors and restart notifications, besides the
+	// application's output
+	Quiet bool
+	// Keys rebinds the interactive controls, e.g. {restart: "R"}; see
+	// keyActions for the action names
+	Keys map[string]string
+	// Observe lets `wind attach` follow the session read-only; daemons
+	// always allow it
+	Observe bool
+	// ControlAddr is the address of the HTTP control API, e.g.
+	// 127.0.0.1:5656; empty disables it
+	ControlAddr string
+
+	// PGOProfile is passed to go build as -pgo=<path> ("auto" is allowed)
+	PGOProfile string
+	// PGOCollectPath is the app's pprof CPU profile endpoint, read by the
+	// dev proxy for `wind pgo`
+	PGOCollectPath     string
+	PGOCollectDuration time.Duration
+	// GoExperiment is exported as GOEXPERIMENT to builds
+	GoExperiment string
+	// SizeAlert warns when the binary grows by more than this between two
+	// builds, e.g. 20% or 5MB; empty turns the warning off
+	SizeAlert string
+	// MinFreeSpace is the space, e.g. 2GB, that must be available where
+	// builds write before each build starts; empty or 0 turns the check off
+	MinFreeSpace string
+	// BuildCache reports or prunes what the session added to the Go build
+	// cache when Wind stops
+	BuildCache BuildCacheConfig
+	// BuildTags, LDFlags and GoFlags are added to go build commands;
+	// LDFlags and GoFlags may use {{gitSHA}} and {{buildTime}}
+	BuildTags []string
+	LDFlags   string
+	GoFlags   string
+	// GOOS and GOARCH cross-compile builds, e.g. linux and arm64 for a
+	// Raspberry Pi; empty builds for this machine
+	GOOS   string
+	GOARCH string
+	// Deploy runs builds on a remote host instead of locally
+	Deploy DeployConfig
+	// RemoteBuild offloads builds to another machine over SSH
+	RemoteBuild RemoteBuildConfig
+	// Docker builds and runs the app as a container
+	Docker DockerConfig
+	// Compose starts the compose services the app depends on and restarts
+	// them when their files change
+	Compose ComposeConfig
+	// Lint checks the changed packages after every successful rebuild
+	Lint LintConfig
+	// CI selects the checks of wind ci
+	CI CIConfig
+	// VulnCheck runs govulncheck in the background whenever go.mod or
+	// go.sum change
+	VulnCheck bool
+	// APISchemas are OpenAPI or GraphQL schema files whose changes across
+	// rebuilds are written to tmp/api-changes.md
+	APISchemas []string
 }
 
 type WindApp struct {
@@ -40,11 +288,130 @@ type WindApp struct {
 	building   bool
 	mutex      sync.Mutex
 	fileStates map[string]time.Time
+	// embeds are the //go:embed patterns of each watched Go file, relative
+	// to the project
+	embeds     map[string][]string
+	fileHashes map[string]string
+	envStates  map[string]time.Time
 	stopChan   chan bool
+
+	// pendingChanges collects changed paths until the next cycle starts;
+	// scanTime is how long the scan took that noticed the changes of the
+	// next cycle; cycles are the stats of the latest cycles
+	scanTime time.Duration
+	cycles   []cycleStats
+
+	// changedFiles holds the paths that triggered the current cycle
+	pendingChanges []string
+	changedFiles   []string
+
+	// mockFingerprints holds the exported interfaces of each mock package
+	mockFingerprints map[string]string
+	// tokenDigests holds the comment-free token digest of each Go file
+	tokenDigests map[string]string
+	// funcSnapshots holds the functions of each Go file as of the last
+	// report, for FunctionReport
+	funcSnapshots map[string]map[string]string
+	buildID       int
+	ab            *abMode
+	proxy         *proxyMode
+	tests         *testRunner
+	depGraph      *depGraph
+	// apiTracker follows APISchemas, shared by every target; nil without
+	apiTracker *apiTracker
+	liveReload *liveReload
+	// readyHooks run whenever the app started and passed its readiness
+	// check, e.g. to trigger end-to-end suites
+	readyHooks []func()
+
+	// name and color identify the target in multi-process mode
+	name  string
+	color string
+
+	// watchFilter is the compiled Watch expression
+	watchFilter watchExpr
+	// roots are the directories scanned for changes: the project and any
+	// go.work or replaced modules outside it
+	roots []string
+	// scanCache reads them in parallel and keeps directory listings;
+	// tooManyWarned and tooDeepWarned are set once the scan limits were
+	// reported
+	scanCache     *scanCache
+	tooManyWarned bool
+	tooDeepWarned bool
+	// ignoreModTime is the modification time of the loaded .windignore,
+	// zero without one
+	ignoreLoaded  bool
+	ignoreModTime time.Time
+	// artifacts are the build outputs and other files written while the
+	// app runs, never watched (see shareArtifacts)
+	artifacts []string
+	// buildTimes holds the durations of the latest successful builds, for
+	// the ETA of a queued rebuild
+	buildTimes []time.Duration
+	// remoteBuild tracks whether builds run on the RemoteBuild host
+	remoteBuild remoteBuildState
+	// buildExitCode is the exit status of the latest failed build, for
+	// wind build
+	buildExitCode int
+	// buildRetries counts the retries of the current build after transient
+	// failures
+	buildRetries int
+	// failedBuilds counts the builds failed since the last good one, for
+	// notifications
+	failedBuilds int
+	// vulnCheck runs govulncheck on dependency changes, shared by every
+	// target; nil without VulnCheck
+	vulnCheck *vulnChecker
+	// binarySize is the size of the latest build's binary, 0 before the
+	// first one
+	binarySize int64
+	// port is the port assigned through AssignPort, 0 without one
+	port int
+	// targetGraph scopes the watched files to the build target's
+	// dependencies (see outsideTarget); targetScoped is set once that was
+	// announced
+	targetGraph  *depGraph
+	targetScoped bool
+
+	// exit waits for the current process, so an exit Wind did not ask for
+	// is reported (see watchProcess)
+	exit *processExit
+	// startFailed is called with an exit status when the first build or run
+	// fails (--exit-on-fail); the next cycle clears it. cycled is set once
+	// the first cycle started.
+	startFailed func(status int)
+	cycled      bool
+	// stdin relays Wind's standard input to the process (Stdin); nil for
+	// the other targets
+	stdin *stdinRelay
+
+	// startedAt is when the current process was started, in Unix
+	// nanoseconds, for SinceRestart
+	startedAt atomic.Int64
+
+	// status is what the control API reports, kept up to date by emit;
+	// compileErrors are the errors of the latest build
+	status        appStatus
+	compileErrors []compileError
+	statusMutex   sync.Mutex
+
+	// externalEvents receives the changed paths of --events-from, which
+	// replace scanning; nil when Wind polls
+	externalEvents chan string
+
+	// Interactive controls
+	paused     atomic.Bool
+	logsHidden atomic.Bool
+	// rawLogs shows JSON log lines as written despite PrettyLogs
+	rawLogs atomic.Bool
+	// logFilter hides lines of the application's output; nil shows all
+	logFilter   *logFilter
+	rebuildChan chan struct{}
 }
 
-func main() {
-	asciiWind := `Wind - Go Web App Watcher
+// banner is printed on startup, except with --log-format=json
+const banner = `Wind - Go Web App Watcher
  _    _ _____ _   _ _____  
 | |  | |_   _| \ | |  __ \ 
 | |  | | | | |  \| | |  | |
@@ -52,196 +419,949 @@ func main() {
 \  /\  /_| |_| |\  | |__| |
  \/  \/ \___/\_| \_|_____/ 
 `
-	fmt.Print(Cyan + asciiWind + Reset)
-
-	// Default to init if no arguments provided
-	if len(os.Args) == 1 {
-		runWatcher()
-		return
-	}
 
+func main() {
+	defer guardTerminal()
 	handleArgs(os.Args[1:])
+	if exitStatus != 0 {
+		os.Exit(exitStatus)
+	}
 }
 
 func handleArgs(args []string) {
-	switch args[0] {
-	case "init":
-		runWatcher()
-	case "help", "-h", "--help":
-		showHelp()
-	case "version", "-v", "--version":
-		fmt.Println("Wind v1.1.0 - Enhanced with smart project detection")
-	default:
-		fmt.Printf(Red+"Error: "+Reset+"Unknown command: %s\n", args[0])
+	applyPalette("")
+
+	// Everything after -- is passed through to the run command
+	var runArgs []string
+	for i, arg := range args {
+		if arg == "--" {
+			args, runArgs = args[:i], args[i+1:]
+			break
+		}
+	}
+
+	args, castPath, err := extractValueFlag(args, recordFlag)
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%v\n", err)
+		return
+	}
+	args, logFormat, err := extractValueFlag(args, logFormatFlag)
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%v\n", err)
+		return
+	}
+	args, editor, err := extractValueFlag(args, editorFlag)
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%v\n", err)
+		return
+	}
+	args, eventsFrom, err := extractValueFlag(args, eventsFromFlag)
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%v\n", err)
+		return
+	}
+	args, tags, err := extractValueFlag(args, tagsFlag)
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%v\n", err)
+		return
+	}
+	args, ldflags, err := extractValueFlag(args, ldflagsFlag)
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%v\n", err)
+		return
+	}
+	args, goflags, err := extractValueFlag(args, goflagsFlag)
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%v\n", err)
+		return
+	}
+	args, logFile, err := extractValueFlag(args, logFileFlag)
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%v\n", err)
+		return
+	}
+	args, grep, err := extractValueFlag(args, grepFlag)
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%v\n", err)
+		return
+	}
+	args, level, err := extractValueFlag(args, levelFlag)
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%v\n", err)
+		return
+	}
+	args, verbose := extractBoolFlag(args, verboseFlag, shortVerboseFlag)
+	args, trace := extractBoolFlag(args, traceFlag)
+	args, quiet := extractBoolFlag(args, quietFlag, quietLongFlag)
+	args, noColor := extractBoolFlag(args, noColorFlag)
+	args, exitOnFail := extractBoolFlag(args, exitOnFailFlag)
+	args, stdin := extractBoolFlag(args, stdinFlag)
+	args, pty := extractBoolFlag(args, ptyFlag)
+	if !useColors(noColor) {
+		redirect, err := stripColors()
+		if err != nil {
+			logf(levelError, Red+"Error: "+Reset+"Failed to turn colors off: %v\n", err)
+			return
+		}
+		defer redirect.restore()
+	}
+	if quiet && (verbose || trace) {
+		logf(levelError, Red+"Error: "+Reset+"%s cannot be combined with %s or %s\n", quietFlag, shortVerboseFlag, traceFlag)
+		return
+	}
+	if _, err := regexp.Compile(grep); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Invalid %s: %v\n", grepFlag, err)
+		return
+	}
+	if _, err := parseLogLevel(level); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%v\n", err)
+		return
+	}
+	if logFormat != "" && logFormat != "text" && logFormat != "json" {
+		logf(levelError, Red+"Error: "+Reset+"%s must be text or json, got %q\n", logFormatFlag, logFormat)
+		return
+	}
+	if eventsFrom != "" && eventsFrom != eventsFromStdin {
+		logf(levelError, Red+"Error: "+Reset+"%s only supports %s, got %q\n", eventsFromFlag, eventsFromStdin, eventsFrom)
+		return
+	}
+	// docs output is meant to be redirected to a file
+	if logFormat != "json" && !quiet && (len(args) == 0 || args[0] != "docs") {
+		fmt.Print(Cyan + banner + Reset)
+	}
+	if castPath != "" {
+		recorder, err := startRecording(castPath)
+		if err != nil {
+			logf(levelError, Red+"Error: "+Reset+"Failed to start session recording: %v\n", err)
+			return
+		}
+		defer func() {
+			recorder.stop()
+			logf(levelInfo, Cyan+"Info: "+Reset+"Session recorded to %s (play with: asciinema play %s)\n", castPath, castPath)
+		}()
+	}
+
+	if logFormat == "json" {
+		l, err := startEventLog(true)
+		if err != nil {
+			logf(levelError, Red+"Error: "+Reset+"Failed to enable JSON output: %v\n", err)
+			return
+		}
+		events = l
+		defer func() {
+			l.stop()
+			events = nil
+		}()
+	}
+
+	name := "init"
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	c := findCommand(name)
+	if c == nil {
+		logf(levelError, Red+"Error: "+Reset+"Unknown command: %s\n", name)
 		showHelp()
+		return
+	}
+	if wantsHelp(args) {
+		showCommandHelp(c)
+		return
+	}
+
+	// Commands that work on the project start from its root, so Wind can be
+	// run from any subdirectory
+	if c.project {
+		if err := enterProjectRoot(); err != nil {
+			logf(levelError, Red+"Error: "+Reset+"Failed to find project root: %v\n", err)
+			return
+		}
 	}
+
+	c.run(watchOptions{
+		runArgs: runArgs, editor: editor, verbose: verbose, trace: trace, quiet: quiet, eventsFrom: eventsFrom,
+		tags: tags, ldflags: ldflags, goflags: goflags, logFile: logFile,
+		grep: grep, level: level, exitOnFail: exitOnFail, stdin: stdin, pty: pty,
+		recording: castPath != "",
+	}, args)
 }
 
-func showHelp() {
-	fmt.Printf(Cyan + "Wind - Go Web Application Watcher" + Reset + "\n")
-	fmt.Println()
-	fmt.Printf(Yellow + "Usage:" + Reset + "\n")
-	fmt.Println("  wind              # Start watching current directory")
-	fmt.Println("  wind init         # Start watching current directory")
-	fmt.Println("  wind help         # Show this help message")
-	fmt.Println("  wind version      # Show version")
-	fmt.Println()
-	fmt.Printf(Yellow + "Features:" + Reset + "\n")
-	fmt.Println("  • Automatic reload on Go file changes")
-	fmt.Println("  • Excludes common directories (vendor, .git, etc.)")
-	fmt.Println("  • Colored output for better visibility")
-	fmt.Println("  • Graceful process management")
-	fmt.Println("  • Zero dependencies - uses only Go standard library")
+// extractValueFlag removes flag <value> (or flag=<value>) from args and
+// returns the remaining arguments and the value
+func extractValueFlag(args []string, flag string) ([]string, string, error) {
+	var rest []string
+	value := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == flag:
+			if i+1 >= len(args) {
+				return nil, "", fmt.Errorf("%s requires a value", flag)
+			}
+			value = args[i+1]
+			i++
+		case strings.HasPrefix(arg, flag+"="):
+			value = strings.TrimPrefix(arg, flag+"=")
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return rest, value, nil
 }
 
-func runWatcher() {
-	// Auto-detect project structure and configure build command
-	buildCmd, buildTarget := detectProjectStructure()
+// verboseFlag enables the Verbose setting
+const verboseFlag = "--verbose"
 
-	config := WindConfig{
-		BuildCmd:      buildCmd,
-		RunCmd:        "./tmp/main",
-		ExcludeDirs:   []string{"vendor", ".git", "node_modules", "tmp", ".idea", ".vscode"},
-		IncludeExts:   []string{".go", ".html", ".css", ".js", ".json", ".yaml", ".yml"},
-		PollInterval:  500 * time.Millisecond,
-		DebounceDelay: 300 * time.Millisecond,
+// extractBoolFlag removes every occurrence of the flags, a flag and its
+// aliases, from args and reports whether there was one
+func extractBoolFlag(args []string, flags ...string) ([]string, bool) {
+	var rest []string
+	found := false
+	for _, arg := range args {
+		if slices.Contains(flags, arg) {
+			found = true
+			continue
+		}
+		rest = append(rest, arg)
 	}
+	return rest, found
+}
+
+// watchOptions selects the mode runWatcher operates in
+type watchOptions struct {
+	// abMode keeps the previous build running next to the new one
+	abMode bool
+	// proxyMode hands traffic over to each new build once it is healthy
+	proxyMode bool
+	// testMode runs go test for affected packages instead of build+run;
+	// testArgs are passed through to go test
+	testMode bool
+	testArgs []string
+	// target selects a detected main package by name
+	target string
+	// runArgs are appended to the run command (`wind -- --port=9090`)
+	runArgs []string
+	// daemon runs detached from the terminal, controlled through
+	// daemonSocket
+	daemon bool
+	// editor overrides the Editor setting (--editor)
+	editor string
+	// verbose turns on the Verbose setting (--verbose, -v), trace the Trace
+	// setting (-vv) and quiet the Quiet setting (-q)
+	verbose bool
+	trace   bool
+	quiet   bool
+	// eventsFrom is the source of changed paths replacing polling
+	// (--events-from); empty polls
+	eventsFrom string
+	// tags, ldflags and goflags override the BuildTags, LDFlags and GoFlags
+	// settings (--tags, --ldflags, --goflags)
+	tags    string
+	ldflags string
+	goflags string
+	// logFile overrides LogFile.Path (--log-file)
+	logFile string
+	// grep and level override LogFilter (--grep, --level)
+	grep  string
+	level string
+	// exitOnFail stops Wind with a non-zero status when the first build or
+	// run fails (--exit-on-fail)
+	exitOnFail bool
+	// stdin and pty turn on the Stdin and PTY settings (--stdin, --pty)
+	stdin bool
+	pty   bool
+	// recording is set while --record-session pipes the output to the
+	// recorder
+	recording bool
+}
 
-	fmt.Printf(Cyan+"Info: "+Reset+"Detected project structure: %s\n", buildTarget)
+// loadWatchConfig returns the defaults overlaid with the project config file
+// and the command line overrides. Errors are printed.
+func loadWatchConfig(opts watchOptions) (WindConfig, bool) {
+	config := defaultConfig()
+
+	// Overlay the optional project config files
+	project, local, err := loadProjectConfig(&config)
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Invalid config: %v\n", err)
+		return config, false
+	}
+	if project {
+		applyPalette(config.Palette)
+		logf(levelInfo, Cyan+"Info: "+Reset+"Loaded config from %s\n", configFileName)
+	}
+	if local {
+		logf(levelInfo, Cyan+"Info: "+Reset+"Loaded local settings from %s\n", localConfigFileName)
+	}
+	if description := applyPreset(&config); description != "" {
+		logf(levelInfo, Cyan+"Info: "+Reset+"%s\n", description)
+	}
+
+	if opts.editor != "" {
+		config.Editor = opts.editor
+	}
+	if opts.logFile != "" {
+		config.LogFile.Path = opts.logFile
+	}
+	if opts.grep != "" {
+		config.LogFilter.Grep = opts.grep
+	}
+	if opts.level != "" {
+		config.LogFilter.Level = opts.level
+	}
+	config.Stdin = config.Stdin || opts.stdin
+	config.PTY = config.PTY || opts.pty
+	// The recorder reads the output through a pipe; in a PTY the application
+	// still writes to a terminal, as it would without recording
+	if opts.recording && terminalOut != nil && ptySupported && !config.PTY {
+		config.PTY = true
+		logf(levelInfo, Cyan+"Info: "+Reset+"Running the application in a PTY while recording\n")
+	}
+	sharedRegistry = config.Shared
+	// The output mode of the command line replaces the configured one;
+	// configured as both, quiet wins
+	switch {
+	case opts.quiet:
+		config.Quiet = true
+	case opts.verbose || opts.trace:
+		config.Quiet, config.Verbose = false, true
+		config.Trace = config.Trace || opts.trace
+	}
+	if config.Quiet {
+		config.Verbose, config.Trace = false, false
+	}
+	if config.Trace {
+		config.Verbose = true
+	}
+	if opts.tags != "" {
+		config.BuildTags = strings.Split(opts.tags, ",")
+	}
+	if opts.ldflags != "" {
+		config.LDFlags = opts.ldflags
+	}
+	if opts.goflags != "" {
+		config.GoFlags = opts.goflags
+	}
+	// Validated as merged, so the local file or the command line can
+	// complete or correct the project's settings
+	if err := validateConfig(&config); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Invalid config: %v\n", err)
+		return config, false
+	}
+	return config, true
+}
 
-	app := &WindApp{
-		config:     config,
-		fileStates: make(map[string]time.Time),
-		stopChan:   make(chan bool),
+// createApps creates the targets of the mode opts select, printing what
+// they build. Docker mode rewrites the build and run commands of config.
+// Errors are printed.
+func createApps(config *WindConfig, opts watchOptions) ([]*WindApp, []*e2eSuite, bool) {
+	var apps []*WindApp
+	var suites []*e2eSuite
+	switch {
+	case opts.testMode:
+		// Test mode watches the whole project; processes don't apply.
+		// Arguments after -- go to the test binaries; the build flag
+		// settings apply to go test too.
+		app := newWindApp(*config, "", "")
+		app.tests = newTestRunner(append(goBuildFlags(*config), opts.testArgs...), opts.runArgs)
+		apps = []*WindApp{app}
+		logf(levelInfo, Cyan+"Info: "+Reset+"Test mode: go test %s\n", strings.Join(app.tests.command([]string{"<affected packages>"})[1:], " "))
+	case len(config.Processes) > 0:
+		if opts.abMode || opts.proxyMode || opts.target != "" || len(opts.runArgs) > 0 {
+			logf(levelError, Red+"Error: "+Reset+"A/B mode, proxy mode, run targets and -- arguments cannot be combined with processes\n")
+			return nil, nil, false
+		}
+		var err error
+		if apps, err = newSupervisors(*config); err != nil {
+			logf(levelError, Red+"Error: "+Reset+"Invalid config: %v\n", err)
+			return nil, nil, false
+		}
+		for _, app := range apps {
+			logf(levelInfo, Cyan+"Info: "+Reset+"%sbuild: %s · run: %s\n", app.label(), app.config.BuildCmd, app.config.RunCmd)
+		}
+		if suites, err = newE2ESuites(*config, apps); err != nil {
+			logf(levelError, Red+"Error: "+Reset+"Invalid config: %v\n", err)
+			return nil, nil, false
+		}
+		for _, suite := range suites {
+			var deps []string
+			for _, dep := range suite.deps {
+				deps = append(deps, dep.name)
+			}
+			logf(levelInfo, Cyan+"Info: "+Reset+"%se2e: %s · after: %s\n", suite.label(), suite.runCmd, strings.Join(deps, ", "))
+		}
+	case config.Docker.enabled():
+		// Arguments after -- reach the container's entrypoint
+		if config.Docker.Service != "" && len(opts.runArgs) > 0 {
+			logf(levelError, Red+"Error: "+Reset+"-- arguments cannot be passed to a compose service; set its command in the compose file\n")
+			return nil, nil, false
+		}
+		logf(levelInfo, Cyan+"Info: "+Reset+"%s\n", applyDocker(config, projectRoot()))
+		config.RunCmd = withRunArgs(config.RunCmd, opts.runArgs)
+		apps = []*WindApp{newWindApp(*config, "", "")}
+	default:
+		detected := config.BuildCmd == "" && opts.target == ""
+		if detected && config.Target == "" && config.Detect && isTerminal(os.Stdin) && terminalOut != nil && opts.eventsFrom != eventsFromStdin {
+			name, err := promptTarget()
+			if err != nil {
+				logf(levelError, Red+"Error: "+Reset+"%v\n", err)
+				return nil, nil, false
+			}
+			config.Target = name
+		}
+		buildTarget, err := resolveBuildCmd(config, opts.target)
+		if err != nil {
+			logf(levelError, Red+"Error: "+Reset+"%v\n", err)
+			return nil, nil, false
+		}
+		logf(levelInfo, Cyan+"Info: "+Reset+"Detected project structure: %s\n", buildTarget)
+		if detected {
+			suggestTaskRunner()
+		}
+		config.RunCmd = withRunArgs(config.RunCmd, opts.runArgs)
+		apps = []*WindApp{newWindApp(*config, "", "")}
 	}
+	return apps, suites, true
+}
 
-	fmt.Printf(Green + "🌪️  Starting Wind watcher..." + Reset + "\n")
-	fmt.Printf(Cyan+"Info: "+Reset+"Current directory: %s\n", getCurrentDir())
+func runWatcher(opts watchOptions) {
+	config, ok := loadWatchConfig(opts)
+	if !ok {
+		return
+	}
+	if config.PTY && !ptySupported {
+		logf(levelError, Red+"Error: "+Reset+"%s is only supported on Linux\n", ptyFlag)
+		return
+	}
+	if config.Stdin && opts.eventsFrom == eventsFromStdin {
+		logf(levelError, Red+"Error: "+Reset+"%s cannot be combined with %s %s\n", stdinFlag, eventsFromFlag, eventsFromStdin)
+		return
+	}
+
+	// The status line is drawn on the terminal, below everything else
+	var status *statusLine
+	if config.StatusLine && terminalOut != nil && (events == nil || events.w == nil) {
+		var err error
+		if status, err = startStatusLine(); err != nil {
+			logf(levelError, Red+"Error: "+Reset+"Failed to start the status line: %v\n", err)
+			return
+		}
+		defer status.stop()
+	} else {
+		config.StatusLine = false
+	}
+
+	// The log is set up first so it gets the timestamps too
+	if config.LogFile.Path != "" {
+		stop, err := startLogFile(config.LogFile)
+		if err != nil {
+			logf(levelError, Red+"Error: "+Reset+"Failed to open the log file: %v\n", err)
+			return
+		}
+		defer stop()
+		logf(levelInfo, Cyan+"Info: "+Reset+"Logging to %s\n", config.LogFile.Path)
+	}
+
+	if config.Timestamps {
+		start := time.Now()
+		redirect, err := redirectOutput(func(dst *os.File) io.Writer {
+			return newTimestampWriter(dst, config.TimestampFormat, start)
+		})
+		if err != nil {
+			logf(levelError, Red+"Error: "+Reset+"Failed to enable timestamps: %v\n", err)
+			return
+		}
+		defer redirect.restore()
+	}
+	// -q hides Wind's info and warning messages from here on
+	quiet.Store(config.Quiet)
+
+	apps, suites, ok := createApps(&config, opts)
+	if !ok {
+		return
+	}
+
+	logf(levelInfo, Green+"🌪️  Starting Wind watcher..."+Reset+"\n")
+	logf(levelInfo, Cyan+"Info: "+Reset+"Current directory: %s\n", getCurrentDir())
 
 	// Create tmp directory if it doesn't exist
 	if err := os.MkdirAll("tmp", 0755); err != nil {
 		log.Printf(Red+"Error: "+Reset+"Failed to create tmp directory: %v", err)
 		return
 	}
+	// wind report size shows the latest session
+	os.Remove(sizeHistoryFile)
+	trimHistory()
 
-	// Initial scan of files
-	app.scanFiles()
+	if opts.abMode {
+		app := apps[0]
+		app.ab = newABMode(app.config.AB)
+		app.ab.app = app
+		if err := app.ab.start(); err != nil {
+			log.Printf(Red+"Error: "+Reset+"Failed to start A/B mode: %v", err)
+			return
+		}
+	}
 
-	// Initial build and run
-	app.buildAndRun()
+	if config.LiveReload.AppURL != "" {
+		lr, err := newLiveReload(config.LiveReload)
+		if err != nil {
+			logf(levelError, Red+"Error: "+Reset+"Invalid config: %v\n", err)
+			return
+		}
+		lr.start()
+		defer lr.stop()
+		for _, app := range apps {
+			app.liveReload = lr
+		}
+	}
 
-	// Setup signal handling
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	if config.Serve.Dir != "" {
+		static := newStaticServer(config.Serve.Dir, config.Serve.Port)
+		if err := static.start(config.PollInterval); err != nil {
+			logf(levelError, Red+"Error: "+Reset+"Failed to serve %s: %v\n", config.Serve.Dir, err)
+			return
+		}
+		defer static.stop()
+	}
+
+	if opts.proxyMode {
+		app := apps[0]
+		app.proxy = newProxyMode(app.config.Proxy)
+		app.proxy.start()
+	}
+
+	// One package graph scopes every target's watched files and, with
+	// DependencyGraph, decides whether a change needs a rebuild
+	graph := newDepGraph()
+	for _, app := range apps {
+		app.targetGraph = graph
+		if config.DependencyGraph {
+			app.depGraph = graph
+		}
+	}
+
+	if config.VulnCheck {
+		checker := &vulnChecker{}
+		for _, app := range apps {
+			app.vulnCheck = checker
+		}
+	}
+
+	if len(config.APISchemas) > 0 {
+		tracker := newAPITracker(config.APISchemas)
+		for _, app := range apps {
+			app.apiTracker = tracker
+		}
+	}
+
+	// Every target sees the same changes, so only the first restarts
+	// compose services
+	for _, app := range apps[1:] {
+		app.config.Compose.Restart = nil
+	}
 
-	fmt.Printf(Yellow + "Press Ctrl+C to stop..." + Reset + "\n")
+	// Offer to clean up processes a crashed session left behind
+	collectAbandoned(isTerminal(os.Stdin))
 
-	// Start file watching in a goroutine
-	go app.watchFiles()
+	// Register the session for wind ps, before taking ports another user's
+	// session of this project would hold
+	if err := registerInstance(newInstance(apps)); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%v\n", err)
+		return
+	}
+	defer unregisterInstance()
+
+	// Reuse the ports of earlier runs and warn about ports other projects
+	// are using
+	assignPorts(apps, fixedPorts(config, opts))
+	shareArtifacts(apps)
+	defer releasePorts()
 
-	// Wait for interrupt signal
-	<-c
+	orch := newOrchestrator(apps)
+	orch.suites = suites
+	if opts.exitOnFail {
+		var failed sync.Once
+		for _, app := range apps {
+			app.startFailed = func(status int) {
+				failed.Do(func() {
+					exitStatus = status
+					logf(levelError, Red+"Error: "+Reset+"%sThe first build or run failed; stopping (%s)\n", app.label(), exitOnFailFlag)
+				})
+				orch.requestQuit()
+			}
+		}
+	}
+	// The bindings were validated with the config
+	orch.keys, _ = bindKeys(config.Keys)
+	if status != nil {
+		status.show(orch.statusSummary)
+	}
+	if config.ControlAddr != "" || opts.daemon || config.Observe {
+		// The control API streams the output, so capture it unless
+		// --log-format=json already does
+		evLog := events
+		if evLog == nil {
+			var err error
+			if evLog, err = startEventLog(false); err != nil {
+				logf(levelError, Red+"Error: "+Reset+"Failed to capture output for the control API: %v\n", err)
+				return
+			}
+			events = evLog
+			defer func() {
+				evLog.stop()
+				events = nil
+			}()
+		}
+		api := newControlAPI(orch, evLog)
+		defer api.stop()
+		if config.ControlAddr != "" {
+			if err := api.listen("tcp", config.ControlAddr); err != nil {
+				logf(levelError, Red+"Error: "+Reset+"Failed to start the control API: %v\n", err)
+				return
+			}
+			logf(levelInfo, Cyan+"Info: "+Reset+"Control API on http://%s\n", config.ControlAddr)
+		}
+		if opts.daemon || config.Observe {
+			if err := api.listenReadOnly(); err != nil {
+				logf(levelError, Red+"Error: "+Reset+"Failed to open %s: %v\n", observeSocket, err)
+				return
+			}
+			defer os.Remove(observeSocket)
+			if !opts.daemon {
+				logf(levelInfo, Cyan+"Info: "+Reset+"Attach a read-only observer with wind attach\n")
+			}
+		}
+		if opts.daemon {
+			// A socket left behind by a crashed daemon would block the listen
+			os.Remove(daemonSocket)
+			if err := api.listen("unix", daemonSocket); err != nil {
+				logf(levelError, Red+"Error: "+Reset+"Failed to open %s: %v\n", daemonSocket, err)
+				return
+			}
+			defer os.Remove(daemonSocket)
+			if err := os.WriteFile(daemonPidFile, []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+				logf(levelError, Red+"Error: "+Reset+"Failed to write %s: %v\n", daemonPidFile, err)
+				return
+			}
+			defer os.Remove(daemonPidFile)
+		}
+	}
+
+	// An external event source replaces polling, but the initial scan still
+	// records the files to compare against
+	if opts.eventsFrom != "" {
+		for _, app := range apps {
+			app.externalEvents = make(chan string, externalEventBuffer)
+		}
+	}
+
+	if err := startComposeServices(config.Compose); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%v\n", err)
+		return
+	}
+
+	defer finishBuildCache(config.BuildCache, time.Now())
+
+	// Frontend watchers and other helpers run for the whole session
+	defer stopSidecars(startSidecars(config, len(apps)))
+
+	if config.Stdin {
+		apps[0].stdin = startStdinRelay(os.Stdin)
+	}
+
+	// Initial scan, build and run of every target, then start watching
+	orch.start()
+	if opts.eventsFrom != "" {
+		logf(levelInfo, Cyan+"Info: "+Reset+"Reading changed paths from %s instead of polling\n", opts.eventsFrom)
+		go orch.readExternalEvents(os.Stdin)
+	}
+
+	// Setup signal handling. Signals the apps reload on are passed
+	// through; the list was validated with the config.
+	forwarded, _ := parseForwardSignals(config.ForwardSignals)
+	defer orch.forwardSignals(forwarded)()
+	defer watchTerminalSize()()
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGTERM)
+	if forwards(forwarded, syscall.SIGINT) {
+		logf(levelInfo, Yellow+"Press Ctrl+C twice to stop..."+Reset+"\n")
+	} else {
+		signal.Notify(c, os.Interrupt)
+		logf(levelInfo, Yellow+"Press Ctrl+C to stop..."+Reset+"\n")
+	}
+
+	// Enable keyboard controls when attached to a terminal that does not
+	// deliver events or input of the application
+	switch {
+	case config.Stdin:
+		// The application's terminal echoes and edits the input, so with
+		// a PTY every key goes to it as typed
+		if config.PTY && isTerminal(os.Stdin) {
+			if err := enableRawInput(); err == nil {
+				defer terminal.restore()
+			}
+		}
+		logf(levelInfo, Cyan+"Info: "+Reset+"Input goes to the application; keyboard controls are off\n")
+	case isTerminal(os.Stdin) && opts.eventsFrom != eventsFromStdin:
+		if err := enableRawInput(); err == nil {
+			defer terminal.restore()
+		}
+		orch.showKeyHelp()
+		go orch.readKeys(os.Stdin)
+	}
+
+	// Wait for interrupt signal or quit key
+	select {
+	case <-c:
+	case <-orch.quitChan:
+	}
 	fmt.Printf("\n" + Yellow + "Shutting down..." + Reset + "\n")
-	close(app.stopChan)
-	app.cleanup()
+	orch.stop()
 }
 
-func (app *WindApp) scanFiles() error {
-	return filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+// resolveBuildCmd fills in config.BuildCmd for a single-target session and
+// describes where it came from. A target named on the command line wins over
+// everything else; a configured target only applies when no build command
+// is configured.
+func resolveBuildCmd(config *WindConfig, cliTarget string) (string, error) {
+	target := cliTarget
+	if target == "" && config.BuildCmd == "" {
+		target = config.Target
+	}
+
+	switch {
+	case target != "" && !config.Detect:
+		return "", fmt.Errorf("wind run %s requires detect; build targets are not detected", target)
+	case target != "":
+		t, err := findTarget(target)
 		if err != nil {
-			return err
+			return "", err
 		}
+		config.BuildCmd = t.buildCmd()
+		return t.Description, nil
+	case config.BuildCmd == "":
+		// Auto-detect project structure and configure build command
+		buildCmd, buildTarget := detectProjectStructure()
+		config.BuildCmd = buildCmd
+		return buildTarget, nil
+	default:
+		return "Custom build command (" + configFileName + ")", nil
+	}
+}
 
-		// Skip excluded directories
-		for _, exclude := range app.config.ExcludeDirs {
-			if strings.Contains(path, exclude) {
-				if info.IsDir() {
-					return filepath.SkipDir
-				}
-				return nil
+func (app *WindApp) scanFiles() error {
+	app.checkEnvChanges()
+
+	for {
+		embedsChanged := false
+		err := app.walkWatched(func(path string, info os.FileInfo, err error) error {
+			// Store file modification times
+			if !info.IsDir() && app.shouldWatch(path) {
+				app.fileStates[path] = info.ModTime()
+				app.contentChanged(path, info)
+				app.tokensChanged(path)
+				app.snapshotFuncs(path)
+				embedsChanged = app.updateEmbeds(path) || embedsChanged
 			}
-		}
 
-		// Store file modification times
-		if !info.IsDir() && app.shouldWatch(path) {
-			app.fileStates[path] = info.ModTime()
+			return nil
+		})
+		// Files embedded by directives found in this pass are only watched
+		// from the next one
+		if err != nil || !embedsChanged {
+			return err
 		}
-
-		return nil
-	})
+	}
 }
 
 func (app *WindApp) watchFiles() {
+	defer guardTerminal()
 	debounce := time.NewTimer(app.config.DebounceDelay)
 	debounce.Stop()
 
-	ticker := time.NewTicker(app.config.PollInterval)
-	defer ticker.Stop()
+	schedule := newPollScheduler(app.config)
+	poll := time.NewTimer(app.config.PollInterval)
+	defer poll.Stop()
 
 	var hasChanges bool
+	events := app.externalEvents
 
 	for {
 		select {
 		case <-app.stopChan:
 			return
 
-		case <-ticker.C:
+		case <-app.rebuildChan:
+			hasChanges = false
+			debounce.Stop()
+			schedule.activity(time.Now())
+			app.clearForCycle()
+			app.beginCycle()
+			app.buildAndRun()
+
+		case path := <-events:
+			if app.checkEnvChanges() && !hasChanges {
+				app.restartProcess()
+			}
+			if app.externalChange(path) && !hasChanges {
+				hasChanges = true
+				debounce.Reset(app.config.DebounceDelay)
+			}
+
+		case <-poll.C:
+			// With an event source the timer only notices resuming, so
+			// events queue up while paused
+			if app.externalEvents != nil {
+				events = app.externalEvents
+				if app.paused.Load() {
+					events = nil
+				}
+				poll.Reset(app.config.PollInterval)
+				continue
+			}
+			if app.paused.Load() {
+				schedule.activity(time.Now())
+				poll.Reset(app.config.PollInterval)
+				continue
+			}
+			if app.checkEnvChanges() && !hasChanges {
+				app.restartProcess()
+			}
+			started := time.Now()
 			changed := app.checkForChanges()
 			if changed && !hasChanges {
 				hasChanges = true
 				debounce.Reset(app.config.DebounceDelay)
 			}
 
+			scanTime := time.Since(started)
+			if changed && app.scanTime == 0 {
+				app.scanTime = scanTime
+			}
+			delay, slow := schedule.next(changed, scanTime, time.Now())
+			if slow {
+				app.warnSlowScan(scanTime)
+			}
+			poll.Reset(delay)
+
 		case <-debounce.C:
 			if hasChanges {
 				hasChanges = false
-				app.buildAndRun()
+				app.beginCycle()
+				if app.vulnCheck != nil {
+					app.vulnCheck.checkChanges(app.changedFiles)
+				}
+				if app.restartComposeServices() {
+					continue
+				}
+				if !app.hotPatch() && !app.signalReload() && !app.reloadAssets() {
+					app.applyChanges()
+				}
 			}
 		}
 	}
 }
 
-func (app *WindApp) checkForChanges() bool {
-	changed := false
+// applyChanges rebuilds, restarts or does nothing depending on what the
+// current cycle's changes mean for the build target
+func (app *WindApp) applyChanges() {
+	switch app.changeImpact() {
+	case impactNone:
+		logf(levelInfo, Cyan+"Info: "+Reset+"%sChanges don't affect this target, skipping rebuild\n", app.label())
+	case impactRestart:
+		logf(levelInfo, Cyan+"Info: "+Reset+"%sOnly runtime files changed, restarting without rebuild\n", app.label())
+		app.restartProcess()
+	default:
+		app.buildAndRun()
+	}
+}
 
-	err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+// beginCycle hands the changes collected since the last cycle to the
+// rebuild that is about to start
+func (app *WindApp) beginCycle() {
+	app.changedFiles = app.pendingChanges
+	app.pendingChanges = nil
+	if app.targetGraph != nil {
+		app.targetGraph.update(app.changedFiles)
+	}
+}
 
-		// Skip excluded directories
-		for _, exclude := range app.config.ExcludeDirs {
-			if strings.Contains(path, exclude) {
-				if info.IsDir() {
-					return filepath.SkipDir
-				}
-				return nil
-			}
-		}
+func (app *WindApp) checkForChanges() bool {
+	changed := false
 
+	err := app.walkWatched(func(path string, info os.FileInfo, err error) error {
 		// Check if file should be watched
-		if !info.IsDir() && app.shouldWatch(path) {
-			modTime := info.ModTime()
-			if lastMod, exists := app.fileStates[path]; !exists || modTime.After(lastMod) {
-				if exists {
-					fmt.Printf(Yellow+"Change: "+Reset+"File changed: %s\n", path)
-					changed = true
-				}
-				app.fileStates[path] = modTime
-			}
+		if !info.IsDir() && app.shouldWatch(path) && app.fileChanged(path, info) {
+			changed = true
 		}
 
 		return nil
 	})
 
 	if err != nil {
-		fmt.Printf(Red+"Error: "+Reset+"Failed to scan files: %v\n", err)
+		logf(levelError, Red+"Error: "+Reset+"Failed to scan files: %v\n", err)
 	}
 
 	return changed
 }
 
+// fileChanged records the state of a watched file and reports whether it
+// changed in a way that needs a rebuild. New files only become known.
+func (app *WindApp) fileChanged(path string, info os.FileInfo) bool {
+	modTime := info.ModTime()
+	lastMod, exists := app.fileStates[path]
+	if exists && !modTime.After(lastMod) {
+		return false
+	}
+	app.fileStates[path] = modTime
+	app.updateEmbeds(path)
+	if !app.contentChanged(path, info) {
+		if app.config.Trace {
+			logf(levelInfo, Cyan+"Trace: "+Reset+"%sTouched without content change: %s\n", app.label(), path)
+		}
+		return false
+	}
+
+	significant := app.tokensChanged(path)
+	switch {
+	case !exists:
+		// Files found by the first scan are not news
+		if app.config.Trace && app.buildID != 0 {
+			logf(levelInfo, Cyan+"Trace: "+Reset+"%sNew file: %s\n", app.label(), path)
+		}
+	case significant:
+		// The first change of a cycle starts it
+		if len(app.pendingChanges) == 0 {
+			app.clearForCycle()
+		}
+		logf(levelInfo, Yellow+"Change: "+Reset+"%sFile changed: %s\n", app.label(), path)
+		app.emit(event{Event: "change", Path: path})
+		app.pendingChanges = append(app.pendingChanges, path)
+		return true
+	default:
+		logf(levelInfo, Cyan+"Info: "+Reset+"%sIgnoring comment/format-only change: %s\n", app.label(), path)
+	}
+	return false
+}
+
 func (app *WindApp) shouldWatch(filename string) bool {
+	if !app.inWatchPaths(filename) || app.outsideTarget(filename) || isArtifact(projectPath(filename), app.artifacts) {
+		return false
+	}
+	if app.matchesGenerator(filename) || app.isEmbedded(filename) || app.matchesAssets(filename) || app.isDockerInput(filename) || app.matchesComposeRestart(filename) {
+		return true
+	}
+	if app.apiTracker != nil && app.apiTracker.watches(filename) {
+		return true
+	}
+	if (app.vulnCheck != nil || app.config.ModCommand != "") && isModuleFile(filename) {
+		return true
+	}
+	if app.watchFilter != nil {
+		return app.watchFilter.match(filename)
+	}
+
 	ext := filepath.Ext(filename)
 	for _, includeExt := range app.config.IncludeExts {
 		if ext == includeExt {
@@ -259,61 +1379,338 @@ func (app *WindApp) buildAndRun() {
 		return
 	}
 	app.building = true
+	defer func() { app.building = false }()
 
-	// Stop current process
-	app.stopProcess()
+	if app.cycled {
+		app.startFailed = nil
+	}
+	app.cycled = true
 
-	fmt.Printf(Cyan + "🔨 Building application..." + Reset + "\n")
+	// Stop current process; in proxy mode it keeps serving until the new
+	// build is healthy
+	if app.proxy == nil {
+		app.stopProcess()
+	}
 
-	// Build the application
-	buildCmd := exec.Command("sh", "-c", app.config.BuildCmd)
-	buildCmd.Stdout = os.Stdout
-	buildCmd.Stderr = os.Stderr
+	cycle := app.startCycle()
 
-	if err := buildCmd.Run(); err != nil {
-		fmt.Printf(Red+"Error: "+Reset+"Build failed: %v\n", err)
-		app.building = false
+	// Absorb formatted and generated files so they don't trigger another
+	// cycle
+	formatted := app.formatChanges()
+	tidied := app.maintainModules()
+	generated := app.runGenerators()
+	if app.regenerateMocks() || generated || formatted || tidied {
+		app.scanFiles()
+	}
+
+	if app.tests != nil {
+		app.runTests()
 		return
 	}
 
-	fmt.Printf(Green + "✅ Build successful" + Reset + "\n")
+	defer app.recordCycle(cycle)
+	cycle.PrepareMs = time.Since(cycle.started).Milliseconds()
+	building := time.Now()
+	built := app.build()
+	cycle.Build, cycle.OK, cycle.BuildMs = app.buildID, built, time.Since(building).Milliseconds()
+	if !built {
+		app.failStart(app.buildExitCode)
+		return
+	}
+	app.trackBinarySize()
+	if !app.lintChanges() {
+		return
+	}
+	app.reportFuncChanges()
+	if app.apiTracker != nil {
+		app.apiTracker.check(app.buildID, app.label())
+	}
 
-	// Run the application
-	fmt.Printf(Cyan + "🚀 Starting application..." + Reset + "\n")
+	starting := time.Now()
+	app.startProcess()
+	cycle.StartupMs = time.Since(starting).Milliseconds()
+}
 
-	runCmd := exec.Command("sh", "-c", app.config.RunCmd)
-	runCmd.Stdout = os.Stdout
-	runCmd.Stderr = os.Stderr
+// restartProcess restarts the application without rebuilding it, e.g. when
+// only its environment changed
+func (app *WindApp) restartProcess() {
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
 
-	if err := runCmd.Start(); err != nil {
-		fmt.Printf(Red+"Error: "+Reset+"Failed to start application: %v\n", err)
-		app.building = false
+	// Test mode has no process to restart
+	if app.building || app.tests != nil {
 		return
 	}
 
-	app.process = runCmd.Process
-	fmt.Printf(Green+"Success: "+Reset+"Application started (PID: %d)\n", app.process.Pid)
+	if app.proxy == nil {
+		app.stopProcess()
+	}
+	app.startProcess()
+}
+
+// build runs the build command and reports whether it succeeded
+func (app *WindApp) build() bool {
+	app.progress(levelInfo, app.label()+Cyan+"🔨 Building application..."+Reset+"\n")
+
+	if err := app.checkDiskSpace(); err != nil {
+		app.emit(event{Event: "build_fail", Error: err.Error()})
+		logf(levelError, Red+"Error: "+Reset+"%sBuild skipped: %v\n", app.label(), err)
+		app.buildExitCode = 1
+		app.buildRetries = 0
+		app.buildFailed(err.Error(), nil)
+		return false
+	}
+
+	// Build the application
+	var buildOutput bytes.Buffer
+	logWriters := []io.Writer{&buildOutput}
+	if id, logFile, err := createBuildLog(); err == nil {
+		app.buildID = id
+		defer logFile.Close()
+		logWriters = append(logWriters, logFile)
+	} else {
+		logf(levelWarn, Yellow+"Warning: "+Reset+"Failed to create build log: %v\n", err)
+	}
+	buildLog := io.MultiWriter(logWriters...)
+	started := time.Now()
+	app.emit(event{Event: "build_start", Build: app.buildID})
+	if app.liveReload != nil {
+		app.liveReload.send("building")
+	}
 
-	app.building = false
+	command := app.buildCommand()
+	remote := app.buildsRemotely()
+	if remote {
+		logf(levelInfo, app.label()+Cyan+"📡 Building on %s..."+Reset+"\n", app.config.RemoteBuild.Host)
+		command = remoteBuildCommand(app.config.RemoteBuild, projectRoot(), command, app.deployBinary(), app.remoteBuildEnv())
+	}
+	if app.config.Verbose {
+		logf(levelInfo, Cyan+"Command: "+Reset+"%s%s\n", app.label(), command)
+	}
+	buildCmd := exec.Command("sh", "-c", command)
+	buildCmd.Env = app.buildEnv()
+	// Compiler errors are collected and summarized once the build is done
+	var stderr bytes.Buffer
+	buildCmd.Stdout = io.MultiWriter(app.output(os.Stdout), buildLog)
+	buildCmd.Stderr = io.MultiWriter(&stderr, buildLog)
+
+	stopQueue := app.watchQueue(started)
+	err := buildCmd.Run()
+	stopQueue()
+	if err != nil {
+		errs, other := parseBuildErrors(stderr.String())
+		if remote && len(errs) == 0 {
+			app.output(os.Stderr).Write(stderr.Bytes())
+			app.remoteBuildFailed()
+			return app.build()
+		}
+		if app.fixMissingModules(stderr.String()) {
+			return app.build()
+		}
+		if len(errs) == 0 && app.retryBuild(stderr.String(), err) {
+			return app.build()
+		}
+		app.buildRetries = 0
+		app.buildExitCode = commandExitCode(err)
+		app.emit(event{Event: "build_fail", Build: app.buildID, DurationMs: time.Since(started).Milliseconds(), Error: err.Error(), Errors: len(errs)})
+		printBuildErrors(app.output(os.Stderr), errs, other)
+		app.setCompileErrors(errs)
+		summary := err.Error()
+		if len(errs) > 0 {
+			summary = pluralize(len(errs), "error")
+		}
+		logf(levelError, Red+"Error: "+Reset+"%sBuild #%d failed: %s (log: %s)\n", app.label(), app.buildID, summary, buildLogPath(app.buildID))
+		app.buildFailed(summary, errs)
+		printBuildHints(buildOutput.String())
+		if app.liveReload != nil {
+			app.liveReload.send("failed")
+		}
+		if app.proxy != nil {
+			app.proxy.setBuildFailure(&buildFailure{Build: app.buildID, Errors: errs, Other: other})
+		}
+		if app.config.OpenErrors && len(errs) > 0 {
+			app.openInEditor(errs[0])
+		}
+		return false
+	}
+	app.setCompileErrors(nil)
+	app.buildRetries = 0
+	// Warnings of successful builds are passed through as they are
+	app.output(os.Stderr).Write(stderr.Bytes())
+
+	app.emit(event{Event: "build_ok", Build: app.buildID, DurationMs: time.Since(started).Milliseconds()})
+	app.buildSucceeded()
+	app.recordBuildTime(time.Since(started))
+	if !remote {
+		app.recordLocalBuild(time.Since(started))
+	}
+	if app.liveReload != nil {
+		app.liveReload.build.Store(int64(app.buildID))
+	}
+	app.progress(levelInfo, app.label()+Green+"✅ Build #%d successful"+Reset+" (log: %s)\n", app.buildID, buildLogPath(app.buildID))
+	return true
 }
 
-func (app *WindApp) stopProcess() {
-	if app.process != nil {
-		fmt.Printf(Yellow+"Info: "+Reset+"Stopping application (PID: %d)...\n", app.process.Pid)
+// startProcess starts the built application. The caller holds app.mutex.
+func (app *WindApp) startProcess() {
+	if app.config.Deploy.Host != "" {
+		app.deploy()
+		return
+	}
+	if app.crossCompiled() {
+		logf(levelInfo, Cyan+"Info: "+Reset+"%sBuilt for %s, which cannot run here; set deploy.host to run it remotely\n", app.label(), app.buildPlatform())
+		return
+	}
+
+	env, err := app.runEnv()
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%sFailed to load environment: %v\n", app.label(), err)
+		return
+	}
 
-		// Try graceful shutdown first
-		if err := app.process.Signal(syscall.SIGTERM); err != nil {
-			// Force kill if graceful shutdown fails
-			app.process.Kill()
+	// In A/B mode the new build runs next to the previous one
+	if app.ab != nil {
+		if err := app.ab.deploy(app.deployBinary(), env); err != nil {
+			logf(levelError, Red+"Error: "+Reset+"Failed to start A/B instances: %v\n", err)
+			return
 		}
+		if app.liveReload != nil {
+			go app.liveReload.reload()
+		}
+		return
+	}
 
-		app.process.Wait()
+	// In proxy mode the previous process serves until the new one is healthy
+	start := app.launch
+	if app.proxy != nil {
+		start = app.handoff
+	}
+	if !start(env) {
+		return
+	}
+
+	if app.liveReload != nil {
+		go app.liveReload.reload()
+	}
+	if app.config.LoadTest.URL != "" {
+		go app.runLoadTestHook()
+	}
+	if len(app.config.Screenshots.URLs) > 0 {
+		go app.runScreenshotHook()
+	}
+	for _, hook := range app.readyHooks {
+		hook()
+	}
+}
+
+// launch runs the application with env and reports whether it started
+func (app *WindApp) launch(env []string) bool {
+	app.progress(levelInfo, app.label()+Cyan+"🚀 Starting application..."+Reset+"\n")
+
+	if app.config.Verbose {
+		logf(levelInfo, Cyan+"Command: "+Reset+"%s%s\n", app.label(), app.config.RunCmd)
+	}
+	exit, err := app.startCommand(app.config.RunCmd, env)
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%sFailed to start application: %v\n", app.label(), err)
+		app.failStart(1)
+		return false
+	}
+	app.process, app.exit = exit.process, exit
+	go app.watchProcess(exit, env)
+
+	app.guardStartup(env)
+
+	probe := app.readyProbe()
+	if probe == nil {
+		app.progress(levelSuccess, Green+"Success: "+Reset+"%sApplication started (PID: %d)\n", app.label(), app.process.Pid)
+		return true
+	}
+
+	latency, err := pollProcessReady(probe, app.config.ReadyTimeout, app.exit)
+	if err != nil {
+		if app.rollBack(env) {
+			return true
+		}
+		status := failureStatus(app.exit)
+		logf(levelError, Red+"Error: "+Reset+"%sRestart failed: application (PID: %d) %v\n", app.label(), app.process.Pid, err)
+		app.stopProcess()
+		app.failStart(status)
+		return false
+	}
+	app.progress(levelSuccess, Green+"Success: "+Reset+"%sApplication started (PID: %d), ready in %v\n", app.label(), app.process.Pid, latency.Round(time.Millisecond))
+	return true
+}
+
+// startCommand starts command with env like the run command: its streams
+// are connected (see connectIO) and its exit is waited for
+func (app *WindApp) startCommand(command string, env []string) (*processExit, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Env = env
+	streams, err := app.connectIO(cmd)
+	if err != nil {
+		return nil, err
+	}
+	// Without a PTY, which gives it a session, the command leads a group
+	// of its own, so a later session can stop what it left behind
+	// (collectAbandoned). Signals reach it through forwardSignals.
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = groupProcAttr()
+	}
+
+	app.startedAt.Store(time.Now().UnixNano())
+	err = cmd.Start()
+	streams.started(app, err == nil)
+	if err != nil {
+		return nil, err
+	}
+	exit := watchExit(cmd.Process)
+	exit.streams = streams
+	recordChild(cmd.Process.Pid, command)
+	app.emit(event{Event: "app_start", PID: cmd.Process.Pid})
+	return exit, nil
+}
+
+func (app *WindApp) stopProcess() {
+	if app.process != nil {
+		app.terminate(app.process, app.exit)
 		app.process = nil
+		app.exit = nil
+	}
+}
+
+// terminate sends process StopSignal and kills it if it is still running
+// after StopTimeout. exit is the process's watchExit, or nil when nothing
+// waits for it yet.
+func (app *WindApp) terminate(process *os.Process, exit *processExit) {
+	app.progress(levelInfo, Yellow+"Info: "+Reset+"%sStopping application (PID: %d)...\n", app.label(), process.Pid)
+
+	// The signal was validated with the config
+	sig, _ := parseStopSignal(app.config.StopSignal)
+	state, killed := stopGracefully(process, exit, sig, app.config.StopTimeout)
+	if killed {
+		logf(levelWarn, Yellow+"Warning: "+Reset+"%sApplication (PID: %d) did not stop within %s; killed it\n",
+			app.label(), process.Pid, app.config.StopTimeout)
 	}
+	if exit != nil {
+		exit.streams.wait()
+	}
+	forgetChild(process.Pid)
+	app.emit(event{Event: "app_exit", PID: process.Pid, ExitCode: exitCode(state), Signal: exitSignal(state)})
 }
 
 func (app *WindApp) cleanup() {
+	// watchProcess reports exits under the mutex
+	app.mutex.Lock()
 	app.stopProcess()
+	app.mutex.Unlock()
+	app.removeContainer()
+	if app.ab != nil {
+		app.ab.stop()
+	}
+	if app.proxy != nil {
+		app.proxy.stop()
+	}
 
 	// Clean up tmp directory
 	if _, err := os.Stat("tmp/main"); err == nil {
@@ -353,6 +1750,11 @@ func detectProjectStructure() (buildCmd, buildTarget string) {
 		}
 	}
 
+	// Option 5: a go.work root whose modules hold the main packages
+	if targets := workspaceTargets(); len(targets) > 0 {
+		return targets[0].buildCmd(), targets[0].Description
+	}
+
 	// Fallback to current directory
 	return "go build -o ./tmp/main .", "Fallback (current directory)"
 }
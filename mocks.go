@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Mock generator presets
+var mockPresets = map[string]string{
+	"mockery": "mockery --dir {pkg} --all",
+	"gomock":  "go generate {pkg}",
+}
+
+// MocksConfig regenerates mocks when the exported interfaces of the
+// configured packages change
+type MocksConfig struct {
+	// Packages are the directories whose interfaces are mocked
+	Packages []string
+	// Preset selects a default Command: "mockery" or "gomock"
+	Preset string
+	// Command runs once per changed package; {pkg} is replaced with the
+	// package directory (./path/to/pkg)
+	Command string
+}
+
+func (c MocksConfig) command() string {
+	if c.Command != "" {
+		return c.Command
+	}
+	if cmd, ok := mockPresets[c.Preset]; ok {
+		return cmd
+	}
+	return mockPresets["gomock"]
+}
+
+// interfaceFingerprint summarizes the exported interfaces declared in the
+// non-test Go files of dir. Comments and formatting do not affect it, so
+// only real API changes trigger regeneration.
+func interfaceFingerprint(dir string) (string, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.SkipObjectResolution)
+	if err != nil {
+		return "", err
+	}
+
+	var decls []string
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					if _, ok := ts.Type.(*ast.InterfaceType); !ok || !ts.Name.IsExported() {
+						continue
+					}
+					var buf bytes.Buffer
+					printer.Fprint(&buf, token.NewFileSet(), ts)
+					decls = append(decls, buf.String())
+				}
+			}
+		}
+	}
+
+	sort.Strings(decls)
+	return strings.Join(decls, "\n"), nil
+}
+
+// mockPackageFor returns the configured mock package containing path
+func (app *WindApp) mockPackageFor(path string) (string, bool) {
+	dir := projectPath(filepath.Dir(path))
+	for _, pkg := range app.config.Mocks.Packages {
+		if projectPath(pkg) == dir {
+			return pkg, true
+		}
+	}
+	return "", false
+}
+
+// initMockFingerprints records the current interfaces of every configured
+// mock package
+func (app *WindApp) initMockFingerprints() {
+	app.mockFingerprints = make(map[string]string)
+	for _, pkg := range app.config.Mocks.Packages {
+		if fp, err := interfaceFingerprint(pkg); err == nil {
+			app.mockFingerprints[pkg] = fp
+		}
+	}
+}
+
+// regenerateMocks reruns the mock generator for packages whose exported
+// interfaces changed in this cycle. It reports whether any generator ran.
+func (app *WindApp) regenerateMocks() bool {
+	if len(app.config.Mocks.Packages) == 0 {
+		return false
+	}
+	if app.mockFingerprints == nil {
+		app.initMockFingerprints()
+	}
+
+	checked := map[string]bool{}
+	ran := false
+	for _, path := range app.changedFiles {
+		if filepath.Ext(path) != ".go" || strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		pkg, ok := app.mockPackageFor(path)
+		if !ok || checked[pkg] {
+			continue
+		}
+		checked[pkg] = true
+
+		fp, err := interfaceFingerprint(pkg)
+		if err != nil || fp == app.mockFingerprints[pkg] {
+			continue
+		}
+		app.mockFingerprints[pkg] = fp
+
+		pkgArg := "./" + projectPath(pkg)
+		command := strings.ReplaceAll(app.config.Mocks.command(), "{pkg}", pkgArg)
+		logf(levelInfo, app.label()+Cyan+"🧩 Interfaces changed in %s, regenerating mocks..."+Reset+"\n", pkgArg)
+
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Stdout = app.output(os.Stdout)
+		cmd.Stderr = app.output(os.Stderr)
+		if err := cmd.Run(); err != nil {
+			logf(levelError, Red+"Error: "+Reset+"%sMock generation failed: %v\n", app.label(), err)
+			continue
+		}
+		ran = true
+	}
+	return ran
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInterfaceFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		if err := os.WriteFile(filepath.Join(dir, "store.go"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write store.go: %v", err)
+		}
+		fp, err := interfaceFingerprint(dir)
+		if err != nil {
+			t.Fatalf("interfaceFingerprint failed: %v", err)
+		}
+		return fp
+	}
+
+	original := write(`package store
+
+type Store interface {
+	Get(id string) (string, error)
+}
+
+type helper interface{ run() }
+
+func impl() {}
+`)
+
+	// Comments, formatting, unexported interfaces and function bodies don't matter
+	same := write(`package store
+
+// Store persists things
+type Store interface {
+	Get(id string)   (string, error) // lookup
+}
+
+type helper interface{ run(); stop() }
+
+func impl() { println("changed") }
+`)
+	if same != original {
+		t.Error("Fingerprint should ignore comments, formatting and non-exported changes")
+	}
+
+	changed := write(`package store
+
+type Store interface {
+	Get(id string) (string, error)
+	Delete(id string) error
+}
+`)
+	if changed == original {
+		t.Error("Fingerprint should change when an exported interface changes")
+	}
+}
+
+func TestMocksPresetCommand(t *testing.T) {
+	tests := []struct {
+		config   MocksConfig
+		expected string
+	}{
+		{MocksConfig{}, "go generate {pkg}"},
+		{MocksConfig{Preset: "mockery"}, "mockery --dir {pkg} --all"},
+		{MocksConfig{Preset: "mockery", Command: "make mocks"}, "make mocks"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.config.command(); got != tt.expected {
+			t.Errorf("command() = %q, expected %q", got, tt.expected)
+		}
+	}
+}
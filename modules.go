@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"regexp"
+)
+
+// missingModulePattern matches the build errors ModCommand can fix, such as
+// an import of a module that is not required yet
+var missingModulePattern = regexp.MustCompile(`no required module provides package|missing go\.sum entry|updates to go\.(mod|sum) needed`)
+
+// moduleFiles returns the contents of the project's go.mod and go.sum
+func moduleFiles() []byte {
+	mod, _ := os.ReadFile("go.mod")
+	sum, _ := os.ReadFile("go.sum")
+	return append(append(mod, 0), sum...)
+}
+
+// runModCommand runs ModCommand and reports whether it succeeded
+func (app *WindApp) runModCommand(reason string) bool {
+	logf(levelInfo, app.label()+Cyan+"⚙️  %s, running %s..."+Reset+"\n", reason, app.config.ModCommand)
+	cmd := exec.Command("sh", "-c", app.config.ModCommand)
+	cmd.Env = app.buildEnv()
+	cmd.Stdout = app.output(os.Stdout)
+	cmd.Stderr = app.output(os.Stderr)
+	if err := cmd.Run(); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%s%q failed: %v\n", app.label(), app.config.ModCommand, err)
+		return false
+	}
+	return true
+}
+
+// maintainModules runs ModCommand before the build when go.mod or go.sum
+// changed in this cycle. It reports whether the command ran, so the files it
+// rewrote are absorbed instead of starting another cycle.
+func (app *WindApp) maintainModules() bool {
+	if app.config.ModCommand == "" {
+		return false
+	}
+	var changed []string
+	for _, path := range app.changedFiles {
+		if isModuleFile(path) {
+			changed = append(changed, path)
+		}
+	}
+	if len(changed) == 0 {
+		return false
+	}
+	app.runModCommand(describeChanged(changed) + " changed")
+	return true
+}
+
+// fixMissingModules runs ModCommand after a build failed for a missing
+// module, e.g. right after an import was added. It reports whether the
+// command changed go.mod or go.sum, in which case the build is worth
+// retrying; otherwise retrying could not help.
+func (app *WindApp) fixMissingModules(output string) bool {
+	if app.config.ModCommand == "" || !missingModulePattern.MatchString(output) {
+		return false
+	}
+	before := moduleFiles()
+	if !app.runModCommand("Missing module") {
+		return false
+	}
+	if bytes.Equal(before, moduleFiles()) {
+		return false
+	}
+	// Absorb the rewritten files so they don't start another cycle
+	app.scanFiles()
+	return true
+}
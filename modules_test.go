@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMaintainModules(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+
+	app := newWindApp(WindConfig{ModCommand: "echo x >> runs"}, "", "")
+	app.changedFiles = []string{"main.go"}
+	if app.maintainModules() {
+		t.Error("Expected no module maintenance without go.mod changes")
+	}
+	app.changedFiles = []string{"main.go", "go.sum"}
+	if !app.maintainModules() {
+		t.Error("Expected module maintenance after go.sum changed")
+	}
+	if data, _ := os.ReadFile("runs"); string(data) != "x\n" {
+		t.Errorf("Expected the command to run once, got %q", data)
+	}
+
+	app.config.ModCommand = ""
+	if app.maintainModules() {
+		t.Error("Expected no module maintenance without modCommand")
+	}
+}
+
+func TestBuildFixesMissingModule(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	os.Mkdir("tmp", 0755)
+	os.WriteFile("go.mod", []byte("module example.com/app\n"), 0644)
+
+	app := newWindApp(WindConfig{
+		BuildCmd:   `grep -q example.com/lib go.mod || { echo "main.go:3:8: no required module provides package example.com/lib; to add it:" >&2; exit 1; }`,
+		ModCommand: `echo "require example.com/lib v1.0.0" >> go.mod`,
+	}, "", "")
+	if !app.build() {
+		t.Fatal("Expected the build to succeed once the module was added")
+	}
+
+	// A command that cannot fix the build is not retried forever
+	app.config.BuildCmd = `echo "main.go:3:8: no required module provides package example.com/other" >&2; echo x >> runs; exit 1`
+	app.config.ModCommand = "true"
+	if app.build() {
+		t.Fatal("Expected the build to fail")
+	}
+	if data, _ := os.ReadFile("runs"); strings.Count(string(data), "x") != 1 {
+		t.Errorf("Expected a single build, got %q", data)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// directivePrefixes start the comments that affect the build: compiler
+// directives, build constraints and cgo exports
+var directivePrefixes = []string{"//go:", "//line ", "//export ", "//extern ", "// +build"}
+
+// isDirective reports whether comment affects the build
+func isDirective(comment string) bool {
+	for _, prefix := range directivePrefixes {
+		if strings.HasPrefix(comment, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenDigest hashes the token stream of Go source, ignoring comments and
+// whitespace. Directives such as //go:build and //go:embed and the cgo
+// preamble directly above import "C" are kept, since they change the build.
+// Automatically inserted semicolons are normalized so moving a line break
+// inside an expression does not count as a change.
+func tokenDigest(src []byte) (string, error) {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+
+	var errs scanner.ErrorList
+	var s scanner.Scanner
+	s.Init(file, src, func(pos token.Position, msg string) { errs.Add(pos, msg) }, scanner.ScanComments)
+
+	h := sha256.New()
+	// group is the comment group last scanned, ending on line groupEnd; it
+	// is the cgo preamble when import "C" follows right below
+	var group []string
+	groupEnd := 0
+	var preamble []string
+	afterImport := false
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		line := file.Line(pos)
+		if tok == token.COMMENT {
+			if isDirective(lit) {
+				fmt.Fprintf(h, "%d:%s\x00", tok, lit)
+			}
+			if line > groupEnd+1 {
+				group = nil
+			}
+			group = append(group, lit)
+			groupEnd = line + strings.Count(lit, "\n")
+			continue
+		}
+		if tok == token.SEMICOLON {
+			lit = ""
+		}
+		if afterImport && tok == token.STRING && lit == `"C"` {
+			for _, comment := range preamble {
+				fmt.Fprintf(h, "%d:%s\x00", token.COMMENT, comment)
+			}
+		}
+		afterImport = tok == token.IMPORT
+		if afterImport && groupEnd == line-1 {
+			preamble = group
+		} else {
+			preamble = nil
+		}
+		group, groupEnd = nil, 0
+		fmt.Fprintf(h, "%d:%s\x00", tok, lit)
+	}
+	if errs.Len() > 0 {
+		return "", errs.Err()
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// tokensChanged records the token digest of a changed Go file and reports
+// whether the change affects more than comments or formatting. Non-Go files
+// and files that fail to tokenize always count as changed.
+func (app *WindApp) tokensChanged(path string) bool {
+	if !app.config.IgnoreNoise || filepath.Ext(path) != ".go" {
+		return true
+	}
+	if app.tokenDigests == nil {
+		app.tokenDigests = make(map[string]string)
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return true
+	}
+	digest, err := tokenDigest(src)
+	if err != nil {
+		delete(app.tokenDigests, path)
+		return true
+	}
+
+	previous, exists := app.tokenDigests[path]
+	app.tokenDigests[path] = digest
+	return !exists || previous != digest
+}
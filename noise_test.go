@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenDigest(t *testing.T) {
+	base := "package main\n\nfunc main() {\n\tprintln(1, 2)\n}\n"
+
+	tests := []struct {
+		name    string
+		src     string
+		changed bool
+	}{
+		{"comment added", "package main\n\n// main runs\nfunc main() {\n\tprintln(1, 2) // numbers\n}\n", false},
+		{"reformatted", "package main\nfunc main() {\n    println(1,\n        2)\n}", false},
+		{"literal changed", "package main\n\nfunc main() {\n\tprintln(1, 3)\n}\n", true},
+		{"statement added", "package main\n\nfunc main() {\n\tprintln(1, 2)\n\treturn\n}\n", true},
+		{"build constraint added", "//go:build linux\n\npackage main\n\nfunc main() {\n\tprintln(1, 2)\n}\n", true},
+		{"directive added", "package main\n\n//go:noinline\nfunc main() {\n\tprintln(1, 2)\n}\n", true},
+	}
+
+	want, err := tokenDigest([]byte(base))
+	if err != nil {
+		t.Fatalf("tokenDigest failed: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tokenDigest([]byte(tt.src))
+			if err != nil {
+				t.Fatalf("tokenDigest failed: %v", err)
+			}
+			if (got != want) != tt.changed {
+				t.Errorf("Expected changed=%v", tt.changed)
+			}
+		})
+	}
+}
+
+func TestTokenDigestCgoPreamble(t *testing.T) {
+	src := func(preamble string) string {
+		return "package main\n\n// Package docs\n\n/*\n" + preamble + "\n*/\nimport \"C\"\n\nfunc main() {}\n"
+	}
+	base, _ := tokenDigest([]byte(src("#include <stdio.h>")))
+	changed, _ := tokenDigest([]byte(src("#include <stdlib.h>")))
+	if base == changed {
+		t.Error("Expected a changed cgo preamble to count as a change")
+	}
+	docs, _ := tokenDigest([]byte(strings.Replace(src("#include <stdio.h>"), "Package docs", "Package notes", 1)))
+	if base != docs {
+		t.Error("Expected a comment apart from the preamble to be noise")
+	}
+}
+
+func TestIgnoreNoiseChanges(t *testing.T) {
+	tmpDir := createTempProject(t, "root")
+	defer os.RemoveAll(tmpDir)
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+
+	app := &WindApp{
+		config: WindConfig{
+			IncludeExts: []string{".go"},
+			IgnoreNoise: true,
+		},
+		fileStates: make(map[string]time.Time),
+	}
+	if err := app.scanFiles(); err != nil {
+		t.Fatalf("Failed to scan files: %v", err)
+	}
+
+	src, _ := os.ReadFile("main.go")
+	edit := func(content string, offset time.Duration) {
+		if err := os.WriteFile("main.go", []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write main.go: %v", err)
+		}
+		when := time.Now().Add(offset)
+		os.Chtimes("main.go", when, when)
+	}
+
+	edit("// Package main says hello\n"+string(src), time.Minute)
+	if app.checkForChanges() {
+		t.Error("Comment-only change should not trigger a rebuild")
+	}
+
+	edit(string(src)+"\nfunc extra() {}\n", 2*time.Minute)
+	if !app.checkForChanges() {
+		t.Error("Code change should trigger a rebuild")
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// notifyErrorsShown is how many compile errors a failure notification lists
+const notifyErrorsShown = 5
+
+// NotificationsConfig POSTs build failures and recoveries to a webhook, so a
+// team sharing a watcher, e.g. `wind daemon` on a staging box, learns when
+// the branch stops building
+type NotificationsConfig struct {
+	// URL receives the notifications; empty turns them off. $NAME reads the
+	// URL from that environment variable, keeping it out of the config.
+	URL string
+	// Format is the payload: slack ({"text": ...}, the default), discord
+	// ({"content": ...}) or json (the notification's fields)
+	Format  string
+	Timeout time.Duration
+}
+
+// notification is what the json format sends; the chat formats send its
+// Text
+type notification struct {
+	Event   string `json:"event"`
+	Project string `json:"project"`
+	Host    string `json:"host,omitempty"`
+	Target  string `json:"target,omitempty"`
+	Build   int    `json:"build"`
+	// Summary is the error count or the failure of the build command
+	// (build_fail)
+	Summary string   `json:"summary,omitempty"`
+	Errors  []string `json:"errors,omitempty"`
+	// Failed is the number of failed builds before the recovery
+	// (build_recover)
+	Failed int    `json:"failed,omitempty"`
+	Text   string `json:"text"`
+}
+
+// validateNotifications checks the notification settings
+func validateNotifications(config NotificationsConfig) error {
+	switch config.Format {
+	case "", "slack", "discord", "json":
+	default:
+		return fmt.Errorf("invalid notifications.format %q (expected slack, discord or json)", config.Format)
+	}
+	if config.URL != "" && config.Timeout <= 0 {
+		return fmt.Errorf("notifications.timeout must be positive")
+	}
+	return nil
+}
+
+// webhookURL returns the configured URL, read from the environment for
+// $NAME
+func (config NotificationsConfig) webhookURL() string {
+	if name, ok := strings.CutPrefix(config.URL, "$"); ok {
+		return os.Getenv(name)
+	}
+	return config.URL
+}
+
+// newNotification describes an event of app's latest build
+func (app *WindApp) newNotification(name string) notification {
+	n := notification{
+		Event:   name,
+		Project: filepath.Base(projectRoot()),
+		Target:  app.name,
+		Build:   app.buildID,
+	}
+	n.Host, _ = os.Hostname()
+	return n
+}
+
+// describe renders the notification as a chat message
+func (n notification) describe() string {
+	where := n.Project
+	if n.Target != "" {
+		where += " (" + n.Target + ")"
+	}
+	if n.Host != "" {
+		where += " on " + n.Host
+	}
+
+	var b strings.Builder
+	switch n.Event {
+	case "build_fail":
+		fmt.Fprintf(&b, "🔴 Build #%d of %s failed: %s", n.Build, where, n.Summary)
+		if len(n.Errors) > 0 {
+			b.WriteString("\n```\n" + strings.Join(n.Errors, "\n") + "\n```")
+		}
+	case "build_recover":
+		fmt.Fprintf(&b, "✅ Build #%d of %s succeeded again after %s", n.Build, where, pluralize(n.Failed, "failed build"))
+	}
+	return b.String()
+}
+
+// payload encodes the notification in the configured format
+func (n notification) payload(format string) []byte {
+	n.Text = n.describe()
+	var data []byte
+	switch format {
+	case "discord":
+		data, _ = json.Marshal(map[string]string{"content": n.Text})
+	case "json":
+		data, _ = json.Marshal(n)
+	default:
+		data, _ = json.Marshal(map[string]string{"text": n.Text})
+	}
+	return data
+}
+
+// notify sends n in the background; a failing webhook only warns
+func (app *WindApp) notify(n notification) {
+	config := app.config.Notifications
+	url := config.webhookURL()
+	if url == "" {
+		return
+	}
+	body := n.payload(config.Format)
+	go func() {
+		client := &http.Client{Timeout: config.Timeout}
+		resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			logf(levelWarn, Yellow+"Warning: "+Reset+"%sFailed to send the %s notification: %v\n", app.label(), n.Event, err)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			logf(levelWarn, Yellow+"Warning: "+Reset+"%sThe webhook rejected the %s notification (%s)\n", app.label(), n.Event, resp.Status)
+		}
+	}()
+}
+
+// buildFailed counts a failed build and notifies the first one after a
+// good build; the failures that follow while it is being fixed are not news
+func (app *WindApp) buildFailed(summary string, errs []compileError) {
+	app.failedBuilds++
+	if app.failedBuilds > 1 {
+		return
+	}
+	n := app.newNotification("build_fail")
+	n.Summary = summary
+	for _, e := range errs[:min(len(errs), notifyErrorsShown)] {
+		n.Errors = append(n.Errors, e.location()+": "+e.Message)
+	}
+	if len(errs) > notifyErrorsShown {
+		n.Errors = append(n.Errors, fmt.Sprintf("… and %d more", len(errs)-notifyErrorsShown))
+	}
+	app.notify(n)
+}
+
+// buildSucceeded reports a build that succeeded after failed ones with the
+// build_recover event
+func (app *WindApp) buildSucceeded() {
+	if app.failedBuilds == 0 {
+		return
+	}
+	n := app.newNotification("build_recover")
+	n.Failed = app.failedBuilds
+	app.failedBuilds = 0
+	app.emit(event{Event: "build_recover", Build: app.buildID})
+	app.notify(n)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildNotifications(t *testing.T) {
+	received := make(chan map[string]any, 10)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		json.NewDecoder(r.Body).Decode(&payload)
+		received <- payload
+	}))
+	defer webhook.Close()
+
+	t.Setenv("WIND_TEST_WEBHOOK", webhook.URL)
+	config := defaultConfig()
+	config.Notifications.URL = "$WIND_TEST_WEBHOOK"
+	app := newWindApp(config, "api", "")
+	next := func() map[string]any {
+		select {
+		case payload := <-received:
+			return payload
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected a notification")
+			return nil
+		}
+	}
+
+	// A good build before any failure is not news
+	app.buildSucceeded()
+
+	app.buildID = 41
+	app.buildFailed("2 errors", []compileError{{File: "main.go", Line: 12, Col: 2, Message: "undefined: foo"}})
+	text, _ := next()["text"].(string)
+	if !strings.Contains(text, "Build #41") || !strings.Contains(text, "(api)") || !strings.Contains(text, "main.go:12:2: undefined: foo") {
+		t.Errorf("Unexpected failure notification %q", text)
+	}
+
+	// Failures while it is being fixed are not sent again
+	app.buildID = 42
+	app.buildFailed("1 error", nil)
+	app.buildID = 43
+	app.buildSucceeded()
+	text, _ = next()["text"].(string)
+	if !strings.Contains(text, "Build #43") || !strings.Contains(text, "after 2 failed builds") {
+		t.Errorf("Unexpected recovery notification %q", text)
+	}
+	select {
+	case payload := <-received:
+		t.Errorf("Unexpected notification %v", payload)
+	default:
+	}
+}
+
+func TestNotificationPayload(t *testing.T) {
+	n := notification{Event: "build_fail", Project: "shop", Build: 7, Summary: "exit status 2"}
+
+	var discord map[string]string
+	json.Unmarshal(n.payload("discord"), &discord)
+	if discord["content"] != "🔴 Build #7 of shop failed: exit status 2" {
+		t.Errorf("Unexpected discord payload %v", discord)
+	}
+
+	var fields notification
+	json.Unmarshal(n.payload("json"), &fields)
+	if fields.Event != "build_fail" || fields.Build != 7 || fields.Text == "" {
+		t.Errorf("Unexpected json payload %+v", fields)
+	}
+
+	if err := validateNotifications(NotificationsConfig{Format: "teams"}); err == nil {
+		t.Error("Expected an unknown format to be rejected")
+	}
+}
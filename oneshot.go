@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// exitStatus is the exit status of commands reporting a result, such as
+// wind build in CI. main exits with it once the deferred cleanup of the
+// command has run.
+var exitStatus int
+
+// commandExitCode returns the exit status of a failed command, 1 when it
+// did not get to exit
+func commandExitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return exitErr.ExitCode()
+	}
+	return 1
+}
+
+// generateAll runs every generator once. A one-shot build has no changes to
+// match, and the generated files may be missing from a fresh checkout.
+func (app *WindApp) generateAll() bool {
+	for _, rule := range app.config.Generators {
+		logf(levelInfo, app.label()+Cyan+"⚙️  Running %s..."+Reset+"\n", rule.Command)
+		cmd := exec.Command("sh", "-c", rule.Command)
+		cmd.Stdout = app.output(os.Stdout)
+		cmd.Stderr = app.output(os.Stderr)
+		if err := cmd.Run(); err != nil {
+			logf(levelError, Red+"Error: "+Reset+"%sGenerator %q failed: %v\n", app.label(), rule.Command, err)
+			return false
+		}
+	}
+	return true
+}
+
+// runBuild implements `wind build [target]`: every target is built once,
+// as the watcher would, and the exit status is the failed build's
+func runBuild(opts watchOptions, args []string) {
+	if len(args) > 0 {
+		opts.target = args[0]
+	}
+	exitStatus = 1
+	config, ok := loadWatchConfig(opts)
+	if !ok {
+		return
+	}
+	apps, _, ok := createApps(&config, opts)
+	if !ok {
+		return
+	}
+	if err := os.MkdirAll("tmp", 0755); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Failed to create tmp directory: %v\n", err)
+		return
+	}
+
+	for _, app := range apps {
+		if !app.generateAll() {
+			return
+		}
+		if !app.build() {
+			exitStatus = app.buildExitCode
+			return
+		}
+	}
+	exitStatus = 0
+}
+
+// runCheck implements `wind check [target]`: it detects the project and
+// validates the config like the watcher, then prints what each target would
+// build, run and watch without building anything
+func runCheck(opts watchOptions, args []string) {
+	if len(args) > 0 {
+		opts.target = args[0]
+	}
+	exitStatus = 1
+	config, ok := loadWatchConfig(opts)
+	if !ok {
+		return
+	}
+	apps, _, ok := createApps(&config, opts)
+	if !ok {
+		return
+	}
+	shareArtifacts(apps)
+
+	width := terminalWidth()
+	for _, app := range apps {
+		var watched []string
+		if err := app.walkWatched(func(path string, info os.FileInfo, err error) error {
+			if !info.IsDir() && app.shouldWatch(path) {
+				watched = append(watched, path)
+			}
+			return nil
+		}); err != nil {
+			logf(levelError, Red+"Error: "+Reset+"%sFailed to scan files: %v\n", app.label(), err)
+			return
+		}
+
+		fmt.Println()
+		if app.name != "" {
+			fmt.Printf(Yellow+"%s"+Reset+"\n", app.name)
+		}
+		rows := newTable("  ")
+		rows.addRow("Build", app.buildCommand())
+		rows.addRow("Run", app.config.RunCmd)
+		if app.config.Watch != "" {
+			rows.addRow("Watch", app.config.Watch)
+		} else {
+			rows.addRow("Extensions", strings.Join(app.config.IncludeExts, " "))
+		}
+		rows.addRow("Excluded", strings.Join(app.config.ExcludeDirs, " "))
+		rows.addRow("Watched", pluralize(len(watched), "file")+" in "+strings.Join(app.roots, " "))
+		fmt.Print(rows.render(width))
+		if app.config.Verbose {
+			for _, path := range watched {
+				fmt.Println("    " + filepath.ToSlash(path))
+			}
+		}
+	}
+
+	fmt.Println()
+	logf(levelSuccess, Green+"Success: "+Reset+"Config is valid\n")
+	exitStatus = 0
+}
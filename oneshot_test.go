@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestCommandExitCode(t *testing.T) {
+	if got := commandExitCode(exec.Command("sh", "-c", "exit 3").Run()); got != 3 {
+		t.Errorf("commandExitCode(exit 3) = %d, expected 3", got)
+	}
+	if got := commandExitCode(exec.Command("/nonexistent/binary").Run()); got != 1 {
+		t.Errorf("commandExitCode(not started) = %d, expected 1", got)
+	}
+}
+
+func TestRunBuildExitStatus(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	defer func() { exitStatus = 0 }()
+
+	os.WriteFile(configFileName, []byte("buildCmd: exit 3\nrunCmd: ./tmp/main\n"), 0644)
+	runBuild(watchOptions{}, nil)
+	if exitStatus != 3 {
+		t.Errorf("Expected the build's exit status 3, got %d", exitStatus)
+	}
+
+	os.WriteFile(configFileName, []byte("buildCmd: test -f generated\nrunCmd: ./tmp/main\ngenerators:\n  - pattern: '*.proto'\n    command: touch generated\n"), 0644)
+	runBuild(watchOptions{}, nil)
+	if exitStatus != 0 {
+		t.Errorf("Expected generators to run before a successful build, got exit status %d", exitStatus)
+	}
+}
+
+func TestRunCheckExitStatus(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	defer func() { exitStatus = 0 }()
+
+	os.WriteFile(configFileName, []byte("buildCmd: go build -o ./tmp/main .\nrunCmd: ./tmp/main\n"), 0644)
+	os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644)
+	runCheck(watchOptions{}, nil)
+	if exitStatus != 0 {
+		t.Errorf("Expected a valid config to exit with 0, got %d", exitStatus)
+	}
+	if _, err := os.Stat("tmp/main"); !os.IsNotExist(err) {
+		t.Error("Expected wind check not to build")
+	}
+
+	os.WriteFile(configFileName, []byte("pollInterval: soon\n"), 0644)
+	runCheck(watchOptions{}, nil)
+	if exitStatus != 1 {
+		t.Errorf("Expected an invalid config to exit with 1, got %d", exitStatus)
+	}
+}
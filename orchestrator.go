@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProcessConfig declares one of several binaries supervised together
+type ProcessConfig struct {
+	Name string
+	// Target builds a detected main package (see `wind targets`) into
+	// ./tmp/<name> and runs it, unless BuildCmd/RunCmd are given
+	Target   string
+	BuildCmd string
+	RunCmd   string
+	// Watch limits restarts to changes under these paths; empty means
+	// every watched file
+	Watch []string
+	Color string
+	// HealthCheckURL overrides the shared readiness check for this process
+	HealthCheckURL string
+	// Kind is "service" (the default) or "test": an end-to-end suite that
+	// runs RunCmd to completion once the services in DependsOn are ready,
+	// and again whenever one of them restarts
+	Kind      string
+	DependsOn []string
+}
+
+// orchestrator owns the per-target supervisors of a Wind session
+type orchestrator struct {
+	apps     []*WindApp
+	quitChan chan struct{}
+	// keys maps each bound key to its action (see bindKeys)
+	keys map[byte]string
+	// testing is set while a run-tests key press is being served
+	testing atomic.Bool
+	// suites are the end-to-end suites of test processes
+	suites []*e2eSuite
+}
+
+func newOrchestrator(apps []*WindApp) *orchestrator {
+	keys, _ := bindKeys(nil)
+	return &orchestrator{
+		apps:     apps,
+		quitChan: make(chan struct{}, 1),
+		keys:     keys,
+	}
+}
+
+// newWindApp creates a supervisor for one build/run target. name is empty
+// for the usual single-target session.
+func newWindApp(config WindConfig, name, color string) *WindApp {
+	app := &WindApp{
+		config:      config,
+		name:        name,
+		color:       color,
+		fileStates:  make(map[string]time.Time),
+		fileHashes:  make(map[string]string),
+		stopChan:    make(chan bool),
+		rebuildChan: make(chan struct{}, 1),
+		logFilter:   newLogFilter(config.LogFilter),
+	}
+	// The expression was validated with the config
+	if config.Watch != "" {
+		app.watchFilter, _ = compileWatchExpr(config.Watch)
+	}
+	return app
+}
+
+// newSupervisors builds one WindApp per configured process, each inheriting
+// the shared settings of config
+func newSupervisors(config WindConfig) ([]*WindApp, error) {
+	var apps []*WindApp
+	seen := map[string]bool{}
+
+	for i, p := range config.Processes {
+		if p.Name == "" {
+			return nil, fmt.Errorf("processes[%d]: name is required", i)
+		}
+		if seen[p.Name] {
+			return nil, fmt.Errorf("processes[%d]: duplicate name %q", i, p.Name)
+		}
+		seen[p.Name] = true
+
+		switch p.Kind {
+		case "", "service":
+		case processKindTest:
+			// Test processes are run by newE2ESuites
+			continue
+		default:
+			return nil, fmt.Errorf("process %s: invalid kind %q (expected service or test)", p.Name, p.Kind)
+		}
+
+		cfg := config
+		cfg.Processes = nil
+		cfg.BuildCmd = p.BuildCmd
+		cfg.RunCmd = p.RunCmd
+		cfg.WatchPaths = p.Watch
+		if p.HealthCheckURL != "" {
+			cfg.HealthCheckURL = p.HealthCheckURL
+		}
+
+		if p.Target != "" {
+			t, err := findTarget(p.Target)
+			if err != nil {
+				return nil, fmt.Errorf("process %s: %v", p.Name, err)
+			}
+			binary := "./" + filepath.ToSlash(filepath.Join("tmp", p.Name))
+			if cfg.BuildCmd == "" {
+				cfg.BuildCmd = fmt.Sprintf("go build -o %s %s", binary, t.Path)
+			}
+			if cfg.RunCmd == "" {
+				cfg.RunCmd = binary
+			}
+		}
+		if cfg.BuildCmd == "" || cfg.RunCmd == "" {
+			return nil, fmt.Errorf("process %s: needs a target or both buildCmd and runCmd", p.Name)
+		}
+
+		color := processColors[i%len(processColors)]
+		if p.Color != "" {
+			c, ok := colorNames[strings.ToLower(p.Color)]
+			if !ok {
+				return nil, fmt.Errorf("process %s: unknown color %q", p.Name, p.Color)
+			}
+			color = c
+		}
+
+		apps = append(apps, newWindApp(cfg, p.Name, color))
+	}
+
+	return apps, nil
+}
+
+// label prefixes Wind's messages about a process in multi-process mode
+func (app *WindApp) label() string {
+	if app.name == "" {
+		return ""
+	}
+	return app.color + "[" + app.name + "]" + Reset + " "
+}
+
+// output wraps a child output stream with the process prefix in
+// multi-process mode
+func (app *WindApp) output(w io.Writer) io.Writer {
+	if app.name == "" {
+		return w
+	}
+	return newPrefixWriter(w, app.label())
+}
+
+// inWatchPaths reports whether path lies under one of the configured
+// WatchPaths. Without WatchPaths every path qualifies.
+func (app *WindApp) inWatchPaths(path string) bool {
+	if len(app.config.WatchPaths) == 0 {
+		return true
+	}
+	path = projectPath(path)
+	for _, dir := range app.config.WatchPaths {
+		if underDir(path, projectPath(dir)) {
+			return true
+		}
+	}
+	return false
+}
+
+// start scans, builds and runs every target, then watches each one
+// independently so a change only restarts the processes it is relevant to
+func (o *orchestrator) start() {
+	for _, app := range o.apps {
+		app.scanFiles()
+		app.emit(event{Event: "scan", Files: len(app.fileStates)})
+		if len(app.config.Mocks.Packages) > 0 {
+			app.initMockFingerprints()
+		}
+		app.buildAndRun()
+	}
+	for _, app := range o.apps {
+		go app.watchFiles()
+	}
+}
+
+// stop shuts every target down in parallel
+func (o *orchestrator) stop() {
+	for _, suite := range o.suites {
+		suite.stop()
+	}
+	var wg sync.WaitGroup
+	for _, app := range o.apps {
+		wg.Add(1)
+		go func(app *WindApp) {
+			defer wg.Done()
+			close(app.stopChan)
+			app.cleanup()
+		}(app)
+	}
+	wg.Wait()
+}
+
+// requestQuit asks runWatcher to shut down as if interrupted
+func (o *orchestrator) requestQuit() {
+	select {
+	case o.quitChan <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := newPrefixWriter(&buf, "[api] ")
+
+	// Lines split across writes get a single prefix
+	w.Write([]byte("listening"))
+	w.Write([]byte(" on :8080\nready\n"))
+	w.Write([]byte("partial"))
+
+	expected := "[api] listening on :8080\n[api] ready\n[api] partial"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestNewSupervisors(t *testing.T) {
+	tmpDir := createTempProject(t, "cmd-api")
+	defer os.RemoveAll(tmpDir)
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+
+	config := defaultConfig()
+	config.Processes = []ProcessConfig{
+		{Name: "api", Target: "api", Watch: []string{"cmd/api", "internal"}},
+		{Name: "consumer", BuildCmd: "go build -o ./tmp/consumer ./cmd/consumer", RunCmd: "./tmp/consumer", Color: "yellow"},
+	}
+
+	apps, err := newSupervisors(config)
+	if err != nil {
+		t.Fatalf("newSupervisors failed: %v", err)
+	}
+	if len(apps) != 2 {
+		t.Fatalf("Expected 2 supervisors, got %d", len(apps))
+	}
+
+	api := apps[0]
+	if api.config.BuildCmd != "go build -o ./tmp/api ./cmd/api" || api.config.RunCmd != "./tmp/api" {
+		t.Errorf("Unexpected api commands: %q / %q", api.config.BuildCmd, api.config.RunCmd)
+	}
+	if apps[1].color != Yellow {
+		t.Errorf("Expected configured color for consumer")
+	}
+
+	// Watch paths scope which changes restart a process
+	if !api.shouldWatch(filepath.Join("cmd", "api", "main.go")) {
+		t.Error("api should watch cmd/api")
+	}
+	if api.shouldWatch(filepath.Join("cmd", "worker", "main.go")) {
+		t.Error("api should not watch cmd/worker")
+	}
+	if !apps[1].shouldWatch(filepath.Join("cmd", "worker", "main.go")) {
+		t.Error("consumer without watch paths should watch everything")
+	}
+}
+
+func TestNewSupervisorsInvalid(t *testing.T) {
+	tests := [][]ProcessConfig{
+		{{Name: ""}},
+		{{Name: "api", RunCmd: "./tmp/api"}},
+		{{Name: "a", BuildCmd: "true", RunCmd: "true"}, {Name: "a", BuildCmd: "true", RunCmd: "true"}},
+		{{Name: "a", BuildCmd: "true", RunCmd: "true", Color: "octarine"}},
+	}
+
+	for _, processes := range tests {
+		config := defaultConfig()
+		config.Processes = processes
+		if _, err := newSupervisors(config); err == nil {
+			t.Errorf("Expected an error for %+v", processes)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// colorNames maps config color names to ANSI codes
+var colorNames = map[string]string{
+	"red":    Red,
+	"green":  Green,
+	"yellow": Yellow,
+	"blue":   Blue,
+	"purple": Purple,
+	"cyan":   Cyan,
+	"white":  White,
+}
+
+// processColors are assigned in order to processes without a configured color
+var processColors = []string{Cyan, Purple, Blue, Green, Yellow, White}
+
+// outputMutex serializes writes from concurrent processes so prefixed
+// lines are never interleaved mid-line
+var outputMutex sync.Mutex
+
+// prefixWriter prefixes every line written through it
+type prefixWriter struct {
+	prefix      func() string
+	w           io.Writer
+	atLineStart bool
+}
+
+func newPrefixWriter(w io.Writer, prefix string) *prefixWriter {
+	return newPrefixFuncWriter(w, func() string { return prefix })
+}
+
+// newPrefixFuncWriter prefixes every line with the result of calling prefix
+// when the line starts
+func newPrefixFuncWriter(w io.Writer, prefix func() string) *prefixWriter {
+	return &prefixWriter{prefix: prefix, w: w, atLineStart: true}
+}
+
+// Timestamp formats for the Timestamps option
+const (
+	TimestampLocal    = "local"
+	TimestampUTC      = "utc"
+	TimestampRelative = "relative"
+)
+
+// newTimestampWriter prefixes every line with the time it was written:
+// local or UTC wall-clock time, the time since start, or any other format
+// as a Go time layout
+func newTimestampWriter(w io.Writer, format string, start time.Time) *prefixWriter {
+	stamp := func() string {
+		switch format {
+		case "", TimestampLocal:
+			return time.Now().Format("15:04:05.000")
+		case TimestampUTC:
+			return time.Now().UTC().Format("15:04:05.000Z")
+		case TimestampRelative:
+			return fmt.Sprintf("+%.3fs", time.Since(start).Seconds())
+		}
+		return time.Now().Format(format)
+	}
+	return newPrefixFuncWriter(w, func() string { return "[" + stamp() + "] " })
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	outputMutex.Lock()
+	defer outputMutex.Unlock()
+
+	var buf bytes.Buffer
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		if p.atLineStart {
+			buf.WriteString(p.prefix())
+		}
+		buf.Write(line)
+		p.atLineStart = line[len(line)-1] == '\n'
+	}
+
+	if _, err := p.w.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// runOutput wraps an output stream of the run command: dropped while hidden
+// with the toggle-logs key, and prefixed with the time since the last
+// restart with SinceRestart, the process prefix in multi-process mode and
+// the stream tag with StreamTags. Prefix writers share outputMutex, so the
+// prefixes are written by a single one. JSON log lines are formatted first
+// with PrettyLogs, after the lines the log filter hides are dropped.
+func (app *WindApp) runOutput(w io.Writer) io.Writer {
+	tag := ""
+	if app.config.StreamTags {
+		tag = streamTag(w == io.Writer(os.Stderr))
+	}
+	w = hideableWriter{w: w, hidden: &app.logsHidden}
+	if app.config.SinceRestart || tag != "" {
+		w = newPrefixFuncWriter(w, func() string {
+			prefix := app.label() + tag
+			if app.config.SinceRestart {
+				started := time.Unix(0, app.startedAt.Load())
+				prefix = fmt.Sprintf(Purple+"+%.1fs"+Reset+" ", time.Since(started).Seconds()) + prefix
+			}
+			return prefix
+		})
+	} else {
+		w = app.output(w)
+	}
+	if app.config.PrettyLogs.Enabled {
+		w = newPrettyLogWriter(w, app.config.PrettyLogs, &app.rawLogs)
+	}
+	if app.logFilter != nil {
+		w = &logFilterWriter{w: w, filter: app.logFilter}
+	}
+	return w
+}
+
+// streamTag tells the application's standard output and error apart with
+// StreamTags
+func streamTag(stderr bool) string {
+	if stderr {
+		return Red + "[err]" + Reset + " "
+	}
+	return Green + "[app]" + Reset + " "
+}
+
+// hideableWriter discards writes while hidden is set
+type hideableWriter struct {
+	w      io.Writer
+	hidden *atomic.Bool
+}
+
+func (h hideableWriter) Write(data []byte) (int, error) {
+	if h.hidden.Load() {
+		return len(data), nil
+	}
+	return h.w.Write(data)
+}
+
+// outputRedirect routes os.Stdout and os.Stderr through pipes, so output of
+// Wind and of the child processes it starts afterwards can be rewritten
+type outputRedirect struct {
+	stdout *os.File
+	stderr *os.File
+	pipes  []*os.File
+	wg     sync.WaitGroup
+}
+
+// redirectOutput replaces os.Stdout and os.Stderr with pipes whose contents
+// are copied to wrap(original stream)
+func redirectOutput(wrap func(dst *os.File) io.Writer) (*outputRedirect, error) {
+	o := &outputRedirect{stdout: os.Stdout, stderr: os.Stderr}
+	stdout, err := o.pipe(wrap(os.Stdout))
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := o.pipe(wrap(os.Stderr))
+	if err != nil {
+		o.restore()
+		return nil, err
+	}
+	os.Stdout, os.Stderr = stdout, stderr
+	log.SetOutput(os.Stderr)
+	return o, nil
+}
+
+func (o *outputRedirect) pipe(dst io.Writer) (*os.File, error) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	o.pipes = append(o.pipes, pw)
+
+	o.wg.Add(1)
+	go func() {
+		defer o.wg.Done()
+		defer pr.Close()
+		io.Copy(dst, pr)
+	}()
+	return pw, nil
+}
+
+// restore puts the original streams back once the pipes are drained.
+// Output of processes that outlive the session is not waited for
+// indefinitely.
+func (o *outputRedirect) restore() {
+	os.Stdout, os.Stderr = o.stdout, o.stderr
+	log.SetOutput(os.Stderr)
+	for _, pipe := range o.pipes {
+		pipe.Close()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		o.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimestampWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := newTimestampWriter(&buf, TimestampRelative, time.Now())
+
+	// Partial writes only get a prefix at the start of each line
+	w.Write([]byte("Building"))
+	w.Write([]byte("...\nStarted\n"))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	pattern := regexp.MustCompile(`^\[\+\d+\.\d{3}s\] (Building\.\.\.|Started)$`)
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", buf.String())
+	}
+	for _, line := range lines {
+		if !pattern.MatchString(line) {
+			t.Errorf("Line %q is not prefixed with a relative timestamp", line)
+		}
+	}
+}
+
+func TestTimestampFormats(t *testing.T) {
+	tests := []struct {
+		format  string
+		pattern string
+	}{
+		{"", `^\[\d{2}:\d{2}:\d{2}\.\d{3}\] hi\n$`},
+		{TimestampUTC, `^\[\d{2}:\d{2}:\d{2}\.\d{3}Z\] hi\n$`},
+		{"2006-01-02", `^\[\d{4}-\d{2}-\d{2}\] hi\n$`},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		newTimestampWriter(&buf, tt.format, time.Now()).Write([]byte("hi\n"))
+		if !regexp.MustCompile(tt.pattern).MatchString(buf.String()) {
+			t.Errorf("Format %q produced %q", tt.format, buf.String())
+		}
+	}
+}
+
+func TestRunOutputSinceRestart(t *testing.T) {
+	var buf bytes.Buffer
+	app := newWindApp(WindConfig{SinceRestart: true}, "", "")
+	app.startedAt.Store(time.Now().Add(-1500 * time.Millisecond).UnixNano())
+
+	app.runOutput(&buf).Write([]byte("listening on :8080\n"))
+	expected := Purple + "+1.5s" + Reset + " listening on :8080\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+
+	buf.Reset()
+	plain := newWindApp(WindConfig{}, "", "")
+	plain.runOutput(&buf).Write([]byte("listening on :8080\n"))
+	if buf.String() != "listening on :8080\n" {
+		t.Errorf("Output should be unchanged without SinceRestart, got %q", buf.String())
+	}
+}
+
+func TestRunOutputStreamTags(t *testing.T) {
+	stderr, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	originalStderr := os.Stderr
+	os.Stderr = stderr
+	defer func() { os.Stderr = originalStderr }()
+
+	app := newWindApp(WindConfig{StreamTags: true}, "api", Cyan)
+	var buf bytes.Buffer
+	app.runOutput(&buf).Write([]byte("listening on :8080\n"))
+	expected := Cyan + "[api]" + Reset + " " + Green + "[app]" + Reset + " listening on :8080\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+
+	app.runOutput(os.Stderr).Write([]byte("panic: boom\n"))
+	data, _ := os.ReadFile(stderr.Name())
+	expected = Cyan + "[api]" + Reset + " " + Red + "[err]" + Reset + " panic: boom\n"
+	if string(data) != expected {
+		t.Errorf("Expected %q, got %q", expected, data)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// errorOverlayPath is the Server-Sent Events endpoint the error overlay
+// waits on for the next successful build
+const errorOverlayPath = "/__wind/build"
+
+// buildFailure is the failed build the proxy shows to browsers instead of
+// the previous process
+type buildFailure struct {
+	Build  int
+	Errors []compileError
+	// Other holds output that is not a compiler diagnostic, e.g. from
+	// custom build commands
+	Other []string
+}
+
+// errorOverlay renders a buildFailure. The page reloads itself once the
+// build is fixed; the EventSource reconnects on its own if the proxy goes
+// away.
+var errorOverlay = template.Must(template.New("overlay").Funcs(template.FuncMap{
+	"location":      compileError.location,
+	"errorColor":    func() template.CSS { return activePalette.errorCSS },
+	"locationColor": func() template.CSS { return activePalette.locationCSS },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Build #{{.Build}} failed</title>
+<style>
+body{margin:0;background:#181818;color:#e8e8e8;font:14px/1.5 ui-monospace,Menlo,Consolas,monospace}
+main{max-width:960px;margin:40px auto;padding:24px;border-top:4px solid {{errorColor}};background:#222}
+h1{margin:0 0 16px;font-size:18px;color:{{errorColor}}}
+.loc{color:{{locationColor}}}
+pre{margin:0 0 12px;white-space:pre-wrap}
+footer{margin-top:24px;color:#888}
+</style>
+</head>
+<body>
+<main>
+<h1>Build #{{.Build}} failed{{with .Errors}}: {{len .}} error(s){{end}}</h1>
+{{range .Errors}}<pre><span class="loc">{{location .}}</span>  {{.Message}}</pre>
+{{end}}{{with .Other}}<pre>{{range .}}{{.}}
+{{end}}</pre>
+{{end}}<footer>Wind reloads this page after the next successful build.</footer>
+</main>
+<script>(function(){var s=new EventSource("` + errorOverlayPath + `");s.addEventListener("fixed",function(){location.reload()})})();</script>
+</body>
+</html>
+`))
+
+// setBuildFailure makes the proxy answer page loads with the overlay for f
+// until clearBuildFailure
+func (p *proxyMode) setBuildFailure(f *buildFailure) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.failure = f
+	if p.fixed == nil {
+		p.fixed = make(chan struct{})
+	}
+}
+
+// clearBuildFailure removes the overlay and reloads the pages showing it
+func (p *proxyMode) clearBuildFailure() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.fixed != nil {
+		close(p.fixed)
+		p.fixed = nil
+	}
+	p.failure = nil
+}
+
+func (p *proxyMode) buildFailure() (*buildFailure, <-chan struct{}) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.failure, p.fixed
+}
+
+// wantsPage reports whether r is a browser loading a page, as opposed to an
+// API call or asset request that should keep reaching the previous process
+func wantsPage(r *http.Request) bool {
+	return (r.Method == http.MethodGet || r.Method == http.MethodHead) &&
+		strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
+// serveOverlay writes the error overlay for f
+func serveOverlay(w http.ResponseWriter, f *buildFailure) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusInternalServerError)
+	errorOverlay.Execute(w, f)
+}
+
+// serveBuildEvents tells an overlay page when the build is fixed
+func (p *proxyMode) serveBuildEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	if _, fixed := p.buildFailure(); fixed != nil {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-fixed:
+		}
+	}
+	fmt.Fprint(w, "event: fixed\ndata: {}\n\n")
+	flusher.Flush()
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProxyErrorOverlay(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "previous")
+	}))
+	defer backend.Close()
+	u, _ := url.Parse(backend.URL)
+	port, _ := strconv.Atoi(u.Port())
+
+	p := newProxyMode(ProxyConfig{HoldTimeout: time.Second})
+	p.switchTo(port)
+	proxy := httptest.NewServer(p)
+	defer proxy.Close()
+
+	get := func(accept string) (int, string) {
+		req, _ := http.NewRequest(http.MethodGet, proxy.URL, nil)
+		req.Header.Set("Accept", accept)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request through proxy failed: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	p.setBuildFailure(&buildFailure{
+		Build:  3,
+		Errors: []compileError{{File: "main.go", Line: 12, Col: 5, Message: "undefined: <db>"}},
+	})
+
+	// Page loads get the overlay, with messages escaped
+	status, body := get("text/html,application/xhtml+xml")
+	if status != http.StatusInternalServerError {
+		t.Errorf("Expected status 500 for the overlay, got %d", status)
+	}
+	for _, want := range []string{"Build #3 failed", "main.go:12:5", "undefined: &lt;db&gt;", errorOverlayPath} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected overlay to contain %q, got:\n%s", want, body)
+		}
+	}
+
+	// Everything else keeps reaching the previous process
+	if _, body := get("application/json"); body != "previous" {
+		t.Errorf("Expected API requests to reach the previous process, got %q", body)
+	}
+
+	// An open overlay learns about the fix and pages are served again
+	resp, err := http.Get(proxy.URL + errorOverlayPath)
+	if err != nil {
+		t.Fatalf("Failed to open build events: %v", err)
+	}
+	defer resp.Body.Close()
+	fixed := make(chan bool)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if scanner.Text() == "event: fixed" {
+				fixed <- true
+				return
+			}
+		}
+		fixed <- false
+	}()
+
+	p.clearBuildFailure()
+	select {
+	case ok := <-fixed:
+		if !ok {
+			t.Error("Expected a fixed event")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the fixed event")
+	}
+	if _, body := get("text/html"); body != "previous" {
+		t.Errorf("Expected pages to reach the app after the fix, got %q", body)
+	}
+}
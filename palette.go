@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"sort"
+	"strings"
+)
+
+// paletteEnv selects a palette for every command, overriding the config
+const paletteEnv = "WIND_PALETTE"
+
+// palette is a set of colors for terminal output and the pages Wind injects
+// into the browser
+type palette struct {
+	red, green, yellow, blue, purple, cyan, white string
+
+	// errorCSS and locationCSS color the build error overlay
+	errorCSS, locationCSS template.CSS
+	// buildingCSS and failedCSS are the dev banner's backgrounds
+	buildingCSS, failedCSS string
+}
+
+// palettes are selected by name with the palette setting. The colorblind
+// palettes follow Okabe and Ito: errors are vermillion rather than red,
+// successes blue rather than green, so the two never depend on telling red
+// from green.
+var palettes = map[string]palette{
+	"default": {
+		red: "\033[31m", green: "\033[32m", yellow: "\033[33m", blue: "\033[34m",
+		purple: "\033[35m", cyan: "\033[36m", white: "\033[37m",
+		errorCSS: "#ff6369", locationCSS: "#f5d90a",
+		buildingCSS: "rgba(160,110,0,.9)", failedCSS: "rgba(180,30,40,.9)",
+	},
+	"deuteranopia": {
+		red: "\033[38;5;166m", green: "\033[38;5;32m", yellow: "\033[38;5;220m", blue: "\033[38;5;25m",
+		purple: "\033[38;5;175m", cyan: "\033[38;5;74m", white: "\033[37m",
+		errorCSS: "#d55e00", locationCSS: "#f0e442",
+		buildingCSS: "rgba(0,114,178,.9)", failedCSS: "rgba(213,94,0,.9)",
+	},
+	// Protanopes see red as dark, so errors are also bold and brighter
+	"protanopia": {
+		red: "\033[1;38;5;208m", green: "\033[38;5;33m", yellow: "\033[38;5;227m", blue: "\033[38;5;25m",
+		purple: "\033[38;5;183m", cyan: "\033[38;5;117m", white: "\033[37m",
+		errorCSS: "#e69f00", locationCSS: "#f0e442",
+		buildingCSS: "rgba(0,114,178,.9)", failedCSS: "rgba(200,120,0,.9)",
+	},
+}
+
+// activePalette colors the browser pages; terminal output reads the color
+// variables directly
+var activePalette = palettes["default"]
+
+// paletteNames lists the palettes for error messages
+func paletteNames() string {
+	names := make([]string, 0, len(palettes))
+	for name := range palettes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// validatePalette checks a palette setting; empty is the default
+func validatePalette(name string) error {
+	if _, ok := palettes[strings.ToLower(name)]; name != "" && !ok {
+		return fmt.Errorf("invalid palette %q (expected %s)", name, paletteNames())
+	}
+	return nil
+}
+
+// applyPalette switches every color to the named palette. WIND_PALETTE,
+// when set, wins over name.
+func applyPalette(name string) {
+	if env := os.Getenv(paletteEnv); env != "" {
+		name = env
+	}
+	p, ok := palettes[strings.ToLower(name)]
+	if !ok {
+		if name != "" {
+			logf(levelWarn, Yellow+"Warning: "+Reset+"Unknown palette %q (expected %s), using the default\n", name, paletteNames())
+		}
+		p = palettes["default"]
+	}
+	activePalette = p
+
+	Red, Green, Yellow, Blue, Purple, Cyan, White = p.red, p.green, p.yellow, p.blue, p.purple, p.cyan, p.white
+	colorNames = map[string]string{
+		"red":    Red,
+		"green":  Green,
+		"yellow": Yellow,
+		"blue":   Blue,
+		"purple": Purple,
+		"cyan":   Cyan,
+		"white":  White,
+	}
+	processColors = []string{Cyan, Purple, Blue, Green, Yellow, White}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePalette(t *testing.T) {
+	for _, name := range []string{"", "default", "deuteranopia", "Protanopia"} {
+		if err := validatePalette(name); err != nil {
+			t.Errorf("validatePalette(%q) = %v", name, err)
+		}
+	}
+	if err := validatePalette("sepia"); err == nil || !strings.Contains(err.Error(), "deuteranopia") {
+		t.Errorf("validatePalette(sepia) = %v, want an error listing the palettes", err)
+	}
+}
+
+func TestApplyPalette(t *testing.T) {
+	t.Setenv(paletteEnv, "")
+	defer applyPalette("default")
+
+	applyPalette("deuteranopia")
+	p := palettes["deuteranopia"]
+	if Red != p.red || Green != p.green {
+		t.Errorf("colors not switched: Red=%q Green=%q", Red, Green)
+	}
+	if colorNames["red"] != p.red || processColors[0] != p.cyan {
+		t.Error("process colors not switched")
+	}
+	if !strings.Contains(devBanner(1), p.failedCSS) {
+		t.Error("dev banner does not use the palette")
+	}
+	var page strings.Builder
+	if err := errorOverlay.Execute(&page, &buildFailure{Build: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(page.String(), "color:"+string(p.errorCSS)) {
+		t.Errorf("overlay does not use the palette:\n%s", page.String())
+	}
+
+	// The environment wins over the config
+	t.Setenv(paletteEnv, "protanopia")
+	applyPalette("deuteranopia")
+	if Red != palettes["protanopia"].red {
+		t.Errorf("%s not applied", paletteEnv)
+	}
+
+	t.Setenv(paletteEnv, "")
+	applyPalette("")
+	if Red != "\033[31m" {
+		t.Errorf("default palette not restored, Red=%q", Red)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Paths are normalized to one form before they are stored, compared or
+// shown: relative to the project root, cleaned and slash-separated, e.g.
+// "internal/store/store.go". Files of modules outside the project keep a
+// leading "../".
+
+// projectRoot returns the project directory with symlinks resolved, so paths
+// reported by tools such as go list compare equal however Wind was invoked
+func projectRoot() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "."
+	}
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		return real
+	}
+	return dir
+}
+
+// projectPath normalizes a relative or absolute path to project-relative,
+// slash-separated form
+func projectPath(path string) string {
+	if filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(projectRoot(), resolveSymlinks(path)); err == nil {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// resolveSymlinks resolves the symlinks of an absolute path. Files that no
+// longer exist, such as deleted ones, are resolved through their nearest
+// existing parent.
+func resolveSymlinks(path string) string {
+	rest := ""
+	for dir := filepath.Clean(path); ; dir = filepath.Dir(dir) {
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(real, rest)
+		}
+		if dir == filepath.Dir(dir) {
+			return path
+		}
+		rest = filepath.Join(filepath.Base(dir), rest)
+	}
+}
+
+// underDir reports whether the normalized path is dir or lies inside it
+func underDir(path, dir string) bool {
+	return dir == "." || path == dir || strings.HasPrefix(path, dir+"/")
+}
+
+// isExcluded reports whether the normalized path lies in an excluded
+// directory. A plain name such as "tmp" excludes directories with that name
+// at any depth, but not "tmpl" or "tmp.go"; an entry containing a slash
+// excludes that project-relative directory.
+func isExcluded(path string, excludes []string) bool {
+	segments := strings.Split(path, "/")
+	for _, exclude := range excludes {
+		exclude = projectPath(exclude)
+		if strings.Contains(exclude, "/") {
+			if exclude != "." && underDir(path, exclude) {
+				return true
+			}
+			continue
+		}
+		for _, segment := range segments {
+			if segment == exclude {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// projectMarkers identify a project root directory
+var projectMarkers = []string{configFileName, "go.mod", ".git"}
+
+// findProjectRoot walks up from dir to the nearest directory containing
+// .wind.yaml, go.mod or .git. It reports false when there is none.
+func findProjectRoot(dir string) (string, bool) {
+	for {
+		for _, marker := range projectMarkers {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				return dir, true
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// enterProjectRoot changes to the project root when Wind is started from one
+// of its subdirectories, so the whole project is watched and built
+func enterProjectRoot() error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	root, ok := findProjectRoot(dir)
+	if !ok || root == dir {
+		return nil
+	}
+	if err := os.Chdir(root); err != nil {
+		return err
+	}
+	logf(levelInfo, Cyan+"Info: "+Reset+"Using project root %s\n", root)
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProjectPath(t *testing.T) {
+	base := t.TempDir()
+	project := filepath.Join(base, "project")
+	os.MkdirAll(filepath.Join(project, "internal"), 0755)
+	link := filepath.Join(base, "link")
+	if err := os.Symlink(project, link); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	// Enter the project through the symlink, as a shell alias might
+	if err := os.Chdir(link); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	tests := map[string]string{
+		"main.go":                               "main.go",
+		"./internal/../internal/a.go":           "internal/a.go",
+		filepath.Join(link, "internal", "a.go"): "internal/a.go",
+		filepath.Join(project, "internal"):      "internal",
+		project:                                 ".",
+		"../shared/util.go":                     "../shared/util.go",
+	}
+	for input, expected := range tests {
+		if got := projectPath(input); got != expected {
+			t.Errorf("projectPath(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
+
+func TestIsExcluded(t *testing.T) {
+	excludes := []string{"vendor", ".git", "tmp", "web/static/"}
+	tests := map[string]bool{
+		"tmp":                   true,
+		"tmp/main":              true,
+		"services/api/tmp/main": true,
+		"vendor/x/y.go":         true,
+		"web/static/app.js":     true,
+		"web/static":            true,
+		"web/tmpl/index.html":   false,
+		"tmp.go":                false,
+		"internal/vendors.go":   false,
+		"api/web/static/app.js": false,
+		"main.go":               false,
+	}
+	for path, expected := range tests {
+		if got := isExcluded(path, excludes); got != expected {
+			t.Errorf("isExcluded(%q) = %v, expected %v", path, got, expected)
+		}
+	}
+}
+
+func TestFindProjectRoot(t *testing.T) {
+	base := t.TempDir()
+	os.MkdirAll(filepath.Join(base, "repo", ".git"), 0755)
+	os.MkdirAll(filepath.Join(base, "repo", "service", "internal", "store"), 0755)
+	os.WriteFile(filepath.Join(base, "repo", "service", "go.mod"), []byte("module example.com/service\n"), 0644)
+	os.MkdirAll(filepath.Join(base, "repo", "docs"), 0755)
+
+	tests := map[string]string{
+		"repo/service/internal/store": "repo/service",
+		"repo/service":                "repo/service",
+		"repo/docs":                   "repo",
+	}
+	for start, expected := range tests {
+		root, ok := findProjectRoot(filepath.Join(base, start))
+		if !ok || root != filepath.Join(base, expected) {
+			t.Errorf("findProjectRoot(%s) = %q, %v, expected %s", start, root, ok, expected)
+		}
+	}
+}
+
+func TestEnterProjectRoot(t *testing.T) {
+	base := t.TempDir()
+	os.WriteFile(filepath.Join(base, "go.mod"), []byte("module example.com/app\n"), 0644)
+	sub := filepath.Join(base, "internal", "handlers")
+	os.MkdirAll(sub, 0755)
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(sub); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	if err := enterProjectRoot(); err != nil {
+		t.Fatalf("enterProjectRoot() failed: %v", err)
+	}
+	if _, err := os.Stat("go.mod"); err != nil {
+		t.Errorf("Expected to be in the project root, go.mod not found: %v", err)
+	}
+}
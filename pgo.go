@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultPGOProfile is where go build looks for a profile with -pgo=auto
+const defaultPGOProfile = "default.pgo"
+
+// withGoBuildFlags inserts flags right after "go build" in a build command.
+// Commands that do not start with "go build" are returned unchanged.
+func withGoBuildFlags(cmd string, flags ...string) string {
+	const prefix = "go build"
+	if len(flags) == 0 || (cmd != prefix && !strings.HasPrefix(cmd, prefix+" ")) {
+		return cmd
+	}
+	return prefix + " " + strings.Join(flags, " ") + cmd[len(prefix):]
+}
+
+// buildCommand returns the configured build command with the flags derived
+// from the rest of the config applied
+func (app *WindApp) buildCommand() string {
+	var flags []string
+	if app.config.PGOProfile != "" {
+		flags = append(flags, shellQuote("-pgo="+app.config.PGOProfile))
+	}
+	for _, flag := range goBuildFlags(app.config) {
+		flags = append(flags, shellQuote(flag))
+	}
+	return withGoBuildFlags(app.config.BuildCmd, flags...)
+}
+
+// buildEnv returns the environment for build commands
+func (app *WindApp) buildEnv() []string {
+	env := os.Environ()
+	if app.config.GoExperiment != "" {
+		env = append(env, "GOEXPERIMENT="+app.config.GoExperiment)
+	}
+	if app.config.GOOS != "" {
+		env = append(env, "GOOS="+app.config.GOOS)
+	}
+	if app.config.GOARCH != "" {
+		env = append(env, "GOARCH="+app.config.GOARCH)
+	}
+	return env
+}
+
+// pgoProfilePath is served by the dev proxy itself: a CPU profile of the
+// current process, taken while it serves the traffic the proxy forwards
+const pgoProfilePath = "/__wind/pgo"
+
+// pgoRequestsHeader tells `wind pgo` how many requests the proxy forwarded
+// while the profile was taken
+const pgoRequestsHeader = "X-Wind-Requests"
+
+// serveProfile reads a CPU profile of ?seconds from the app's pprof
+// endpoint at ?path, counting the requests forwarded in the meantime
+func (p *proxyMode) serveProfile(w http.ResponseWriter, r *http.Request) {
+	seconds, err := strconv.Atoi(r.URL.Query().Get("seconds"))
+	path := r.URL.Query().Get("path")
+	if err != nil || seconds <= 0 || !strings.HasPrefix(path, "/") {
+		http.Error(w, "Wind: expected ?seconds=<n>&path=<pprof path>", http.StatusBadRequest)
+		return
+	}
+	port, switched := p.state()
+	if port == 0 {
+		http.Error(w, "Wind: application is not running", http.StatusServiceUnavailable)
+		return
+	}
+
+	before := p.requests.Load()
+	endpoint := fmt.Sprintf("http://127.0.0.1:%d%s?seconds=%d", port, path, seconds)
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, endpoint, nil)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Wind: %v", err), http.StatusBadRequest)
+		return
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Wind: %v", err), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	profile, err := io.ReadAll(resp.Body)
+	switch {
+	case err != nil:
+		http.Error(w, fmt.Sprintf("Wind: %v", err), http.StatusBadGateway)
+		return
+	case resp.StatusCode != http.StatusOK:
+		http.Error(w, fmt.Sprintf("Wind: %s returned %s (does the app import net/http/pprof?)", path, resp.Status), http.StatusBadGateway)
+		return
+	}
+	select {
+	case <-switched:
+		// Part of the traffic went to the next build
+		http.Error(w, "Wind: the application was restarted while profiling", http.StatusConflict)
+		return
+	default:
+	}
+
+	w.Header().Set(pgoRequestsHeader, strconv.FormatInt(p.requests.Load()-before, 10))
+	w.Write(profile)
+}
+
+// collectPGOProfile asks the dev proxy at proxyURL for a profile of the app's
+// pprof endpoint at path while it serves traffic, and stores it at dest. It
+// returns how many requests the profile covers; without any, nothing is
+// stored.
+func collectPGOProfile(proxyURL, path string, duration time.Duration, dest string) (int, error) {
+	seconds := max(int(duration.Seconds()), 1)
+	query := url.Values{"seconds": {strconv.Itoa(seconds)}, "path": {path}}
+
+	client := &http.Client{Timeout: duration + 30*time.Second}
+	resp, err := client.Get(proxyURL + pgoProfilePath + "?" + query.Encode())
+	if err != nil {
+		return 0, fmt.Errorf("%v (is wind proxy running?)", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return 0, fmt.Errorf("%s", strings.TrimPrefix(strings.TrimSpace(string(message)), "Wind: "))
+	}
+	requests, _ := strconv.Atoi(resp.Header.Get(pgoRequestsHeader))
+	if requests == 0 {
+		return 0, fmt.Errorf("no requests went through %s while profiling; the profile would not reflect its traffic", proxyURL)
+	}
+
+	// Write to a temporary file first so a failed download never replaces
+	// a good profile
+	tmp := dest + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return 0, err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return 0, err
+	}
+	return requests, os.Rename(tmp, dest)
+}
+
+// runPGO implements `wind pgo [seconds]`: collect a profile of the app
+// serving the traffic of the dev proxy (wind proxy) into the configured
+// profile path, ready for the next -pgo build
+func runPGO(args []string) {
+	config := defaultConfig()
+	_, _, err := loadProjectConfig(&config)
+	if err == nil {
+		err = validateConfig(&config)
+	}
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Invalid config: %v\n", err)
+		return
+	}
+
+	duration := config.PGOCollectDuration
+	if len(args) > 0 {
+		seconds, err := strconv.Atoi(args[0])
+		if err != nil || seconds <= 0 {
+			logf(levelError, Red+"Error: "+Reset+"Invalid duration: %s (expected seconds)\n", args[0])
+			return
+		}
+		duration = time.Duration(seconds) * time.Second
+	}
+
+	dest := config.PGOProfile
+	if dest == "" || dest == "auto" {
+		dest = defaultPGOProfile
+	}
+
+	proxyURL := fmt.Sprintf("http://localhost:%d", config.Proxy.Port)
+	logf(levelInfo, Cyan+"Info: "+Reset+"Collecting %v CPU profile while wind proxy serves %s — send traffic through it now...\n",
+		duration, proxyURL)
+	requests, err := collectPGOProfile(proxyURL, config.PGOCollectPath, duration, dest)
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Failed to collect profile: %v\n", err)
+		return
+	}
+	logf(levelSuccess, Green+"Success: "+Reset+"Profile of %d proxied request(s) saved to %s\n", requests, dest)
+	if config.PGOProfile == "" {
+		logf(levelInfo, Cyan+"Info: "+Reset+"Set pgoProfile: %s in %s to build with it\n", dest, configFileName)
+	}
+}
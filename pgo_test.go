@@ -0,0 +1,92 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithGoBuildFlags(t *testing.T) {
+	tests := []struct {
+		cmd      string
+		flags    []string
+		expected string
+	}{
+		{"go build -o ./tmp/main .", []string{"-pgo=default.pgo"}, "go build -pgo=default.pgo -o ./tmp/main ."},
+		{"go build", []string{"-pgo=auto"}, "go build -pgo=auto"},
+		{"make build", []string{"-pgo=auto"}, "make build"},
+		{"go build -o ./tmp/main .", nil, "go build -o ./tmp/main ."},
+	}
+
+	for _, tt := range tests {
+		if got := withGoBuildFlags(tt.cmd, tt.flags...); got != tt.expected {
+			t.Errorf("withGoBuildFlags(%q, %v) = %q, expected %q", tt.cmd, tt.flags, got, tt.expected)
+		}
+	}
+}
+
+func TestBuildEnvGoExperiment(t *testing.T) {
+	app := &WindApp{config: WindConfig{GoExperiment: "rangefunc"}}
+
+	env := app.buildEnv()
+	if env[len(env)-1] != "GOEXPERIMENT=rangefunc" {
+		t.Errorf("Expected GOEXPERIMENT in build env, got %q", env[len(env)-1])
+	}
+}
+
+func TestCollectPGOProfile(t *testing.T) {
+	proxy := newProxyMode(ProxyConfig{})
+	srv := httptest.NewServer(proxy)
+	defer srv.Close()
+
+	traffic := true
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/debug/pprof/profile":
+			if r.URL.Query().Get("seconds") != "1" {
+				t.Errorf("Expected seconds=1, got %q", r.URL.RawQuery)
+			}
+			// Traffic through the proxy while the profile is taken
+			if traffic {
+				if resp, err := http.Get(srv.URL + "/orders"); err == nil {
+					resp.Body.Close()
+				}
+			}
+			w.Write([]byte("profile-bytes"))
+		case "/orders":
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer app.Close()
+	port, _ := strconv.Atoi(app.URL[strings.LastIndex(app.URL, ":")+1:])
+	proxy.switchTo(port)
+
+	dest := filepath.Join(t.TempDir(), "default.pgo")
+	requests, err := collectPGOProfile(srv.URL, "/debug/pprof/profile", time.Second, dest)
+	if err != nil || requests != 1 {
+		t.Fatalf("Expected a profile covering 1 request, got %d (%v)", requests, err)
+	}
+	data, err := os.ReadFile(dest)
+	if err != nil || string(data) != "profile-bytes" {
+		t.Errorf("Expected profile to be saved, got %q (%v)", data, err)
+	}
+
+	// A failing endpoint, or no traffic, must not replace the profile
+	_, err = collectPGOProfile(srv.URL, "/missing", time.Second, dest)
+	if err == nil || !strings.Contains(err.Error(), "pprof") {
+		t.Errorf("Expected a pprof hint in the error, got %v", err)
+	}
+	traffic = false
+	if _, err := collectPGOProfile(srv.URL, "/debug/pprof/profile", time.Second, dest); err == nil || !strings.Contains(err.Error(), "no requests") {
+		t.Errorf("Expected an error without traffic, got %v", err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "profile-bytes" {
+		t.Error("Existing profile should be kept when collection fails")
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// localTargetPattern matches the top-level target setting of the local
+// config file
+var localTargetPattern = regexp.MustCompile(`(?m)^target:.*$`)
+
+// promptTarget lets the developer pick the target when the project has
+// several main packages, instead of building the first one, and remembers
+// the choice in the local config file. It returns "" without asking when
+// there is at most one target.
+func promptTarget() (string, error) {
+	targets := detectTargets()
+	if len(targets) < 2 {
+		return "", nil
+	}
+
+	logf(levelInfo, Cyan+"Info: "+Reset+"Found %d main packages; which one should Wind run?\n", len(targets))
+	fmt.Println("  ↑/↓ to move, Enter to select, q to quit")
+	if err := enableRawInput(); err != nil {
+		// Without raw input the arrows can't be read; build the default
+		return "", nil
+	}
+	// Ctrl+C arrives as a key, so the terminal is restored before quitting
+	stty("-isig")
+	choice, ok := selectTarget(bufio.NewReader(os.Stdin), os.Stdout, targets)
+	terminal.restore()
+	if !ok {
+		return "", fmt.Errorf("no target selected")
+	}
+
+	name := targets[choice].Name
+	if err := rememberTarget(name); err != nil {
+		logf(levelWarn, Yellow+"Warning: "+Reset+"Failed to remember the target: %v\n", err)
+	} else {
+		logf(levelSuccess, Green+"Success: "+Reset+"Saved target: %s to %s; change it there or with wind run <target>\n", name, localConfigFileName)
+	}
+	return name, nil
+}
+
+// selectTarget draws the targets to w and moves the selection with the keys
+// read from r: the arrow keys or j/k, a number to jump to a target, Enter to
+// select. It returns false when q or Ctrl+C was pressed or r ended.
+func selectTarget(r *bufio.Reader, w io.Writer, targets []projectTarget) (int, bool) {
+	selected := 0
+	draw := func(redraw bool) {
+		if redraw {
+			fmt.Fprintf(w, "\033[%dA", len(targets))
+		}
+		for i, t := range targets {
+			marker := "  "
+			if i == selected {
+				marker = Cyan + "❯ " + Reset
+			}
+			fmt.Fprintf(w, "\r\033[K  %s%-12s %s\n", marker, t.Name, t.Description)
+		}
+	}
+	draw(false)
+
+	for {
+		key, err := r.ReadByte()
+		if err != nil {
+			return 0, false
+		}
+		switch key {
+		case '\r', '\n':
+			return selected, true
+		case 'q', 3:
+			return 0, false
+		case 'k':
+			selected = (selected + len(targets) - 1) % len(targets)
+		case 'j':
+			selected = (selected + 1) % len(targets)
+		case '\033':
+			// Arrow keys are ESC [ A and ESC [ B
+			if next, _ := r.ReadByte(); next != '[' {
+				continue
+			}
+			switch arrow, _ := r.ReadByte(); arrow {
+			case 'A':
+				selected = (selected + len(targets) - 1) % len(targets)
+			case 'B':
+				selected = (selected + 1) % len(targets)
+			}
+		default:
+			if key >= '1' && key <= '9' && int(key-'1') < len(targets) {
+				selected = int(key - '1')
+			}
+		}
+		draw(true)
+	}
+}
+
+// rememberTarget sets target in the local config file, keeping its other
+// settings
+func rememberTarget(name string) error {
+	data, err := os.ReadFile(localConfigFileName)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	line := "target: " + name
+	content := string(data)
+	if localTargetPattern.MatchString(content) {
+		content = localTargetPattern.ReplaceAllLiteralString(content, line)
+	} else {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += line + "\n"
+	}
+	return os.WriteFile(localConfigFileName, []byte(content), 0644)
+}
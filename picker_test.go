@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSelectTarget(t *testing.T) {
+	targets := []projectTarget{{Name: "api"}, {Name: "worker"}, {Name: "cli"}}
+	tests := []struct {
+		keys     string
+		expected int
+		ok       bool
+	}{
+		{"\r", 0, true},
+		{"\033[B\033[B\r", 2, true},
+		{"\033[A\n", 2, true},
+		{"jjk\r", 1, true},
+		{"3\r", 2, true},
+		{"9\r", 0, true},
+		{"\033[Bq", 0, false},
+		{"\x03", 0, false},
+		{"j", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := selectTarget(bufio.NewReader(strings.NewReader(tt.keys)), io.Discard, targets)
+		if got != tt.expected || ok != tt.ok {
+			t.Errorf("selectTarget(%q) = %d, %v, expected %d, %v", tt.keys, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestRememberTarget(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+
+	if err := rememberTarget("api"); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(localConfigFileName, []byte("verbose: true\ntarget: api\nenv:\n  PORT: 8081"), 0644)
+	if err := rememberTarget("worker"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(localConfigFileName)
+	if string(data) != "verbose: true\ntarget: worker\nenv:\n  PORT: 8081" {
+		t.Errorf("Expected the target to be replaced, got %q", data)
+	}
+
+	os.WriteFile(localConfigFileName, []byte("verbose: true"), 0644)
+	rememberTarget("cli")
+	config := defaultConfig()
+	if _, err := loadConfigFile(localConfigFileName, &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Target != "cli" || !config.Verbose {
+		t.Errorf("Expected the local config to select cli, got %q", config.Target)
+	}
+}
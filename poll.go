@@ -0,0 +1,59 @@
+package main
+
+import (
+	"time"
+)
+
+// pollIdleAfter is how long no change may be seen before polling slows down
+const pollIdleAfter = 2 * time.Minute
+
+// pollScheduler picks the delay before the next scan: PollInterval while
+// files are changing, doubling up to MaxPollInterval once the tree has been
+// idle for pollIdleAfter. The delay never drops below the duration of the
+// last scan, so a slow scan cannot keep a core busy.
+type pollScheduler struct {
+	min, max time.Duration
+
+	interval   time.Duration
+	lastChange time.Time
+	// warned is set once a scan took longer than PollInterval
+	warned bool
+}
+
+func newPollScheduler(config WindConfig) *pollScheduler {
+	return &pollScheduler{
+		min:        config.PollInterval,
+		max:        max(config.MaxPollInterval, config.PollInterval),
+		interval:   config.PollInterval,
+		lastChange: time.Now(),
+	}
+}
+
+// activity returns to the fast interval, e.g. after a manual rebuild
+func (p *pollScheduler) activity(now time.Time) {
+	p.lastChange = now
+	p.interval = p.min
+}
+
+// next records a scan that took scanTime and returns the delay before the
+// next one. slow reports the first scan that took longer than PollInterval.
+func (p *pollScheduler) next(changed bool, scanTime time.Duration, now time.Time) (delay time.Duration, slow bool) {
+	switch {
+	case changed:
+		p.activity(now)
+	case now.Sub(p.lastChange) >= pollIdleAfter:
+		p.interval = min(p.interval*2, p.max)
+	}
+
+	if scanTime > p.min && !p.warned {
+		p.warned = true
+		slow = true
+	}
+	return max(p.interval, scanTime), slow
+}
+
+// warnSlowScan suggests ways to make scans cheaper
+func (app *WindApp) warnSlowScan(scanTime time.Duration) {
+	logf(levelWarn, Yellow+"Warning: "+Reset+"%sScanning the project took %v, longer than pollInterval (%v); polling slows down to match. Add large directories to excludeDirs or narrow watch to speed it up\n",
+		app.label(), scanTime.Round(time.Millisecond), app.config.PollInterval)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPollSchedulerBacksOffWhenIdle(t *testing.T) {
+	config := defaultConfig()
+	config.PollInterval = 500 * time.Millisecond
+	config.MaxPollInterval = 2 * time.Second
+	p := newPollScheduler(config)
+	start := p.lastChange
+
+	if delay, _ := p.next(false, time.Millisecond, start.Add(time.Minute)); delay != 500*time.Millisecond {
+		t.Errorf("before idle: delay = %v, want 500ms", delay)
+	}
+
+	idle := start.Add(pollIdleAfter)
+	var delays []time.Duration
+	for i := 0; i < 4; i++ {
+		delay, _ := p.next(false, time.Millisecond, idle)
+		delays = append(delays, delay)
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 2 * time.Second, 2 * time.Second}
+	for i := range want {
+		if delays[i] != want[i] {
+			t.Fatalf("idle delays = %v, want %v", delays, want)
+		}
+	}
+
+	if delay, _ := p.next(true, time.Millisecond, idle); delay != 500*time.Millisecond {
+		t.Errorf("after a change: delay = %v, want 500ms", delay)
+	}
+}
+
+func TestPollSchedulerFixedInterval(t *testing.T) {
+	config := defaultConfig()
+	config.PollInterval = time.Second
+	config.MaxPollInterval = 0
+	p := newPollScheduler(config)
+
+	delay, _ := p.next(false, time.Millisecond, p.lastChange.Add(time.Hour))
+	if delay != time.Second {
+		t.Errorf("delay = %v, want the fixed 1s", delay)
+	}
+}
+
+func TestPollSchedulerSlowScan(t *testing.T) {
+	config := defaultConfig()
+	config.PollInterval = 500 * time.Millisecond
+	p := newPollScheduler(config)
+	now := p.lastChange
+
+	delay, slow := p.next(false, 800*time.Millisecond, now)
+	if !slow {
+		t.Error("expected the slow scan to be reported")
+	}
+	if delay != 800*time.Millisecond {
+		t.Errorf("delay = %v, want at least the scan time", delay)
+	}
+	if _, slow := p.next(false, 900*time.Millisecond, now); slow {
+		t.Error("slow scans should only be reported once")
+	}
+}
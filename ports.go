@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// portRegistryFile is shared by every project of the user, under
+// os.UserConfigDir(), or of every user in shared mode (see registryPath)
+const portRegistryFile = "wind/ports.json"
+
+// portEntry is a port used by a target of a project. Assigned ports are
+// kept after the session ends so the next run reuses them; fixed ports
+// (proxies, control API) are only listed while in use.
+type portEntry struct {
+	Project string `json:"project"`
+	Target  string `json:"target"`
+	Port    int    `json:"port"`
+	Fixed   bool   `json:"fixed,omitempty"`
+	// PID is the Wind session using the port, 0 when none is
+	PID int `json:"pid,omitempty"`
+	// Owner is the user of the session, listed in shared mode
+	Owner   string    `json:"owner,omitempty"`
+	Updated time.Time `json:"updated"`
+}
+
+// live reports whether the session that registered the port still runs
+func (e portEntry) live() bool {
+	return e.PID != 0 && processAlive(e.PID)
+}
+
+type portRegistry struct {
+	Entries []portEntry `json:"entries"`
+}
+
+func portRegistryPath() (string, error) {
+	return registryPath(portRegistryFile)
+}
+
+// readPorts loads the registry; a missing file is an empty registry
+func readPorts(path string) (portRegistry, error) {
+	var registry portRegistry
+	err := readJSON(path, &registry)
+	return registry, err
+}
+
+// updatePorts applies fn to the registry while holding a lock against other
+// Wind sessions
+func updatePorts(fn func(r *portRegistry) error) error {
+	path, err := portRegistryPath()
+	if err != nil {
+		return err
+	}
+	var registry portRegistry
+	return updateJSON(path, &registry, func() error { return fn(&registry) })
+}
+
+// portAvailable reports whether port can be listened on
+func portAvailable(port int) bool {
+	l, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		return false
+	}
+	l.Close()
+	return true
+}
+
+// assignPort returns the port of a project's target: the one registered by
+// an earlier run if it is still free, otherwise a new one no other project
+// has. The returned note explains a changed port.
+func assignPort(project, target string) (port int, note string, err error) {
+	err = updatePorts(func(r *portRegistry) error {
+		taken := map[int]bool{}
+		index := -1
+		for i, e := range r.Entries {
+			if e.Project == project && e.Target == target && !e.Fixed {
+				index = i
+				continue
+			}
+			taken[e.Port] = true
+		}
+
+		if index >= 0 {
+			previous := r.Entries[index].Port
+			if !taken[previous] && portAvailable(previous) {
+				port = previous
+			} else {
+				note = fmt.Sprintf("port %d is in use, moved to a new one", previous)
+			}
+		}
+		for port == 0 {
+			p, err := freePort()
+			if err != nil {
+				return err
+			}
+			if !taken[p] {
+				port = p
+			}
+		}
+
+		entry := portEntry{Project: project, Target: target, Port: port, PID: os.Getpid(), Owner: currentUser(), Updated: time.Now()}
+		if index >= 0 {
+			r.Entries[index] = entry
+		} else {
+			r.Entries = append(r.Entries, entry)
+		}
+		return nil
+	})
+	return port, note, err
+}
+
+// claimPort registers a fixed port used by a project and returns the live
+// entries of other projects using the same port
+func claimPort(project, target string, port int) (conflicts []portEntry, err error) {
+	err = updatePorts(func(r *portRegistry) error {
+		entries := r.Entries[:0]
+		for _, e := range r.Entries {
+			if e.Project == project && e.Target == target && e.Fixed {
+				continue
+			}
+			if e.Port == port && e.Project != project && e.live() {
+				conflicts = append(conflicts, e)
+			}
+			entries = append(entries, e)
+		}
+		r.Entries = append(entries, portEntry{Project: project, Target: target, Port: port, Fixed: true, PID: os.Getpid(), Owner: currentUser(), Updated: time.Now()})
+		return nil
+	})
+	return conflicts, err
+}
+
+// releasePorts marks the ports of this session as unused: assigned ports
+// stay reserved for the next run, fixed ones are dropped
+func releasePorts() {
+	pid := os.Getpid()
+	updatePorts(func(r *portRegistry) error {
+		entries := r.Entries[:0]
+		for _, e := range r.Entries {
+			if e.PID == pid {
+				if e.Fixed {
+					continue
+				}
+				e.PID = 0
+			}
+			entries = append(entries, e)
+		}
+		r.Entries = entries
+		return nil
+	})
+}
+
+// assignPorts gives every target a port in its AssignPort variable and
+// registers the fixed ports of the session, warning about ports another
+// project is using
+func assignPorts(apps []*WindApp, fixed map[string]int) {
+	project := projectRoot()
+	for _, app := range apps {
+		// Proxy and A/B mode hand out their own internal ports
+		if app.config.AssignPort == "" || app.proxy != nil || app.ab != nil {
+			continue
+		}
+		target := app.name
+		if target == "" {
+			target = "main"
+		}
+		port, note, err := assignPort(project, target)
+		if err != nil {
+			logf(levelWarn, Yellow+"Warning: "+Reset+"%sFailed to assign a port: %v\n", app.label(), err)
+			continue
+		}
+		if note != "" {
+			logf(levelWarn, Yellow+"Warning: "+Reset+"%s%s\n", app.label(), note)
+		}
+		app.port = port
+		logf(levelInfo, Cyan+"Info: "+Reset+"%sPort %d (%s)\n", app.label(), port, app.config.AssignPort)
+	}
+
+	names := make([]string, 0, len(fixed))
+	for name := range fixed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		conflicts, err := claimPort(project, name, fixed[name])
+		if err != nil {
+			continue
+		}
+		for _, c := range conflicts {
+			logf(levelWarn, Yellow+"Warning: "+Reset+"Port %d (%s) is also used by %s (%s, PID: %d%s)\n", c.Port, name, c.Project, c.Target, c.PID, ownedBy(c.Owner))
+		}
+	}
+}
+
+// fixedPorts lists the configured ports a session listens on
+func fixedPorts(config WindConfig, opts watchOptions) map[string]int {
+	ports := map[string]int{}
+	switch {
+	case opts.proxyMode:
+		ports["proxy"] = config.Proxy.Port
+	case opts.abMode:
+		ports["ab"] = config.AB.Port
+	}
+	if config.LiveReload.AppURL != "" {
+		ports["liveReload"] = config.LiveReload.Port
+	}
+	if _, port, err := net.SplitHostPort(config.ControlAddr); err == nil {
+		if n, err := strconv.Atoi(port); err == nil {
+			ports["control"] = n
+		}
+	}
+	return ports
+}
+
+// runPorts implements `wind ports`: the ports of every project, running or
+// reserved
+func runPorts() {
+	var entries []portEntry
+	for _, path := range registryPaths(portRegistryFile) {
+		registry, err := readPorts(path)
+		if err != nil {
+			logf(levelError, Red+"Error: "+Reset+"Failed to read %s: %v\n", path, err)
+			return
+		}
+		entries = append(entries, registry.Entries...)
+	}
+	fmt.Print(formatPorts(entries, terminalWidth()))
+}
+
+// formatPorts lists entries by project and port in width columns
+func formatPorts(entries []portEntry, width int) string {
+	if len(entries) == 0 {
+		return "No ports registered\n"
+	}
+	sorted := append([]portEntry(nil), entries...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Project != sorted[j].Project {
+			return sorted[i].Project < sorted[j].Project
+		}
+		return sorted[i].Port < sorted[j].Port
+	})
+
+	rows := newTable("")
+	for _, e := range sorted {
+		state := "reserved"
+		if e.live() {
+			state = fmt.Sprintf(Green+"running"+Reset+" (PID: %d%s)", e.PID, ownedBy(e.Owner))
+		} else if e.Fixed {
+			state = "stale"
+		}
+		rows.addRow(strconv.Itoa(e.Port), e.Target, state, e.Project)
+	}
+	return rows.render(width)
+}
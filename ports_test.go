@@ -0,0 +1,127 @@
+package main
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAssignPortReusesPort(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	first, note, err := assignPort("/src/a", "main")
+	if err != nil || note != "" {
+		t.Fatalf("assignPort() = %d, %q, %v", first, note, err)
+	}
+	releasePorts()
+
+	second, note, err := assignPort("/src/a", "main")
+	if err != nil || note != "" {
+		t.Fatalf("assignPort() = %d, %q, %v", second, note, err)
+	}
+	if second != first {
+		t.Errorf("port changed from %d to %d", first, second)
+	}
+
+	other, _, err := assignPort("/src/b", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == first {
+		t.Errorf("another project got port %d too", other)
+	}
+}
+
+func TestAssignPortBusy(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	port, _, err := assignPort("/src/a", "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		t.Skipf("cannot listen on %d: %v", port, err)
+	}
+	defer l.Close()
+
+	moved, note, err := assignPort("/src/a", "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved == port {
+		t.Errorf("busy port %d was assigned again", port)
+	}
+	if !strings.Contains(note, "in use") {
+		t.Errorf("note = %q, want an explanation", note)
+	}
+}
+
+func TestClaimPortConflicts(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	conflicts, err := claimPort("/src/a", "proxy", 3000)
+	if err != nil || len(conflicts) != 0 {
+		t.Fatalf("claimPort() = %v, %v", conflicts, err)
+	}
+	conflicts, err = claimPort("/src/b", "proxy", 3000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Project != "/src/a" {
+		t.Errorf("conflicts = %+v, want /src/a", conflicts)
+	}
+	conflicts, _ = claimPort("/src/a", "proxy", 3000)
+	if len(conflicts) != 1 || conflicts[0].Project != "/src/b" {
+		t.Errorf("reclaiming: conflicts = %+v, want /src/b", conflicts)
+	}
+}
+
+func TestReleasePorts(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	port, _, err := assignPort("/src/a", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimPort("/src/a", "control", 9999)
+	releasePorts()
+
+	path, _ := portRegistryPath()
+	registry, err := readPorts(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registry.Entries) != 1 {
+		t.Fatalf("entries = %+v, want only the assigned port", registry.Entries)
+	}
+	e := registry.Entries[0]
+	if e.Port != port || e.PID != 0 || e.Fixed {
+		t.Errorf("entry = %+v, want port %d reserved", e, port)
+	}
+}
+
+func TestFormatPorts(t *testing.T) {
+	if got := formatPorts(nil, 80); got != "No ports registered\n" {
+		t.Errorf("formatPorts(nil, 80) = %q", got)
+	}
+	out := formatPorts([]portEntry{
+		{Project: "/src/b", Target: "main", Port: 4000},
+		{Project: "/src/a", Target: "proxy", Port: 3000, Fixed: true},
+		{Project: "/src/a", Target: "api", Port: 5000, PID: os.Getpid()},
+	}, 120)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("formatPorts() = %q", out)
+	}
+	for i, want := range []string{"stale", "running", "reserved"} {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("line %d = %q, want %s", i, lines[i], want)
+		}
+	}
+	if !strings.HasPrefix(lines[0], "3000") || !strings.HasPrefix(lines[2], "4000") {
+		t.Errorf("entries not sorted by project and port:\n%s", out)
+	}
+}
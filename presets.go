@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// languagePreset adapts Wind to a project in another language: its build
+// and run commands, the files to watch and the directories to skip
+type languagePreset struct {
+	// build and run are the default BuildCmd and RunCmd; run may depend on
+	// the project's files
+	build string
+	run   func() string
+	exts  []string
+	// exclude is added to ExcludeDirs
+	exclude []string
+}
+
+// languagePresets are the values of Preset
+var languagePresets = map[string]languagePreset{
+	"python": {
+		// Compiling catches syntax errors before the restart, like a build
+		build:   "python3 -m compileall -q .",
+		run:     pythonRunCmd,
+		exts:    []string{".py", ".html", ".toml", ".cfg", ".ini"},
+		exclude: []string{"__pycache__", ".venv", "venv", ".pytest_cache", ".mypy_cache"},
+	},
+	"node": {
+		build:   "npm run build --if-present",
+		run:     func() string { return "npm start" },
+		exts:    []string{".js", ".mjs", ".cjs", ".ts", ".json", ".html", ".css"},
+		exclude: []string{"dist", "build", ".next", "coverage"},
+	},
+	"rust": {
+		build:   "cargo build",
+		run:     func() string { return "cargo run --quiet" },
+		exts:    []string{".rs", ".toml"},
+		exclude: []string{"target"},
+	},
+}
+
+// presetNames lists the presets for messages
+func presetNames() string {
+	var names []string
+	for name := range languagePresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// pythonRunCmd starts a Django project's dev server, without its own
+// reloader, or the first of main.py and app.py
+func pythonRunCmd() string {
+	if _, err := os.Stat("manage.py"); err == nil {
+		return "python3 manage.py runserver --noreload"
+	}
+	if _, err := os.Stat("app.py"); err == nil {
+		if _, err := os.Stat("main.py"); err != nil {
+			return "python3 app.py"
+		}
+	}
+	return "python3 main.py"
+}
+
+// validatePreset checks the Preset setting
+func validatePreset(name string) error {
+	if _, ok := languagePresets[name]; !ok && name != "" && name != "go" {
+		return fmt.Errorf("invalid preset %q (expected go, %s)", name, presetNames())
+	}
+	return nil
+}
+
+// applyPreset fills in the settings of config's language preset that the
+// config left at their defaults, and turns off the detection of Go main
+// packages. It returns a description of the commands, or "" for Go.
+func applyPreset(config *WindConfig) string {
+	preset, ok := languagePresets[config.Preset]
+	if !ok {
+		return ""
+	}
+	defaults := defaultConfig()
+	if config.BuildCmd == "" {
+		config.BuildCmd = preset.build
+	}
+	if config.RunCmd == defaults.RunCmd {
+		config.RunCmd = preset.run()
+	}
+	if reflect.DeepEqual(config.IncludeExts, defaults.IncludeExts) {
+		config.IncludeExts = preset.exts
+	}
+	for _, dir := range preset.exclude {
+		if !containsString(config.ExcludeDirs, dir) {
+			config.ExcludeDirs = append(config.ExcludeDirs, dir)
+		}
+	}
+	config.Detect = false
+	return fmt.Sprintf("%s preset: build with %s, run %s", config.Preset, config.BuildCmd, config.RunCmd)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
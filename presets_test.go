@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestApplyPreset(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+
+	config := defaultConfig()
+	config.Preset = "rust"
+	if description := applyPreset(&config); description == "" {
+		t.Error("Expected a description of the preset")
+	}
+	if config.BuildCmd != "cargo build" || config.RunCmd != "cargo run --quiet" || config.Detect {
+		t.Errorf("Expected the rust commands without detection, got %q, %q, detect %v", config.BuildCmd, config.RunCmd, config.Detect)
+	}
+	if !reflect.DeepEqual(config.IncludeExts, []string{".rs", ".toml"}) {
+		t.Errorf("Expected the rust extensions, got %v", config.IncludeExts)
+	}
+	if !containsString(config.ExcludeDirs, "target") || !containsString(config.ExcludeDirs, ".git") {
+		t.Errorf("Expected target to be added to the excluded directories, got %v", config.ExcludeDirs)
+	}
+
+	// Settings of the config file win
+	config = defaultConfig()
+	config.Preset = "node"
+	config.RunCmd = "node server.js"
+	config.IncludeExts = []string{".js"}
+	applyPreset(&config)
+	if config.BuildCmd != "npm run build --if-present" || config.RunCmd != "node server.js" || !reflect.DeepEqual(config.IncludeExts, []string{".js"}) {
+		t.Errorf("Expected configured settings to be kept, got %q, %q, %v", config.BuildCmd, config.RunCmd, config.IncludeExts)
+	}
+
+	config = defaultConfig()
+	if description := applyPreset(&config); description != "" || config.BuildCmd != "" || !config.Detect {
+		t.Error("Expected Go projects to be left alone")
+	}
+}
+
+func TestPythonRunCmd(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+
+	if got := pythonRunCmd(); got != "python3 main.py" {
+		t.Errorf("pythonRunCmd() = %q, expected the main.py default", got)
+	}
+	os.WriteFile("app.py", nil, 0644)
+	if got := pythonRunCmd(); got != "python3 app.py" {
+		t.Errorf("pythonRunCmd() = %q, expected app.py", got)
+	}
+	os.WriteFile("manage.py", nil, 0644)
+	if got := pythonRunCmd(); got != "python3 manage.py runserver --noreload" {
+		t.Errorf("pythonRunCmd() = %q, expected the Django dev server", got)
+	}
+}
+
+func TestValidatePreset(t *testing.T) {
+	for _, name := range []string{"", "go", "python", "node", "rust"} {
+		if err := validatePreset(name); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", name, err)
+		}
+	}
+	if err := validatePreset("ruby"); err == nil {
+		t.Error("Expected an error for an unknown preset")
+	}
+}
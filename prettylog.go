@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Field names of the JSON loggers of zap, zerolog, slog and logrus
+var (
+	logTimeKeys    = []string{"time", "ts", "timestamp", "@timestamp"}
+	logLevelKeys   = []string{"level", "lvl", "severity"}
+	logMessageKeys = []string{"msg", "message"}
+	// logStackKeys hold stack traces, printed below the line
+	logStackKeys = []string{"stacktrace", "stack", "error_verbose"}
+)
+
+// PrettyLogsConfig formats the JSON log lines of the application, e.g. of
+// zap, zerolog or slog, as readable lines with a colored level
+type PrettyLogsConfig struct {
+	Enabled bool
+	// Hide lists fields that are left out, such as caller or hostname
+	Hide []string
+	// MaxValue shortens longer field values (80 characters by default);
+	// 0 shows them in full
+	MaxValue int
+}
+
+// logLevelColor returns the color of a log level
+func logLevelColor(level string) string {
+	switch strings.ToLower(level) {
+	case "trace", "debug":
+		return Blue
+	case "info":
+		return Green
+	case "warn", "warning":
+		return Yellow
+	case "error", "err", "fatal", "panic", "dpanic", "critical":
+		return Red
+	}
+	return White
+}
+
+// takeField removes the first of keys present in fields and returns its
+// value
+func takeField(fields map[string]any, keys []string) (any, bool) {
+	for _, key := range keys {
+		if v, ok := fields[key]; ok {
+			delete(fields, key)
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// formatLogTime renders a log timestamp as a time of day: RFC 3339 strings
+// and Unix seconds or milliseconds as zap and zerolog write them
+func formatLogTime(v any) string {
+	switch t := v.(type) {
+	case string:
+		if parsed, err := time.Parse(time.RFC3339Nano, t); err == nil {
+			return parsed.Local().Format("15:04:05.000")
+		}
+		return t
+	case json.Number:
+		f, err := t.Float64()
+		if err != nil {
+			return t.String()
+		}
+		if f > 1e12 {
+			f /= 1000
+		}
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*1e9)).Format("15:04:05.000")
+	}
+	return fmt.Sprint(v)
+}
+
+// formatLogValue renders a field value: strings unquoted unless they
+// contain spaces, everything else as compact JSON
+func formatLogValue(v any) string {
+	if s, ok := v.(string); ok {
+		if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+			return fmt.Sprintf("%q", s)
+		}
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// prettyLogLine formats a JSON log line, or returns false when line is not
+// a JSON object
+func prettyLogLine(line []byte, config PrettyLogsConfig) (string, bool) {
+	trimmed := bytes.TrimSpace(line)
+	if len(trimmed) < 2 || trimmed[0] != '{' {
+		return "", false
+	}
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+	fields := map[string]any{}
+	if err := decoder.Decode(&fields); err != nil || decoder.More() {
+		return "", false
+	}
+
+	var b strings.Builder
+	if t, ok := takeField(fields, logTimeKeys); ok {
+		b.WriteString(Purple + formatLogTime(t) + Reset + " ")
+	}
+	if level, ok := takeField(fields, logLevelKeys); ok {
+		name := strings.ToUpper(fmt.Sprint(level))
+		fmt.Fprintf(&b, "%s%-5s%s ", logLevelColor(name), name, Reset)
+	}
+	if msg, ok := takeField(fields, logMessageKeys); ok {
+		b.WriteString(fmt.Sprint(msg))
+	}
+	stack, _ := takeField(fields, logStackKeys)
+	for _, key := range config.Hide {
+		delete(fields, key)
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := formatLogValue(fields[key])
+		if runes := []rune(value); config.MaxValue > 0 && len(runes) > config.MaxValue {
+			value = string(runes[:config.MaxValue]) + "…"
+		}
+		fmt.Fprintf(&b, " %s%s=%s%s", Cyan, key, Reset, value)
+	}
+	if s, ok := stack.(string); ok && s != "" {
+		b.WriteString("\n    " + strings.ReplaceAll(strings.TrimRight(s, "\n"), "\n", "\n    "))
+	}
+	return b.String(), true
+}
+
+// prettyLogWriter formats the JSON log lines written to it unless raw is
+// set. Other output passes through as it comes, so prompts without a
+// newline still show.
+type prettyLogWriter struct {
+	mutex  sync.Mutex
+	w      io.Writer
+	config PrettyLogsConfig
+	raw    *atomic.Bool
+	// pending is the start of a JSON line waiting for its newline
+	pending []byte
+}
+
+func newPrettyLogWriter(w io.Writer, config PrettyLogsConfig, raw *atomic.Bool) *prettyLogWriter {
+	return &prettyLogWriter{w: w, config: config, raw: raw}
+}
+
+func (p *prettyLogWriter) Write(data []byte) (int, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.raw.Load() && len(p.pending) == 0 {
+		return p.w.Write(data)
+	}
+
+	var out bytes.Buffer
+	rest := append(p.pending, data...)
+	p.pending = nil
+	for len(rest) > 0 {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			// A JSON line is only complete with its newline
+			if bytes.HasPrefix(bytes.TrimLeft(rest, " \t"), []byte("{")) {
+				p.pending = rest
+			} else {
+				out.Write(rest)
+			}
+			break
+		}
+		line := rest[:i+1]
+		rest = rest[i+1:]
+		if pretty, ok := prettyLogLine(line, p.config); ok && !p.raw.Load() {
+			out.WriteString(pretty + "\n")
+		} else {
+			out.Write(line)
+		}
+	}
+	if out.Len() > 0 {
+		if _, err := p.w.Write(out.Bytes()); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPrettyLogLine(t *testing.T) {
+	config := PrettyLogsConfig{Hide: []string{"caller"}, MaxValue: 10}
+	at, _ := time.Parse(time.RFC3339, "2026-10-16T09:12:04Z")
+	clock := at.Local().Format("15:04:05.000")
+
+	tests := []struct {
+		name     string
+		line     string
+		expected string
+	}{
+		{
+			"zerolog",
+			`{"level":"info","time":"2026-10-16T09:12:04Z","message":"listening","port":8080}`,
+			Purple + clock + Reset + " " + Green + "INFO " + Reset + " listening " + Cyan + "port=" + Reset + "8080",
+		},
+		{
+			"zap",
+			`{"level":"error","ts":1792141924,"caller":"api/main.go:42","msg":"query failed","error":"connection refused","stacktrace":"main.main\n\tmain.go:42"}`,
+			Purple + time.Unix(1792141924, 0).Format("15:04:05.000") + Reset + " " + Red + "ERROR" + Reset + " query failed " + Cyan + "error=" + Reset + `"connectio…` + "\n    main.main\n    \tmain.go:42",
+		},
+		{
+			"slog",
+			`{"time":"2026-10-16T09:12:04Z","level":"WARN","msg":"slow","user":{"id":7}}`,
+			Purple + clock + Reset + " " + Yellow + "WARN " + Reset + " slow " + Cyan + "user=" + Reset + `{"id":7}`,
+		},
+	}
+	for _, tt := range tests {
+		got, ok := prettyLogLine([]byte(tt.line), config)
+		if !ok || got != tt.expected {
+			t.Errorf("%s: expected %q, got %q (%v)", tt.name, tt.expected, got, ok)
+		}
+	}
+
+	for _, line := range []string{"listening on :8080", "{not json}", `{"a":1} {"b":2}`, "[1,2]"} {
+		if _, ok := prettyLogLine([]byte(line), config); ok {
+			t.Errorf("Expected %q to be left alone", line)
+		}
+	}
+}
+
+func TestPrettyLogWriter(t *testing.T) {
+	var buf bytes.Buffer
+	var raw atomic.Bool
+	w := newPrettyLogWriter(&buf, PrettyLogsConfig{}, &raw)
+
+	// A JSON line split across writes is formatted once complete; other
+	// output passes through immediately
+	w.Write([]byte("Enter name: "))
+	w.Write([]byte(`{"level":"debug","msg":"hel`))
+	if buf.String() != "Enter name: " {
+		t.Errorf("Expected only the prompt so far, got %q", buf.String())
+	}
+	w.Write([]byte("lo\"}\nplain line\n"))
+	expected := "Enter name: " + Blue + "DEBUG" + Reset + " hello\nplain line\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+
+	buf.Reset()
+	raw.Store(true)
+	w.Write([]byte("{\"msg\":\"as is\"}\n"))
+	if !strings.HasPrefix(buf.String(), `{"msg":"as is"}`) {
+		t.Errorf("Expected the raw line, got %q", buf.String())
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProxyConfig configures `wind proxy`, which keeps serving the previous
+// process while a new one builds and starts, and switches over once the new
+// one is healthy
+type ProxyConfig struct {
+	// Port is the public port the proxy listens on
+	Port int
+	// PortEnv names the variable that tells the app its internal port
+	PortEnv string
+	// HealthPath is polled on the new process until it answers below 500;
+	// without it Wind waits for the port to accept connections
+	HealthPath string
+	// StartTimeout bounds how long a new process may take to become healthy
+	StartTimeout time.Duration
+	// HoldTimeout bounds how long requests wait while no process is up
+	HoldTimeout time.Duration
+}
+
+// retriedKey marks requests that were already held once after a failure
+type retriedKey struct{}
+
+// proxyMode forwards the public port to whichever process is current
+type proxyMode struct {
+	config ProxyConfig
+	proxy  *httputil.ReverseProxy
+	server *http.Server
+
+	mutex sync.Mutex
+	// port is the internal port of the current process, 0 before the
+	// first one is healthy
+	port int
+	// switched is closed and replaced whenever port changes
+	switched chan struct{}
+	// failure is the latest build while it is broken; fixed is closed
+	// when it is cleared (see overlay.go)
+	failure *buildFailure
+	fixed   chan struct{}
+	// requests counts the requests forwarded to the app (see pgo.go)
+	requests atomic.Int64
+}
+
+func newProxyMode(config ProxyConfig) *proxyMode {
+	p := &proxyMode{config: config, switched: make(chan struct{})}
+	p.proxy = &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			port, _ := p.state()
+			r.SetURL(&url.URL{Scheme: "http", Host: fmt.Sprintf("127.0.0.1:%d", port)})
+			r.Out.Host = r.In.Host
+			r.SetXForwarded()
+		},
+		ErrorHandler: p.handleError,
+	}
+	return p
+}
+
+// start launches the proxy on the public port
+func (p *proxyMode) start() {
+	p.server = &http.Server{
+		Addr:    fmt.Sprintf(":%d", p.config.Port),
+		Handler: p,
+	}
+
+	go func() {
+		if err := p.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logf(levelError, Red+"Error: "+Reset+"Proxy failed: %v\n", err)
+		}
+	}()
+
+	logf(levelInfo, Cyan+"Info: "+Reset+"Proxy on http://localhost:%d (app port via %s)\n", p.config.Port, p.config.PortEnv)
+}
+
+func (p *proxyMode) state() (int, <-chan struct{}) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.port, p.switched
+}
+
+func (p *proxyMode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == errorOverlayPath {
+		p.serveBuildEvents(w, r)
+		return
+	}
+	if r.URL.Path == pgoProfilePath {
+		p.serveProfile(w, r)
+		return
+	}
+	// While the build is broken, pages show its errors instead of the
+	// previous process
+	if failure, _ := p.buildFailure(); failure != nil && wantsPage(r) {
+		serveOverlay(w, failure)
+		return
+	}
+	if port, switched := p.state(); port == 0 && !p.hold(r, switched) {
+		http.Error(w, "Wind: application is not running", http.StatusServiceUnavailable)
+		return
+	}
+	p.requests.Add(1)
+	p.proxy.ServeHTTP(w, r)
+}
+
+// hold waits until the proxy switches to a new process. It reports false
+// when HoldTimeout passes or the client goes away first.
+func (p *proxyMode) hold(r *http.Request, switched <-chan struct{}) bool {
+	timer := time.NewTimer(p.config.HoldTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-switched:
+		return true
+	case <-timer.C:
+		return false
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// handleError holds a failed GET or HEAD until the next process is up and
+// retries it once, covering a crashed process being rebuilt
+func (p *proxyMode) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	_, switched := p.state()
+	retryable := (r.Method == http.MethodGet || r.Method == http.MethodHead) && r.Context().Value(retriedKey{}) == nil
+	if retryable && p.hold(r, switched) {
+		p.proxy.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), retriedKey{}, true)))
+		return
+	}
+	http.Error(w, fmt.Sprintf("Wind: application unavailable (%v)", err), http.StatusBadGateway)
+}
+
+// switchTo sends all new requests to the process listening on port
+func (p *proxyMode) switchTo(port int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.port = port
+	close(p.switched)
+	p.switched = make(chan struct{})
+}
+
+// waitHealthy polls the process on port until it is ready or StartTimeout
+// passes
+func (p *proxyMode) waitHealthy(port int, exit *processExit) error {
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	probe := probeTCP(addr)
+	if p.config.HealthPath != "" {
+		probe = probeHTTP("http://" + addr + p.config.HealthPath)
+	}
+	_, err := pollProcessReady(probe, p.config.StartTimeout, exit)
+	return err
+}
+
+func (p *proxyMode) stop() {
+	if p.server != nil {
+		p.server.Close()
+	}
+}
+
+// freePort asks the OS for an unused local port
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// handoff starts the new build on a free internal port and, once it is
+// healthy, switches the proxy to it and stops the previous process. If the
+// new process never becomes healthy the previous one keeps serving. It
+// reports whether the switch happened; the caller holds app.mutex.
+func (app *WindApp) handoff(env []string) bool {
+	// Only successful builds are handed off, so pages showing the error
+	// overlay can reload, onto the new process if it came up
+	defer app.proxy.clearBuildFailure()
+
+	port, err := freePort()
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%sFailed to allocate a port: %v\n", app.label(), err)
+		return false
+	}
+
+	logf(levelInfo, app.label()+Cyan+"🚀 Starting application on internal port %d..."+Reset+"\n", port)
+
+	exit, err := app.startCommand(app.config.RunCmd, append(env[:len(env):len(env)], fmt.Sprintf("%s=%d", app.proxy.config.PortEnv, port)))
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%sFailed to start application: %v\n", app.label(), err)
+		app.failStart(1)
+		return false
+	}
+	// Exits are watched as with launch; until the switch, the health
+	// check below reports them
+	go app.watchProcess(exit, env)
+
+	if err := app.proxy.waitHealthy(port, exit); err != nil {
+		status := failureStatus(exit)
+		logf(levelError, Red+"Error: "+Reset+"%sNew process (PID: %d) %v; keeping the previous one\n", app.label(), exit.process.Pid, err)
+		app.terminate(exit.process, exit)
+		app.failStart(status)
+		return false
+	}
+
+	app.proxy.switchTo(port)
+	previous, previousExit := app.process, app.exit
+	app.process, app.exit = exit.process, exit
+	logf(levelSuccess, Green+"Success: "+Reset+"%sApplication started (PID: %d), proxy switched to :%d\n", app.label(), app.process.Pid, port)
+
+	if previous != nil {
+		app.terminate(previous, previousExit)
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProxyHoldsUntilSwitch(t *testing.T) {
+	backend := func(name string) (*httptest.Server, int) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, name)
+		}))
+		u, _ := url.Parse(srv.URL)
+		port, _ := strconv.Atoi(u.Port())
+		return srv, port
+	}
+
+	p := newProxyMode(ProxyConfig{HoldTimeout: 5 * time.Second})
+	proxy := httptest.NewServer(p)
+	defer proxy.Close()
+
+	get := func() string {
+		resp, err := http.Get(proxy.URL)
+		if err != nil {
+			t.Errorf("Request through proxy failed: %v", err)
+			return ""
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	// A request arriving before any process is up waits for the first one
+	first, firstPort := backend("first")
+	defer first.Close()
+	held := make(chan string)
+	go func() { held <- get() }()
+	time.Sleep(50 * time.Millisecond)
+	p.switchTo(firstPort)
+	if body := <-held; body != "first" {
+		t.Errorf("Expected held request to reach the first process, got %q", body)
+	}
+
+	second, secondPort := backend("second")
+	defer second.Close()
+	p.switchTo(secondPort)
+	if body := get(); body != "second" {
+		t.Errorf("Expected requests to reach the new process after a switch, got %q", body)
+	}
+}
+
+func TestProxyHoldTimeout(t *testing.T) {
+	p := newProxyMode(ProxyConfig{HoldTimeout: 50 * time.Millisecond})
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a running process, got %d", rec.Code)
+	}
+}
+
+func TestProxyWaitHealthy(t *testing.T) {
+	var ready atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" || !ready.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+	port, _ := strconv.Atoi(u.Port())
+
+	p := newProxyMode(ProxyConfig{HealthPath: "/healthz", StartTimeout: 300 * time.Millisecond})
+	if err := p.waitHealthy(port, nil); err == nil {
+		t.Error("Expected an unhealthy process to time out")
+	}
+
+	ready.Store(true)
+	if err := p.waitHealthy(port, nil); err != nil {
+		t.Errorf("Expected a healthy process, got %v", err)
+	}
+}
+
+func TestHandoffFailsFastWhenTheProcessExits(t *testing.T) {
+	config := defaultConfig()
+	config.RunCmd = "exit 3"
+	config.Proxy.StartTimeout = 10 * time.Second
+	app := newWindApp(config, "", "")
+	app.proxy = newProxyMode(config.Proxy)
+
+	app.mutex.Lock()
+	defer app.mutex.Unlock()
+	started := time.Now()
+	if app.handoff(os.Environ()) {
+		t.Fatal("Expected the handoff to fail")
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("Expected the exit to end the health check early, took %s", elapsed)
+	}
+}
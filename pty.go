@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// ptyDrainTimeout is how long the output of a stopped application's
+// terminal is still copied
+const ptyDrainTimeout = time.Second
+
+// stdinFlag passes Wind's standard input on to the application (Stdin)
+const stdinFlag = "--stdin"
+
+// ptyFlag runs the application in a pseudo-terminal (PTY)
+const ptyFlag = "--pty"
+
+// stdinRelay passes Wind's standard input on to the running application,
+// across restarts. Input typed while no process runs is dropped.
+type stdinRelay struct {
+	mutex sync.Mutex
+	// w is the input of the current process, nil while none runs
+	w io.WriteCloser
+}
+
+// startStdinRelay passes r on to the attached process until r ends
+func startStdinRelay(r io.Reader) *stdinRelay {
+	s := &stdinRelay{}
+	go s.run(r)
+	return s
+}
+
+func (s *stdinRelay) run(r io.Reader) {
+	buf := make([]byte, 4096)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			// A process that does not read must not block attaching its
+			// successor, so the write happens outside the mutex
+			s.mutex.Lock()
+			w := s.w
+			s.mutex.Unlock()
+			if w != nil {
+				if _, err := w.Write(buf[:n]); err != nil {
+					s.detach(w)
+				}
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// attach sends the input to w from now on, closing the previous process's
+// input
+func (s *stdinRelay) attach(w io.WriteCloser) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.w != nil {
+		s.w.Close()
+	}
+	s.w = w
+}
+
+// detach stops sending input to w, unless another process took over
+func (s *stdinRelay) detach(w io.WriteCloser) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.w == w {
+		s.w.Close()
+		s.w = nil
+	}
+}
+
+// childIO holds the ends of the application's standard streams Wind keeps
+type childIO struct {
+	// pty is the master side of the application's terminal (PTY), input
+	// where Wind writes its standard input (Stdin)
+	pty   *os.File
+	input *os.File
+	// output receives what the application writes to its terminal; copied
+	// is closed once copyPTY finished
+	output io.Writer
+	copied chan struct{}
+	// inherited are the ends the application got, closed once it started
+	inherited []*os.File
+}
+
+// connectIO connects the standard streams of cmd: with PTY to a new
+// pseudo-terminal whose output Wind shows, otherwise to the output writers.
+// With Stdin the target's input comes from Wind's.
+func (app *WindApp) connectIO(cmd *exec.Cmd) (*childIO, error) {
+	c := &childIO{}
+	if app.config.PTY {
+		master, slave, err := openPTY()
+		if err != nil {
+			return nil, fmt.Errorf("failed to allocate a PTY: %w", err)
+		}
+		resizePTY(master)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = slave, slave, slave
+		cmd.SysProcAttr = ptyProcAttr()
+		c.pty, c.input, c.inherited = master, master, []*os.File{slave}
+		c.output, c.copied = app.runOutput(os.Stdout), make(chan struct{})
+		return c, nil
+	}
+
+	cmd.Stdout = app.runOutput(os.Stdout)
+	cmd.Stderr = app.runOutput(os.Stderr)
+	if app.stdin != nil {
+		r, w, err := os.Pipe()
+		if err != nil {
+			return nil, err
+		}
+		cmd.Stdin = r
+		c.input, c.inherited = w, []*os.File{r}
+	}
+	return c, nil
+}
+
+// started hands the streams over to the application once it started, or
+// closes them when it failed to
+func (c *childIO) started(app *WindApp, ok bool) {
+	for _, f := range c.inherited {
+		f.Close()
+	}
+	if !ok {
+		if c.input != nil {
+			c.input.Close()
+		}
+		return
+	}
+	if c.pty != nil {
+		go c.copyPTY()
+	}
+	if app.stdin != nil && c.input != nil {
+		app.stdin.attach(c.input)
+	}
+}
+
+// copyPTY shows the output of the application's terminal and keeps its size
+// in step with Wind's, until the application and its children closed it
+func (c *childIO) copyPTY() {
+	defer close(c.copied)
+	master := c.pty
+	resized := make(chan os.Signal, 1)
+	notifyResize(resized)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-resized:
+				resizePTY(master)
+			}
+		}
+	}()
+
+	// Reading fails with EIO once the last process using the terminal
+	// exited
+	io.Copy(c.output, master)
+	signal.Stop(resized)
+	close(done)
+	master.Close()
+}
+
+// wait waits until the output of the stopped application was copied.
+// Children it left behind may keep its terminal open, so the terminal is
+// closed after ptyDrainTimeout.
+func (c *childIO) wait() {
+	if c == nil || c.copied == nil {
+		return
+	}
+	select {
+	case <-c.copied:
+	case <-time.After(ptyDrainTimeout):
+		c.pty.Close()
+		<-c.copied
+	}
+}
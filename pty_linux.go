@@ -0,0 +1,84 @@
+//go:build linux
+
+package main
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// ptySupported reports whether openPTY works on this platform
+const ptySupported = true
+
+// openPTY allocates a pseudo-terminal. Output processing of the
+// application's side is off: Wind's own terminal translates newlines.
+func openPTY() (master, slave *os.File, err error) {
+	master, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	var n uint32
+	var unlock int32
+	if err := ioctl(master, syscall.TIOCGPTN, unsafe.Pointer(&n)); err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	if err := ioctl(master, syscall.TIOCSPTLCK, unsafe.Pointer(&unlock)); err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	slave, err = os.OpenFile("/dev/pts/"+strconv.Itoa(int(n)), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+
+	var attrs syscall.Termios
+	if err := ioctl(slave, syscall.TCGETS, unsafe.Pointer(&attrs)); err == nil {
+		attrs.Oflag &^= syscall.ONLCR
+		ioctl(slave, syscall.TCSETS, unsafe.Pointer(&attrs))
+	}
+	return master, slave, nil
+}
+
+// ptyProcAttr makes the PTY the controlling terminal of a new session, so
+// the application gets job control and SIGWINCH as in a terminal
+func ptyProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true, Setctty: true}
+}
+
+// resizePTY gives the pseudo-terminal the size of Wind's terminal, if it
+// runs in one
+func resizePTY(master *os.File) {
+	if terminalOut == nil {
+		return
+	}
+	var size struct{ rows, cols, x, y uint16 }
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, terminalOut.Fd(),
+		uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&size)))
+	if errno != 0 || size.cols == 0 {
+		return
+	}
+	ioctl(master, syscall.TIOCSWINSZ, unsafe.Pointer(&size))
+}
+
+// ioctl runs an ioctl on f without switching it to blocking mode
+func ioctl(f *os.File, request uintptr, arg unsafe.Pointer) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var errno syscall.Errno
+	err = conn.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(arg))
+	})
+	if err != nil {
+		return err
+	}
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
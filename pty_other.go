@@ -0,0 +1,20 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// ptySupported reports whether openPTY works on this platform
+const ptySupported = false
+
+func openPTY() (master, slave *os.File, err error) {
+	return nil, nil, errors.New("PTY mode is only supported on Linux")
+}
+
+func ptyProcAttr() *syscall.SysProcAttr { return nil }
+
+func resizePTY(master *os.File) {}
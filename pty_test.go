@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// closeBuffer is a buffer for the stdin relay that records being closed
+type closeBuffer struct {
+	mutex  sync.Mutex
+	buf    bytes.Buffer
+	closed bool
+}
+
+func (b *closeBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *closeBuffer) Close() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.closed = true
+	return nil
+}
+
+func (b *closeBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.String()
+}
+
+func TestStdinRelay(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	relay := startStdinRelay(r)
+	waitFor := func(b *closeBuffer, expected string) {
+		deadline := time.Now().Add(2 * time.Second)
+		for b.String() != expected {
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected %q, got %q", expected, b.String())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	first, second := &closeBuffer{}, &closeBuffer{}
+	relay.attach(first)
+	w.Write([]byte("one\n"))
+	waitFor(first, "one\n")
+
+	// A restarted process takes the input over
+	relay.attach(second)
+	w.Write([]byte("two\n"))
+	waitFor(second, "two\n")
+	if !first.closed || first.String() != "one\n" {
+		t.Errorf("Expected the first input to be closed after %q, got %q", "one\n", first.String())
+	}
+
+	// Detaching a process that was already replaced changes nothing
+	relay.detach(first)
+	relay.detach(second)
+	if !second.closed {
+		t.Error("Expected the detached input to be closed")
+	}
+}
+
+func TestLaunchInPTY(t *testing.T) {
+	if !ptySupported {
+		t.Skip("PTY mode is not supported on this platform")
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	app := newWindApp(WindConfig{
+		RunCmd: "[ -t 0 ] && [ -t 1 ] && [ -t 2 ] && echo tty > " + out + "; sleep 5",
+		PTY:    true,
+	}, "", "")
+	app.mutex.Lock()
+	if !app.launch(os.Environ()) {
+		t.Fatal("Expected the application to start")
+	}
+	streams := app.exit.streams
+	app.mutex.Unlock()
+	defer func() {
+		app.mutex.Lock()
+		app.stopProcess()
+		app.mutex.Unlock()
+		// The output must not be copied after the process was stopped
+		select {
+		case <-streams.copied:
+		default:
+			t.Error("Expected the terminal output to be copied before stopProcess returned")
+		}
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		data, _ := os.ReadFile(out)
+		if strings.TrimSpace(string(data)) == "tty" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected every standard stream of the application to be a terminal")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// buildTimeWindow is how many recent successful builds the ETA averages
+const buildTimeWindow = 5
+
+// queueRefresh is how often the queued rebuild line is updated
+const queueRefresh = time.Second
+
+// recordBuildTime adds the duration of a successful build to the rolling
+// average
+func (app *WindApp) recordBuildTime(d time.Duration) {
+	app.buildTimes = append(app.buildTimes, d)
+	if len(app.buildTimes) > buildTimeWindow {
+		app.buildTimes = app.buildTimes[1:]
+	}
+}
+
+// averageBuildTime returns the mean of times, or 0 without any
+func averageBuildTime(times []time.Duration) time.Duration {
+	if len(times) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, d := range times {
+		total += d
+	}
+	return total / time.Duration(len(times))
+}
+
+// queueETA estimates how long until a rebuild queued behind a build that
+// has been running for elapsed is done: what is left of the current build
+// plus one more. It is 0 before any build has succeeded.
+func queueETA(times []time.Duration, elapsed time.Duration) time.Duration {
+	avg := averageBuildTime(times)
+	if avg == 0 {
+		return 0
+	}
+	return max(avg-elapsed, 0) + avg
+}
+
+// formatQueue describes the rebuild queued behind the running build
+func formatQueue(changed int, eta time.Duration) string {
+	line := Yellow + "Queued: " + Reset + "1 rebuild queued"
+	if changed > 0 {
+		line += " (" + pluralize(changed, "file") + " changed)"
+	}
+	if eta > 0 {
+		line += fmt.Sprintf(" · ETA ~%s", max(eta.Round(time.Second), time.Second))
+	}
+	return line
+}
+
+// queuedChanges counts the known files modified since they were last seen.
+// It only reads fileStates, which builds leave alone.
+func (app *WindApp) queuedChanges() int {
+	changed := 0
+	for path, modTime := range app.fileStates {
+		if info, err := os.Stat(path); err == nil && info.ModTime().After(modTime) {
+			changed++
+		}
+	}
+	return changed
+}
+
+// watchQueue reports a rebuild queued behind the build started at started,
+// by a saved file, a rebuild request or an external event, until the
+// returned function is called. On a terminal the line is updated in place.
+func (app *WindApp) watchQueue(started time.Time) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	inPlace := app.name == "" && terminalOut != nil
+
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(queueRefresh)
+		defer ticker.Stop()
+		shown := false
+		for {
+			select {
+			case <-done:
+				if shown && inPlace {
+					fmt.Println()
+				}
+				return
+			case <-ticker.C:
+			}
+
+			changed := app.queuedChanges()
+			if changed == 0 && len(app.rebuildChan) == 0 && len(app.externalEvents) == 0 {
+				continue
+			}
+			line := app.label() + formatQueue(changed, queueETA(app.buildTimes, time.Since(started)))
+			switch {
+			case inPlace:
+				fmt.Print("\r\033[K" + line)
+			case !shown:
+				fmt.Println(line)
+			}
+			shown = true
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+	}
+}
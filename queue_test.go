@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordBuildTime(t *testing.T) {
+	app := &WindApp{}
+	for i := 1; i <= 7; i++ {
+		app.recordBuildTime(time.Duration(i) * time.Second)
+	}
+
+	if len(app.buildTimes) != buildTimeWindow {
+		t.Fatalf("Expected the last %d build times, got %v", buildTimeWindow, app.buildTimes)
+	}
+	if avg := averageBuildTime(app.buildTimes); avg != 5*time.Second {
+		t.Errorf("Expected an average of 5s over builds 3-7, got %s", avg)
+	}
+}
+
+func TestQueueETA(t *testing.T) {
+	times := []time.Duration{4 * time.Second, 6 * time.Second}
+	tests := []struct {
+		elapsed  time.Duration
+		expected time.Duration
+	}{
+		{0, 10 * time.Second},
+		{3 * time.Second, 7 * time.Second},
+		// A build running longer than usual leaves one more build
+		{8 * time.Second, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := queueETA(times, tt.elapsed); got != tt.expected {
+			t.Errorf("queueETA(%s) = %s, expected %s", tt.elapsed, got, tt.expected)
+		}
+	}
+	if got := queueETA(nil, time.Second); got != 0 {
+		t.Errorf("Expected no ETA without build history, got %s", got)
+	}
+}
+
+func TestFormatQueue(t *testing.T) {
+	if got := formatQueue(2, 7400*time.Millisecond); !strings.HasSuffix(got, "1 rebuild queued (2 files changed) · ETA ~7s") {
+		t.Errorf("Unexpected queue line: %q", got)
+	}
+	if got := formatQueue(0, 0); !strings.HasSuffix(got, "1 rebuild queued") {
+		t.Errorf("Expected no details for a rebuild request, got %q", got)
+	}
+	if got := formatQueue(1, 200*time.Millisecond); !strings.HasSuffix(got, "ETA ~1s") {
+		t.Errorf("Expected the ETA to be at least 1s, got %q", got)
+	}
+}
+
+func TestQueuedChanges(t *testing.T) {
+	dir := t.TempDir()
+	saved := filepath.Join(dir, "main.go")
+	untouched := filepath.Join(dir, "util.go")
+	for _, path := range []string{saved, untouched} {
+		if err := os.WriteFile(path, []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	seen := time.Now().Add(-time.Minute)
+	os.Chtimes(saved, seen, seen)
+	os.Chtimes(untouched, seen, seen)
+
+	app := &WindApp{fileStates: map[string]time.Time{saved: seen, untouched: seen}}
+	if got := app.queuedChanges(); got != 0 {
+		t.Errorf("Expected no queued changes, got %d", got)
+	}
+	os.Chtimes(saved, time.Now(), time.Now())
+	if got := app.queuedChanges(); got != 1 {
+		t.Errorf("Expected 1 queued change, got %d", got)
+	}
+}
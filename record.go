@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// recordFlag names the option that records the session to a cast file
+const recordFlag = "--record-session"
+
+// sessionRecorder tees everything Wind and its child processes write to the
+// terminal into an asciinema v2 cast file
+type sessionRecorder struct {
+	file     *os.File
+	start    time.Time
+	mutex    sync.Mutex
+	redirect *outputRedirect
+}
+
+// castHeader is the first line of an asciinema v2 file
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// startRecording redirects os.Stdout and os.Stderr through the recorder.
+// Child processes started afterwards inherit the redirected streams.
+func startRecording(path string) (*sessionRecorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := terminalSize()
+	header, _ := json.Marshal(castHeader{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: time.Now().Unix(),
+		Env:       map[string]string{"SHELL": os.Getenv("SHELL"), "TERM": os.Getenv("TERM")},
+	})
+	if _, err := fmt.Fprintf(file, "%s\n", header); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	r := &sessionRecorder{file: file, start: time.Now()}
+	r.redirect, err = redirectOutput(func(dst *os.File) io.Writer {
+		return &castStream{dst: dst, recorder: r}
+	})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// castStream copies one output stream to the terminal and records it
+type castStream struct {
+	dst      io.Writer
+	recorder *sessionRecorder
+	// pending holds a multi-byte character split across writes
+	pending []byte
+}
+
+func (c *castStream) Write(data []byte) (int, error) {
+	var complete []byte
+	complete, c.pending = splitUTF8(append(c.pending, data...))
+	c.recorder.event(complete)
+	return c.dst.Write(data)
+}
+
+// event appends an output event with the time since the recording started
+func (r *sessionRecorder) event(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	line, _ := json.Marshal([]any{
+		json.Number(strconv.FormatFloat(time.Since(r.start).Seconds(), 'f', 6, 64)),
+		"o",
+		string(data),
+	})
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	fmt.Fprintf(r.file, "%s\n", line)
+}
+
+// stop restores the original streams and finishes the cast file
+func (r *sessionRecorder) stop() {
+	r.redirect.restore()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.file.Close()
+}
+
+// splitUTF8 splits data before a trailing incomplete UTF-8 sequence, which
+// is kept for the next read so multi-byte characters are not mangled
+func splitUTF8(data []byte) (complete, rest []byte) {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(data[i]) {
+			continue
+		}
+		if !utf8.FullRune(data[i:]) {
+			return data[:i], append([]byte(nil), data[i:]...)
+		}
+		break
+	}
+	return data, nil
+}
+
+// terminalSize returns the size of the controlling terminal, defaulting to
+// 80x24
+func terminalSize() (width, height int) {
+	width, height = 80, 24
+	out, err := stty("size")
+	if err != nil {
+		return width, height
+	}
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return width, height
+	}
+	if rows, err := strconv.Atoi(fields[0]); err == nil && rows > 0 {
+		height = rows
+	}
+	if cols, err := strconv.Atoi(fields[1]); err == nil && cols > 0 {
+		width = cols
+	}
+	return width, height
+}
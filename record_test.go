@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractRecordFlag(t *testing.T) {
+	tests := []struct {
+		args     []string
+		rest     []string
+		expected string
+	}{
+		{[]string{"--record-session", "out.cast", "run", "api"}, []string{"run", "api"}, "out.cast"},
+		{[]string{"ab", "--record-session=ab.cast"}, []string{"ab"}, "ab.cast"},
+		{[]string{"init"}, []string{"init"}, ""},
+	}
+
+	for _, tt := range tests {
+		rest, path, err := extractValueFlag(tt.args, recordFlag)
+		if err != nil {
+			t.Fatalf("extractValueFlag(%v) failed: %v", tt.args, err)
+		}
+		if path != tt.expected || !reflect.DeepEqual(rest, tt.rest) {
+			t.Errorf("extractValueFlag(%v) = %v, %q; expected %v, %q", tt.args, rest, path, tt.rest, tt.expected)
+		}
+	}
+
+	if _, _, err := extractValueFlag([]string{"--record-session"}, recordFlag); err == nil {
+		t.Error("Expected an error when the file name is missing")
+	}
+}
+
+func TestSplitUTF8(t *testing.T) {
+	data := []byte("ok 🌪")
+	complete, rest := splitUTF8(data[:len(data)-2])
+	if string(complete) != "ok " || len(rest) != 2 {
+		t.Errorf("Expected the partial rune to be held back, got %q + %d bytes", complete, len(rest))
+	}
+
+	complete, rest = splitUTF8(data)
+	if string(complete) != "ok 🌪" || rest != nil {
+		t.Errorf("Expected complete input to pass through, got %q + %q", complete, rest)
+	}
+}
+
+func TestSessionRecording(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.cast")
+	recorder, err := startRecording(path)
+	if err != nil {
+		t.Fatalf("startRecording failed: %v", err)
+	}
+	fmt.Println("Build #1 successful")
+	fmt.Fprintln(os.Stderr, "Build #2 failed")
+	recorder.stop()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open cast file: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Scan()
+	var header castHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil || header.Version != 2 {
+		t.Fatalf("Invalid cast header %q: %v", scanner.Text(), err)
+	}
+
+	var output strings.Builder
+	for scanner.Scan() {
+		var event []any
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || len(event) != 3 || event[1] != "o" {
+			t.Fatalf("Invalid cast event %q: %v", scanner.Text(), err)
+		}
+		output.WriteString(event[2].(string))
+	}
+	for _, expected := range []string{"Build #1 successful\n", "Build #2 failed\n"} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("Expected recording to contain %q, got %q", expected, output.String())
+		}
+	}
+}
+
+func TestRecordingKeepsTerminal(t *testing.T) {
+	if !ptySupported {
+		t.Skip("PTY mode is not supported on this platform")
+	}
+	master, slave, err := openPTY()
+	if err != nil {
+		t.Fatalf("openPTY failed: %v", err)
+	}
+	defer master.Close()
+	defer slave.Close()
+	// Wind started on a terminal, which the recorder's pipe replaced
+	saved := terminalOut
+	terminalOut = slave
+	defer func() { terminalOut = saved }()
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+
+	if config, ok := loadWatchConfig(watchOptions{recording: true}); !ok || !config.PTY {
+		t.Error("Expected the application to run in a PTY while recording on a terminal")
+	}
+	if config, ok := loadWatchConfig(watchOptions{}); !ok || config.PTY {
+		t.Error("Expected no PTY without recording")
+	}
+}
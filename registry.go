@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+)
+
+// sharedDirEnv overrides defaultSharedDir
+const sharedDirEnv = "WIND_SHARED_DIR"
+
+// defaultSharedDir holds the registries of every user in shared mode. It
+// survives reboots less often than /tmp is cleaned.
+const defaultSharedDir = "/var/tmp/wind"
+
+// sharedRegistry is set in shared mode: ports and instances are registered
+// machine-wide instead of per user, so teammates on one dev box see each
+// other
+var sharedRegistry bool
+
+func sharedDir() string {
+	if dir := os.Getenv(sharedDirEnv); dir != "" {
+		return dir
+	}
+	return defaultSharedDir
+}
+
+// registryPath returns where the registry file (e.g. wind/ports.json) of
+// this session lives: under os.UserConfigDir, or the shared directory in
+// shared mode
+func registryPath(file string) (string, error) {
+	if sharedRegistry {
+		return filepath.Join(sharedDir(), filepath.Base(file)), nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, file), nil
+}
+
+// registryPaths returns every registry file to list: the user's own and,
+// on a machine where shared mode was used, the shared one
+func registryPaths(file string) []string {
+	var paths []string
+	if dir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(dir, file))
+	}
+	if info, err := os.Stat(sharedDir()); err == nil && info.IsDir() {
+		paths = append(paths, filepath.Join(sharedDir(), filepath.Base(file)))
+	}
+	return paths
+}
+
+// currentUser names the owner of this session
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// readJSON decodes the file at path into v; a missing file leaves v as is
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// updateJSON decodes the file at path into v, applies fn and writes v back,
+// holding a lock against other Wind sessions. The shared directory is made
+// writable by every user; files are replaced rather than rewritten, so
+// they need not be.
+func updateJSON(path string, v any, fn func() error) error {
+	dir := filepath.Dir(path)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		if sharedRegistry {
+			os.Chmod(dir, 0777)
+		}
+	}
+	unlock, err := lockPath(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := readJSON(path, v); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	os.Remove(tmp)
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ownedBy names the owner of another user's session, e.g. ", alice", and is
+// empty for this user's own
+func ownedBy(owner string) string {
+	if owner == "" || owner == currentUser() {
+		return ""
+	}
+	return ", " + owner
+}
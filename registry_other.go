@@ -0,0 +1,33 @@
+//go:build !unix
+
+package main
+
+import (
+	"os"
+	"time"
+)
+
+// staleLockAge is how old a lock directory is taken to be left behind by a
+// session that crashed while holding it
+const staleLockAge = 10 * time.Second
+
+// lockPath takes an exclusive lock on path, waiting for other Wind sessions
+// to release it. Without flock the lock is a directory, path+".lock.d",
+// since creating one fails while it exists.
+func lockPath(path string) (unlock func(), err error) {
+	dir := path + ".lock.d"
+	for {
+		err := os.Mkdir(dir, 0755)
+		if err == nil {
+			return func() { os.Remove(dir) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, err := os.Stat(dir); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(dir)
+			continue
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
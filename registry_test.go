@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistryPath(t *testing.T) {
+	config := t.TempDir()
+	shared := filepath.Join(t.TempDir(), "wind")
+	t.Setenv("XDG_CONFIG_HOME", config)
+	t.Setenv(sharedDirEnv, shared)
+
+	if got, _ := registryPath(portRegistryFile); got != filepath.Join(config, "wind", "ports.json") {
+		t.Errorf("registryPath() = %q, expected the user's config dir", got)
+	}
+	if got := registryPaths(portRegistryFile); len(got) != 1 {
+		t.Errorf("Expected only the user's registry before shared mode was used, got %q", got)
+	}
+
+	sharedRegistry = true
+	defer func() { sharedRegistry = false }()
+	path, _ := registryPath(portRegistryFile)
+	if path != filepath.Join(shared, "ports.json") {
+		t.Errorf("registryPath() = %q, expected the shared dir", path)
+	}
+
+	var registry portRegistry
+	if err := updateJSON(path, &registry, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(shared)
+	if err != nil || info.Mode().Perm() != 0777 {
+		t.Errorf("Expected the shared dir to be writable by every user, got %v (%v)", info.Mode(), err)
+	}
+	if got := registryPaths(portRegistryFile); len(got) != 2 || got[1] != path {
+		t.Errorf("Expected the shared registry to be listed too, got %q", got)
+	}
+}
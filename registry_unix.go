@@ -0,0 +1,27 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockPath takes an exclusive lock on path+".lock", waiting for other Wind
+// sessions to release it
+func lockPath(path string) (unlock func(), err error) {
+	// A read-only descriptor is enough to lock, and works for lock files
+	// other users created
+	lock, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		lock.Close()
+		return nil, err
+	}
+	return func() {
+		syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+		lock.Close()
+	}, nil
+}
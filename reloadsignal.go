@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"syscall"
+)
+
+// ReloadSignalConfig configures soft reloads: when only files matching
+// Patterns changed, Wind signals the running app, which reloads them itself,
+// instead of rebuilding and restarting it. Open connections survive.
+type ReloadSignalConfig struct {
+	// Patterns select the files the app reloads on its own (globs as in
+	// generator rules); empty disables soft reloads. Every file changed in
+	// a cycle must match.
+	Patterns []string
+	// Signal is sent to the app, SIGHUP by default
+	Signal string
+}
+
+// softReloadable reports whether every file changed in this cycle matches a
+// ReloadSignal pattern
+func (app *WindApp) softReloadable() bool {
+	if len(app.config.ReloadSignal.Patterns) == 0 || len(app.changedFiles) == 0 {
+		return false
+	}
+	for _, path := range app.changedFiles {
+		matched := false
+		for _, pattern := range app.config.ReloadSignal.Patterns {
+			if matchPattern(pattern, path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// signalReload sends ReloadSignal to the running app when the cycle's
+// changes allow it, and reports whether that replaced the restart
+func (app *WindApp) signalReload() bool {
+	if !app.softReloadable() {
+		return false
+	}
+
+	app.mutex.Lock()
+	process := app.process
+	app.mutex.Unlock()
+	if process == nil {
+		return false
+	}
+
+	// The signal was validated with the config
+	sig, _ := parseSignal(app.config.ReloadSignal.Signal)
+	if err := process.Signal(sig); err != nil {
+		logf(levelInfo, Yellow+"Info: "+Reset+"%sFailed to send %s (%v), restarting\n", app.label(), signalName(sig), err)
+		return false
+	}
+
+	files := make([]string, len(app.changedFiles))
+	for i, path := range app.changedFiles {
+		files[i] = filepath.ToSlash(path)
+	}
+	logf(levelSuccess, Green+"Success: "+Reset+"%sSent %s to the application (PID: %d) for %s, no restart\n",
+		app.label(), signalName(sig), process.Pid, describeChanged(files))
+	if app.liveReload != nil {
+		go app.liveReload.reload()
+	}
+	return true
+}
+
+// validateReloadSignal checks the ReloadSignal settings
+func validateReloadSignal(config ReloadSignalConfig) error {
+	if sig, ok := parseSignal(config.Signal); !ok || sig == syscall.SIGTERM {
+		return fmt.Errorf("invalid reloadSignal.signal %q (expected SIGHUP, SIGUSR1, SIGUSR2, SIGINT or SIGQUIT)", config.Signal)
+	}
+	for _, pattern := range config.Patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid reloadSignal pattern %q", pattern)
+		}
+	}
+	return nil
+}
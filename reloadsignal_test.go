@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSignalReload(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "reloaded")
+	cmd := exec.Command("sh", "-c", "trap 'touch "+marker+"' HUP; while :; do sleep 0.05; done")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start process: %v", err)
+	}
+	defer cmd.Process.Kill()
+	time.Sleep(100 * time.Millisecond)
+
+	config := defaultConfig()
+	config.ReloadSignal.Patterns = []string{"config/*.yaml"}
+	app := newWindApp(config, "", "")
+
+	// Without a running process the usual restart applies
+	app.changedFiles = []string{"config/app.yaml"}
+	if app.signalReload() {
+		t.Error("Expected no soft reload without a running process")
+	}
+
+	app.process = cmd.Process
+	if !app.signalReload() {
+		t.Fatal("Expected a soft reload for config changes")
+	}
+	for i := 0; i < 50; i++ {
+		if _, err := os.Stat(marker); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Error("Expected the application to receive SIGHUP")
+	}
+
+	// Any other change in the cycle needs a restart
+	app.changedFiles = []string{"config/app.yaml", "main.go"}
+	if app.signalReload() {
+		t.Error("Expected cycles with other changes to restart")
+	}
+}
+
+func TestValidateReloadSignal(t *testing.T) {
+	valid := ReloadSignalConfig{Patterns: []string{"config/*.yaml"}, Signal: "usr1"}
+	if err := validateReloadSignal(valid); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	for _, config := range []ReloadSignalConfig{
+		{Signal: "SIGTERM"},
+		{Signal: "SIGKILL"},
+		{Signal: "SIGHUP", Patterns: []string{"[config"}},
+	} {
+		if err := validateReloadSignal(config); err == nil {
+			t.Errorf("Expected an error for %+v", config)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RemoteBuildConfig offloads builds of large services to a faster machine
+// over SSH: the source is synced there, built, and the binary copied back
+type RemoteBuildConfig struct {
+	// Host is the SSH destination of the builder, e.g. build@builder.lan;
+	// empty builds locally
+	Host string
+	// Path is the directory the source is synced to, relative to the remote
+	// home directory; wind-build/<project> by default
+	Path string
+	// Threshold switches to the builder once a local build takes longer;
+	// zero always builds remotely
+	Threshold time.Duration
+}
+
+// remoteBuildExcludes are never synced to the builder. Excluded paths are
+// also kept by --delete, so the remote build outputs in tmp survive.
+var remoteBuildExcludes = []string{".git", "tmp", "node_modules"}
+
+// remoteBuildState tracks when a target builds remotely
+type remoteBuildState struct {
+	// slow is set once a local build exceeded the threshold
+	slow bool
+	// down is set once the builder failed without compile errors, such as
+	// when it is unreachable; builds are local for the rest of the session
+	down bool
+}
+
+// validateRemoteBuild checks the remoteBuild settings
+func validateRemoteBuild(config WindConfig) error {
+	r := config.RemoteBuild
+	switch {
+	case r.Host == "" && (r.Path != "" || r.Threshold != 0):
+		return fmt.Errorf("remoteBuild requires a host")
+	case r.Threshold < 0:
+		return fmt.Errorf("remoteBuild.threshold must not be negative")
+	case r.Host != "" && config.Docker.enabled():
+		return fmt.Errorf("remoteBuild cannot be combined with docker")
+	}
+	return nil
+}
+
+// remotePath is the directory the source is synced to
+func (r RemoteBuildConfig) remotePath(project string) string {
+	if r.Path != "" {
+		return r.Path
+	}
+	return "wind-build/" + filepath.Base(project)
+}
+
+// remoteBuildCommand wraps command, run in the project root, so it syncs
+// the source to the builder, runs command there for the local platform and
+// copies binary back
+func remoteBuildCommand(r RemoteBuildConfig, project, command, binary string, env []string) string {
+	ssh := "ssh " + strings.Join(sshOptions, " ")
+	dir := r.remotePath(project)
+	binary = filepath.ToSlash(filepath.Clean(binary))
+
+	sync := "rsync -az --delete -e " + shellQuote(ssh)
+	for _, exclude := range remoteBuildExcludes {
+		sync += " --exclude " + shellQuote("/"+exclude)
+	}
+	sync += " ./ " + shellQuote(r.Host+":"+dir+"/")
+
+	remote := "mkdir -p " + shellQuote(filepath.Dir(filepath.Join(dir, binary))) + " && cd " + shellQuote(dir) + " && "
+	for _, v := range env {
+		remote += shellQuote(v) + " "
+	}
+	remote += command
+
+	fetch := "scp -q " + strings.Join(sshOptions, " ") + " " + shellQuote(r.Host+":"+dir+"/"+binary) + " " + shellQuote(binary)
+	return sync + " && " + ssh + " " + shellQuote(r.Host) + " " + shellQuote(remote) + " && " + fetch
+}
+
+// remoteBuildEnv is the environment of a remote build, making the builder
+// compile for the platform the binary runs on
+func (app *WindApp) remoteBuildEnv() []string {
+	goos, goarch, _ := strings.Cut(app.buildPlatform(), "/")
+	env := []string{"GOOS=" + goos, "GOARCH=" + goarch}
+	if app.config.GoExperiment != "" {
+		env = append(env, "GOEXPERIMENT="+app.config.GoExperiment)
+	}
+	return env
+}
+
+// buildsRemotely reports whether the next build runs on the builder
+func (app *WindApp) buildsRemotely() bool {
+	r := app.config.RemoteBuild
+	return r.Host != "" && !app.remoteBuild.down && (r.Threshold == 0 || app.remoteBuild.slow)
+}
+
+// recordLocalBuild switches to the builder once a local build took longer
+// than the threshold
+func (app *WindApp) recordLocalBuild(d time.Duration) {
+	r := app.config.RemoteBuild
+	if r.Host == "" || app.remoteBuild.slow || app.remoteBuild.down || d <= r.Threshold {
+		return
+	}
+	app.remoteBuild.slow = true
+	logf(levelInfo, Cyan+"Info: "+Reset+"%sBuild took %s (threshold %s), building on %s from now on\n",
+		app.label(), d.Round(time.Millisecond), r.Threshold, r.Host)
+}
+
+// remoteBuildFailed falls back to local builds when the builder failed
+// without reporting compile errors
+func (app *WindApp) remoteBuildFailed() {
+	app.remoteBuild.down = true
+	logf(levelWarn, Yellow+"Warning: "+Reset+"%sRemote build on %s failed, building locally from now on\n", app.label(), app.config.RemoteBuild.Host)
+}
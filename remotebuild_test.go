@@ -0,0 +1,130 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRemoteBuildCommand(t *testing.T) {
+	r := RemoteBuildConfig{Host: "build@builder.lan"}
+	got := remoteBuildCommand(r, "/home/me/shop", "go build -o ./tmp/main .", "./tmp/main", []string{"GOOS=linux", "GOARCH=amd64"})
+	expected := "rsync -az --delete -e 'ssh -o BatchMode=yes' --exclude /.git --exclude /tmp --exclude /node_modules ./ build@builder.lan:wind-build/shop/" +
+		" && ssh -o BatchMode=yes build@builder.lan 'mkdir -p wind-build/shop/tmp && cd wind-build/shop && GOOS=linux GOARCH=amd64 go build -o ./tmp/main .'" +
+		" && scp -q -o BatchMode=yes build@builder.lan:wind-build/shop/tmp/main tmp/main"
+	if got != expected {
+		t.Errorf("remoteBuildCommand() =\n%s\nexpected\n%s", got, expected)
+	}
+
+	r.Path = "/srv/build/shop"
+	if got := remoteBuildCommand(r, "/home/me/shop", "go build", "app", nil); !strings.Contains(got, " build@builder.lan:/srv/build/shop/ ") {
+		t.Errorf("Expected the configured path, got %s", got)
+	}
+}
+
+func TestValidateRemoteBuild(t *testing.T) {
+	valid := WindConfig{RemoteBuild: RemoteBuildConfig{Host: "builder", Threshold: 30 * time.Second}}
+	if err := validateRemoteBuild(valid); err != nil {
+		t.Errorf("Expected valid remoteBuild, got %v", err)
+	}
+
+	for _, config := range []WindConfig{
+		{RemoteBuild: RemoteBuildConfig{Threshold: time.Second}},
+		{RemoteBuild: RemoteBuildConfig{Path: "build"}},
+		{RemoteBuild: RemoteBuildConfig{Host: "builder", Threshold: -time.Second}},
+		{RemoteBuild: RemoteBuildConfig{Host: "builder"}, Docker: DockerConfig{Image: "shop:dev"}},
+	} {
+		if err := validateRemoteBuild(config); err == nil {
+			t.Errorf("Expected %+v to be invalid", config.RemoteBuild)
+		}
+	}
+}
+
+func TestBuildsRemotelyAfterThreshold(t *testing.T) {
+	app := newWindApp(WindConfig{RemoteBuild: RemoteBuildConfig{Host: "builder", Threshold: 10 * time.Second}}, "", "")
+	if app.buildsRemotely() {
+		t.Error("Expected local builds until one exceeds the threshold")
+	}
+	app.recordLocalBuild(5 * time.Second)
+	if app.buildsRemotely() {
+		t.Error("Expected a fast build to stay local")
+	}
+	app.recordLocalBuild(12 * time.Second)
+	if !app.buildsRemotely() {
+		t.Error("Expected a slow build to switch to the builder")
+	}
+	app.remoteBuildFailed()
+	if app.buildsRemotely() {
+		t.Error("Expected a failed builder to switch back to local builds")
+	}
+
+	always := newWindApp(WindConfig{RemoteBuild: RemoteBuildConfig{Host: "builder"}}, "", "")
+	if !always.buildsRemotely() {
+		t.Error("Expected a zero threshold to always build remotely")
+	}
+}
+
+// fakeRemote puts rsync, ssh and scp first on PATH that work on the local
+// file system: ssh runs the remote command in the current directory and
+// scp drops the host from its source
+func fakeRemote(t *testing.T, rsyncExit int) {
+	dir := t.TempDir()
+	scripts := map[string]string{
+		"rsync": "#!/bin/sh\nexit " + strconv.Itoa(rsyncExit) + "\n",
+		"ssh":   "#!/bin/sh\nexec sh -c \"$4\"\n",
+		"scp":   "#!/bin/sh\ncp \"${4#*:}\" \"$5\"\n",
+	}
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestRemoteBuild(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	os.Mkdir("tmp", 0755)
+	fakeRemote(t, 0)
+
+	app := newWindApp(WindConfig{
+		BuildCmd:    `printf remote > out; : -o out`,
+		RemoteBuild: RemoteBuildConfig{Host: "builder", Path: "remote"},
+	}, "", "")
+	if !app.build() {
+		t.Fatal("Expected the remote build to succeed")
+	}
+	if data, _ := os.ReadFile("out"); string(data) != "remote" {
+		t.Errorf("Expected the binary to be copied back, got %q", data)
+	}
+	if _, err := os.Stat(filepath.Join("remote", "out")); err != nil {
+		t.Errorf("Expected the build to run in the remote path: %v", err)
+	}
+}
+
+func TestRemoteBuildFallsBackToLocal(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	os.Mkdir("tmp", 0755)
+	fakeRemote(t, 9)
+
+	app := newWindApp(WindConfig{
+		BuildCmd:    `printf local > out; : -o out`,
+		RemoteBuild: RemoteBuildConfig{Host: "builder"},
+	}, "", "")
+	if !app.build() {
+		t.Fatal("Expected the local build to succeed after the builder failed")
+	}
+	if !app.remoteBuild.down {
+		t.Error("Expected the builder to be marked as down")
+	}
+	if data, _ := os.ReadFile("out"); string(data) != "local" {
+		t.Errorf("Expected a local build, got %q", data)
+	}
+}
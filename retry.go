@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+)
+
+// defaultTransientPatterns classify build failures that usually pass on a
+// second try: network errors while fetching modules, a binary still being
+// written or executed, and a compiler or linker killed by the system
+var defaultTransientPatterns = []string{
+	`dial tcp .*: (i/o timeout|connection refused|connection reset by peer)`,
+	`(?i)temporary failure in name resolution`,
+	`net/http: TLS handshake timeout`,
+	`reading https?://\S+: (50[234] |.*unexpected EOF|.*timeout)`,
+	`text file busy`,
+	`resource temporarily unavailable`,
+	`/(compile|link|asm|cgo): signal: (killed|bus error)`,
+}
+
+// RetryConfig retries builds whose failures look transient, so a flaky
+// network or a busy linker doesn't cost a manual rebuild
+type RetryConfig struct {
+	// Attempts is how often a transient failure is retried (1 by default);
+	// 0 turns retries off
+	Attempts int
+	// Backoff is the wait before the first retry, doubled for every further
+	// retry (2s by default)
+	Backoff time.Duration
+	// Patterns are the regular expressions that mark build output as a
+	// transient failure; they replace the defaults
+	Patterns []string
+}
+
+// validateRetry checks the retry settings
+func validateRetry(config RetryConfig) error {
+	if config.Attempts < 0 {
+		return fmt.Errorf("retry.attempts must not be negative")
+	}
+	if config.Backoff < 0 {
+		return fmt.Errorf("retry.backoff must not be negative")
+	}
+	for i, pattern := range config.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("retry.patterns[%d]: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// transientFailure returns the part of a failed build's output that marks
+// the failure as transient, or "" when it looks like a real one
+func transientFailure(patterns []string, output string) string {
+	for _, pattern := range patterns {
+		// The patterns were validated with the config
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			continue
+		}
+		if match := re.FindString(output); match != "" {
+			return match
+		}
+	}
+	return ""
+}
+
+// retryBuild decides whether a build that failed without compile errors is
+// retried. It waits for the backoff and reports true when the failure looks
+// transient and attempts are left.
+func (app *WindApp) retryBuild(output string, err error) bool {
+	if app.buildRetries >= app.config.Retry.Attempts {
+		return false
+	}
+	match := transientFailure(app.config.Retry.Patterns, output)
+	if match == "" {
+		return false
+	}
+	backoff := app.config.Retry.Backoff << app.buildRetries
+	app.buildRetries++
+	app.output(os.Stderr).Write([]byte(output))
+	app.emit(event{Event: "build_retry", Build: app.buildID, Error: err.Error()})
+	logf(levelWarn, Yellow+"Warning: "+Reset+"%sBuild #%d failed with a transient error (%s), retrying in %s (%d/%d)...\n",
+		app.label(), app.buildID, match, backoff, app.buildRetries, app.config.Retry.Attempts)
+	time.Sleep(backoff)
+	return true
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestTransientFailure(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected string
+	}{
+		{"go: example.com/lib@v1.2.0: Get \"https://proxy.golang.org/example.com/lib/@v/v1.2.0.zip\": dial tcp 142.250.74.81:443: i/o timeout", "dial tcp 142.250.74.81:443: i/o timeout"},
+		{"go: downloading example.com/lib v1.2.0\ngo: reading https://proxy.golang.org/example.com/lib/@v/list: 502 Bad Gateway", "reading https://proxy.golang.org/example.com/lib/@v/list: 502 "},
+		{"open tmp/main: text file busy", "text file busy"},
+		{"go build example.com/app: /usr/local/go/pkg/tool/linux_amd64/link: signal: killed", "/link: signal: killed"},
+		{"./main.go:5:2: undefined: foo", ""},
+		{"go: example.com/lib@v1.2.0: missing go.sum entry", ""},
+	}
+	for _, tt := range tests {
+		if got := transientFailure(defaultTransientPatterns, tt.output); got != tt.expected {
+			t.Errorf("transientFailure(%q) = %q, expected %q", tt.output, got, tt.expected)
+		}
+	}
+	if got := transientFailure([]string{`flaky`}, "text file busy"); got != "" {
+		t.Errorf("Configured patterns should replace the defaults, got %q", got)
+	}
+}
+
+func TestValidateRetry(t *testing.T) {
+	for _, config := range []RetryConfig{{}, {Attempts: 2, Patterns: []string{`i/o timeout`}}} {
+		if err := validateRetry(config); err != nil {
+			t.Errorf("Expected %+v to be valid, got %v", config, err)
+		}
+	}
+	for _, config := range []RetryConfig{{Attempts: -1}, {Attempts: 1, Backoff: -1}, {Attempts: 1, Patterns: []string{`(`}}} {
+		if err := validateRetry(config); err == nil {
+			t.Errorf("Expected %+v to be invalid", config)
+		}
+	}
+}
+
+func TestBuildRetriesTransientFailure(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	os.Mkdir("tmp", 0755)
+
+	// Fails with a transient error the first time only
+	app := newWindApp(WindConfig{
+		BuildCmd: `if [ ! -e tried ]; then touch tried; echo "text file busy" >&2; exit 1; fi`,
+		Retry:    RetryConfig{Attempts: 1, Patterns: defaultTransientPatterns},
+	}, "", "")
+	if !app.build() {
+		t.Fatal("Expected the retried build to succeed")
+	}
+	if app.buildRetries != 0 {
+		t.Errorf("Expected the retries to be reset after a success, got %d", app.buildRetries)
+	}
+
+	// A failure that keeps happening fails once the attempts are used up
+	app.config.BuildCmd = `echo "text file busy" >&2; echo x >> runs; exit 1`
+	if app.build() {
+		t.Fatal("Expected the build to fail")
+	}
+	if data, _ := os.ReadFile("runs"); string(data) != "x\nx\n" {
+		t.Errorf("Expected one build and one retry, got %q", data)
+	}
+
+	// Real failures are not retried
+	os.Remove("runs")
+	app.config.BuildCmd = `echo "./main.go:5:2: undefined: foo" >&2; echo x >> runs; exit 1`
+	if app.build() {
+		t.Fatal("Expected the build to fail")
+	}
+	if data, _ := os.ReadFile("runs"); string(data) != "x\n" {
+		t.Errorf("Expected no retry of a compile error, got %q", data)
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// rollbackDir holds the last binaries that started without crashing, named
+// after their SHA-256
+const rollbackDir = "tmp/rollback"
+
+// RollbackConfig keeps the binaries that started fine, so a build that
+// crashes at startup doesn't leave the session without a running app
+type RollbackConfig struct {
+	// Keep is how many good binaries are kept; 0 (the default) turns
+	// rollbacks off
+	Keep int
+	// Window is how long a new binary has to run to count as good (5s by
+	// default). Failing before is a crash at startup.
+	Window time.Duration
+}
+
+// validateRollback checks the rollback settings
+func validateRollback(config RollbackConfig) error {
+	if config.Keep < 0 {
+		return fmt.Errorf("rollback.keep must not be negative")
+	}
+	if config.Keep > 0 && config.Window <= 0 {
+		return fmt.Errorf("rollback.window must be positive")
+	}
+	return nil
+}
+
+// binaryHash returns the hex SHA-256 of the file at path
+func binaryHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// goodBinaries returns the binaries kept in dir, most recently good first
+func goodBinaries(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	type binary struct {
+		path string
+		good time.Time
+	}
+	var binaries []binary
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		binaries = append(binaries, binary{filepath.Join(dir, entry.Name()), info.ModTime()})
+	}
+	sort.SliceStable(binaries, func(i, j int) bool { return binaries[i].good.After(binaries[j].good) })
+	paths := make([]string, len(binaries))
+	for i, b := range binaries {
+		paths[i] = b.path
+	}
+	return paths
+}
+
+// keepGood adds binary to the good binaries in dir, or marks it as the most
+// recent one, and removes all but the newest keep
+func keepGood(binary, dir string, keep int) error {
+	hash, err := binaryHash(binary)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, hash[:12])
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		if err := copyFile(binary, path+".tmp"); err != nil {
+			return err
+		}
+		if err := os.Rename(path+".tmp", path); err != nil {
+			return err
+		}
+	}
+	if good := goodBinaries(dir); len(good) > keep {
+		for _, old := range good[keep:] {
+			os.Remove(old)
+		}
+	}
+	return nil
+}
+
+// rollbackPath is the directory of app's good binaries
+func (app *WindApp) rollbackPath() string {
+	if app.name == "" {
+		return rollbackDir
+	}
+	return filepath.Join(rollbackDir, app.name)
+}
+
+// guardStartup watches a process just started from a new build: once it
+// ran for the rollback window its binary is kept as a good one, and when it
+// fails before, watchProcess restarts the last good binary instead. Binaries that
+// already ran fine are not watched; their crashes are not the build's
+// fault. The caller holds app.mutex.
+func (app *WindApp) guardStartup(env []string) {
+	if app.config.Rollback.Keep == 0 {
+		return
+	}
+	binary := app.deployBinary()
+	hash, err := binaryHash(binary)
+	if err != nil {
+		return
+	}
+	if _, err := os.Stat(filepath.Join(app.rollbackPath(), hash[:12])); err == nil {
+		return
+	}
+
+	process, exit := app.process, app.exit
+	exit.guarded = true
+	go func() {
+		timer := time.NewTimer(app.config.Rollback.Window)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			app.mutex.Lock()
+			defer app.mutex.Unlock()
+			// A build since then replaced the binary
+			if app.process != process {
+				return
+			}
+			exit.guarded = false
+			if err := keepGood(binary, app.rollbackPath(), app.config.Rollback.Keep); err != nil {
+				logf(levelWarn, Yellow+"Warning: "+Reset+"%sFailed to keep the binary for rollbacks: %v\n", app.label(), err)
+			}
+		case <-exit.done:
+			// watchProcess rolls back
+		}
+	}()
+}
+
+// rollBack restarts the last good binary when the current process, started
+// from a new build, crashed at startup. It reports whether the good binary
+// was started. The caller holds app.mutex.
+func (app *WindApp) rollBack(env []string) bool {
+	exit := app.exit
+	if exit == nil || exit.process != app.process || !exit.guarded {
+		return false
+	}
+	select {
+	case <-exit.done:
+	default:
+		return false
+	}
+	if exit.state == nil || exit.state.Success() {
+		return false
+	}
+
+	forgetChild(exit.process.Pid)
+	app.emit(event{Event: "app_exit", PID: exit.process.Pid, ExitCode: exitCode(exit.state), Signal: exitSignal(exit.state)})
+	app.process, app.exit = nil, nil
+
+	good := goodBinaries(app.rollbackPath())
+	if len(good) == 0 {
+		logf(levelError, Red+"Error: "+Reset+"%sBuild #%d crashed at startup (%s); there is no good binary to roll back to\n", app.label(), app.buildID, exit.state)
+		return false
+	}
+	binary := app.deployBinary()
+	err := copyFile(good[0], binary+".rollback")
+	if err == nil {
+		err = os.Rename(binary+".rollback", binary)
+	}
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%sBuild #%d crashed at startup (%s); failed to roll back: %v\n", app.label(), app.buildID, exit.state, err)
+		return false
+	}
+
+	app.emit(event{Event: "rollback", Build: app.buildID, Error: exit.state.String()})
+	logf(levelWarn, Yellow+"Warning: "+Reset+"%sBuild #%d crashed at startup (%s); rolled back to the last good binary (%s)\n",
+		app.label(), app.buildID, exit.state, filepath.Base(good[0]))
+	return app.launch(env)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKeepGood(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "main")
+	good := filepath.Join(dir, "good")
+
+	for _, content := range []string{"v1", "v2", "v3", "v2"} {
+		os.WriteFile(binary, []byte(content), 0755)
+		if err := keepGood(binary, good, 2); err != nil {
+			t.Fatalf("keepGood failed: %v", err)
+		}
+		// Modification times order the binaries
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	kept := goodBinaries(good)
+	if len(kept) != 2 {
+		t.Fatalf("Expected 2 binaries to be kept, got %v", kept)
+	}
+	newest, _ := os.ReadFile(kept[0])
+	older, _ := os.ReadFile(kept[1])
+	if string(newest) != "v2" || string(older) != "v3" {
+		t.Errorf("Expected v2 then v3, got %s then %s", newest, older)
+	}
+}
+
+func TestRollBackOnStartupCrash(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	os.Mkdir("tmp", 0755)
+
+	os.WriteFile("tmp/main", []byte("#!/bin/sh\nexec sleep 5\n"), 0755)
+	if err := keepGood("tmp/main", rollbackDir, 3); err != nil {
+		t.Fatalf("keepGood failed: %v", err)
+	}
+	os.WriteFile("tmp/main", []byte("#!/bin/sh\nexit 3\n"), 0755)
+
+	app := newWindApp(WindConfig{
+		RunCmd:   "./tmp/main",
+		Rollback: RollbackConfig{Keep: 3, Window: 2 * time.Second},
+	}, "", "")
+	app.mutex.Lock()
+	app.launch(os.Environ())
+	app.mutex.Unlock()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		app.mutex.Lock()
+		restarted := app.process != nil && !app.exit.guarded
+		app.mutex.Unlock()
+		if restarted {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the good binary to be restarted")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	defer func() {
+		app.mutex.Lock()
+		app.stopProcess()
+		app.mutex.Unlock()
+	}()
+
+	data, _ := os.ReadFile("tmp/main")
+	if string(data) != "#!/bin/sh\nexec sleep 5\n" {
+		t.Errorf("Expected the good binary to be restored, got %q", data)
+	}
+}
+
+func TestValidateRollback(t *testing.T) {
+	if err := validateRollback(defaultConfig().Rollback); err != nil {
+		t.Errorf("Expected the defaults to be valid, got %v", err)
+	}
+	if err := validateRollback(RollbackConfig{Keep: -1}); err == nil {
+		t.Error("Expected an error for a negative keep")
+	}
+	if err := validateRollback(RollbackConfig{Keep: 2}); err == nil {
+		t.Error("Expected an error for a missing window")
+	}
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// scanWorkers bounds the directories read concurrently by a scan
+var scanWorkers = max(runtime.NumCPU(), 4)
+
+// dirEntry is a non-excluded entry of a scanned directory
+type dirEntry struct {
+	// path is normalized (see projectPath)
+	path  string
+	isDir bool
+}
+
+// dirListing is a directory's entries as of its modification time. A
+// directory's mtime changes whenever entries are added, removed or renamed,
+// but not when a file inside is edited in place, so files are still
+// stat'ed on every scan; only reading and filtering the listing is skipped.
+type dirListing struct {
+	modTime time.Time
+	entries []dirEntry
+}
+
+// scannedPath is a path found by a scan with its info (see scanCache.stat)
+type scannedPath struct {
+	path string
+	info os.FileInfo
+}
+
+// scanStats describes one scan for verbose output
+type scanStats struct {
+	// files counts the wanted files
+	files    int
+	dirs     int
+	reused   int
+	duration time.Duration
+	// tooDeep are the directories not scanned because of maxDepth
+	tooDeep []string
+}
+
+// scanCache walks watch roots with a pool of workers and remembers
+// directory listings between scans
+type scanCache struct {
+	excludes []string
+	// ignore are the .windignore rules, see setIgnore
+	ignore ignoreRules
+	// artifacts are skipped like excluded directories
+	artifacts []string
+	// followSymlinks descends into symlinked directories and stats the
+	// targets of symlinked files; each directory is scanned once, which
+	// also breaks cycles
+	followSymlinks bool
+	// maxDepth limits how many directory levels below a root are scanned;
+	// 0 is unlimited
+	maxDepth int
+
+	mutex    sync.Mutex
+	listings map[string]dirListing
+}
+
+func newScanCache(excludes []string) *scanCache {
+	return &scanCache{excludes: excludes, listings: make(map[string]dirListing)}
+}
+
+// walk returns the non-excluded directories under roots and the files for
+// which want reports true, sorted by path. Only those files are stat'ed.
+// want is called by one worker at a time, so it may keep lazy state. Paths
+// that vanish during the scan are skipped.
+func (c *scanCache) walk(roots []string, want func(path string) bool) ([]scannedPath, scanStats, error) {
+	started := time.Now()
+
+	var (
+		mutex     sync.Mutex
+		wantMutex sync.Mutex
+		results   []scannedPath
+		visited   = map[string]bool{}
+		// inodes identifies the directories scanned so far when following
+		// symlinks
+		inodes  = map[fileID]bool{}
+		stats   scanStats
+		walkErr error
+		wg      sync.WaitGroup
+	)
+	sem := make(chan struct{}, scanWorkers)
+
+	var visit func(dir string, depth int)
+	visit = func(dir string, depth int) {
+		defer wg.Done()
+
+		sem <- struct{}{}
+		info, listing, reused, err := c.list(dir)
+		var found []scannedPath
+		if err == nil {
+			found = append(found, scannedPath{dir, info})
+			for _, entry := range listing.entries {
+				if entry.isDir {
+					continue
+				}
+				wantMutex.Lock()
+				wanted := want(entry.path)
+				wantMutex.Unlock()
+				if !wanted {
+					continue
+				}
+				fileInfo, err := c.stat(entry.path)
+				if err != nil {
+					continue
+				}
+				found = append(found, scannedPath{entry.path, fileInfo})
+			}
+		}
+		<-sem
+
+		mutex.Lock()
+		if err == nil && c.followSymlinks {
+			id, ok := idOf(dir, info)
+			if ok && inodes[id] {
+				// Reached again through a symlink
+				mutex.Unlock()
+				return
+			}
+			inodes[id] = true
+		}
+		switch {
+		case err != nil && (depth == 0 || !os.IsNotExist(err)):
+			if walkErr == nil {
+				walkErr = err
+			}
+		case err == nil:
+			visited[dir] = true
+			results = append(results, found...)
+			stats.dirs++
+			stats.files += len(found) - 1
+			if reused {
+				stats.reused++
+			}
+		}
+		mutex.Unlock()
+		if err != nil {
+			return
+		}
+
+		for _, entry := range listing.entries {
+			if !entry.isDir {
+				continue
+			}
+			if c.maxDepth > 0 && depth >= c.maxDepth {
+				mutex.Lock()
+				stats.tooDeep = append(stats.tooDeep, entry.path)
+				mutex.Unlock()
+				continue
+			}
+			wg.Add(1)
+			go visit(entry.path, depth+1)
+		}
+	}
+
+	for _, root := range roots {
+		root = projectPath(root)
+		if isExcluded(root, c.excludes) {
+			continue
+		}
+		wg.Add(1)
+		go visit(root, 0)
+	}
+	wg.Wait()
+
+	// Forget directories that are gone
+	c.mutex.Lock()
+	for dir := range c.listings {
+		if !visited[dir] {
+			delete(c.listings, dir)
+		}
+	}
+	c.mutex.Unlock()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].path < results[j].path })
+	sort.Strings(stats.tooDeep)
+	stats.duration = time.Since(started)
+	return results, stats, walkErr
+}
+
+// setIgnore replaces the ignore rules. Cached listings were filtered with
+// the old ones, so they are dropped.
+func (c *scanCache) setIgnore(rules ignoreRules) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.ignore = rules
+	c.listings = make(map[string]dirListing)
+}
+
+// stat returns the info of path, or of its target when following symlinks
+func (c *scanCache) stat(path string) (os.FileInfo, error) {
+	if c.followSymlinks {
+		return os.Stat(path)
+	}
+	return os.Lstat(path)
+}
+
+// list returns the info of dir and its listing, read from disk unless the
+// cached one is still current
+func (c *scanCache) list(dir string) (os.FileInfo, dirListing, bool, error) {
+	info, err := c.stat(dir)
+	if err != nil {
+		return nil, dirListing{}, false, err
+	}
+	c.mutex.Lock()
+	cached, ok := c.listings[dir]
+	c.mutex.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) {
+		return info, cached, true, nil
+	}
+
+	names, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, dirListing{}, false, err
+	}
+	listing := dirListing{modTime: info.ModTime()}
+	for _, entry := range names {
+		path := projectPath(filepath.Join(dir, entry.Name()))
+		if isExcluded(path, c.excludes) || isArtifact(path, c.artifacts) {
+			continue
+		}
+		isDir := entry.IsDir()
+		if c.followSymlinks && entry.Type()&os.ModeSymlink != 0 {
+			if target, err := os.Stat(path); err == nil {
+				isDir = target.IsDir()
+			}
+		}
+		// Directories are only listed when they are not ignored, so only
+		// the entry itself needs checking
+		if c.ignore.decide(path, isDir) && !strings.HasPrefix(path, "../") {
+			continue
+		}
+		listing.entries = append(listing.entries, dirEntry{path: path, isDir: isDir})
+	}
+
+	c.mutex.Lock()
+	c.listings[dir] = listing
+	c.mutex.Unlock()
+	return info, listing, false, nil
+}
+
+// String formats the stats for verbose output
+func (s scanStats) String() string {
+	return fmt.Sprintf("%d files in %d directories (%d listings reused) in %v",
+		s.files, s.dirs, s.reused, s.duration.Round(time.Microsecond))
+}
+
+// dirCount is a directory and the number of watched files below it
+type dirCount struct {
+	dir   string
+	count int
+}
+
+// biggestDirs groups the files by their directory two levels below the
+// project (or below a watch root outside of it) and returns the n largest
+// groups
+func biggestDirs(files []string, n int) []dirCount {
+	counts := map[string]int{}
+	for _, file := range files {
+		segments := strings.Split(path.Dir(file), "/")
+		keep := 2
+		for _, s := range segments {
+			if s != ".." {
+				break
+			}
+			keep++
+		}
+		if len(segments) > keep {
+			segments = segments[:keep]
+		}
+		counts[strings.Join(segments, "/")]++
+	}
+
+	dirs := make([]dirCount, 0, len(counts))
+	for dir, count := range counts {
+		dirs = append(dirs, dirCount{dir, count})
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if dirs[i].count != dirs[j].count {
+			return dirs[i].count > dirs[j].count
+		}
+		return dirs[i].dir < dirs[j].dir
+	})
+	if len(dirs) > n {
+		dirs = dirs[:n]
+	}
+	return dirs
+}
+
+// checkScanLimits warns, once per session each, when a scan watched more
+// than MaxWatchedFiles files or left directories out because of MaxDepth
+func (app *WindApp) checkScanLimits(paths []scannedPath, stats scanStats) {
+	if limit := app.config.MaxWatchedFiles; limit > 0 && stats.files > limit && !app.tooManyWarned {
+		app.tooManyWarned = true
+		var files []string
+		for _, p := range paths {
+			if !p.info.IsDir() {
+				files = append(files, p.path)
+			}
+		}
+		logf(levelWarn, Yellow+"Warning: "+Reset+"%sWatching %d files, more than maxWatchedFiles (%d); every scan stats them all. The biggest directories:\n",
+			app.label(), stats.files, limit)
+		for _, d := range biggestDirs(files, 5) {
+			logf(levelWarn, "  %6d  %s\n", d.count, d.dir)
+		}
+		logf(levelWarn, "  Add the ones that need no rebuild to excludeDirs, or raise maxWatchedFiles\n")
+	}
+
+	if len(stats.tooDeep) > 0 && !app.tooDeepWarned {
+		app.tooDeepWarned = true
+		shown := stats.tooDeep
+		if len(shown) > 5 {
+			shown = shown[:5]
+		}
+		logf(levelWarn, Yellow+"Warning: "+Reset+"%sNot watching directories deeper than maxDepth (%d):\n",
+			app.label(), app.config.MaxDepth)
+		for _, dir := range shown {
+			logf(levelWarn, "  %s\n", dir)
+		}
+		if more := len(stats.tooDeep) - len(shown); more > 0 {
+			logf(levelWarn, "  and %d more\n", more)
+		}
+		logf(levelWarn, "  Exclude them with excludeDirs to silence this, or raise maxDepth if they matter\n")
+	}
+}
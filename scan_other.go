@@ -0,0 +1,20 @@
+//go:build !unix
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// fileID identifies a directory independently of the path it is reached by:
+// without inode numbers, by the path with every symlink resolved
+type fileID string
+
+func idOf(path string, info os.FileInfo) (fileID, bool) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", false
+	}
+	return fileID(resolved), true
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScanCacheWalk(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"main.go", "api/api.go", "api/v1/v1.go", "vendor/x/x.go", "web/node_modules/a.js"} {
+		path := filepath.Join(tmpDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("package x\n"), 0644)
+	}
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	cache := newScanCache([]string{"vendor", "node_modules"})
+	all := func(string) bool { return true }
+	walk := func() ([]string, scanStats) {
+		found, stats, err := cache.walk([]string{"."}, all)
+		if err != nil {
+			t.Fatalf("walk failed: %v", err)
+		}
+		var paths []string
+		for _, p := range found {
+			paths = append(paths, p.path)
+		}
+		return paths, stats
+	}
+
+	paths, stats := walk()
+	expected := []string{".", "api", "api/api.go", "api/v1", "api/v1/v1.go", "main.go", "web"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+	if stats.files != 3 || stats.dirs != 4 || stats.reused != 0 {
+		t.Errorf("Unexpected first scan stats: %+v", stats)
+	}
+
+	// Unchanged directories reuse their listing, but files are stat'ed so
+	// in-place edits are still seen
+	later := time.Now().Add(time.Minute)
+	os.Chtimes("api/api.go", later, later)
+	found, stats, _ := cache.walk([]string{"."}, all)
+	if stats.reused != 4 {
+		t.Errorf("Expected every listing to be reused, got %+v", stats)
+	}
+	for _, p := range found {
+		if p.path == "api/api.go" && !p.info.ModTime().Equal(later) {
+			t.Error("Expected the edited file's new mtime")
+		}
+	}
+
+	// Added and removed entries are picked up
+	os.WriteFile("api/v1/new.go", []byte("package v1\n"), 0644)
+	os.RemoveAll("web")
+	paths, _ = walk()
+	expected = []string{".", "api", "api/api.go", "api/v1", "api/v1/new.go", "api/v1/v1.go", "main.go"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+	if _, ok := cache.listings["web"]; ok {
+		t.Error("Expected the removed directory to be forgotten")
+	}
+
+	// Only wanted files are reported
+	found, stats, _ = cache.walk([]string{"."}, func(path string) bool { return path == "main.go" })
+	if stats.files != 1 || len(found) != 4 {
+		t.Errorf("Expected only main.go besides the directories, got %+v", found)
+	}
+
+	// A missing root is an error
+	if _, _, err := cache.walk([]string{".", "../missing"}, all); err == nil {
+		t.Error("Expected an error for a missing root")
+	}
+}
+
+func TestScanCacheFollowSymlinks(t *testing.T) {
+	base := t.TempDir()
+	project := filepath.Join(base, "project")
+	os.MkdirAll(project, 0755)
+	os.MkdirAll(filepath.Join(base, "shared", "assets"), 0755)
+	os.WriteFile(filepath.Join(project, "main.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(base, "shared", "assets", "app.css"), []byte("body {}\n"), 0644)
+	os.WriteFile(filepath.Join(base, "shared", "config.yaml"), []byte("a: 1\n"), 0644)
+	os.Symlink(filepath.Join("..", "shared", "assets"), filepath.Join(project, "assets"))
+	os.Symlink(filepath.Join("..", "shared", "config.yaml"), filepath.Join(project, "config.yaml"))
+	// A cycle back to the symlinked directory's parent
+	os.Symlink("..", filepath.Join(base, "shared", "assets", "up"))
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(project); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	all := func(string) bool { return true }
+	paths := func(cache *scanCache) map[string]os.FileInfo {
+		found, _, err := cache.walk([]string{"."}, all)
+		if err != nil {
+			t.Fatalf("walk failed: %v", err)
+		}
+		infos := map[string]os.FileInfo{}
+		for _, p := range found {
+			infos[p.path] = p.info
+		}
+		return infos
+	}
+
+	// Without following, links are plain files
+	found := paths(newScanCache(nil))
+	if _, ok := found["assets/app.css"]; ok {
+		t.Errorf("Expected the symlinked directory not to be followed, got %v", found)
+	}
+
+	cache := newScanCache(nil)
+	cache.followSymlinks = true
+	found = paths(cache)
+	for _, want := range []string{"assets", "assets/app.css", "assets/up", "assets/up/config.yaml", "config.yaml"} {
+		if _, ok := found[want]; !ok {
+			t.Errorf("Expected %s to be scanned, got %v", want, found)
+		}
+	}
+	// assets/up/assets is assets itself
+	if _, ok := found["assets/up/assets/app.css"]; ok {
+		t.Error("Expected the cycle to be scanned once")
+	}
+
+	// Symlinked files report their target's modification time
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(filepath.Join(base, "shared", "config.yaml"), later, later)
+	if info := paths(cache)["config.yaml"]; info == nil || !info.ModTime().Equal(later) {
+		t.Error("Expected the target's new mtime for a symlinked file")
+	}
+}
+
+func TestScanCacheMaxDepth(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"main.go", "a/a.go", "a/b/b.go", "a/b/c/c.go", "x/y/z/z.go"} {
+		path := filepath.Join(tmpDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("package x\n"), 0644)
+	}
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	cache := newScanCache(nil)
+	cache.maxDepth = 2
+	found, stats, err := cache.walk([]string{"."}, func(string) bool { return true })
+	if err != nil {
+		t.Fatalf("walk failed: %v", err)
+	}
+	var paths []string
+	for _, p := range found {
+		paths = append(paths, p.path)
+	}
+	expected := []string{".", "a", "a/a.go", "a/b", "a/b/b.go", "main.go", "x", "x/y"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+	if !reflect.DeepEqual(stats.tooDeep, []string{"a/b/c", "x/y/z"}) {
+		t.Errorf("Expected a/b/c and x/y/z to be reported, got %v", stats.tooDeep)
+	}
+}
+
+func TestBiggestDirs(t *testing.T) {
+	files := []string{
+		"main.go",
+		"web/static/a.js", "web/static/b.js", "web/static/vendor/c.js",
+		"internal/api/api.go", "internal/api/v1/v1.go",
+		"internal/db/db.go",
+		"../proto/api/a.proto",
+	}
+	got := biggestDirs(files, 3)
+	expected := []dirCount{{"web/static", 3}, {"internal/api", 2}, {".", 1}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("biggestDirs() = %v, expected %v", got, expected)
+	}
+
+	got = biggestDirs(files, 10)
+	if len(got) != 5 || got[len(got)-1] != (dirCount{"internal/db", 1}) {
+		t.Errorf("biggestDirs() = %v", got)
+	}
+	for _, d := range got {
+		if d.dir == "../proto/api" {
+			return
+		}
+	}
+	t.Errorf("Expected files outside the project grouped below their root, got %v", got)
+}
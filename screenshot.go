@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ScreenshotConfig configures the optional headless browser check run after
+// each restart
+type ScreenshotConfig struct {
+	// URLs are loaded one after another; empty disables the hook
+	URLs []string
+	// Browser is a Chrome or Chromium binary; empty searches PATH
+	Browser string
+	Width   int
+	Height  int
+	// Delay gives the application time to start listening
+	Delay   time.Duration
+	Timeout time.Duration
+}
+
+// screenshotDir holds the screenshots and console logs of the latest restart
+const screenshotDir = "tmp/screenshots"
+
+// browserCandidates are tried in order when Screenshots.Browser is empty
+var browserCandidates = []string{
+	"chromium",
+	"chromium-browser",
+	"google-chrome",
+	"google-chrome-stable",
+	"chrome",
+	"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
+	"/Applications/Chromium.app/Contents/MacOS/Chromium",
+}
+
+// findBrowser resolves the configured browser, or the first installed
+// candidate
+func findBrowser(configured string) (string, error) {
+	candidates := browserCandidates
+	if configured != "" {
+		candidates = []string{configured}
+	}
+	for _, name := range candidates {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	if configured != "" {
+		return "", fmt.Errorf("browser %q not found", configured)
+	}
+	return "", fmt.Errorf("no Chrome or Chromium found (set screenshots.browser)")
+}
+
+// consoleMessagePattern matches the console messages Chrome logs to stderr
+// with --enable-logging=stderr, e.g.
+// [1016/101500.123:INFO:CONSOLE(12)] "Uncaught Error: boom", source: http://localhost:8080/app.js (12)
+var consoleMessagePattern = regexp.MustCompile(`:CONSOLE\(\d+\)\] "(.*)", source: (.*)$`)
+
+// consoleMessages extracts the page's console messages from browser stderr
+func consoleMessages(stderr string) []string {
+	var messages []string
+	for _, line := range strings.Split(stderr, "\n") {
+		if m := consoleMessagePattern.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			messages = append(messages, m[1]+" ("+m[2]+")")
+		}
+	}
+	return messages
+}
+
+// nonFileChars are replaced when a URL is turned into a file name
+var nonFileChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// screenshotName turns a URL into a file name, e.g.
+// http://localhost:8080/users?page=2 becomes localhost-8080_users_page-2
+func screenshotName(url string) string {
+	name := url
+	if i := strings.Index(name, "://"); i >= 0 {
+		name = name[i+3:]
+	}
+	name = strings.ReplaceAll(name, ":", "-")
+	name = strings.ReplaceAll(name, "=", "-")
+	name = strings.Trim(nonFileChars.ReplaceAllString(name, "_"), "_")
+	if name == "" {
+		return "page"
+	}
+	return name
+}
+
+// screenshotResult is what loading one URL produced
+type screenshotResult struct {
+	path    string
+	console []string
+}
+
+// takeScreenshot loads url in the headless browser and saves a screenshot
+// to dir, plus the console messages next to it when there are any
+func takeScreenshot(browser, url, dir string, config ScreenshotConfig) (screenshotResult, error) {
+	name := screenshotName(url)
+	result := screenshotResult{path: filepath.Join(dir, name+".png")}
+	os.Remove(result.path)
+
+	// A fresh profile keeps the browser from attaching to a running one
+	profile, err := os.MkdirTemp("", "wind-browser-")
+	if err != nil {
+		return result, err
+	}
+	defer os.RemoveAll(profile)
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, browser,
+		"--headless=new",
+		"--disable-gpu",
+		"--hide-scrollbars",
+		"--no-first-run",
+		"--enable-logging=stderr",
+		"--user-data-dir="+profile,
+		fmt.Sprintf("--window-size=%d,%d", config.Width, config.Height),
+		"--screenshot="+result.path,
+		url)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	result.console = consoleMessages(stderr.String())
+	logPath := filepath.Join(dir, name+".console.log")
+	if len(result.console) > 0 {
+		os.WriteFile(logPath, []byte(strings.Join(result.console, "\n")+"\n"), 0644)
+	} else {
+		os.Remove(logPath)
+	}
+
+	if ctx.Err() != nil {
+		return result, fmt.Errorf("timed out after %s", config.Timeout)
+	}
+	if _, err := os.Stat(result.path); err != nil {
+		if runErr != nil {
+			return result, fmt.Errorf("browser failed: %v", runErr)
+		}
+		return result, fmt.Errorf("browser saved no screenshot")
+	}
+	return result, nil
+}
+
+// runScreenshotHook loads every configured URL once the restarted
+// application had time to come up and reports the screenshots and console
+// messages
+func (app *WindApp) runScreenshotHook() {
+	config := app.config.Screenshots
+	time.Sleep(config.Delay)
+
+	browser, err := findBrowser(config.Browser)
+	if err != nil {
+		logf(levelWarn, Yellow+"Warning: "+Reset+"%sSkipping screenshots: %v\n", app.label(), err)
+		return
+	}
+	dir := screenshotDir
+	if app.name != "" {
+		dir = filepath.Join(dir, app.name)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%sFailed to create %s: %v\n", app.label(), dir, err)
+		return
+	}
+
+	for _, url := range config.URLs {
+		result, err := takeScreenshot(browser, url, dir, config)
+		if err != nil {
+			logf(levelError, Red+"Error: "+Reset+"%sScreenshot of %s failed: %v\n", app.label(), url, err)
+			continue
+		}
+		if len(result.console) == 0 {
+			logf(levelInfo, Green+"Screenshot: "+Reset+"%s%s → %s\n", app.label(), url, result.path)
+			continue
+		}
+		logf(levelInfo, Yellow+"Screenshot: "+Reset+"%s%s → %s · %s\n", app.label(), url, result.path, pluralize(len(result.console), "console message"))
+		for _, message := range result.console {
+			logf(levelInfo, "  %s\n", message)
+		}
+	}
+}
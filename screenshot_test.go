@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScreenshotName(t *testing.T) {
+	cases := map[string]string{
+		"http://localhost:8080/":             "localhost-8080",
+		"http://localhost:8080/users?page=2": "localhost-8080_users_page-2",
+		"https://example.com/a/b.html":       "example.com_a_b.html",
+		"http://":                            "page",
+	}
+	for url, want := range cases {
+		if got := screenshotName(url); got != want {
+			t.Errorf("screenshotName(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+func TestConsoleMessages(t *testing.T) {
+	stderr := `[1016/101500.100:WARNING:sandbox_linux.cc(400)] unrelated
+[1016/101500.123:INFO:CONSOLE(12)] "Uncaught Error: boom", source: http://localhost:8080/app.js (12)
+[1016/101500.124:INFO:CONSOLE(3)] "deprecated API", source: http://localhost:8080/ (3)
+`
+	messages := consoleMessages(stderr)
+	expected := []string{
+		"Uncaught Error: boom (http://localhost:8080/app.js (12))",
+		"deprecated API (http://localhost:8080/ (3))",
+	}
+	if strings.Join(messages, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected %q, got %q", expected, messages)
+	}
+}
+
+func TestTakeScreenshot(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// The fake browser writes the requested screenshot and logs a console
+	// message like Chrome does
+	browser := filepath.Join(tmpDir, "fake-chrome")
+	script := `#!/bin/sh
+for arg in "$@"; do
+	case "$arg" in --screenshot=*) echo png > "${arg#--screenshot=}" ;; esac
+done
+echo '[1016/101500.123:INFO:CONSOLE(1)] "Uncaught TypeError: x is undefined", source: http://localhost:8080/ (1)' >&2
+`
+	if err := os.WriteFile(browser, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake browser: %v", err)
+	}
+
+	if found, err := findBrowser(browser); err != nil || found != browser {
+		t.Fatalf("Expected the configured browser to be found, got %q, %v", found, err)
+	}
+	if _, err := findBrowser(filepath.Join(tmpDir, "missing")); err == nil {
+		t.Error("Expected an error for a missing browser")
+	}
+
+	config := ScreenshotConfig{Width: 800, Height: 600, Timeout: 5 * time.Second}
+	result, err := takeScreenshot(browser, "http://localhost:8080/", tmpDir, config)
+	if err != nil {
+		t.Fatalf("takeScreenshot failed: %v", err)
+	}
+	if result.path != filepath.Join(tmpDir, "localhost-8080.png") {
+		t.Errorf("Unexpected screenshot path %s", result.path)
+	}
+	if _, err := os.Stat(result.path); err != nil {
+		t.Errorf("Expected the screenshot to exist: %v", err)
+	}
+	if len(result.console) != 1 {
+		t.Errorf("Expected 1 console message, got %q", result.console)
+	}
+	log, _ := os.ReadFile(filepath.Join(tmpDir, "localhost-8080.console.log"))
+	if !strings.Contains(string(log), "Uncaught TypeError") {
+		t.Errorf("Expected the console log to be saved, got %q", log)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// servePortFlag sets the port of `wind serve`
+const servePortFlag = "--port"
+
+// ServeConfig serves a directory of frontend assets next to the watched
+// app, for projects whose frontend the Go binary doesn't serve during
+// development
+type ServeConfig struct {
+	// Dir is the directory to serve; empty turns the server off
+	Dir string
+	// Port is the port to open in the browser
+	Port int
+}
+
+// validateServe checks the static server settings
+func validateServe(config WindConfig) error {
+	if config.Serve.Dir == "" {
+		return nil
+	}
+	if config.Serve.Port <= 0 || config.Serve.Port > 65535 {
+		return fmt.Errorf("invalid serve.port %d", config.Serve.Port)
+	}
+	if config.LiveReload.AppURL != "" && config.LiveReload.Port == config.Serve.Port {
+		return fmt.Errorf("serve.port and liveReload.port are both %d", config.Serve.Port)
+	}
+	// The directory is polled like the project
+	if config.PollInterval <= 0 {
+		return fmt.Errorf("serve needs a positive pollInterval, got %s", config.PollInterval)
+	}
+	return nil
+}
+
+// staticServer serves a directory with the live reload script injected
+// into HTML pages and reloads the browsers when a file in it changes
+type staticServer struct {
+	dir  string
+	port int
+	// events streams reload events to the browsers like the dev proxy does
+	events *liveReload
+	server *http.Server
+	done   chan struct{}
+}
+
+func newStaticServer(dir string, port int) *staticServer {
+	return &staticServer{
+		dir:    dir,
+		port:   port,
+		events: &liveReload{clients: make(map[chan string]bool)},
+		done:   make(chan struct{}),
+	}
+}
+
+func (s *staticServer) handler() http.Handler {
+	files := http.FileServer(http.Dir(s.dir))
+	mux := http.NewServeMux()
+	mux.HandleFunc(liveReloadPath, s.events.serveEvents)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		// Assets change all the time during development
+		w.Header().Set("Cache-Control", "no-cache")
+
+		name := path.Clean("/" + r.URL.Path)
+		if strings.HasSuffix(r.URL.Path, "/") {
+			name = path.Join(name, "index.html")
+		}
+		if ext := path.Ext(name); ext == ".html" || ext == ".htm" {
+			if body, err := os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(name))); err == nil {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				w.Write(injectSnippet(body, liveReloadScript))
+				return
+			}
+		}
+		files.ServeHTTP(w, r)
+	})
+	return mux
+}
+
+// start listens on the port and watches the directory, polling every
+// interval
+func (s *staticServer) start(interval time.Duration) error {
+	info, err := os.Stat(s.dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", s.dir)
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		return err
+	}
+	s.server = &http.Server{Handler: s.handler()}
+	go s.server.Serve(listener)
+	go s.watch(interval)
+	logf(levelInfo, Cyan+"Info: "+Reset+"Serving %s on http://localhost:%d\n", s.dir, s.port)
+	return nil
+}
+
+// watch reloads the browsers whenever a file in the directory is added,
+// changed or removed
+func (s *staticServer) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	previous := snapshotDir(s.dir)
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+		current := snapshotDir(s.dir)
+		if changed := changedPaths(previous, current); len(changed) > 0 {
+			n := s.events.broadcast()
+			logf(levelInfo, Cyan+"Info: "+Reset+"%s changed, reloaded %d browser tab(s)\n", describeChanged(changed), n)
+		}
+		previous = current
+	}
+}
+
+func (s *staticServer) stop() {
+	close(s.done)
+	if s.server != nil {
+		s.server.Close()
+	}
+}
+
+// snapshotDir returns the modification time of every file below dir
+func snapshotDir(dir string) map[string]time.Time {
+	files := map[string]time.Time{}
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			files[projectPath(path)] = info.ModTime()
+		}
+		return nil
+	})
+	return files
+}
+
+// changedPaths lists the files added, changed or removed between two
+// snapshots
+func changedPaths(previous, current map[string]time.Time) []string {
+	var changed []string
+	for path, modTime := range current {
+		if before, ok := previous[path]; !ok || !before.Equal(modTime) {
+			changed = append(changed, path)
+		}
+	}
+	for path := range previous {
+		if _, ok := current[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
+
+// runServe implements `wind serve [dir] [--port n]`
+func runServe(args []string) {
+	args, portValue, err := extractValueFlag(args, servePortFlag)
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%v\n", err)
+		return
+	}
+	config := defaultConfig()
+	if portValue != "" {
+		if config.Serve.Port, err = strconv.Atoi(portValue); err != nil {
+			logf(levelError, Red+"Error: "+Reset+"Invalid %s %q\n", servePortFlag, portValue)
+			return
+		}
+	}
+	config.Serve.Dir = "."
+	switch len(args) {
+	case 0:
+	case 1:
+		config.Serve.Dir = args[0]
+	default:
+		logf(levelError, Red+"Error: "+Reset+"Usage: wind serve [dir] [%s n]\n", servePortFlag)
+		return
+	}
+	if err := validateServe(config); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"%v\n", err)
+		return
+	}
+
+	server := newStaticServer(config.Serve.Dir, config.Serve.Port)
+	if err := server.start(config.PollInterval); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Failed to serve %s: %v\n", config.Serve.Dir, err)
+		return
+	}
+	defer server.stop()
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	logf(levelInfo, Yellow+"Press Ctrl+C to stop..."+Reset+"\n")
+	<-c
+}
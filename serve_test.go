@@ -0,0 +1,77 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStaticServerInjectsLiveReload(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "docs"), 0755)
+	os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html><body><h1>Home</h1></body></html>"), 0644)
+	os.WriteFile(filepath.Join(dir, "docs", "index.html"), []byte("<p>Docs</p>"), 0644)
+	os.WriteFile(filepath.Join(dir, "app.css"), []byte("body{}"), 0644)
+
+	server := httptest.NewServer(newStaticServer(dir, 0).handler())
+	defer server.Close()
+	get := func(path string) string {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	if body := get("/"); !strings.Contains(body, liveReloadScript+"</body>") {
+		t.Errorf("Expected the script before </body>, got %q", body)
+	}
+	if body := get("/docs/"); body != "<p>Docs</p>"+liveReloadScript {
+		t.Errorf("Expected the script appended to the page, got %q", body)
+	}
+	if body := get("/app.css"); body != "body{}" {
+		t.Errorf("Other files should be served unchanged, got %q", body)
+	}
+}
+
+func TestChangedPaths(t *testing.T) {
+	now := time.Now()
+	previous := map[string]time.Time{"a.css": now, "b.js": now, "c.html": now}
+	current := map[string]time.Time{"a.css": now, "b.js": now.Add(time.Second), "d.png": now}
+	got := changedPaths(previous, current)
+	sort.Strings(got)
+	if expected := []string{"b.js", "c.html", "d.png"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("changedPaths() = %v, expected %v", got, expected)
+	}
+}
+
+func TestValidateServe(t *testing.T) {
+	config := defaultConfig()
+	config.Serve.Dir = "public"
+	if err := validateServe(config); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	config.LiveReload.AppURL = "http://localhost:8080"
+	if err := validateServe(config); err == nil {
+		t.Error("Expected an error when serve and liveReload share a port")
+	}
+	config.Serve.Port = 0
+	if err := validateServe(config); err == nil {
+		t.Error("Expected an error for an invalid port")
+	}
+	config = defaultConfig()
+	config.Serve.Dir = "public"
+	config.PollInterval = 0
+	if err := validateServe(config); err == nil {
+		t.Error("Expected an error for a zero pollInterval")
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Restart backoff of sidecars that exit: doubled after every crash up to
+// the maximum, and reset once a sidecar ran for sidecarStableAfter
+const (
+	sidecarMinBackoff  = time.Second
+	sidecarMaxBackoff  = 30 * time.Second
+	sidecarStableAfter = 10 * time.Second
+)
+
+// SidecarConfig is a long-running auxiliary command such as `npx vite` or
+// `tailwindcss --watch` that runs next to the app for the whole session
+type SidecarConfig struct {
+	// Name prefixes the output; the first word of Command by default
+	Name string
+	// Command runs through sh -c
+	Command string
+	// Dir is the working directory, the project by default
+	Dir string
+}
+
+// validateSidecars checks the sidecar settings
+func validateSidecars(sidecars []SidecarConfig) error {
+	names := map[string]bool{}
+	for i, s := range sidecars {
+		if strings.TrimSpace(s.Command) == "" {
+			return fmt.Errorf("sidecars[%d]: command is required", i)
+		}
+		name := s.name()
+		if names[name] {
+			return fmt.Errorf("sidecars[%d]: duplicate name %q", i, name)
+		}
+		names[name] = true
+	}
+	return nil
+}
+
+// name is the configured name or the first word of the command
+func (s SidecarConfig) name() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return strings.Fields(s.Command)[0]
+}
+
+// sidecar supervises one SidecarConfig: it restarts the command when it
+// exits and stops it, with everything it started, when the session ends
+type sidecar struct {
+	config SidecarConfig
+	color  string
+	grace  time.Duration
+	mutex  sync.Mutex
+	cmd    *exec.Cmd
+	done   chan struct{}
+	// stopped is closed once the supervising goroutine returned
+	stopped chan struct{}
+}
+
+func newSidecar(config SidecarConfig, color string, grace time.Duration) *sidecar {
+	return &sidecar{
+		config:  config,
+		color:   color,
+		grace:   grace,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+func (s *sidecar) label() string {
+	return s.color + "[" + s.config.name() + "]" + Reset + " "
+}
+
+// start launches the command and supervises it in the background
+func (s *sidecar) start() {
+	go s.supervise()
+}
+
+func (s *sidecar) supervise() {
+	defer close(s.stopped)
+	backoff := sidecarMinBackoff
+	for {
+		cmd := exec.Command("sh", "-c", s.config.Command)
+		cmd.Dir = s.config.Dir
+		cmd.Stdout = newPrefixWriter(os.Stdout, s.label())
+		cmd.Stderr = newPrefixWriter(os.Stderr, s.label())
+		cmd.SysProcAttr = groupProcAttr()
+		// Children left behind could keep the output open forever
+		cmd.WaitDelay = time.Second
+
+		s.mutex.Lock()
+		select {
+		case <-s.done:
+			s.mutex.Unlock()
+			return
+		default:
+		}
+		started := time.Now()
+		err := cmd.Start()
+		if err == nil {
+			s.cmd = cmd
+			recordChild(cmd.Process.Pid, s.config.Command)
+			logf(levelSuccess, Green+"Success: "+Reset+"%sStarted %s (PID: %d)\n", s.label(), s.config.Command, cmd.Process.Pid)
+		}
+		s.mutex.Unlock()
+
+		if err == nil {
+			err = cmd.Wait()
+			forgetChild(cmd.Process.Pid)
+		}
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+
+		if time.Since(started) >= sidecarStableAfter {
+			backoff = sidecarMinBackoff
+		}
+		status := "exited"
+		if err != nil {
+			status = err.Error()
+		}
+		logf(levelWarn, Yellow+"Warning: "+Reset+"%s%s (%s), restarting in %s\n", s.label(), s.config.Command, status, backoff)
+		select {
+		case <-s.done:
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, sidecarMaxBackoff)
+	}
+}
+
+// stop ends supervision and stops the command's process group, killing it
+// when it is still running after the grace period
+func (s *sidecar) stop() {
+	s.mutex.Lock()
+	close(s.done)
+	cmd := s.cmd
+	s.mutex.Unlock()
+
+	if cmd == nil {
+		<-s.stopped
+		return
+	}
+	// A group that already exited is gone; the signal then fails
+	signalGroup(cmd.Process, syscall.SIGTERM)
+	if s.grace > 0 {
+		select {
+		case <-s.stopped:
+		case <-time.After(s.grace):
+			logf(levelWarn, Yellow+"Warning: "+Reset+"%sDid not stop within %s; killed it\n", s.label(), s.grace)
+			signalGroup(cmd.Process, syscall.SIGKILL)
+		}
+	}
+	<-s.stopped
+}
+
+// startSidecars starts every configured sidecar, colored after the targets
+func startSidecars(config WindConfig, targets int) []*sidecar {
+	var sidecars []*sidecar
+	for i, c := range config.Sidecars {
+		s := newSidecar(c, processColors[(targets+i)%len(processColors)], config.StopTimeout)
+		s.start()
+		sidecars = append(sidecars, s)
+	}
+	return sidecars
+}
+
+// stopSidecars stops the sidecars in parallel
+func stopSidecars(sidecars []*sidecar) {
+	var wg sync.WaitGroup
+	for _, s := range sidecars {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.stop()
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,20 @@
+//go:build !unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// groupProcAttr returns nil: there are no process groups to start commands
+// in here
+func groupProcAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+// signalGroup kills process; what it started keeps running. Processes
+// cannot be asked to terminate here, so SIGTERM kills as well.
+func signalGroup(process *os.Process, sig syscall.Signal) error {
+	return process.Kill()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateSidecars(t *testing.T) {
+	valid := []SidecarConfig{{Command: "npx vite"}, {Name: "css", Command: "npx tailwindcss --watch"}}
+	if err := validateSidecars(valid); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := validateSidecars([]SidecarConfig{{Name: "vite"}}); err == nil {
+		t.Error("Expected an error for a sidecar without a command")
+	}
+	if err := validateSidecars([]SidecarConfig{{Command: "npx vite"}, {Command: "npx tailwindcss"}}); err == nil {
+		t.Error("Expected an error for two sidecars named npx")
+	}
+}
+
+func TestSidecarRestartsAfterExit(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+
+	s := newSidecar(SidecarConfig{Command: "echo x >> runs"}, Cyan, time.Second)
+	s.start()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if data, _ := os.ReadFile("runs"); strings.Count(string(data), "x") >= 2 {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	s.stop()
+	if data, _ := os.ReadFile("runs"); strings.Count(string(data), "x") < 2 {
+		t.Errorf("Expected the sidecar to be restarted, got %q", data)
+	}
+}
+
+func TestSidecarStopsProcessGroup(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+
+	s := newSidecar(SidecarConfig{Name: "watcher", Command: "sleep 30 & echo $! > child; wait"}, Cyan, 5*time.Second)
+	s.start()
+	var pid int
+	for i := 0; i < 100 && pid == 0; i++ {
+		data, _ := os.ReadFile("child")
+		pid, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+		time.Sleep(20 * time.Millisecond)
+	}
+	if pid == 0 {
+		t.Fatal("The sidecar did not start")
+	}
+
+	s.stop()
+	for i := 0; i < 50 && running(pid); i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if running(pid) {
+		t.Errorf("Expected the sidecar's child (PID %d) to be stopped", pid)
+	}
+}
+
+// running reports whether pid is alive and not a zombie waiting for an init
+// process that doesn't reap, as in some containers
+func running(pid int) bool {
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return processAlive(pid)
+	}
+	fields := strings.Fields(string(stat[strings.LastIndex(string(stat), ")")+1:]))
+	return len(fields) > 0 && fields[0] != "Z"
+}
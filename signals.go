@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// doubleInterruptWindow is how soon a second SIGINT must follow a forwarded
+// one to quit Wind
+const doubleInterruptWindow = 2 * time.Second
+
+// parseForwardSignals validates ForwardSignals. SIGTERM always stops Wind,
+// so it cannot be forwarded.
+func parseForwardSignals(names []string) ([]syscall.Signal, error) {
+	var signals []syscall.Signal
+	for _, name := range names {
+		sig, ok := parseSignal(name)
+		if !ok || sig == syscall.SIGTERM {
+			return nil, fmt.Errorf("invalid forwardSignals entry %q (expected SIGINT, SIGQUIT, SIGHUP, SIGUSR1 or SIGUSR2)", name)
+		}
+		signals = append(signals, sig)
+	}
+	return signals, nil
+}
+
+// forwards reports whether sig is among signals
+func forwards(signals []syscall.Signal, sig syscall.Signal) bool {
+	for _, s := range signals {
+		if s == sig {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardSignals passes the given signals on to every running application
+// until the returned function is called. A forwarded SIGINT does not stop
+// Wind; a second one within doubleInterruptWindow does.
+func (o *orchestrator) forwardSignals(signals []syscall.Signal) (stop func()) {
+	if len(signals) == 0 {
+		return func() {}
+	}
+
+	c := make(chan os.Signal, 4)
+	notify := make([]os.Signal, len(signals))
+	for i, sig := range signals {
+		notify[i] = sig
+	}
+	signal.Notify(c, notify...)
+
+	done := make(chan struct{})
+	go func() {
+		var lastInterrupt time.Time
+		for {
+			select {
+			case <-done:
+				return
+			case s := <-c:
+				sig := s.(syscall.Signal)
+				if sig == syscall.SIGINT {
+					if time.Since(lastInterrupt) < doubleInterruptWindow {
+						o.requestQuit()
+						continue
+					}
+					lastInterrupt = time.Now()
+				}
+				o.forward(sig)
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(c)
+		close(done)
+	}
+}
+
+// forward sends sig to the running process of every target
+func (o *orchestrator) forward(sig syscall.Signal) {
+	for _, app := range o.apps {
+		pid := app.currentStatus().PID
+		if pid == 0 {
+			continue
+		}
+		if err := signalPID(pid, sig); err != nil {
+			logf(levelWarn, Yellow+"Warning: "+Reset+"%sFailed to forward %s: %v\n", app.label(), signalName(sig), err)
+			continue
+		}
+		logf(levelInfo, Cyan+"Info: "+Reset+"%sForwarded %s to the application (PID: %d)\n", app.label(), signalName(sig), pid)
+	}
+	if sig == syscall.SIGINT {
+		logf(levelInfo, Cyan+"Info: "+Reset+"Interrupt again to stop Wind\n")
+	}
+}
+
+// signalName returns the SIG name of sig
+func signalName(sig syscall.Signal) string {
+	for name, s := range signalNames {
+		if s == sig {
+			return name
+		}
+	}
+	if name, ok := crashSignals[sig]; ok {
+		return name
+	}
+	return sig.String()
+}
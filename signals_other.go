@@ -0,0 +1,7 @@
+//go:build !unix
+
+package main
+
+// defaultForwardSignals is empty: Wind is not sent SIGHUP or user-defined
+// signals here
+var defaultForwardSignals []string
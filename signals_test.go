@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestParseForwardSignals(t *testing.T) {
+	signals, err := parseForwardSignals([]string{"SIGHUP", "quit", "INT"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(signals) != 3 || signals[0] != syscall.SIGHUP || signals[1] != syscall.SIGQUIT || signals[2] != syscall.SIGINT {
+		t.Errorf("Unexpected signals %v", signals)
+	}
+	for _, name := range []string{"SIGTERM", "SIGKILL", "reload"} {
+		if _, err := parseForwardSignals([]string{name}); err == nil {
+			t.Errorf("Expected an error for %q", name)
+		}
+	}
+}
+
+func TestForwardSignal(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "reloaded")
+	cmd := exec.Command("sh", "-c", "trap 'touch "+marker+"' HUP; while :; do sleep 0.05; done")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start process: %v", err)
+	}
+	defer cmd.Process.Kill()
+	time.Sleep(100 * time.Millisecond)
+
+	app := newWindApp(WindConfig{}, "", "")
+	app.updateStatus(event{Event: "app_start", PID: cmd.Process.Pid})
+	orch := newOrchestrator([]*WindApp{app, newWindApp(WindConfig{}, "idle", "")})
+	orch.forward(syscall.SIGHUP)
+
+	for i := 0; i < 50; i++ {
+		if _, err := os.Stat(marker); err == nil {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Error("Expected the application to receive SIGHUP")
+}
+
+func TestForwardedInterruptTwiceQuits(t *testing.T) {
+	orch := newOrchestrator([]*WindApp{newWindApp(WindConfig{}, "", "")})
+	stop := orch.forwardSignals([]syscall.Signal{syscall.SIGINT})
+	defer stop()
+
+	// The first interrupt is only forwarded
+	signalPID(os.Getpid(), syscall.SIGINT)
+	time.Sleep(100 * time.Millisecond)
+	if len(orch.quitChan) != 0 {
+		t.Fatal("Expected a single interrupt not to quit")
+	}
+
+	signalPID(os.Getpid(), syscall.SIGINT)
+	select {
+	case <-orch.quitChan:
+	case <-time.After(2 * time.Second):
+		t.Error("Expected a second interrupt to quit")
+	}
+}
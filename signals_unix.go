@@ -0,0 +1,6 @@
+//go:build unix
+
+package main
+
+// defaultForwardSignals are the signals apps commonly reload on
+var defaultForwardSignals = []string{"SIGHUP", "SIGUSR1", "SIGUSR2"}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sizeHistoryFile records the binary size of every build of the session,
+// one JSON object per line, for wind report size
+const sizeHistoryFile = "tmp/binary-sizes.jsonl"
+
+// sizeBars draw the trend of wind report size, smallest first
+var sizeBars = []rune("▁▂▃▄▅▆▇█")
+
+// sizeBarWidth is the width of the largest bar of wind report size
+const sizeBarWidth = 30
+
+// sizeRecord is one line of sizeHistoryFile
+type sizeRecord struct {
+	Build  int    `json:"build"`
+	Target string `json:"target,omitempty"`
+	Time   string `json:"time"`
+	Bytes  int64  `json:"bytes"`
+}
+
+// sizeThreshold is a parsed SizeAlert: a growth in bytes or in percent of
+// the previous size
+type sizeThreshold struct {
+	bytes   uint64
+	percent float64
+}
+
+// parseSizeThreshold parses a SizeAlert such as 5MB or 10%
+func parseSizeThreshold(s string) (sizeThreshold, error) {
+	if number, ok := strings.CutSuffix(strings.TrimSpace(s), "%"); ok {
+		percent, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+		if err != nil || percent <= 0 {
+			return sizeThreshold{}, fmt.Errorf("invalid sizeAlert %q (expected e.g. 10%% or 5MB)", s)
+		}
+		return sizeThreshold{percent: percent}, nil
+	}
+	n, err := parseSize(s)
+	if err != nil || n == 0 {
+		return sizeThreshold{}, fmt.Errorf("invalid sizeAlert %q (expected e.g. 10%% or 5MB)", s)
+	}
+	return sizeThreshold{bytes: n}, nil
+}
+
+// exceeded reports whether growing from previous to current crosses the
+// threshold
+func (t sizeThreshold) exceeded(previous, current int64) bool {
+	growth := current - previous
+	if growth <= 0 || previous <= 0 {
+		return false
+	}
+	if t.percent > 0 {
+		return float64(growth)*100/float64(previous) > t.percent
+	}
+	return uint64(growth) > t.bytes
+}
+
+// formatSizeDelta renders a size change, e.g. +1.2 MB or -300 KB
+func formatSizeDelta(delta int64) string {
+	if delta < 0 {
+		return "-" + formatSize(uint64(-delta))
+	}
+	return "+" + formatSize(uint64(delta))
+}
+
+// trackBinarySize records the size of the binary just built, prints it with
+// the change since the previous build and warns when it grew beyond
+// SizeAlert, e.g. because a huge asset was embedded by accident
+func (app *WindApp) trackBinarySize() {
+	info, err := os.Stat(app.deployBinary())
+	if err != nil || !info.Mode().IsRegular() {
+		return
+	}
+	size := info.Size()
+	previous := app.binarySize
+	app.binarySize = size
+
+	record := sizeRecord{Build: app.buildID, Target: app.name, Time: time.Now().Format(time.RFC3339), Bytes: size}
+	if err := appendSizeRecord(record); err != nil {
+		logf(levelWarn, Yellow+"Warning: "+Reset+"%sFailed to write %s: %v\n", app.label(), sizeHistoryFile, err)
+	}
+
+	if previous == 0 {
+		logf(levelInfo, Cyan+"Info: "+Reset+"%sBinary size %s\n", app.label(), formatSize(uint64(size)))
+		return
+	}
+	if size == previous {
+		return
+	}
+	logf(levelInfo, Cyan+"Info: "+Reset+"%sBinary size %s (%s)\n", app.label(), formatSize(uint64(size)), formatSizeDelta(size-previous))
+	// The threshold was validated with the config
+	if threshold, err := parseSizeThreshold(app.config.SizeAlert); err == nil && threshold.exceeded(previous, size) {
+		logf(levelWarn, Yellow+"Warning: "+Reset+"%sBinary grew by %s to %s (sizeAlert %s); check for large embedded files or new dependencies\n",
+			app.label(), formatSize(uint64(size-previous)), formatSize(uint64(size)), app.config.SizeAlert)
+	}
+}
+
+func appendSizeRecord(record sizeRecord) error {
+	file, err := os.OpenFile(sizeHistoryFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	data, _ := json.Marshal(record)
+	_, err = fmt.Fprintf(file, "%s\n", data)
+	return err
+}
+
+// readSizeHistory returns the records of the latest session
+func readSizeHistory() ([]sizeRecord, error) {
+	file, err := os.Open(sizeHistoryFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []sizeRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record sizeRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// sizeBar draws bytes as a bar scaled between the smallest and the largest
+// size of the session, so small changes of a large binary stay visible
+func sizeBar(bytes, smallest, largest int64) string {
+	if largest == smallest {
+		return strings.Repeat(string(sizeBars[len(sizeBars)-1]), sizeBarWidth/2)
+	}
+	// Eighths of a character, at least one so every build has a bar
+	eighths := 8 + int((bytes-smallest)*int64(sizeBarWidth-1)*8/(largest-smallest))
+	bar := strings.Repeat(string(sizeBars[len(sizeBars)-1]), eighths/8)
+	if rest := eighths % 8; rest > 0 {
+		bar += string(sizeBars[rest-1])
+	}
+	return bar
+}
+
+// formatSizeReport renders the trend of records as a table with a bar per
+// build, grouped by target
+func formatSizeReport(records []sizeRecord, width int) string {
+	var targets []string
+	byTarget := map[string][]sizeRecord{}
+	for _, r := range records {
+		if _, ok := byTarget[r.Target]; !ok {
+			targets = append(targets, r.Target)
+		}
+		byTarget[r.Target] = append(byTarget[r.Target], r)
+	}
+
+	var b strings.Builder
+	for _, target := range targets {
+		records := byTarget[target]
+		smallest, largest := records[0].Bytes, records[0].Bytes
+		for _, r := range records {
+			smallest, largest = min(smallest, r.Bytes), max(largest, r.Bytes)
+		}
+
+		if target != "" {
+			fmt.Fprintf(&b, Yellow+"%s"+Reset+"\n", target)
+		}
+		rows := newTable("  ")
+		for i, r := range records {
+			delta := ""
+			if i > 0 && r.Bytes != records[i-1].Bytes {
+				delta = formatSizeDelta(r.Bytes - records[i-1].Bytes)
+			}
+			at := r.Time
+			if t, err := time.Parse(time.RFC3339, r.Time); err == nil {
+				at = t.Format("15:04:05")
+			}
+			rows.addRow("#"+strconv.Itoa(r.Build), at, formatSize(uint64(r.Bytes)), delta, sizeBar(r.Bytes, smallest, largest))
+		}
+		b.WriteString(rows.render(width))
+		first, last := records[0].Bytes, records[len(records)-1].Bytes
+		fmt.Fprintf(&b, "  %s over %s: %s → %s\n", formatSizeDelta(last-first), pluralize(len(records), "build"), formatSize(uint64(first)), formatSize(uint64(last)))
+	}
+	return b.String()
+}
+
+// runReport implements `wind report size`
+func runReport(args []string) {
+	if len(args) == 0 || args[0] != "size" {
+		fmt.Println("Usage: wind report size")
+		return
+	}
+	records, err := readSizeHistory()
+	if os.IsNotExist(err) || (err == nil && len(records) == 0) {
+		logf(levelInfo, Yellow+"Info: "+Reset+"No binary sizes recorded yet; they are recorded for every build of a session\n")
+		return
+	}
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Failed to read %s: %v\n", sizeHistoryFile, err)
+		return
+	}
+	fmt.Print(formatSizeReport(records, terminalWidth()))
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseSizeThreshold(t *testing.T) {
+	if got, err := parseSizeThreshold("20%"); err != nil || got.percent != 20 {
+		t.Errorf("parseSizeThreshold(20%%) = %+v, %v", got, err)
+	}
+	if got, err := parseSizeThreshold("5MB"); err != nil || got.bytes != 5<<20 {
+		t.Errorf("parseSizeThreshold(5MB) = %+v, %v", got, err)
+	}
+	for _, input := range []string{"", "0%", "-5%", "lots", "0"} {
+		if _, err := parseSizeThreshold(input); err == nil {
+			t.Errorf("Expected parseSizeThreshold(%q) to fail", input)
+		}
+	}
+}
+
+func TestSizeThresholdExceeded(t *testing.T) {
+	percent := sizeThreshold{percent: 20}
+	bytes := sizeThreshold{bytes: 5 << 20}
+	tests := []struct {
+		threshold         sizeThreshold
+		previous, current int64
+		expected          bool
+	}{
+		{percent, 10 << 20, 11 << 20, false},
+		{percent, 10 << 20, 13 << 20, true},
+		{percent, 10 << 20, 5 << 20, false},
+		{bytes, 10 << 20, 14 << 20, false},
+		{bytes, 10 << 20, 16 << 20, true},
+		{bytes, 0, 16 << 20, false},
+	}
+	for _, tt := range tests {
+		if got := tt.threshold.exceeded(tt.previous, tt.current); got != tt.expected {
+			t.Errorf("%+v exceeded(%d, %d) = %v, expected %v", tt.threshold, tt.previous, tt.current, got, tt.expected)
+		}
+	}
+}
+
+func TestTrackBinarySize(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	os.Mkdir("tmp", 0755)
+
+	app := newWindApp(WindConfig{BuildCmd: "go build -o ./tmp/main .", SizeAlert: "20%"}, "", "")
+	for i, size := range []int{1000, 1100, 4000} {
+		os.WriteFile("tmp/main", make([]byte, size), 0755)
+		app.buildID = i + 1
+		app.trackBinarySize()
+	}
+	if app.binarySize != 4000 {
+		t.Errorf("Expected the latest size to be kept, got %d", app.binarySize)
+	}
+
+	records, err := readSizeHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || records[0].Build != 1 || records[2].Bytes != 4000 {
+		t.Fatalf("Expected three records, got %+v", records)
+	}
+
+	report := formatSizeReport(records, 100)
+	for _, expected := range []string{"#1", "#3", "+2.8 KB", "+2.9 KB over 3 builds: 1000 B → 3.9 KB"} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("Expected the report to contain %q:\n%s", expected, report)
+		}
+	}
+}
+
+func TestSizeBar(t *testing.T) {
+	if got := sizeBar(100, 100, 200); got != "█" {
+		t.Errorf("Expected the smallest size to get one block, got %q", got)
+	}
+	if got := sizeBar(200, 100, 200); len([]rune(got)) != sizeBarWidth {
+		t.Errorf("Expected the largest size to get %d blocks, got %q", sizeBarWidth, got)
+	}
+}
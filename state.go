@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// stateFile records the children of the running session so a later session
+// can find processes left behind when Wind crashed
+const stateFile = "tmp/wind-state.json"
+
+type sessionState struct {
+	WindPID  int           `json:"wind_pid"`
+	Children []childRecord `json:"children"`
+}
+
+type childRecord struct {
+	PID int `json:"pid"`
+	// PGID is the process group the child leads, 0 where there are none
+	PGID    int       `json:"pgid,omitempty"`
+	Command string    `json:"command"`
+	Started time.Time `json:"started"`
+	// StartTime tells the child from a later process reusing its PID (see
+	// processStartTime)
+	StartTime string `json:"start_time,omitempty"`
+}
+
+var stateMutex sync.Mutex
+
+func readState() (sessionState, error) {
+	var state sessionState
+	data, err := os.ReadFile(stateFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return state, err
+	}
+	err = json.Unmarshal(data, &state)
+	return state, err
+}
+
+func writeState(state sessionState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := stateFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, stateFile)
+}
+
+// recordChild adds a started child process to the state file
+func recordChild(pid int, command string) {
+	child := childRecord{
+		PID:       pid,
+		PGID:      processGroup(pid),
+		Command:   command,
+		Started:   time.Now(),
+		StartTime: processStartTime(pid),
+	}
+
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+
+	state, _ := readState()
+	state.WindPID = os.Getpid()
+	state.Children = append(state.Children, child)
+	writeState(state)
+}
+
+// forgetChild removes a stopped child process from the state file
+func forgetChild(pid int) {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+
+	state, err := readState()
+	if err != nil {
+		return
+	}
+	children := state.Children[:0]
+	for _, child := range state.Children {
+		if child.PID != pid {
+			children = append(children, child)
+		}
+	}
+	state.Children = children
+	writeState(state)
+}
+
+// processCommand returns the command line of a running process
+func processCommand(pid int) string {
+	if data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/cmdline"); err == nil {
+		return strings.TrimSpace(strings.ReplaceAll(string(data), "\x00", " "))
+	}
+	out, err := exec.Command("ps", "-o", "command=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// processStartTime returns when a running process started, in a form only
+// meant for comparison, or "" when it can't be told
+func processStartTime(pid int) string {
+	if data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat"); err == nil {
+		// The fields after the parenthesized command; starttime is the 22nd
+		// field of the line
+		if i := strings.LastIndexByte(string(data), ')'); i >= 0 {
+			if fields := strings.Fields(string(data[i+1:])); len(fields) > 19 {
+				return fields[19]
+			}
+		}
+		return ""
+	}
+	out, err := exec.Command("ps", "-o", "lstart=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// running reports whether the recorded child, or a process it started in
+// its group, still runs. A live PID only counts when it started when the
+// child did, so a recycled PID is never mistaken for an orphan. Without the
+// leader, a live group is the child's: a group ID isn't reused while the
+// group has members.
+func (c childRecord) running() bool {
+	if processAlive(c.PID) {
+		return c.StartTime != "" && processStartTime(c.PID) == c.StartTime
+	}
+	return c.PGID != 0 && groupAlive(c.PGID)
+}
+
+// stop terminates the child with everything in its process group, so the
+// application goes too when a shell ran it without exec
+func (c childRecord) stop() error {
+	if c.PGID != 0 {
+		return signalPGID(c.PGID, syscall.SIGTERM)
+	}
+	return signalPID(c.PID, syscall.SIGTERM)
+}
+
+// findAbandoned returns the recorded children of a previous session that are
+// still running (see childRecord.running).
+func findAbandoned() ([]childRecord, error) {
+	state, err := readState()
+	if err != nil {
+		return nil, err
+	}
+
+	// Another live Wind owns these children
+	if state.WindPID != os.Getpid() && processAlive(state.WindPID) &&
+		strings.Contains(processCommand(state.WindPID), "wind") {
+		return nil, fmt.Errorf("another Wind session (PID %d) is running in this project", state.WindPID)
+	}
+
+	var abandoned []childRecord
+	for _, child := range state.Children {
+		if child.running() {
+			abandoned = append(abandoned, child)
+		}
+	}
+	return abandoned, nil
+}
+
+// collectAbandoned offers to terminate children left behind by a crashed
+// session and resets the state file for this session
+func collectAbandoned(interactive bool) {
+	abandoned, err := findAbandoned()
+	if err != nil {
+		logf(levelWarn, Yellow+"Warning: "+Reset+"%v\n", err)
+		return
+	}
+
+	if len(abandoned) > 0 {
+		logf(levelWarn, Yellow+"Warning: "+Reset+"Found %d process(es) left running by a previous Wind session:\n", len(abandoned))
+		rows := newTable("  ")
+		for _, child := range abandoned {
+			rows.addRow(fmt.Sprintf("PID %d", child.PID), "started "+child.Started.Format(time.Stamp), child.Command)
+		}
+		fmt.Print(rows.render(terminalWidth()))
+
+		kill := false
+		if interactive {
+			fmt.Print("Terminate them? [Y/n] ")
+			answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			answer = strings.ToLower(strings.TrimSpace(answer))
+			kill = answer == "" || answer == "y" || answer == "yes"
+		} else {
+			logf(levelInfo, Yellow+"Info: "+Reset+"Not attached to a terminal; leaving them running\n")
+		}
+
+		if kill {
+			for _, child := range abandoned {
+				if err := child.stop(); err != nil {
+					logf(levelError, Red+"Error: "+Reset+"Failed to stop PID %d: %v\n", child.PID, err)
+					continue
+				}
+				logf(levelSuccess, Green+"Success: "+Reset+"Stopped abandoned PID %d\n", child.PID)
+			}
+		}
+	}
+
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+	writeState(sessionState{WindPID: os.Getpid()})
+}
@@ -0,0 +1,50 @@
+//go:build !unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// processAlive reports whether a process with the given pid exists
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
+
+// signalPID sends sig to the process with the given pid. Processes cannot
+// be asked to terminate here, so SIGTERM kills them; other signals fail.
+func signalPID(pid int, sig syscall.Signal) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	defer p.Release()
+	if sig == syscall.SIGTERM {
+		return p.Kill()
+	}
+	return p.Signal(sig)
+}
+
+// processGroup returns 0: there are no process groups here
+func processGroup(pid int) int {
+	return 0
+}
+
+// groupAlive reports false: there are no process groups here
+func groupAlive(pgid int) bool {
+	return false
+}
+
+// signalPGID signals the group's leader, as there are no process groups here
+func signalPGID(pgid int, sig syscall.Signal) error {
+	return signalPID(pgid, sig)
+}
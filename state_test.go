@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindAbandoned(t *testing.T) {
+	tmpDir := createTempProject(t, "root")
+	defer os.RemoveAll(tmpDir)
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+	if err := os.MkdirAll("tmp", 0755); err != nil {
+		t.Fatalf("Failed to create tmp dir: %v", err)
+	}
+
+	// A child that outlives its (crashed) Wind session
+	child := exec.Command("sleep", "30")
+	if err := child.Start(); err != nil {
+		t.Skipf("sleep not available: %v", err)
+	}
+	defer child.Process.Kill()
+
+	recordChild(child.Process.Pid, "sleep 30")
+	recordChild(999999, "./tmp/main") // long gone
+
+	// Pretend the recorded session was a different, dead Wind process
+	state, _ := readState()
+	state.WindPID = 999998
+	if err := writeState(state); err != nil {
+		t.Fatalf("Failed to write state: %v", err)
+	}
+
+	abandoned, err := findAbandoned()
+	if err != nil {
+		t.Fatalf("findAbandoned failed: %v", err)
+	}
+	if len(abandoned) != 1 || abandoned[0].PID != child.Process.Pid {
+		t.Errorf("Expected only the live child to be reported, got %+v", abandoned)
+	}
+
+	// Stopped children are forgotten
+	forgetChild(child.Process.Pid)
+	if abandoned, _ := findAbandoned(); len(abandoned) != 0 {
+		t.Errorf("Expected no abandoned children after forgetChild, got %+v", abandoned)
+	}
+}
+
+func TestAbandonedProcessGroup(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	os.MkdirAll("tmp", 0755)
+
+	// A shell that doesn't exec into the application, started like the run
+	// command with quoted arguments
+	command := `sleep "30"; echo 'done'`
+	cmd := exec.Command("sh", "-c", command)
+	cmd.SysProcAttr = groupProcAttr()
+	if err := cmd.Start(); err != nil {
+		t.Skipf("sh not available: %v", err)
+	}
+	defer cmd.Process.Kill()
+	recordChild(cmd.Process.Pid, command)
+
+	state, _ := readState()
+	child := state.Children[0]
+	if child.PGID == 0 {
+		t.Skip("No process groups on this platform")
+	}
+	state.WindPID = 999998
+	writeState(state)
+
+	abandoned, err := findAbandoned()
+	if err != nil || len(abandoned) != 1 {
+		t.Fatalf("Expected the shell to be reported, got %+v (%v)", abandoned, err)
+	}
+
+	// A recycled PID started at another time is not the child
+	recycled := child
+	recycled.StartTime = "0"
+	if recycled.running() {
+		t.Errorf("Expected a different start time not to match")
+	}
+
+	// The application outlives the shell in its group
+	time.Sleep(200 * time.Millisecond)
+	cmd.Process.Kill()
+	cmd.Wait()
+	if !child.running() {
+		t.Fatalf("Expected the group to still run without the shell")
+	}
+	if err := child.stop(); err != nil {
+		t.Fatalf("stop failed: %v", err)
+	}
+	for i := 0; i < 50 && groupRunning(child.PGID); i++ {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if groupRunning(child.PGID) {
+		t.Errorf("Expected the whole group to be stopped")
+	}
+}
+
+// groupRunning reports whether a process of the group pgid runs. Unlike
+// groupAlive it skips zombies, which init may not have reaped yet.
+func groupRunning(pgid int) bool {
+	out, _ := exec.Command("ps", "-eo", "pgid=,stat=").Output()
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == strconv.Itoa(pgid) && !strings.HasPrefix(fields[1], "Z") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+//go:build unix
+
+package main
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processAlive reports whether a process with the given pid exists
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// signalPID sends sig to the process with the given pid
+func signalPID(pid int, sig syscall.Signal) error {
+	return syscall.Kill(pid, sig)
+}
+
+// processGroup returns the process group pid leads, or 0 when it shares
+// another process's group
+func processGroup(pid int) int {
+	if pgid, err := syscall.Getpgid(pid); err == nil && pgid == pid {
+		return pgid
+	}
+	return 0
+}
+
+// groupAlive reports whether the process group pgid has a member
+func groupAlive(pgid int) bool {
+	err := syscall.Kill(-pgid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// signalPGID sends sig to every process in the group pgid
+func signalPGID(pgid int, sig syscall.Signal) error {
+	return syscall.Kill(-pgid, sig)
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// historyFile records every build cycle, one JSON object per line, when
+// History is set; wind stats summarizes it
+const historyFile = "tmp/wind-history.jsonl"
+
+const (
+	// cycleMemory is how many cycles a target keeps for the control API
+	cycleMemory = 200
+	// historyKeep is how many cycles historyFile keeps across sessions
+	historyKeep = 2000
+	// maxTriggers is how many changed files a cycle records
+	maxTriggers = 10
+	// slowestShown is how many of the slowest cycles wind stats lists
+	slowestShown = 5
+)
+
+// cycleStats is the timing of one build cycle: the scan that noticed the
+// change, the generators and formatters before the build, the build and the
+// start of the new process
+type cycleStats struct {
+	Time   string `json:"time"`
+	Target string `json:"target,omitempty"`
+	Build  int    `json:"build"`
+	OK     bool   `json:"ok"`
+	// Trigger holds the first changed files, Changed counts all of them
+	Trigger   []string `json:"trigger,omitempty"`
+	Changed   int      `json:"changed,omitempty"`
+	ScanMs    int64    `json:"scan_ms"`
+	PrepareMs int64    `json:"prepare_ms"`
+	BuildMs   int64    `json:"build_ms"`
+	StartupMs int64    `json:"startup_ms"`
+	// TotalMs is the time from the end of the debounce to the running app
+	TotalMs int64 `json:"total_ms"`
+
+	started time.Time
+}
+
+// startCycle begins the stats of a build cycle with the changes and the
+// scan that triggered it
+func (app *WindApp) startCycle() *cycleStats {
+	now := time.Now()
+	cycle := &cycleStats{
+		Time:    now.Format(time.RFC3339),
+		Target:  app.name,
+		Changed: len(app.changedFiles),
+		ScanMs:  app.scanTime.Milliseconds(),
+		started: now,
+	}
+	for _, path := range app.changedFiles[:min(len(app.changedFiles), maxTriggers)] {
+		cycle.Trigger = append(cycle.Trigger, projectPath(path))
+	}
+	app.scanTime = 0
+	return cycle
+}
+
+// recordCycle finishes cycle and keeps it in memory and, with History, in
+// historyFile
+func (app *WindApp) recordCycle(cycle *cycleStats) {
+	cycle.TotalMs = time.Since(cycle.started).Milliseconds()
+
+	app.statusMutex.Lock()
+	app.cycles = append(app.cycles, *cycle)
+	if len(app.cycles) > cycleMemory {
+		app.cycles = app.cycles[1:]
+	}
+	app.statusMutex.Unlock()
+
+	if app.config.Verbose {
+		logf(levelInfo, Cyan+"Timing: "+Reset+"%s%s\n", app.label(), formatCycle(*cycle))
+	}
+	if !app.config.History {
+		return
+	}
+	if err := appendHistory(*cycle); err != nil {
+		logf(levelWarn, Yellow+"Warning: "+Reset+"%sFailed to write %s: %v\n", app.label(), historyFile, err)
+	}
+}
+
+// recentCycles returns the cycles kept in memory
+func (app *WindApp) recentCycles() []cycleStats {
+	app.statusMutex.Lock()
+	defer app.statusMutex.Unlock()
+	return append([]cycleStats(nil), app.cycles...)
+}
+
+func appendHistory(cycle cycleStats) error {
+	file, err := os.OpenFile(historyFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	data, _ := json.Marshal(cycle)
+	_, err = fmt.Fprintf(file, "%s\n", data)
+	return err
+}
+
+// readHistory returns the cycles recorded in historyFile, oldest first
+func readHistory() ([]cycleStats, error) {
+	file, err := os.Open(historyFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var cycles []cycleStats
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var cycle cycleStats
+		if err := json.Unmarshal(scanner.Bytes(), &cycle); err != nil {
+			continue
+		}
+		cycles = append(cycles, cycle)
+	}
+	return cycles, scanner.Err()
+}
+
+// trimHistory drops all but the last historyKeep cycles from historyFile,
+// so the history of a long-lived project stays small
+func trimHistory() {
+	cycles, err := readHistory()
+	if err != nil || len(cycles) <= historyKeep {
+		return
+	}
+	var b strings.Builder
+	for _, cycle := range cycles[len(cycles)-historyKeep:] {
+		data, _ := json.Marshal(cycle)
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	os.WriteFile(historyFile, []byte(b.String()), 0644)
+}
+
+// formatPhase renders a phase duration rounded for reading, e.g. 1.25s
+func formatPhase(d time.Duration) string {
+	if d >= time.Second {
+		return d.Round(10 * time.Millisecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+// formatCycle renders the phases of one cycle for verbose output
+func formatCycle(c cycleStats) string {
+	ms := func(v int64) string { return formatPhase(time.Duration(v) * time.Millisecond) }
+	line := fmt.Sprintf("scan %s, prepare %s, build %s", ms(c.ScanMs), ms(c.PrepareMs), ms(c.BuildMs))
+	if c.OK {
+		line += ", startup " + ms(c.StartupMs)
+	}
+	return line + ", total " + ms(c.TotalMs)
+}
+
+// formatStats renders the averages, p95s and slowest cycles of each target
+func formatStats(cycles []cycleStats, width int) string {
+	var targets []string
+	byTarget := map[string][]cycleStats{}
+	for _, c := range cycles {
+		if _, ok := byTarget[c.Target]; !ok {
+			targets = append(targets, c.Target)
+		}
+		byTarget[c.Target] = append(byTarget[c.Target], c)
+	}
+
+	var b strings.Builder
+	for _, target := range targets {
+		cycles := byTarget[target]
+		failed := 0
+		var scan, prepare, build, startup, total []time.Duration
+		for _, c := range cycles {
+			if !c.OK {
+				failed++
+			}
+			scan = append(scan, time.Duration(c.ScanMs)*time.Millisecond)
+			prepare = append(prepare, time.Duration(c.PrepareMs)*time.Millisecond)
+			build = append(build, time.Duration(c.BuildMs)*time.Millisecond)
+			// Failed builds never start the app
+			if c.OK {
+				startup = append(startup, time.Duration(c.StartupMs)*time.Millisecond)
+			}
+			total = append(total, time.Duration(c.TotalMs)*time.Millisecond)
+		}
+
+		name := target
+		if name == "" {
+			name = "Builds"
+		}
+		fmt.Fprintf(&b, Yellow+"%s"+Reset+": %s, %d failed\n", name, pluralize(len(cycles), "cycle"), failed)
+		rows := newTable("  ")
+		rows.addRow("", "avg", "p95", "max")
+		for _, phase := range []struct {
+			name   string
+			values []time.Duration
+		}{
+			{"scan", scan},
+			{"prepare", prepare},
+			{"build", build},
+			{"startup", startup},
+			{"total", total},
+		} {
+			if len(phase.values) == 0 {
+				continue
+			}
+			slices.Sort(phase.values)
+			rows.addRow(phase.name, formatPhase(averageBuildTime(phase.values)), formatPhase(percentile(phase.values, 95)), formatPhase(phase.values[len(phase.values)-1]))
+		}
+		b.WriteString(rows.render(width))
+
+		slowest := append([]cycleStats(nil), cycles...)
+		sort.SliceStable(slowest, func(i, j int) bool { return slowest[i].BuildMs > slowest[j].BuildMs })
+		fmt.Fprintf(&b, "\n  Slowest builds:\n")
+		rows = newTable("    ")
+		for _, c := range slowest[:min(len(slowest), slowestShown)] {
+			at := c.Time
+			if t, err := time.Parse(time.RFC3339, c.Time); err == nil {
+				at = t.Format("Jan 2 15:04")
+			}
+			rows.addRow("#"+strconv.Itoa(c.Build), at, formatPhase(time.Duration(c.BuildMs)*time.Millisecond), describeTrigger(c))
+		}
+		b.WriteString(rows.render(width))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// describeTrigger names the files that started a cycle
+func describeTrigger(c cycleStats) string {
+	switch {
+	case len(c.Trigger) == 0:
+		return "(start or manual rebuild)"
+	case c.Changed > 1:
+		return fmt.Sprintf("%s and %d more", c.Trigger[0], c.Changed-1)
+	default:
+		return c.Trigger[0]
+	}
+}
+
+// runStats implements `wind stats`
+func runStats() {
+	cycles, err := readHistory()
+	if os.IsNotExist(err) || (err == nil && len(cycles) == 0) {
+		logf(levelInfo, Yellow+"Info: "+Reset+"No build history yet; set history: true in %s to record it\n", configFileName)
+		return
+	}
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Failed to read %s: %v\n", historyFile, err)
+		return
+	}
+	fmt.Print(formatStats(cycles, terminalWidth()))
+}
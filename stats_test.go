@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordCycle(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	os.Mkdir("tmp", 0755)
+
+	app := newWindApp(WindConfig{History: true}, "api", "")
+	app.changedFiles = []string{"internal/store/store.go", "main.go"}
+	app.scanTime = 12 * time.Millisecond
+	cycle := app.startCycle()
+	cycle.Build, cycle.OK, cycle.BuildMs = 3, true, 900
+	app.recordCycle(cycle)
+
+	if app.scanTime != 0 {
+		t.Error("Expected the scan time to be used up by the cycle")
+	}
+	history, err := readHistory()
+	if err != nil || len(history) != 1 {
+		t.Fatalf("Expected one cycle in the history, got %v (%v)", history, err)
+	}
+	got := history[0]
+	if got.Target != "api" || got.Build != 3 || got.ScanMs != 12 || got.Changed != 2 || got.Trigger[0] != "internal/store/store.go" {
+		t.Errorf("Unexpected cycle %+v", got)
+	}
+	if recent := app.recentCycles(); len(recent) != 1 || recent[0].Build != 3 {
+		t.Errorf("Expected the cycle to be kept in memory, got %v", recent)
+	}
+
+	// Without history cycles are only kept in memory
+	app.config.History = false
+	app.recordCycle(app.startCycle())
+	if history, _ := readHistory(); len(history) != 1 {
+		t.Errorf("Expected the history to be left alone, got %d cycles", len(history))
+	}
+}
+
+func TestFormatStats(t *testing.T) {
+	var cycles []cycleStats
+	for i := 1; i <= 20; i++ {
+		cycles = append(cycles, cycleStats{Build: i, OK: i != 7, BuildMs: int64(i * 100), StartupMs: 50, TotalMs: int64(i*100 + 50), Trigger: []string{"main.go"}, Changed: 1})
+	}
+	cycles[19].Trigger, cycles[19].Changed = []string{"store.go", "main.go"}, 3
+
+	out := formatStats(cycles, 120)
+	for _, want := range []string{"20 cycles, 1 failed", "build", "1.05s", "1.9s", "2s", "#20", "#16", "store.go and 2 more"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "#15 ") {
+		t.Errorf("Expected only the 5 slowest builds in:\n%s", out)
+	}
+}
+
+func TestFormatCycle(t *testing.T) {
+	c := cycleStats{OK: true, ScanMs: 12, PrepareMs: 40, BuildMs: 1250, StartupMs: 300, TotalMs: 1602}
+	if got, expected := formatCycle(c), "scan 12ms, prepare 40ms, build 1.25s, startup 300ms, total 1.6s"; got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+	c.OK = false
+	if got := formatCycle(c); strings.Contains(got, "startup") {
+		t.Errorf("Expected no startup for a failed build, got %q", got)
+	}
+}
+
+func TestTrimHistory(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+	os.Mkdir("tmp", 0755)
+
+	for i := 1; i <= historyKeep+5; i++ {
+		appendHistory(cycleStats{Build: i})
+	}
+	trimHistory()
+	history, _ := readHistory()
+	if len(history) != historyKeep || history[0].Build != 6 {
+		t.Errorf("Expected the last %d cycles to be kept, got %d starting at #%d", historyKeep, len(history), history[0].Build)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clearScreen moves the cursor home and clears the terminal
+const clearScreen = "\033[H\033[2J"
+
+// statusRefresh is how often the status line is redrawn while nothing is
+// printed
+const statusRefresh = 250 * time.Millisecond
+
+// clearForCycle clears the terminal as a new cycle starts (ClearOnRebuild)
+func (app *WindApp) clearForCycle() {
+	if app.config.ClearOnRebuild {
+		fmt.Print(clearScreen)
+	}
+}
+
+// progress prints a progress message of the build cycle at level. With
+// StatusLine the status line shows the progress instead.
+func (app *WindApp) progress(level msgLevel, format string, args ...any) {
+	if app.config.StatusLine {
+		return
+	}
+	logf(level, format, args...)
+}
+
+// formatStatusLine renders the state of one target for the status line,
+// e.g. "✅ build 412ms · running PID 3021 · 37 files watched"
+func formatStatusLine(s appStatus) string {
+	var parts []string
+	switch s.State {
+	case "building":
+		parts = append(parts, Cyan+fmt.Sprintf("🔨 building #%d", s.Build)+Reset)
+	case "build_failed":
+		failed := "❌ build failed"
+		if s.Errors > 0 {
+			failed += ": " + pluralize(s.Errors, "error")
+		}
+		parts = append(parts, Red+failed+Reset)
+	default:
+		if s.BuildMs > 0 {
+			parts = append(parts, Green+"✅ build "+formatPhase(time.Duration(s.BuildMs)*time.Millisecond)+Reset)
+		}
+		switch {
+		case s.PID != 0:
+			parts = append(parts, fmt.Sprintf("running PID %d", s.PID))
+		case s.State == "stopped":
+			parts = append(parts, Yellow+"stopped"+Reset)
+		}
+	}
+	if s.Files > 0 {
+		parts = append(parts, pluralize(s.Files, "file")+" watched")
+	}
+	if s.Paused {
+		parts = append(parts, Yellow+"paused"+Reset)
+	}
+	line := strings.Join(parts, " · ")
+	if s.Target != "" {
+		line = Cyan + s.Target + Reset + " " + line
+	}
+	return line
+}
+
+// statusSummary is the status line of every target
+func (o *orchestrator) statusSummary() string {
+	lines := make([]string, len(o.apps))
+	for i, app := range o.apps {
+		lines[i] = formatStatusLine(app.currentStatus())
+	}
+	return strings.Join(lines, "  │  ")
+}
+
+// truncateLine shortens line to width columns, ending it with an ellipsis.
+// Color codes are dropped from a shortened line.
+func truncateLine(line string, width int) string {
+	if visibleWidth(line) <= width {
+		return line
+	}
+	if width < 1 {
+		return ""
+	}
+	runes := []rune(ansiPattern.ReplaceAllString(line, ""))
+	return string(runes[:width-1]) + "…"
+}
+
+// statusLine keeps a line at the bottom of the terminal showing the state
+// of every target. Output is written above it: the line is erased before
+// and redrawn after every complete line.
+type statusLine struct {
+	mutex sync.Mutex
+	out   io.Writer
+	// render returns the line; nothing is drawn before it is set
+	render func() string
+	// shown is set while the line is drawn, drawn is its text; open is set
+	// while a line of output waits for its newline, which the status line
+	// must not interrupt
+	shown    bool
+	open     bool
+	drawn    string
+	redirect *outputRedirect
+	done     chan struct{}
+	finished chan struct{}
+}
+
+// startStatusLine keeps the status line below everything written to
+// os.Stdout and os.Stderr from now on, which must be a terminal. Output
+// redirected later, e.g. to a log file, passes through it, so it never
+// sees the status line.
+func startStatusLine() (*statusLine, error) {
+	s := &statusLine{out: os.Stdout, done: make(chan struct{}), finished: make(chan struct{})}
+	redirect, err := redirectOutput(func(dst *os.File) io.Writer {
+		return &statusStream{line: s, dst: dst}
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.redirect = redirect
+	go s.refresh()
+	return s, nil
+}
+
+// show starts drawing the line render returns
+func (s *statusLine) show(render func() string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.render = render
+	if !s.open {
+		s.draw()
+	}
+}
+
+// refresh redraws the line while nothing is printed, until stop
+func (s *statusLine) refresh() {
+	defer close(s.finished)
+	ticker := time.NewTicker(statusRefresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.mutex.Lock()
+			if !s.open && (!s.shown || s.render != nil && s.render() != s.drawn) {
+				s.draw()
+			}
+			s.mutex.Unlock()
+		}
+	}
+}
+
+// stop erases the line and restores the output streams
+func (s *statusLine) stop() {
+	close(s.done)
+	<-s.finished
+	s.redirect.restore()
+	s.mutex.Lock()
+	s.erase()
+	s.mutex.Unlock()
+}
+
+// draw replaces the status line with the current one. The caller holds
+// the mutex.
+func (s *statusLine) draw() {
+	if s.render == nil {
+		return
+	}
+	s.drawn = s.render()
+	fmt.Fprint(s.out, "\r\033[K"+truncateLine(s.drawn, terminalWidth()-1))
+	s.shown = true
+}
+
+// erase removes the status line. The caller holds the mutex.
+func (s *statusLine) erase() {
+	if s.shown {
+		fmt.Fprint(s.out, "\r\033[K")
+		s.shown = false
+	}
+}
+
+// statusStream writes one output stream above the status line
+type statusStream struct {
+	line *statusLine
+	dst  io.Writer
+}
+
+func (w *statusStream) Write(data []byte) (int, error) {
+	s := w.line
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.erase()
+	n, err := w.dst.Write(data)
+	s.open = len(data) > 0 && !bytes.HasSuffix(data, []byte("\n"))
+	if !s.open {
+		s.draw()
+	}
+	return n, err
+}
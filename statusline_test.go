@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFormatStatusLine(t *testing.T) {
+	tests := []struct {
+		status   appStatus
+		expected string
+	}{
+		{
+			appStatus{State: "running", BuildMs: 412, PID: 3021, Files: 37},
+			Green + "✅ build 412ms" + Reset + " · running PID 3021 · 37 files watched",
+		},
+		{
+			appStatus{State: "building", Build: 7, Files: 1},
+			Cyan + "🔨 building #7" + Reset + " · 1 file watched",
+		},
+		{
+			appStatus{Target: "api", State: "build_failed", Errors: 2, Paused: true},
+			Cyan + "api" + Reset + " " + Red + "❌ build failed: 2 errors" + Reset + " · " + Yellow + "paused" + Reset,
+		},
+	}
+	for _, tt := range tests {
+		if got := formatStatusLine(tt.status); got != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, got)
+		}
+	}
+}
+
+func TestTruncateLine(t *testing.T) {
+	line := Green + "✅ build 412ms" + Reset + " · running PID 3021"
+	if got := truncateLine(line, 40); got != line {
+		t.Errorf("Expected a fitting line to be left alone, got %q", got)
+	}
+	if got, expected := truncateLine(line, 12), "✅ build 412…"; got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestStatusStream(t *testing.T) {
+	var buf bytes.Buffer
+	s := &statusLine{out: &buf, render: func() string { return "status" }}
+	w := &statusStream{line: s, dst: &buf}
+
+	// Output goes above the line, which is redrawn after complete lines
+	w.Write([]byte("hello\n"))
+	w.Write([]byte("Password: "))
+	w.Write([]byte("\n"))
+	expected := "hello\n\r\033[Kstatus" + "\r\033[KPassword: " + "\n\r\033[Kstatus"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// parseSignal looks up a signal name such as SIGHUP, HUP or hup
+func parseSignal(name string) (syscall.Signal, bool) {
+	key := strings.ToUpper(name)
+	if !strings.HasPrefix(key, "SIG") {
+		key = "SIG" + key
+	}
+	sig, ok := signalNames[key]
+	return sig, ok
+}
+
+// parseStopSignal looks up a StopSignal name; empty means SIGTERM
+func parseStopSignal(name string) (syscall.Signal, error) {
+	if name == "" {
+		return syscall.SIGTERM, nil
+	}
+	sig, ok := parseSignal(name)
+	if !ok {
+		return 0, fmt.Errorf("invalid stopSignal %q (expected SIGTERM, SIGINT, SIGQUIT, SIGHUP, SIGUSR1 or SIGUSR2)", name)
+	}
+	return sig, nil
+}
+
+// processExit reaps a process in the background, so its exit can be
+// noticed while it runs; state is set once done is closed
+type processExit struct {
+	process *os.Process
+	done    chan struct{}
+	state   *os.ProcessState
+	// stopping is set once Wind stops the process, so its exit is expected
+	stopping atomic.Bool
+	// guarded is set while a crash rolls back to the last good binary
+	// (see guardStartup); it is guarded by app.mutex
+	guarded bool
+	// streams are the process's standard streams Wind keeps
+	streams *childIO
+}
+
+// watchExit starts waiting for process to exit
+func watchExit(process *os.Process) *processExit {
+	e := &processExit{process: process, done: make(chan struct{})}
+	go func() {
+		e.state, _ = process.Wait()
+		close(e.done)
+	}()
+	return e
+}
+
+// stopGracefully sends sig to process and waits up to grace for it to exit
+// before killing it. A grace of zero waits indefinitely. exit is the
+// process's watchExit, or nil when nothing waits for it yet. It reports
+// whether the process had to be killed.
+func stopGracefully(process *os.Process, exit *processExit, sig syscall.Signal, grace time.Duration) (*os.ProcessState, bool) {
+	if exit != nil {
+		exit.stopping.Store(true)
+	}
+	if err := process.Signal(sig); err != nil {
+		process.Kill()
+	}
+
+	if exit == nil {
+		exit = watchExit(process)
+	}
+	if grace <= 0 {
+		<-exit.done
+		return exit.state, false
+	}
+
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	select {
+	case <-exit.done:
+		return exit.state, false
+	case <-timer.C:
+		process.Kill()
+		<-exit.done
+		return exit.state, true
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package main
+
+import "syscall"
+
+// signalNames are the signals StopSignal and ForwardSignals may name, with
+// or without the SIG prefix. There are no user-defined signals here.
+var signalNames = map[string]syscall.Signal{
+	"SIGTERM": syscall.SIGTERM,
+	"SIGINT":  syscall.SIGINT,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGHUP":  syscall.SIGHUP,
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestParseStopSignal(t *testing.T) {
+	cases := map[string]syscall.Signal{
+		"":       syscall.SIGTERM,
+		"SIGINT": syscall.SIGINT,
+		"quit":   syscall.SIGQUIT,
+	}
+	for name, want := range cases {
+		if got, err := parseStopSignal(name); err != nil || got != want {
+			t.Errorf("parseStopSignal(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	for _, name := range []string{"SIGKILL", "SIGSTOP", "term!"} {
+		if _, err := parseStopSignal(name); err == nil {
+			t.Errorf("Expected an error for %q", name)
+		}
+	}
+}
+
+func TestStopGracefully(t *testing.T) {
+	// A process that handles the signal exits on its own
+	cmd := exec.Command("sh", "-c", "trap 'exit 3' INT; while :; do sleep 0.05; done")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start process: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	state, killed := stopGracefully(cmd.Process, nil, syscall.SIGINT, 5*time.Second)
+	if killed || state == nil || state.ExitCode() != 3 {
+		t.Errorf("Expected a graceful exit with code 3, got %v (killed: %v)", state, killed)
+	}
+
+	// One that ignores it is killed once the grace period is over
+	cmd = exec.Command("sh", "-c", "trap '' TERM; while :; do sleep 0.05; done")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start process: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	started := time.Now()
+	if _, killed := stopGracefully(cmd.Process, nil, syscall.SIGTERM, 200*time.Millisecond); !killed {
+		t.Error("Expected the process to be killed")
+	}
+	if elapsed := time.Since(started); elapsed > 3*time.Second {
+		t.Errorf("Expected the kill after the grace period, took %s", elapsed)
+	}
+}
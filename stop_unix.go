@@ -0,0 +1,16 @@
+//go:build unix
+
+package main
+
+import "syscall"
+
+// signalNames are the signals StopSignal and ForwardSignals may name, with
+// or without the SIG prefix
+var signalNames = map[string]syscall.Signal{
+	"SIGTERM": syscall.SIGTERM,
+	"SIGINT":  syscall.SIGINT,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGUSR1": syscall.SIGUSR1,
+	"SIGUSR2": syscall.SIGUSR2,
+}
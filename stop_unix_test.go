@@ -0,0 +1,20 @@
+//go:build unix
+
+package main
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestParseUserSignals(t *testing.T) {
+	if got, err := parseStopSignal("SigUsr2"); err != nil || got != syscall.SIGUSR2 {
+		t.Errorf("parseStopSignal(%q) = %v, %v; want %v", "SigUsr2", got, err, syscall.SIGUSR2)
+	}
+	if got, ok := parseSignal("usr1"); !ok || got != syscall.SIGUSR1 {
+		t.Errorf("parseSignal(%q) = %v, %v; want %v", "usr1", got, ok, syscall.SIGUSR1)
+	}
+	if signals, err := parseForwardSignals([]string{"usr2"}); err != nil || len(signals) != 1 || signals[0] != syscall.SIGUSR2 {
+		t.Errorf("Expected usr2 to be forwarded as SIGUSR2, got %v (%v)", signals, err)
+	}
+}
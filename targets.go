@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// projectTarget is a buildable main package found in the project
+type projectTarget struct {
+	// Name is what `wind run <name>` selects: the cmd/ subdirectory name,
+	// "cmd" for cmd/main.go or "root" for a main.go in the project root
+	Name        string
+	Path        string
+	Description string
+}
+
+// buildCmd returns the build command for the target
+func (t projectTarget) buildCmd() string {
+	return fmt.Sprintf("go build -o ./tmp/main %s", t.Path)
+}
+
+// detectTargets lists every main package detectProjectStructure considers,
+// in its order of preference
+func detectTargets() []projectTarget {
+	var targets []projectTarget
+
+	if _, err := os.Stat("cmd/main.go"); err == nil {
+		targets = append(targets, projectTarget{Name: "cmd", Path: "./cmd", Description: "Standard layout (cmd/)"})
+	}
+
+	if _, err := os.Stat("main.go"); err == nil {
+		targets = append(targets, projectTarget{Name: "root", Path: ".", Description: "Simple layout (root main.go)"})
+	}
+
+	if entries, err := os.ReadDir("cmd"); err == nil {
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join("cmd", entry.Name(), "main.go")); err == nil {
+				targets = append(targets, projectTarget{
+					Name:        entry.Name(),
+					Path:        "./cmd/" + entry.Name(),
+					Description: fmt.Sprintf("Standard layout (cmd/%s/)", entry.Name()),
+				})
+			}
+		}
+	}
+
+	// cmd/api is the conventional web entry point and is preferred
+	for i, t := range targets {
+		if t.Name == "api" && i > 0 {
+			targets = append([]projectTarget{t}, append(targets[:i:i], targets[i+1:]...)...)
+			break
+		}
+	}
+
+	// Modules of a go.work workspace come after the project's own targets
+	return append(targets, workspaceTargets()...)
+}
+
+// outsideTarget reports whether path belongs to a package the build target
+// doesn't depend on, such as another binary of the project or a package only
+// that binary imports (see depGraph.outsideTarget). Assets are always kept.
+func (app *WindApp) outsideTarget(path string) bool {
+	if app.targetGraph == nil || !app.config.Detect || app.matchesAssets(path) {
+		return false
+	}
+	dir, ok := buildPackageDir(app.config.BuildCmd)
+	if !ok {
+		return false
+	}
+	outside := app.targetGraph.outsideTarget(dir, path)
+	if !app.targetScoped && app.targetGraph.scopes(dir) {
+		app.targetScoped = true
+		logf(levelInfo, Cyan+"Info: "+Reset+"%sIgnoring changes to packages %s doesn't depend on\n", app.label(), dir)
+	}
+	return outside
+}
+
+// findTarget looks up a detected target by name
+func findTarget(name string) (projectTarget, error) {
+	targets := detectTargets()
+	for _, t := range targets {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+
+	if len(targets) == 0 {
+		return projectTarget{}, fmt.Errorf("unknown target %q: no main packages found", name)
+	}
+	names := make([]string, len(targets))
+	for i, t := range targets {
+		names[i] = t.Name
+	}
+	return projectTarget{}, fmt.Errorf("unknown target %q (available: %v)", name, names)
+}
+
+// showTargets implements `wind targets`
+func showTargets() {
+	targets := detectTargets()
+	if len(targets) == 0 {
+		logf(levelInfo, Yellow+"Info: "+Reset+"No main packages found\n")
+		return
+	}
+
+	fmt.Printf(Yellow + "Targets:" + Reset + "\n")
+	rows := newTable("  ")
+	for i, t := range targets {
+		marker := " "
+		if i == 0 {
+			marker = "*"
+		}
+		rows.addRow(marker, t.Name, t.Path, t.Description)
+	}
+	fmt.Print(rows.render(terminalWidth()))
+	fmt.Println()
+	fmt.Println("  * default target · select another with: wind run <target>")
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectTargets(t *testing.T) {
+	tmpDir := createTempProject(t, "root")
+	defer os.RemoveAll(tmpDir)
+
+	for _, name := range []string{"worker", "api", "migrator"} {
+		dir := filepath.Join(tmpDir, "cmd", name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+			t.Fatalf("Failed to write main.go: %v", err)
+		}
+	}
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+
+	targets := detectTargets()
+	var names []string
+	for _, target := range targets {
+		names = append(names, target.Name)
+	}
+
+	// cmd/api is preferred, matching detectProjectStructure
+	expected := []string{"api", "root", "migrator", "worker"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected targets %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("Expected targets %v, got %v", expected, names)
+		}
+	}
+
+	worker, err := findTarget("worker")
+	if err != nil {
+		t.Fatalf("findTarget(worker) failed: %v", err)
+	}
+	if cmd := worker.buildCmd(); cmd != "go build -o ./tmp/main ./cmd/worker" {
+		t.Errorf("Unexpected build command for worker: %q", cmd)
+	}
+
+	if _, err := findTarget("missing"); err == nil {
+		t.Error("Expected an error for an unknown target")
+	}
+}
+
+func TestOutsideTarget(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"go.mod":                        "module example.com/ot\n\ngo 1.21\n",
+		"main.go":                       "package main\n\nfunc main() {}\n",
+		"config.yaml":                   "port: 8080\n",
+		"cmd/api/main.go":               "package main\n\nimport (\n\t_ \"example.com/ot/cmd/worker/jobs\"\n\t_ \"example.com/ot/internal/store\"\n)\n\nfunc main() {}\n",
+		"cmd/worker/main.go":            "package main\n\nimport (\n\t_ \"example.com/ot/cmd/worker/store\"\n\t_ \"example.com/ot/internal/workeronly\"\n)\n\nfunc main() {}\n",
+		"cmd/worker/config.yaml":        "queue: jobs\n",
+		"cmd/worker/jobs/jobs.go":       "package jobs\n",
+		"cmd/worker/store/store.go":     "package store\n",
+		"internal/store/store.go":       "package store\n",
+		"internal/workeronly/w.go":      "package workeronly\n",
+		"internal/workeronly/data.json": "{}\n",
+		"internal/workeronly/seed.sql":  "SELECT 1;\n",
+		"templates/index.html":          "<html></html>\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+
+	graph := newDepGraph()
+	config := defaultConfig()
+	config.BuildCmd = "go build -o ./tmp/main ./cmd/api"
+	config.AssetsGlobs = []string{"**/*.sql"}
+	app := newWindApp(config, "", "")
+	app.targetGraph = graph
+	tests := map[string]bool{
+		"cmd/api/main.go":               false,
+		"main.go":                       true,
+		"cmd/worker/main.go":            true,
+		"cmd/worker/config.yaml":        true,
+		"cmd/worker/jobs/jobs.go":       false,
+		"cmd/worker/store/store.go":     true,
+		"internal/store/store.go":       false,
+		"internal/workeronly/w.go":      true,
+		"internal/workeronly/data.json": true,
+		"internal/workeronly/seed.sql":  false,
+		"internal/newpkg/new.go":        false,
+		"config.yaml":                   false,
+		"go.mod":                        false,
+		"templates/index.html":          false,
+	}
+	for path, expected := range tests {
+		if got := app.outsideTarget(path); got != expected {
+			t.Errorf("outsideTarget(%q) = %v, expected %v", path, got, expected)
+		}
+	}
+
+	// Building the root package, every package below cmd/ is left out
+	config.BuildCmd = "go build -o ./tmp/main ."
+	root := newWindApp(config, "", "")
+	root.targetGraph = graph
+	if !root.outsideTarget("cmd/api/main.go") || !root.outsideTarget("cmd/worker/jobs/jobs.go") {
+		t.Errorf("Expected the cmd/ packages to be left out for the root target")
+	}
+
+	// A new import brings the package into the target's closure
+	os.WriteFile("cmd/api/main.go", []byte("package main\n\nimport _ \"example.com/ot/internal/workeronly\"\n\nfunc main() {}\n"), 0644)
+	graph.update([]string{"cmd/api/main.go"})
+	if app.outsideTarget("internal/workeronly/w.go") {
+		t.Errorf("Expected a newly imported package to be watched")
+	}
+
+	config.Detect = false
+	plain := newWindApp(config, "", "")
+	plain.targetGraph = graph
+	if plain.outsideTarget("cmd/worker/main.go") {
+		t.Errorf("Expected no exclusions without detection")
+	}
+}
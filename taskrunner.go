@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Task runner targets that map to buildCmd and runCmd. dev is used for
+// runCmd when there is no run target.
+var (
+	taskRunnerBuildTargets = []string{"build"}
+	taskRunnerRunTargets   = []string{"run", "dev"}
+)
+
+var (
+	// makeTargetPattern matches a rule such as "build: deps" but not a
+	// variable assignment such as "FLAGS := -v"
+	makeTargetPattern = regexp.MustCompile(`^([A-Za-z0-9_.-]+)\s*:([^=]|$)`)
+	// mageTargetPattern matches an exported mage target
+	mageTargetPattern = regexp.MustCompile(`^func ([A-Z][A-Za-z0-9_]*)\(`)
+)
+
+// taskRunner is a Makefile, Taskfile or magefile with targets Wind can use
+type taskRunner struct {
+	// File is the file the targets were found in
+	File string
+	// Build and Run are the commands of the matching targets; empty when
+	// there is none
+	Build string
+	Run   string
+}
+
+// detectTaskRunner looks for a Makefile, Taskfile.yml or magefile.go with
+// build, run or dev targets, in that order. It returns nil when none has
+// them.
+func detectTaskRunner() *taskRunner {
+	candidates := []struct {
+		file    string
+		command string
+		targets func(data string) map[string]bool
+	}{
+		{"Makefile", "make", makeTargets},
+		{"makefile", "make", makeTargets},
+		{"GNUmakefile", "make", makeTargets},
+		{"Taskfile.yml", "task", taskfileTargets},
+		{"Taskfile.yaml", "task", taskfileTargets},
+		{"magefile.go", "mage", mageTargets},
+	}
+	for _, c := range candidates {
+		data, err := os.ReadFile(c.file)
+		if err != nil {
+			continue
+		}
+		targets := c.targets(string(data))
+		runner := &taskRunner{File: c.file}
+		for _, name := range taskRunnerBuildTargets {
+			if targets[name] && runner.Build == "" {
+				runner.Build = c.command + " " + name
+			}
+		}
+		for _, name := range taskRunnerRunTargets {
+			if targets[name] && runner.Run == "" {
+				runner.Run = c.command + " " + name
+			}
+		}
+		if runner.Build != "" || runner.Run != "" {
+			return runner
+		}
+	}
+	return nil
+}
+
+// makeTargets returns the rule names of a Makefile
+func makeTargets(data string) map[string]bool {
+	targets := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		if m := makeTargetPattern.FindStringSubmatch(scanner.Text()); m != nil {
+			targets[m[1]] = true
+		}
+	}
+	return targets
+}
+
+// taskfileTargets returns the task names of a Taskfile: the keys one level
+// below tasks:
+func taskfileTargets(data string) map[string]bool {
+	targets := map[string]bool{}
+	inTasks, indent := false, -1
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		depth := len(line) - len(strings.TrimLeftFunc(line, unicode.IsSpace))
+		if depth == 0 {
+			inTasks, indent = trimmed == "tasks:", -1
+			continue
+		}
+		if !inTasks {
+			continue
+		}
+		if indent < 0 {
+			indent = depth
+		}
+		if depth != indent {
+			continue
+		}
+		if name, _, ok := strings.Cut(trimmed, ":"); ok {
+			targets[strings.Trim(name, `"'`)] = true
+		}
+	}
+	return targets
+}
+
+// mageTargets returns the exported targets of a magefile, lowercased as
+// mage accepts them
+func mageTargets(data string) map[string]bool {
+	targets := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		if m := mageTargetPattern.FindStringSubmatch(scanner.Text()); m != nil {
+			targets[strings.ToLower(m[1])] = true
+		}
+	}
+	return targets
+}
+
+// suggestTaskRunner points out a task runner whose targets could replace
+// the detected build, since teams often keep their build flags there
+func suggestTaskRunner() {
+	runner := detectTaskRunner()
+	if runner == nil {
+		return
+	}
+	logf(levelInfo, Cyan+"Info: "+Reset+"%s has targets Wind can use; add them to %s:\n", runner.File, configFileName)
+	if runner.Build != "" {
+		fmt.Printf("  buildCmd: %s\n", runner.Build)
+	}
+	if runner.Run != "" {
+		fmt.Printf("  runCmd: %s\n", runner.Run)
+	}
+}
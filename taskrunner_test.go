@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestMakeTargets(t *testing.T) {
+	makefile := "FLAGS := -trimpath\nBIN = bin/app\n\n.PHONY: build run\n\nbuild: generate\n\tgo build $(FLAGS) -o $(BIN) ./cmd/api\n\nrun:\n\t./$(BIN)\n"
+	got := makeTargets(makefile)
+	expected := map[string]bool{".PHONY": true, "build": true, "run": true}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("makeTargets() = %v, expected %v", got, expected)
+	}
+}
+
+func TestTaskfileTargets(t *testing.T) {
+	taskfile := "version: '3'\n\nvars:\n  BIN: bin/app\n\ntasks:\n  build:\n    cmds:\n      - go build -o {{.BIN}} ./cmd/api\n  dev:\n    deps: [build]\n    cmds:\n      - ./{{.BIN}}\n"
+	got := taskfileTargets(taskfile)
+	expected := map[string]bool{"build": true, "dev": true}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("taskfileTargets() = %v, expected %v", got, expected)
+	}
+}
+
+func TestMageTargets(t *testing.T) {
+	magefile := "//go:build mage\n\npackage main\n\nfunc Build() error { return nil }\n\nfunc Dev(ctx context.Context) error { return nil }\n\nfunc helper() {}\n"
+	got := mageTargets(magefile)
+	expected := map[string]bool{"build": true, "dev": true}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("mageTargets() = %v, expected %v", got, expected)
+	}
+}
+
+func TestDetectTaskRunner(t *testing.T) {
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	os.Chdir(t.TempDir())
+
+	if runner := detectTaskRunner(); runner != nil {
+		t.Errorf("Expected no task runner, got %+v", runner)
+	}
+
+	// A Makefile without matching targets doesn't count
+	os.WriteFile("Makefile", []byte("lint:\n\tgolangci-lint run\n"), 0644)
+	os.WriteFile("Taskfile.yml", []byte("tasks:\n  build:\n    cmds: [go build ./...]\n  run:\n    cmds: [./app]\n  dev:\n    cmds: [air]\n"), 0644)
+	expected := &taskRunner{File: "Taskfile.yml", Build: "task build", Run: "task run"}
+	if runner := detectTaskRunner(); !reflect.DeepEqual(runner, expected) {
+		t.Errorf("detectTaskRunner() = %+v, expected %+v", runner, expected)
+	}
+
+	os.WriteFile("Makefile", []byte("dev:\n\tgo run ./cmd/api\n"), 0644)
+	expected = &taskRunner{File: "Makefile", Run: "make dev"}
+	if runner := detectTaskRunner(); !reflect.DeepEqual(runner, expected) {
+		t.Errorf("detectTaskRunner() = %+v, expected %+v", runner, expected)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// showCursor and resetAttributes undo escape sequences an interrupted
+// redraw may have left behind
+const (
+	showCursor      = "\033[?25h"
+	resetAttributes = "\033[0m"
+)
+
+// terminalGuard undoes changes to the terminal, such as raw input, however
+// Wind exits: on return, on a stop signal or on a panic in any goroutine
+// that defers guardTerminal
+type terminalGuard struct {
+	mutex    sync.Mutex
+	restores []func()
+	// out receives the cursor and attribute resets; nil when stdout is not a
+	// terminal
+	out *os.File
+}
+
+var terminal = &terminalGuard{}
+
+// add registers a function undoing one change to the terminal. Functions
+// run in reverse order of registration.
+func (g *terminalGuard) add(restore func()) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.out == nil && terminalOut != nil {
+		g.out = terminalOut
+	}
+	g.restores = append(g.restores, restore)
+}
+
+// restore undoes every registered change once; it is safe to call from
+// several goroutines and more than once
+func (g *terminalGuard) restore() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	for i := len(g.restores) - 1; i >= 0; i-- {
+		g.restores[i]()
+	}
+	if g.out != nil && len(g.restores) > 0 {
+		fmt.Fprint(g.out, resetAttributes+showCursor)
+	}
+	g.restores = nil
+}
+
+// guardTerminal is deferred at the top of main and of long-running
+// goroutines. A panic kills the process without running the deferred calls
+// of other goroutines, so the terminal is restored before the panic
+// continues.
+func guardTerminal() {
+	if r := recover(); r != nil {
+		terminal.restore()
+		panic(r)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTerminalGuardRestore(t *testing.T) {
+	g := &terminalGuard{}
+	var order []string
+	g.add(func() { order = append(order, "first") })
+	g.add(func() { order = append(order, "second") })
+
+	g.restore()
+	g.restore()
+	if expected := []string{"second", "first"}; !reflect.DeepEqual(order, expected) {
+		t.Errorf("Expected restores once in reverse order %v, got %v", expected, order)
+	}
+}
+
+func TestGuardTerminalPanic(t *testing.T) {
+	restored := false
+	terminal.add(func() { restored = true })
+	defer terminal.restore()
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("Expected the panic to continue, recovered %v", r)
+		}
+		if !restored {
+			t.Error("Expected the terminal to be restored on panic")
+		}
+	}()
+	func() {
+		defer guardTerminal()
+		panic("boom")
+	}()
+}
+
+// crashMarkerEnv makes TestGuardTerminalCrash's child crash, recording the
+// restore in the named file
+const crashMarkerEnv = "WIND_TEST_CRASH_MARKER"
+
+func TestGuardTerminalCrash(t *testing.T) {
+	if marker := os.Getenv(crashMarkerEnv); marker != "" {
+		terminal.add(func() { os.WriteFile(marker, []byte("restored"), 0644) })
+		done := make(chan struct{})
+		go func() {
+			defer guardTerminal()
+			panic("crash in a watcher goroutine")
+		}()
+		<-done
+	}
+
+	marker := filepath.Join(t.TempDir(), "marker")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestGuardTerminalCrash$")
+	cmd.Env = append(os.Environ(), crashMarkerEnv+"="+marker)
+	if err := cmd.Run(); err == nil {
+		t.Fatal("Expected the child process to crash")
+	}
+	if data, err := os.ReadFile(marker); err != nil || string(data) != "restored" {
+		t.Errorf("Expected the terminal to be restored before the crash, got %q (%v)", data, err)
+	}
+}
@@ -231,6 +231,20 @@ fi
 
 echo
 
+# Unix-only system calls must stay behind build tags
+print_section "Checking Other Platforms"
+
+for goos in windows darwin; do
+    if GOOS=$goos go vet ./...; then
+        print_success "go vet passed for $goos"
+    else
+        print_error "go vet failed for $goos"
+        exit 1
+    fi
+done
+
+echo
+
 # Test summary
 print_section "Test Summary"
 
@@ -251,6 +265,7 @@ if [ "$RUN_COVERAGE" = true ]; then
 fi
 
 print_success "Build test: PASSED"
+print_success "Cross-platform vet: PASSED"
 
 echo
 print_success "All tests completed successfully!"
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// affectedPackages returns the directories (./dir) of the packages that
+// contain a changed file or depend on a package that does. Files outside any
+// package, such as testdata, count for the nearest enclosing package.
+func affectedPackages(pkgs []goPackage, changed []string) []string {
+	byDir := map[string]goPackage{}
+	for _, pkg := range pkgs {
+		byDir[pkg.Dir] = pkg
+	}
+
+	changedPaths := map[string]bool{}
+	for _, path := range changed {
+		for dir := filepath.Dir(projectPath(path)); ; dir = filepath.Dir(dir) {
+			if pkg, ok := byDir[projectPath(dir)]; ok {
+				changedPaths[pkg.ImportPath] = true
+				break
+			}
+			if dir == "." || dir == string(filepath.Separator) {
+				break
+			}
+		}
+	}
+
+	var dirs []string
+	for _, pkg := range pkgs {
+		affected := changedPaths[pkg.ImportPath]
+		for _, imp := range append(pkg.Deps[:len(pkg.Deps):len(pkg.Deps)], pkg.TestImports...) {
+			if affected {
+				break
+			}
+			affected = changedPaths[imp]
+		}
+		if affected && pkg.Dir == "." {
+			dirs = append(dirs, ".")
+		} else if affected {
+			dirs = append(dirs, "./"+pkg.Dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// testEvent is a line of `go test -json` output
+type testEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// testResult is the latest outcome of a package's tests
+type testResult struct {
+	passed  bool
+	cached  bool
+	elapsed time.Duration
+}
+
+// testRunner implements `wind test`: instead of build+run, every change runs
+// go test for the affected packages
+type testRunner struct {
+	// args are passed through to go test (-run, -race, -count, ...);
+	// binaryArgs follow -args and reach the test binaries
+	args       []string
+	binaryArgs []string
+	// results caches the latest result of every package tested this session
+	results map[string]testResult
+}
+
+func newTestRunner(args, binaryArgs []string) *testRunner {
+	return &testRunner{args: args, binaryArgs: binaryArgs, results: make(map[string]testResult)}
+}
+
+// command returns the go test arguments for targets
+func (t *testRunner) command(targets []string) []string {
+	args := []string{"test", "-json"}
+	args = append(args, t.args...)
+	args = append(args, targets...)
+	if len(t.binaryArgs) > 0 {
+		args = append(append(args, "-args"), t.binaryArgs...)
+	}
+	return args
+}
+
+// runTests tests the packages affected by the current cycle's changes, or
+// every package when there are none (first run, manual rebuild)
+func (app *WindApp) runTests() {
+	targets := []string{"./..."}
+	if len(app.changedFiles) > 0 {
+		pkgs, err := loadPackages()
+		if err != nil {
+			logf(levelError, Red+"Error: "+Reset+"Failed to list packages: %v\n", err)
+			return
+		}
+		targets = affectedPackages(pkgs, app.changedFiles)
+		if len(targets) == 0 {
+			logf(levelInfo, Cyan+"Info: "+Reset+"No packages affected by this change\n")
+			return
+		}
+	}
+
+	logf(levelInfo, Cyan+"🧪 Testing %s..."+Reset+"\n", strings.Join(targets, " "))
+	app.tests.run(targets)
+}
+
+// packageDirOf returns the package directory (./dir) of path: its own
+// directory, or the nearest enclosing one with Go files for files such as
+// testdata
+func packageDirOf(path string) string {
+	dir := filepath.Dir(projectPath(path))
+	for {
+		if files, _ := filepath.Glob(filepath.Join(dir, "*.go")); len(files) > 0 || dir == "." {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	if dir == "." || filepath.IsAbs(dir) || strings.HasPrefix(dir, "..") {
+		return filepath.ToSlash(dir)
+	}
+	return "./" + filepath.ToSlash(dir)
+}
+
+// testLastChange runs go test for the package of the most recently changed
+// file in the background, next to the running application. Key presses
+// during a run are ignored.
+func (o *orchestrator) testLastChange() {
+	var changed string
+	for _, app := range o.apps {
+		if changed = app.currentStatus().LastChange; changed != "" {
+			break
+		}
+	}
+	if changed == "" {
+		logf(levelInfo, Cyan+"Info: "+Reset+"No file changed yet; nothing to test\n")
+		return
+	}
+	if !o.testing.CompareAndSwap(false, true) {
+		logf(levelInfo, Cyan+"Info: "+Reset+"Tests are already running\n")
+		return
+	}
+
+	dir := packageDirOf(changed)
+	go func() {
+		defer o.testing.Store(false)
+		logf(levelInfo, Cyan+"🧪 Testing %s (changed %s)..."+Reset+"\n", dir, changed)
+		newTestRunner(nil, nil).run([]string{dir})
+	}()
+}
+
+// run executes go test -json for targets and prints a compact summary. The
+// output of a package is only shown when it fails. It reports whether every
+// package passed.
+func (t *testRunner) run(targets []string) bool {
+	cmd := exec.Command("go", t.command(targets)...)
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Failed to run go test: %v\n", err)
+		return false
+	}
+	if err := cmd.Start(); err != nil {
+		logf(levelError, Red+"Error: "+Reset+"Failed to run go test: %v\n", err)
+		return false
+	}
+
+	output := map[string][]string{}
+	var tested []string
+	passed := true
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var ev testEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			fmt.Println(scanner.Text())
+			continue
+		}
+
+		switch {
+		case ev.Action == "build-output":
+			fmt.Print(ev.Output)
+		case ev.Action == "output":
+			output[ev.Package] = append(output[ev.Package], ev.Output)
+		case ev.Test != "":
+		case ev.Action == "pass" || ev.Action == "fail":
+			result := testResult{
+				passed:  ev.Action == "pass",
+				elapsed: time.Duration(ev.Elapsed * float64(time.Second)),
+			}
+			for _, line := range output[ev.Package] {
+				if strings.Contains(line, "(cached)") {
+					result.cached = true
+				}
+			}
+			t.results[ev.Package] = result
+			passed = passed && result.passed
+			tested = append(tested, ev.Package)
+			t.printResult(ev.Package, result, output[ev.Package])
+		}
+	}
+	// Packages that fail to build report no result on older Go versions
+	if err := cmd.Wait(); err != nil {
+		passed = false
+	}
+
+	t.printSummary(tested)
+	return passed
+}
+
+func (t *testRunner) printResult(pkg string, result testResult, output []string) {
+	if result.passed {
+		suffix := result.elapsed.Round(time.Millisecond).String()
+		if result.cached {
+			suffix = "cached"
+		}
+		fmt.Printf(Green+"  ✓ "+Reset+"%s (%s)\n", pkg, suffix)
+		return
+	}
+
+	fmt.Printf(Red+"  ✗ "+Reset+"%s\n", pkg)
+	for _, line := range output {
+		if strings.HasPrefix(line, "=== ") || strings.HasPrefix(line, "PASS") {
+			continue
+		}
+		fmt.Print("    " + line)
+	}
+}
+
+// printSummary counts this run's results and reminds of packages that
+// failed in earlier runs and were not retested
+func (t *testRunner) printSummary(tested []string) {
+	passed, failed := 0, 0
+	current := map[string]bool{}
+	for _, pkg := range tested {
+		current[pkg] = true
+		if t.results[pkg].passed {
+			passed++
+		} else {
+			failed++
+		}
+	}
+
+	var stale []string
+	for pkg, result := range t.results {
+		if !current[pkg] && !result.passed {
+			stale = append(stale, pkg)
+		}
+	}
+	sort.Strings(stale)
+
+	color := Green
+	if failed > 0 {
+		color = Red
+	}
+	fmt.Printf(color+"Tests: "+Reset+"%d passed, %d failed\n", passed, failed)
+	if len(stale) > 0 {
+		fmt.Println(fitLine(Yellow+"Still failing: "+Reset+strings.Join(stale, ", "), ", ", "  ", terminalWidth()))
+	}
+}
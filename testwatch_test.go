@@ -0,0 +1,111 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAffectedPackages(t *testing.T) {
+	pkgs := []goPackage{
+		{ImportPath: "example.com/app", Dir: ".", Deps: []string{"example.com/app/internal/store", "fmt"}},
+		{ImportPath: "example.com/app/internal/store", Dir: "internal/store", Deps: []string{"database/sql"}},
+		{ImportPath: "example.com/app/internal/api", Dir: "internal/api", Deps: []string{"net/http"}},
+		{ImportPath: "example.com/app/internal/apitest", Dir: "internal/apitest", TestImports: []string{"example.com/app/internal/api"}},
+	}
+
+	tests := []struct {
+		changed  []string
+		expected []string
+	}{
+		{[]string{"internal/store/store.go"}, []string{".", "./internal/store"}},
+		{[]string{"internal/api/testdata/golden.json"}, []string{"./internal/api", "./internal/apitest"}},
+		{[]string{"main.go"}, []string{"."}},
+		{[]string{"docs/readme.md"}, []string{"."}},
+	}
+
+	for _, tt := range tests {
+		if got := affectedPackages(pkgs, tt.changed); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("affectedPackages(%v) = %v, expected %v", tt.changed, got, tt.expected)
+		}
+	}
+}
+
+func TestTestRunnerCommand(t *testing.T) {
+	runner := newTestRunner([]string{"-run", "TestStore", "-race"}, []string{"-update"})
+	expected := []string{"test", "-json", "-run", "TestStore", "-race", "./internal/store", "-args", "-update"}
+	if got := runner.command([]string{"./internal/store"}); !reflect.DeepEqual(got, expected) {
+		t.Errorf("command() = %v, expected %v", got, expected)
+	}
+}
+
+func TestTestRunnerResults(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"go.mod":            "module example.com/tw\n\ngo 1.21\n",
+		"good/good.go":      "package good\n\nfunc One() int { return 1 }\n",
+		"good/good_test.go": "package good\n\nimport \"testing\"\n\nfunc TestOne(t *testing.T) {\n\tif One() != 1 {\n\t\tt.Fatal(\"bad\")\n\t}\n}\n",
+		"bad/bad.go":        "package bad\n",
+		"bad/bad_test.go":   "package bad\n\nimport \"testing\"\n\nfunc TestFail(t *testing.T) { t.Fatal(\"broken\") }\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	runner := newTestRunner(nil, nil)
+	runner.run([]string{"./..."})
+
+	if result, ok := runner.results["example.com/tw/good"]; !ok || !result.passed {
+		t.Errorf("Expected good package to pass, got %+v (found %v)", result, ok)
+	}
+	if result, ok := runner.results["example.com/tw/bad"]; !ok || result.passed {
+		t.Errorf("Expected bad package to fail, got %+v (found %v)", result, ok)
+	}
+}
+
+func TestPackageDirOf(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"main.go", "api/api.go", "api/testdata/golden/out.json", "web/static/app.js"} {
+		path := filepath.Join(tmpDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, nil, 0644)
+	}
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	cases := map[string]string{
+		"main.go":                      ".",
+		"api/api.go":                   "./api",
+		"api/testdata/golden/out.json": "./api",
+		"web/static/app.js":            ".",
+		filepath.Join(tmpDir, "api/x"): "./api",
+	}
+	for path, want := range cases {
+		if got := packageDirOf(path); got != want {
+			t.Errorf("packageDirOf(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestTestLastChangeWithoutChanges(t *testing.T) {
+	orch := newOrchestrator([]*WindApp{newWindApp(WindConfig{}, "", "")})
+	orch.handleKey(keyRunTests)
+	if orch.testing.Load() {
+		t.Error("Expected no test run before any file changed")
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// Output mode flags. -q sets Quiet, -v is short for --verbose and -vv sets
+// Trace as well.
+const (
+	quietFlag        = "-q"
+	quietLongFlag    = "--quiet"
+	shortVerboseFlag = "-v"
+	traceFlag        = "-vv"
+	noColorFlag      = "--no-color"
+)
+
+// noColorEnv turns colors off like --no-color (https://no-color.org)
+const noColorEnv = "NO_COLOR"
+
+// useColors reports whether the output may contain escape sequences: not
+// with --no-color or NO_COLOR, and only on a terminal, so CI logs and
+// redirected output stay readable
+func useColors(noColor bool) bool {
+	if noColor || os.Getenv(noColorEnv) != "" {
+		return false
+	}
+	return terminalOut != nil
+}
+
+// colorStripper removes escape sequences from a stream. A sequence split
+// across writes is held back until it is complete.
+type colorStripper struct {
+	mutex   sync.Mutex
+	w       io.Writer
+	pending []byte
+}
+
+func (c *colorStripper) Write(data []byte) (int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	buf := append(c.pending, data...)
+	c.pending = nil
+	// Hold back an escape sequence that is still missing its final letter
+	if i := bytes.LastIndexByte(buf, '\x1b'); i >= 0 && !escapePattern.Match(buf[i:]) && len(buf)-i < 32 {
+		c.pending = append([]byte(nil), buf[i:]...)
+		buf = buf[:i]
+	}
+	if _, err := c.w.Write(escapePattern.ReplaceAll(buf, nil)); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// stripColors removes escape sequences from everything written to
+// os.Stdout and os.Stderr, including the output of child processes
+func stripColors() (*outputRedirect, error) {
+	return redirectOutput(func(dst *os.File) io.Writer {
+		return &colorStripper{w: dst}
+	})
+}
+
+// msgLevel is the level of one of Wind's own messages
+type msgLevel int
+
+const (
+	levelInfo msgLevel = iota
+	levelWarn
+	// levelSuccess announces restarts and reloads
+	levelSuccess
+	levelError
+)
+
+// quiet hides info and warning messages for the rest of the session (Quiet).
+// Errors, successes and the application's own output still show.
+var quiet atomic.Bool
+
+// logf prints one of Wind's messages at level, unless quiet hides it
+func logf(level msgLevel, format string, args ...any) {
+	if quiet.Load() && level < levelSuccess {
+		return
+	}
+	fmt.Printf(format, args...)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestColorStripper(t *testing.T) {
+	var buf bytes.Buffer
+	w := &colorStripper{w: &buf}
+
+	// A sequence split across writes is removed once complete
+	w.Write([]byte(Red + "Error: " + Reset + "build failed\n\033[3"))
+	w.Write([]byte("3mWarning: \033[0mslow\n\r\033[K42%"))
+	if expected := "Error: build failed\nWarning: slow\n\r42%"; buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestUseColors(t *testing.T) {
+	t.Setenv(noColorEnv, "1")
+	if useColors(false) {
+		t.Errorf("Expected NO_COLOR to turn colors off")
+	}
+	t.Setenv(noColorEnv, "")
+	if useColors(true) {
+		t.Errorf("Expected --no-color to turn colors off")
+	}
+}
+
+func TestLogfQuiet(t *testing.T) {
+	defer quiet.Store(false)
+	tests := []struct {
+		level msgLevel
+		shown bool
+	}{
+		{levelInfo, false},
+		{levelWarn, false},
+		{levelSuccess, true},
+		{levelError, true},
+	}
+	for _, tt := range tests {
+		quiet.Store(true)
+		output := captureStdout(t, func() { logf(tt.level, "message %d\n", tt.level) })
+		if shown := output != ""; shown != tt.shown {
+			t.Errorf("Level %d: expected shown %v, got %q", tt.level, tt.shown, output)
+		}
+		quiet.Store(false)
+		if output := captureStdout(t, func() { logf(tt.level, "message\n") }); output != "message\n" {
+			t.Errorf("Level %d: expected the message without -q, got %q", tt.level, output)
+		}
+	}
+}
+
+func TestQuietScanWarnings(t *testing.T) {
+	defer quiet.Store(false)
+	app := &WindApp{config: defaultConfig()}
+	app.config.MaxDepth = 1
+	stats := scanStats{tooDeep: []string{"a/b/c"}}
+
+	// The continuation lines share the level of the warning
+	quiet.Store(true)
+	if output := captureStdout(t, func() { app.checkScanLimits(nil, stats) }); output != "" {
+		t.Errorf("Expected -q to hide the whole warning, got %q", output)
+	}
+	quiet.Store(false)
+	app.tooDeepWarned = false
+	if output := captureStdout(t, func() { app.checkScanLimits(nil, stats) }); !strings.Contains(output, "  a/b/c\n") {
+		t.Errorf("Expected the warning without -q, got %q", output)
+	}
+}
+
+func TestExtractBoolFlagAliases(t *testing.T) {
+	rest, found := extractBoolFlag([]string{"-q", "run", "api", "--quiet"}, quietFlag, quietLongFlag)
+	if !found || !reflect.DeepEqual(rest, []string{"run", "api"}) {
+		t.Errorf("Expected run api and true, got %v and %v", rest, found)
+	}
+	if _, found := extractBoolFlag([]string{"-vv"}, verboseFlag, shortVerboseFlag); found {
+		t.Errorf("Expected -vv not to count as -v")
+	}
+}
+
+// captureStdout returns what fn prints to os.Stdout
+func captureStdout(t *testing.T, fn func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	fn()
+	os.Stdout = stdout
+	w.Close()
+	data, _ := io.ReadAll(r)
+	r.Close()
+	return string(data)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// vulnerabilityPattern matches a finding header of govulncheck's text output
+var vulnerabilityPattern = regexp.MustCompile(`^Vulnerability #\d+: (\S+)`)
+
+// vulnFinding is a vulnerability reachable from the project's code
+type vulnFinding struct {
+	ID      string
+	Summary string
+	FixedIn string
+}
+
+// parseVulncheck returns the findings of govulncheck's text output. Newer
+// versions also list vulnerabilities in imported packages and required
+// modules that the code does not call; only the symbol results count.
+func parseVulncheck(output string) []vulnFinding {
+	sections := strings.Contains(output, "=== Symbol Results ===")
+	inSymbols := !sections
+
+	var findings []vulnFinding
+	var current *vulnFinding
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "=== ") {
+			inSymbols = line == "=== Symbol Results ==="
+			current = nil
+			continue
+		}
+		if !inSymbols {
+			continue
+		}
+		if m := vulnerabilityPattern.FindStringSubmatch(line); m != nil {
+			findings = append(findings, vulnFinding{ID: m[1]})
+			current = &findings[len(findings)-1]
+			continue
+		}
+		switch {
+		case current == nil || line == "":
+		case current.Summary == "":
+			current.Summary = line
+		case strings.HasPrefix(line, "Fixed in: ") && current.FixedIn == "":
+			current.FixedIn = strings.TrimPrefix(line, "Fixed in: ")
+		}
+	}
+	return findings
+}
+
+// isModuleFile reports whether path is a go.mod or go.sum
+func isModuleFile(path string) bool {
+	base := filepath.Base(path)
+	return base == "go.mod" || base == "go.sum"
+}
+
+// vulnChecker runs govulncheck in the background when the dependencies of
+// the project change. It is shared by every target.
+type vulnChecker struct {
+	running atomic.Bool
+	mutex   sync.Mutex
+	// findings are the results of the latest completed check
+	findings []vulnFinding
+	// missing is set once govulncheck was not found, so the hint is shown
+	// once
+	missing bool
+}
+
+// ids returns the IDs of the current findings, for the status
+func (v *vulnChecker) ids() []string {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	var ids []string
+	for _, f := range v.findings {
+		ids = append(ids, f.ID)
+	}
+	return ids
+}
+
+// checkChanges starts a check when go.mod or go.sum changed in this cycle,
+// unless one is already running
+func (v *vulnChecker) checkChanges(changed []string) {
+	for _, path := range changed {
+		if isModuleFile(path) {
+			if v.running.CompareAndSwap(false, true) {
+				go func() {
+					defer v.running.Store(false)
+					v.check()
+				}()
+			}
+			return
+		}
+	}
+}
+
+// check runs govulncheck ./... and reports its findings. govulncheck exits
+// with status 3 when it found vulnerabilities.
+func (v *vulnChecker) check() {
+	logf(levelInfo, Cyan+"Info: "+Reset+"Dependencies changed, running govulncheck in the background...\n")
+	var output bytes.Buffer
+	cmd := exec.Command("govulncheck", "./...")
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(err, exec.ErrNotFound):
+		v.mutex.Lock()
+		defer v.mutex.Unlock()
+		if !v.missing {
+			v.missing = true
+			logf(levelWarn, Yellow+"Warning: "+Reset+"govulncheck not found; install it with go install golang.org/x/vuln/cmd/govulncheck@latest\n")
+		}
+		return
+	case err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 3):
+		logf(levelWarn, Yellow+"Warning: "+Reset+"govulncheck failed: %v\n", err)
+		fmt.Print(output.String())
+		return
+	}
+
+	findings := parseVulncheck(output.String())
+	v.mutex.Lock()
+	v.findings = findings
+	v.mutex.Unlock()
+
+	if len(findings) == 0 {
+		logf(levelSuccess, Green+"Success: "+Reset+"govulncheck found no vulnerabilities reachable from your code\n")
+		return
+	}
+	count := "1 vulnerability"
+	if len(findings) > 1 {
+		count = fmt.Sprintf("%d vulnerabilities", len(findings))
+	}
+	fmt.Printf(Red+"Vulnerabilities: "+Reset+"govulncheck found %s reachable from your code\n", count)
+	for _, f := range findings {
+		line := "  " + Yellow + f.ID + Reset + "  " + f.Summary
+		if f.FixedIn != "" {
+			line += " (fixed in " + f.FixedIn + ")"
+		}
+		fmt.Println(line)
+	}
+}
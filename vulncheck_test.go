@@ -0,0 +1,69 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVulncheck(t *testing.T) {
+	withSections := `=== Symbol Results ===
+
+Vulnerability #1: GO-2024-2687
+    HTTP/2 CONTINUATION flood in net/http
+  More info: https://pkg.go.dev/vuln/GO-2024-2687
+  Standard library
+    Found in: net/http@go1.21.0
+    Fixed in: net/http@go1.21.9
+    Example traces found:
+      #1: main.go:12:25: main.main calls http.ListenAndServe
+
+Your code is affected by 1 vulnerability from the Go standard library.
+
+=== Package Results ===
+
+Vulnerability #1: GO-2023-1988
+    Improper rendering of text nodes in golang.org/x/net/html
+  Module: golang.org/x/net
+    Found in: golang.org/x/net@v0.10.0
+    Fixed in: golang.org/x/net@v0.13.0
+`
+	expected := []vulnFinding{{ID: "GO-2024-2687", Summary: "HTTP/2 CONTINUATION flood in net/http", FixedIn: "net/http@go1.21.9"}}
+	if got := parseVulncheck(withSections); !reflect.DeepEqual(got, expected) {
+		t.Errorf("parseVulncheck() = %+v, expected %+v", got, expected)
+	}
+
+	withoutSections := `Vulnerability #1: GO-2023-1571
+    A maliciously crafted HTTP/2 stream could cause excessive CPU
+  More info: https://pkg.go.dev/vuln/GO-2023-1571
+  Module: golang.org/x/net
+    Found in: golang.org/x/net@v0.5.0
+    Fixed in: golang.org/x/net@v0.7.0
+
+Vulnerability #2: GO-2022-0969
+    HTTP/2 server connections can hang forever
+`
+	expected = []vulnFinding{
+		{ID: "GO-2023-1571", Summary: "A maliciously crafted HTTP/2 stream could cause excessive CPU", FixedIn: "golang.org/x/net@v0.7.0"},
+		{ID: "GO-2022-0969", Summary: "HTTP/2 server connections can hang forever"},
+	}
+	if got := parseVulncheck(withoutSections); !reflect.DeepEqual(got, expected) {
+		t.Errorf("parseVulncheck() = %+v, expected %+v", got, expected)
+	}
+
+	if got := parseVulncheck("No vulnerabilities found.\n"); len(got) != 0 {
+		t.Errorf("Expected no findings, got %+v", got)
+	}
+}
+
+func TestIsModuleFile(t *testing.T) {
+	for path, expected := range map[string]bool{
+		"go.mod":          true,
+		"tools/go.sum":    true,
+		"go.work":         false,
+		"internal/mod.go": false,
+	} {
+		if got := isModuleFile(path); got != expected {
+			t.Errorf("isModuleFile(%q) = %v, expected %v", path, got, expected)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// goDirectiveArgs returns the arguments of every use of directive in a
+// go.mod or go.work file, covering both the single-line and block forms
+func goDirectiveArgs(data, directive string) [][]string {
+	var args [][]string
+	inBlock := false
+	for _, line := range strings.Split(data, "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case inBlock && fields[0] == ")":
+			inBlock = false
+		case inBlock:
+			args = append(args, unquoteFields(fields))
+		case fields[0] == directive && len(fields) == 2 && fields[1] == "(":
+			inBlock = true
+		case fields[0] == directive && len(fields) > 1:
+			args = append(args, unquoteFields(fields[1:]))
+		}
+	}
+	return args
+}
+
+func unquoteFields(fields []string) []string {
+	for i, f := range fields {
+		fields[i] = strings.Trim(f, "\"`")
+	}
+	return fields
+}
+
+// isLocalPath reports whether a replace target is a directory rather than a
+// module path
+func isLocalPath(path string) bool {
+	return path == "." || path == ".." || strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") || filepath.IsAbs(path)
+}
+
+// workspaceModules returns the directories of the modules used by go.work in
+// the current directory, or nil without a go.work
+func workspaceModules() []string {
+	data, err := os.ReadFile("go.work")
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, args := range goDirectiveArgs(string(data), "use") {
+		dirs = append(dirs, filepath.Clean(args[0]))
+	}
+	return dirs
+}
+
+// localReplaces returns the directories that the go.mod in moduleDir
+// replaces modules with
+func localReplaces(moduleDir string) []string {
+	data, err := os.ReadFile(filepath.Join(moduleDir, "go.mod"))
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, args := range goDirectiveArgs(string(data), "replace") {
+		// old [version] => new [version]
+		for i, arg := range args {
+			if arg == "=>" && i+1 < len(args) && isLocalPath(args[i+1]) {
+				target := args[i+1]
+				if !filepath.IsAbs(target) {
+					target = filepath.Join(moduleDir, target)
+				}
+				dirs = append(dirs, filepath.Clean(target))
+			}
+		}
+	}
+	return dirs
+}
+
+// watchRoots returns the directories to scan: the project itself plus every
+// workspace module and locally replaced module outside of it
+func watchRoots() []string {
+	modules := append([]string{"."}, workspaceModules()...)
+	for _, dir := range modules {
+		modules = append(modules, localReplaces(dir)...)
+	}
+
+	roots := []string{"."}
+	seen := map[string]bool{".": true}
+	for _, dir := range modules {
+		// Directories inside the project are covered by scanning "."
+		if !seen[dir] && (dir == ".." || strings.HasPrefix(dir, ".."+string(filepath.Separator)) || filepath.IsAbs(dir)) {
+			roots = append(roots, dir)
+		}
+		seen[dir] = true
+	}
+	return roots
+}
+
+// watchDirs returns the WatchDirs entries that roots do not cover yet,
+// warning about entries that are not directories. "~/" is the home
+// directory.
+func (app *WindApp) watchDirs(roots []string) []string {
+	var dirs []string
+	for _, dir := range app.config.WatchDirs {
+		if rest, ok := strings.CutPrefix(dir, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				dir = filepath.Join(home, rest)
+			}
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			logf(levelWarn, Yellow+"Warning: "+Reset+"%swatchDirs entry %s is not a directory, skipping\n", app.label(), dir)
+			continue
+		}
+
+		path := projectPath(dir)
+		covered := false
+		for _, root := range append(roots, dirs...) {
+			root = projectPath(root)
+			// underDir treats "." as containing everything
+			if root == "." {
+				covered = path != ".." && !strings.HasPrefix(path, "../") && !filepath.IsAbs(path)
+			} else {
+				covered = underDir(path, root)
+			}
+			if covered {
+				break
+			}
+		}
+		if !covered {
+			dirs = append(dirs, filepath.Clean(dir))
+		}
+	}
+	return dirs
+}
+
+// walkWatched scans every watch root, skipping excluded directories, and
+// passes the normalized paths of the directories and watched files to fn in
+// lexical order. Directories are read in parallel and their listings cached
+// between scans (see scanCache).
+func (app *WindApp) walkWatched(fn filepath.WalkFunc) error {
+	if app.roots == nil {
+		app.roots = watchRoots()
+		for _, root := range app.roots[1:] {
+			logf(levelInfo, Cyan+"Info: "+Reset+"%sAlso watching module %s\n", app.label(), root)
+		}
+		for _, dir := range app.watchDirs(app.roots) {
+			logf(levelInfo, Cyan+"Info: "+Reset+"%sAlso watching %s\n", app.label(), dir)
+			app.roots = append(app.roots, dir)
+		}
+	}
+	if app.scanCache == nil {
+		app.scanCache = newScanCache(app.config.ExcludeDirs)
+		app.scanCache.followSymlinks = app.config.FollowSymlinks
+		app.scanCache.maxDepth = app.config.MaxDepth
+		app.scanCache.artifacts = app.artifacts
+	}
+	app.reloadIgnore()
+
+	paths, stats, err := app.scanCache.walk(app.roots, app.shouldWatch)
+	if app.config.Verbose {
+		logf(levelInfo, Cyan+"Scan: "+Reset+"%s%s\n", app.label(), stats)
+	}
+	if err != nil {
+		return err
+	}
+	app.checkScanLimits(paths, stats)
+	for _, p := range paths {
+		if err := fn(p.path, p.info, nil); err != nil && err != filepath.SkipDir {
+			return err
+		}
+	}
+	return nil
+}
+
+// workspaceTargets lists the main packages of the go.work modules inside the
+// project, for workspace roots that have no main package of their own
+func workspaceTargets() []projectTarget {
+	var targets []projectTarget
+	for _, dir := range workspaceModules() {
+		if dir == "." || strings.HasPrefix(dir, "..") || filepath.IsAbs(dir) {
+			continue
+		}
+		slashed := filepath.ToSlash(dir)
+
+		if _, err := os.Stat(filepath.Join(dir, "main.go")); err == nil {
+			targets = append(targets, projectTarget{
+				Name:        filepath.Base(dir),
+				Path:        "./" + slashed,
+				Description: fmt.Sprintf("Workspace module (%s/)", slashed),
+			})
+		}
+		entries, _ := os.ReadDir(filepath.Join(dir, "cmd"))
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(dir, "cmd", entry.Name(), "main.go")); err == nil {
+				targets = append(targets, projectTarget{
+					Name:        entry.Name(),
+					Path:        "./" + slashed + "/cmd/" + entry.Name(),
+					Description: fmt.Sprintf("Workspace module (%s/cmd/%s/)", slashed, entry.Name()),
+				})
+			}
+		}
+	}
+	return targets
+}
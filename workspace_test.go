@@ -0,0 +1,111 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGoDirectiveArgs(t *testing.T) {
+	gowork := `go 1.23
+
+use (
+	./api   // HTTP service
+	./shared
+	"../lib"
+)
+use ./worker
+`
+	var dirs []string
+	for _, args := range goDirectiveArgs(gowork, "use") {
+		dirs = append(dirs, args[0])
+	}
+	expected := []string{"./api", "./shared", "../lib", "./worker"}
+	if !reflect.DeepEqual(dirs, expected) {
+		t.Errorf("use directives = %v, expected %v", dirs, expected)
+	}
+}
+
+func TestWatchRootsAndWorkspaceTargets(t *testing.T) {
+	base := t.TempDir()
+	project := filepath.Join(base, "project")
+	files := map[string]string{
+		"project/go.work":                "go 1.23\n\nuse (\n\t./api\n\t../shared\n)\n",
+		"project/api/go.mod":             "module example.com/api\n",
+		"project/api/cmd/server/main.go": "package main\n",
+		"project/go.mod":                 "module example.com/project\n\nreplace example.com/lib => ../lib\nreplace example.com/x v1.0.0 => example.com/y v1.0.0\n",
+		"shared/go.mod":                  "module example.com/shared\n",
+		"lib/go.mod":                     "module example.com/lib\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(base, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(project); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	expected := []string{".", filepath.Join("..", "shared"), filepath.Join("..", "lib")}
+	if roots := watchRoots(); !reflect.DeepEqual(roots, expected) {
+		t.Errorf("watchRoots() = %v, expected %v", roots, expected)
+	}
+
+	buildCmd, _ := detectProjectStructure()
+	if buildCmd != "go build -o ./tmp/main ./api/cmd/server" {
+		t.Errorf("Expected the workspace module's main package to be built, got %q", buildCmd)
+	}
+
+	// Files in a module outside the project are watched too
+	os.WriteFile(filepath.Join(base, "shared", "util.go"), []byte("package shared\n"), 0644)
+	app := newWindApp(defaultConfig(), "", "")
+	app.scanFiles()
+	if _, ok := app.fileStates[filepath.Join("..", "shared", "util.go")]; !ok {
+		t.Errorf("Expected ../shared/util.go to be watched, got %v", app.fileStates)
+	}
+}
+
+func TestWatchDirs(t *testing.T) {
+	base := t.TempDir()
+	project := filepath.Join(base, "project")
+	for _, dir := range []string{filepath.Join(project, "internal"), filepath.Join(base, "proto")} {
+		os.MkdirAll(dir, 0755)
+	}
+	os.WriteFile(filepath.Join(project, "main.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(base, "proto", "api.proto"), []byte("syntax = \"proto3\";\n"), 0644)
+
+	originalDir, _ := os.Getwd()
+	defer os.Chdir(originalDir)
+	if err := os.Chdir(project); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	config := defaultConfig()
+	config.IncludeExts = append(config.IncludeExts, ".proto")
+	config.WatchDirs = []string{"../proto", "internal", "missing", filepath.Join(base, "proto")}
+	app := newWindApp(config, "", "")
+
+	expected := []string{filepath.Join("..", "proto")}
+	if dirs := app.watchDirs([]string{"."}); !reflect.DeepEqual(dirs, expected) {
+		t.Errorf("watchDirs() = %v, expected %v", dirs, expected)
+	}
+
+	app.scanFiles()
+	protoFile := filepath.Join("..", "proto", "api.proto")
+	if _, ok := app.fileStates[protoFile]; !ok {
+		t.Fatalf("Expected %s to be watched, got %v", protoFile, app.fileStates)
+	}
+
+	later := time.Now().Add(time.Second)
+	os.Chtimes(protoFile, later, later)
+	if !app.checkForChanges() {
+		t.Errorf("Expected a change in %s to be detected", protoFile)
+	}
+}
@@ -0,0 +1,420 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseYAML parses the subset of YAML used by Wind config files: block
+// mappings and sequences, flow collections ([a, b] and {k: v}), quoted and
+// plain scalars, literal (|) and folded (>) block scalars, and comments.
+// Scalars are returned as strings; typing happens when the result is decoded
+// into a config struct.
+func parseYAML(data []byte) (map[string]any, error) {
+	p := &yamlParser{}
+	for i, raw := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		p.lines = append(p.lines, yamlLine{
+			num:    i + 1,
+			raw:    raw,
+			indent: len(raw) - len(strings.TrimLeft(raw, " ")),
+			text:   strings.TrimSpace(stripYAMLComment(raw)),
+		})
+	}
+
+	_, _, ok := p.peek()
+	if !ok {
+		return map[string]any{}, nil
+	}
+
+	indent, _, _ := p.peek()
+	doc, err := p.parseMap(indent)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, ok := p.peek(); ok {
+		line := p.lines[p.pos]
+		return nil, fmt.Errorf("line %d: unexpected indentation", line.num)
+	}
+	return doc, nil
+}
+
+type yamlLine struct {
+	num    int
+	raw    string
+	indent int
+	text   string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// peek returns the next significant line without consuming it
+func (p *yamlParser) peek() (indent int, text string, ok bool) {
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.text != "" && line.text != "---" {
+			return line.indent, line.text, true
+		}
+		p.pos++
+	}
+	return 0, "", false
+}
+
+func isSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func (p *yamlParser) parseBlock(indent int) (any, error) {
+	_, text, _ := p.peek()
+	if isSeqItem(text) {
+		return p.parseSeq(indent)
+	}
+	return p.parseMap(indent)
+}
+
+func (p *yamlParser) parseMap(indent int) (map[string]any, error) {
+	m := map[string]any{}
+	for {
+		ind, text, ok := p.peek()
+		if !ok || ind < indent {
+			return m, nil
+		}
+		line := p.lines[p.pos]
+		if ind > indent || isSeqItem(text) {
+			return nil, fmt.Errorf("line %d: unexpected indentation", line.num)
+		}
+
+		key, rest, ok := splitYAMLKey(text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", line.num)
+		}
+		p.pos++
+
+		var value any
+		var err error
+		switch {
+		case rest == "":
+			nind, ntext, ok := p.peek()
+			if ok && (nind > indent || (nind == indent && isSeqItem(ntext))) {
+				value, err = p.parseBlock(nind)
+			} else {
+				value = ""
+			}
+		case rest == "|" || rest == "|-" || rest == ">" || rest == ">-":
+			value = p.parseBlockScalar(indent, rest)
+		default:
+			value, err = parseYAMLValue(rest)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line.num, err)
+		}
+		m[key] = value
+	}
+}
+
+func (p *yamlParser) parseSeq(indent int) ([]any, error) {
+	var seq []any
+	for {
+		ind, text, ok := p.peek()
+		if !ok || ind != indent || !isSeqItem(text) {
+			return seq, nil
+		}
+		line := p.lines[p.pos]
+		item := strings.TrimSpace(strings.TrimPrefix(text, "-"))
+
+		var value any
+		var err error
+		switch {
+		case item == "":
+			p.pos++
+			nind, _, ok := p.peek()
+			if ok && nind > indent {
+				value, err = p.parseBlock(nind)
+			} else {
+				value = ""
+			}
+		case isMapItem(item):
+			// Re-read "- key: value" as the first line of a mapping
+			// indented to where the key starts
+			itemIndent := ind + strings.Index(line.raw[ind:], item)
+			p.lines[p.pos].indent = itemIndent
+			p.lines[p.pos].text = item
+			value, err = p.parseMap(itemIndent)
+		default:
+			p.pos++
+			value, err = parseYAMLValue(item)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line.num, err)
+		}
+		seq = append(seq, value)
+	}
+}
+
+// parseBlockScalar reads the lines of a | or > scalar indented beyond indent
+func (p *yamlParser) parseBlockScalar(indent int, style string) string {
+	var lines []string
+	blockIndent := -1
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if strings.TrimSpace(line.raw) == "" {
+			lines = append(lines, "")
+			p.pos++
+			continue
+		}
+		if line.indent <= indent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = line.indent
+		}
+		lines = append(lines, line.raw[min(blockIndent, line.indent):])
+		p.pos++
+	}
+
+	// Trailing blank lines belong to whatever follows the block
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	sep := "\n"
+	if strings.HasPrefix(style, ">") {
+		sep = " "
+	}
+	value := strings.Join(lines, sep)
+	if !strings.HasSuffix(style, "-") && value != "" {
+		value += "\n"
+	}
+	return value
+}
+
+func isMapItem(text string) bool {
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		return false
+	}
+	_, _, ok := splitYAMLKey(text)
+	return ok
+}
+
+// splitYAMLKey splits "key: rest" at the first colon outside quotes that is
+// followed by a space or ends the line
+func splitYAMLKey(text string) (key, rest string, ok bool) {
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case (c == '"' || c == '\'') && opensYAMLQuote(text, i):
+			i = closingYAMLQuote(text, i)
+		case c == ':' && (i+1 == len(text) || text[i+1] == ' '):
+			key = strings.TrimSpace(text[:i])
+			if unquoted, err := unquoteYAML(key); err == nil {
+				key = unquoted
+			}
+			return key, strings.TrimSpace(text[i+1:]), key != ""
+		}
+	}
+	return "", "", false
+}
+
+// stripYAMLComment removes a trailing # comment that is outside quoted
+// scalars
+func stripYAMLComment(line string) string {
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case (c == '"' || c == '\'') && opensYAMLQuote(line, i):
+			i = closingYAMLQuote(line, i)
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// opensYAMLQuote reports whether the quote at text[i] starts a quoted
+// scalar. Elsewhere, as in don't, it is part of a plain scalar.
+func opensYAMLQuote(text string, i int) bool {
+	before := strings.TrimRight(text[:i], " \t")
+	return before == "" || strings.ContainsRune(":-[{,", rune(before[len(before)-1]))
+}
+
+// closingYAMLQuote returns the index of the quote that ends the scalar
+// quoted at text[start], or len(text) when it does not end on this line
+func closingYAMLQuote(text string, start int) int {
+	quote := text[start]
+	for i := start + 1; i < len(text); i++ {
+		switch {
+		case quote == '"' && text[i] == '\\':
+			i++
+		case quote == '\'' && text[i] == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			// '' is an escaped quote
+			i++
+		case text[i] == quote:
+			return i
+		}
+	}
+	return len(text)
+}
+
+func unquoteYAML(s string) (string, error) {
+	if len(s) >= 2 {
+		switch {
+		case s[0] == '"' && s[len(s)-1] == '"':
+			return strconv.Unquote(s)
+		case s[0] == '\'' && s[len(s)-1] == '\'':
+			return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+		}
+	}
+	return s, fmt.Errorf("not quoted")
+}
+
+// parseYAMLValue parses an inline value: a flow collection or a scalar
+func parseYAMLValue(s string) (any, error) {
+	f := &yamlFlow{s: s}
+	value, err := f.value()
+	if err != nil {
+		return nil, err
+	}
+	f.skipSpace()
+	if f.pos != len(f.s) {
+		return nil, fmt.Errorf("unexpected %q after value", f.s[f.pos:])
+	}
+	return value, nil
+}
+
+type yamlFlow struct {
+	s   string
+	pos int
+	// depth tracks flow nesting; plain scalars inside a collection end at
+	// the collection's delimiters
+	depth int
+}
+
+func (f *yamlFlow) skipSpace() {
+	for f.pos < len(f.s) && f.s[f.pos] == ' ' {
+		f.pos++
+	}
+}
+
+func (f *yamlFlow) value() (any, error) {
+	f.skipSpace()
+	if f.pos >= len(f.s) {
+		return "", nil
+	}
+
+	switch f.s[f.pos] {
+	case '[':
+		f.pos++
+		f.depth++
+		seq := []any{}
+		for {
+			f.skipSpace()
+			if f.pos < len(f.s) && f.s[f.pos] == ']' {
+				f.pos++
+				f.depth--
+				return seq, nil
+			}
+			item, err := f.value()
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, item)
+			if err := f.separator(']'); err != nil {
+				return nil, err
+			}
+		}
+
+	case '{':
+		f.pos++
+		f.depth++
+		m := map[string]any{}
+		for {
+			f.skipSpace()
+			if f.pos < len(f.s) && f.s[f.pos] == '}' {
+				f.pos++
+				f.depth--
+				return m, nil
+			}
+			key, err := f.scalar(true)
+			if err != nil {
+				return nil, err
+			}
+			f.skipSpace()
+			if f.pos >= len(f.s) || f.s[f.pos] != ':' {
+				return nil, fmt.Errorf("expected ':' after key %q", key)
+			}
+			f.pos++
+			value, err := f.value()
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+			if err := f.separator('}'); err != nil {
+				return nil, err
+			}
+		}
+
+	default:
+		return f.scalar(false)
+	}
+}
+
+// separator consumes a ',' or leaves the closing delimiter for the caller
+func (f *yamlFlow) separator(closing byte) error {
+	f.skipSpace()
+	if f.pos >= len(f.s) {
+		return fmt.Errorf("missing '%c'", closing)
+	}
+	switch f.s[f.pos] {
+	case ',':
+		f.pos++
+		return nil
+	case closing:
+		return nil
+	}
+	return fmt.Errorf("unexpected %q", f.s[f.pos])
+}
+
+func (f *yamlFlow) scalar(isKey bool) (string, error) {
+	f.skipSpace()
+	if f.pos < len(f.s) && (f.s[f.pos] == '"' || f.s[f.pos] == '\'') {
+		quote := f.s[f.pos]
+		end := f.pos + 1
+		for end < len(f.s) {
+			if f.s[end] == '\\' && quote == '"' {
+				end += 2
+				continue
+			}
+			if f.s[end] == quote {
+				if quote == '\'' && end+1 < len(f.s) && f.s[end+1] == '\'' {
+					end += 2
+					continue
+				}
+				break
+			}
+			end++
+		}
+		if end >= len(f.s) {
+			return "", fmt.Errorf("unterminated string")
+		}
+		value, err := unquoteYAML(f.s[f.pos : end+1])
+		f.pos = end + 1
+		return value, err
+	}
+
+	start := f.pos
+	if f.depth == 0 && !isKey {
+		f.pos = len(f.s)
+		return strings.TrimSpace(f.s[start:]), nil
+	}
+	for f.pos < len(f.s) {
+		c := f.s[f.pos]
+		if c == ',' || c == ']' || c == '}' || (isKey && c == ':') {
+			break
+		}
+		f.pos++
+	}
+	return strings.TrimSpace(f.s[start:f.pos]), nil
+}
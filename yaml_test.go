@@ -0,0 +1,65 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	input := `# Wind config
+buildCmd: go build -o ./tmp/main ./cmd/api   # trailing comment
+runCmd: "./tmp/main --port=9090"
+excludeDirs:
+  - vendor
+  - 'gen # not a comment'
+  - 'it''s # quoted'
+message: don't restart # comment
+it's: "a \" # b" # comment
+includeExts: [.go, .tmpl]
+env: {APP_ENV: dev, DEBUG: "1"}
+targets:
+  - name: api
+    buildCmd: go build ./cmd/api
+  - name: worker
+script: |
+  echo one
+  echo two
+`
+
+	expected := map[string]any{
+		"buildCmd":    "go build -o ./tmp/main ./cmd/api",
+		"runCmd":      "./tmp/main --port=9090",
+		"excludeDirs": []any{"vendor", "gen # not a comment", "it's # quoted"},
+		"message":     "don't restart",
+		"it's":        "a \" # b",
+		"includeExts": []any{".go", ".tmpl"},
+		"env":         map[string]any{"APP_ENV": "dev", "DEBUG": "1"},
+		"targets": []any{
+			map[string]any{"name": "api", "buildCmd": "go build ./cmd/api"},
+			map[string]any{"name": "worker"},
+		},
+		"script": "echo one\necho two\n",
+	}
+
+	doc, err := parseYAML([]byte(input))
+	if err != nil {
+		t.Fatalf("parseYAML failed: %v", err)
+	}
+	if !reflect.DeepEqual(doc, expected) {
+		t.Errorf("parseYAML() =\n%#v\nexpected\n%#v", doc, expected)
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []string{
+		"key value",
+		"a: 1\n   b: 2",
+		"list: [a, b",
+	}
+
+	for _, input := range tests {
+		if _, err := parseYAML([]byte(input)); err == nil {
+			t.Errorf("Expected an error parsing %q", input)
+		}
+	}
+}